@@ -12,10 +12,16 @@ import (
 
 	"synthezia/internal/api"
 	"synthezia/internal/auth"
+	"synthezia/internal/backup"
 	"synthezia/internal/config"
 	"synthezia/internal/database"
+	"synthezia/internal/dropzone"
 	"synthezia/internal/queue"
+	"synthezia/internal/recovery"
+	"synthezia/internal/search"
 	"synthezia/internal/transcription"
+	"synthezia/internal/voices"
+	"synthezia/internal/webhooks"
 	"synthezia/pkg/logger"
 
 	_ "synthezia/api-docs"                        // Import generated Swagger docs
@@ -54,8 +60,10 @@ var (
 // @description JWT token with Bearer prefix
 
 func main() {
-	// Handle version flag
+	// Handle command line flags
 	var showVersion = flag.Bool("version", false, "Show version information")
+	var migrateOnly = flag.Bool("migrate-only", false, "Apply pending database migrations and exit")
+	var migrateDryRun = flag.Bool("migrate-dry-run", false, "List pending database migrations without applying them and exit")
 	flag.Parse()
 
 	if *showVersion {
@@ -73,6 +81,24 @@ func main() {
 	logger.Startup("config", "Loading configuration")
 	cfg := config.Load()
 
+	// Only migrate the database when asked to
+	if *migrateOnly || *migrateDryRun {
+		if err := runMigrations(cfg, *migrateDryRun); err != nil {
+			logger.Error("Database migration failed", "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Backup and restore run as subcommands and exit
+	if flag.NArg() > 0 {
+		if err := runCommand(cfg, flag.Args()); err != nil {
+			logger.Error("Command failed", "command", flag.Arg(0), "error", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
 	// Initialize database
 	logger.Startup("database", "Connecting to database")
 	if err := database.Initialize(cfg); err != nil {
@@ -80,6 +106,16 @@ func main() {
 		os.Exit(1)
 	}
 	defer database.Close()
+	if version, err := database.SchemaVersion(); err == nil {
+		logger.Debug("Database schema is up to date", "version", version)
+	}
+
+	// Index any completed transcripts missing from the full-text search index
+	if indexed, err := search.BackfillIndex(); err != nil {
+		logger.Warn("Failed to backfill search index", "error", err)
+	} else if indexed > 0 {
+		logger.Info("Indexed existing transcripts for search", "count", indexed)
+	}
 
 	// Initialize authentication service
 	logger.Startup("auth", "Setting up authentication")
@@ -89,6 +125,17 @@ func main() {
 	logger.Startup("transcription", "Initializing transcription service")
 	unifiedProcessor := transcription.NewUnifiedJobProcessor()
 
+	// Reconcile jobs interrupted by a crash or restart before any worker starts
+	logger.Startup("recovery", "Recovering interrupted jobs")
+	if _, err := recovery.Run(recovery.Options{
+		Policy:      cfg.RecoveryPolicy,
+		MaxRequeues: cfg.RecoveryMaxRequeues,
+		UploadDir:   cfg.UploadDir,
+		TempDir:     unifiedProcessor.GetUnifiedService().TempDirectory(),
+	}); err != nil {
+		logger.Error("Crash recovery failed", "error", err)
+	}
+
 	// Bootstrap embedded Python environment (for all adapters)
 	logger.Startup("python", "Preparing Python environment")
 	if err := unifiedProcessor.InitEmbeddedPythonEnv(); err != nil {
@@ -112,12 +159,45 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Match diarized speakers against enrolled voices at the configured similarities
+	voices.SetThresholds(voices.Thresholds{
+		Suggest:   cfg.VoiceSuggestThreshold,
+		AutoApply: cfg.VoiceAutoApplyThreshold,
+	})
+
 	// Initialize task queue
 	logger.Startup("queue", "Starting background processing")
 	taskQueue := queue.NewTaskQueue(2, unifiedProcessor) // 2 workers
+	taskQueue.SetRetryPolicy(queue.RetryPolicy{
+		MaxAttempts: cfg.RetryMaxAttempts,
+		Backoff:     time.Duration(cfg.RetryBackoffSeconds) * time.Second,
+		MaxBackoff:  time.Duration(cfg.RetryMaxBackoffSeconds) * time.Second,
+		OOMFallback: cfg.RetryOOMFallback,
+	})
+	taskQueue.SetResourceBudget(queue.ResourceBudget{
+		RAMMB:  cfg.ResourceRAMBudgetMB,
+		VRAMMB: cfg.ResourceVRAMBudgetMB,
+	})
 	taskQueue.Start()
 	defer taskQueue.Stop()
 
+	// Start watching the dropzone for new audio files
+	if cfg.DropzoneEnabled {
+		logger.Startup("dropzone", "Watching dropzone folder")
+		dropzoneService := dropzone.NewService(cfg, taskQueue)
+		if err := dropzoneService.Start(); err != nil {
+			logger.Warn("Failed to start dropzone service", "path", dropzoneService.Path(), "error", err)
+		} else {
+			defer dropzoneService.Stop()
+		}
+	}
+
+	// Start webhook delivery
+	logger.Startup("webhooks", "Starting webhook dispatcher")
+	webhookDispatcher := webhooks.NewDispatcher()
+	webhookDispatcher.Start()
+	defer webhookDispatcher.Stop()
+
 	// Initialize API handlers
 	handler := api.NewHandler(cfg, authService, taskQueue, unifiedProcessor, liveTranscriptionService, quickTranscriptionService)
 
@@ -164,3 +244,99 @@ func main() {
 
 	logger.Info("Server stopped")
 }
+
+// runMigrations applies the pending database migrations, or only lists them in a dry run
+func runMigrations(cfg *config.Config, dryRun bool) error {
+	if err := database.Open(cfg); err != nil {
+		return err
+	}
+	defer database.Close()
+
+	from, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	migrations, err := database.Migrate(database.MigrateOptions{DryRun: dryRun})
+	for _, migration := range migrations {
+		if dryRun {
+			logger.Info("Pending migration", "version", migration.Version, "name", migration.Name)
+		} else {
+			logger.Info("Applied migration", "version", migration.Version, "name", migration.Name)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	to, err := database.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	logger.Info("Database migration finished",
+		"dry_run", dryRun, "from_version", from, "to_version", to, "latest_version", database.LatestSchemaVersion())
+	return nil
+}
+
+// runCommand runs a subcommand: backup writes an archive of the database and uploaded files,
+// restore merges such an archive into this instance. Both work while the server is running.
+func runCommand(cfg *config.Config, args []string) error {
+	switch args[0] {
+	case "backup":
+		flags := flag.NewFlagSet("backup", flag.ExitOnError)
+		output := flags.String("o", "", "Archive to write (default synthezia-backup-<time>.zip)")
+		flags.Parse(args[1:])
+		if *output == "" {
+			*output = fmt.Sprintf("synthezia-backup-%s.zip", time.Now().Format("20060102-150405"))
+		}
+
+		if err := database.Open(cfg); err != nil {
+			return err
+		}
+		defer database.Close()
+
+		snapshot, err := backup.Prepare(backup.Options{UploadDir: cfg.UploadDir, AppVersion: version})
+		if err != nil {
+			return err
+		}
+		defer snapshot.Close()
+		manifest, err := snapshot.WriteFile(*output)
+		if err != nil {
+			return err
+		}
+		logger.Info("Backup written", "path", *output, "files", len(manifest.Files), "missing_files", len(manifest.Missing),
+			"schema_version", manifest.SchemaVersion)
+		return nil
+
+	case "restore":
+		flags := flag.NewFlagSet("restore", flag.ExitOnError)
+		verifyOnly := flags.Bool("verify", false, "Only check the archive against its manifest")
+		flags.Parse(args[1:])
+		if flags.NArg() != 1 {
+			return fmt.Errorf("usage: %s restore [-verify] <archive>", os.Args[0])
+		}
+
+		if *verifyOnly {
+			manifest, err := backup.Verify(flags.Arg(0))
+			if err != nil {
+				return err
+			}
+			logger.Info("Backup verified", "backup_id", manifest.ID, "created_at", manifest.CreatedAt, "files", len(manifest.Files))
+			return nil
+		}
+
+		if err := database.Initialize(cfg); err != nil {
+			return err
+		}
+		defer database.Close()
+
+		result, err := backup.Restore(flags.Arg(0), backup.RestoreOptions{UploadDir: cfg.UploadDir})
+		if err != nil {
+			return err
+		}
+		logger.Info("Backup restored", "backup_id", result.BackupID, "records", result.Records,
+			"files", result.Files, "upload_root", result.UploadRoot, "skipped_rows", result.SkippedRows)
+		return nil
+	}
+	return fmt.Errorf("unknown command %q, expected backup or restore", args[0])
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"synthezia/internal/backup"
+	"synthezia/pkg/logger"
+)
+
+// CreateBackup streams a backup archive of the instance
+// @Summary Download a backup
+// @Description Streams a ZIP archive with a consistent snapshot of the database, the uploaded files it refers to and a manifest with their SHA-256 checksums. Referenced files that can't be found are listed in the manifest as missing. The server keeps running while the backup is taken. The archive can be restored with the restore endpoint or the restore command.
+// @Tags admin
+// @Produce application/zip
+// @Success 200 {file} binary
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/backup [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateBackup(c *gin.Context) {
+	snapshot, err := backup.Prepare(backup.Options{UploadDir: h.config.UploadDir})
+	if err != nil {
+		logger.Error("Failed to snapshot database for backup", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create backup"})
+		return
+	}
+	defer snapshot.Close()
+
+	filename := fmt.Sprintf("synthezia-backup-%s.zip", time.Now().Format("20060102-150405"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	if _, err := snapshot.Write(c.Writer); err != nil {
+		logger.Error("Failed to write backup archive", "error", err)
+	}
+}
+
+// RestoreBackup merges a backup archive into the instance
+// @Summary Restore a backup
+// @Description Verifies an archive made by the backup endpoint or command against its manifest and merges it into this instance in one transaction. Records keep their IDs unless they are taken, users and tags merge with existing ones of the same name, and uploaded files are restored below a folder of their own when their paths are taken.
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param archive formData file true "Backup archive"
+// @Success 200 {object} backup.RestoreResult
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/admin/restore [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) RestoreBackup(c *gin.Context) {
+	file, err := c.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Backup archive is required"})
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "synthezia-upload-")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save backup archive"})
+		return
+	}
+	defer os.RemoveAll(dir)
+	archivePath := filepath.Join(dir, "backup.zip")
+	if err := c.SaveUploadedFile(file, archivePath); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save backup archive"})
+		return
+	}
+
+	result, err := backup.Restore(archivePath, backup.RestoreOptions{UploadDir: h.config.UploadDir})
+	if err != nil {
+		if errors.Is(err, backup.ErrInvalidArchive) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Error("Failed to restore backup", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore backup"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
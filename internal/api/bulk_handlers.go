@@ -0,0 +1,522 @@
+package api
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/export"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+)
+
+// maxBulkJobs caps how many jobs a single bulk action may run on
+const maxBulkJobs = 1000
+
+// Outcomes of a bulk action for one job
+const (
+	BulkStatusOK      = "ok"
+	BulkStatusSkipped = "skipped"
+	BulkStatusError   = "error"
+)
+
+// BulkSelection picks the jobs a bulk action runs on: the listed IDs, or every job matching a ListJobs filter
+type BulkSelection struct {
+	IDs    []string       `json:"ids,omitempty"`
+	Filter *JobListFilter `json:"filter,omitempty"`
+}
+
+// BulkItemResult is the outcome of a bulk action for one job
+type BulkItemResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"` // ok, skipped or error
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResponse reports the outcome of a bulk action for every selected job
+type BulkResponse struct {
+	Results   []BulkItemResult `json:"results"`
+	Succeeded int              `json:"succeeded"`
+	Skipped   int              `json:"skipped"`
+	Failed    int              `json:"failed"`
+}
+
+// BulkDeleteRequest deletes the selected jobs
+type BulkDeleteRequest struct {
+	BulkSelection
+}
+
+// BulkRetranscribeRequest transcribes the selected jobs again
+type BulkRetranscribeRequest struct {
+	BulkSelection
+	ProfileID string  `json:"profile_id,omitempty"` // Parameters to use; each job keeps its own when empty
+	Priority  *string `json:"priority,omitempty"`   // low, normal, high, urgent or a number from -100 to 100
+}
+
+// BulkTagRequest adds tags to and removes tags from the selected jobs
+type BulkTagRequest struct {
+	BulkSelection
+	Add    []string `json:"add,omitempty"` // Tag names; missing tags are created
+	Remove []string `json:"remove,omitempty"`
+}
+
+// BulkMoveRequest moves the selected jobs into a folder
+type BulkMoveRequest struct {
+	BulkSelection
+	FolderID string `json:"folder_id"` // Empty moves the jobs out of any folder
+}
+
+// BulkExportRequest downloads the selected jobs as a ZIP archive
+type BulkExportRequest struct {
+	BulkSelection
+	Formats      []string `json:"formats,omitempty"` // Export formats to include for completed jobs
+	IncludeAudio bool     `json:"include_audio"`
+}
+
+func newBulkResponse(results []BulkItemResult) BulkResponse {
+	response := BulkResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case BulkStatusOK:
+			response.Succeeded++
+		case BulkStatusSkipped:
+			response.Skipped++
+		default:
+			response.Failed++
+		}
+	}
+	return response
+}
+
+// selectBulkJobs loads the jobs of a bulk selection owned by the current user. Listed IDs that
+// don't match a job come back as error results; they don't fail the whole request.
+func selectBulkJobs(c *gin.Context, selection BulkSelection) ([]models.TranscriptionJob, []BulkItemResult, error) {
+	if len(selection.IDs) > 0 && selection.Filter != nil {
+		return nil, nil, fmt.Errorf("use either ids or filter, not both")
+	}
+
+	if selection.Filter != nil {
+		var jobs []models.TranscriptionJob
+		if err := database.DB.Scopes(ownedBy(c), selection.Filter.scope).Order("created_at DESC").Limit(maxBulkJobs + 1).Find(&jobs).Error; err != nil {
+			return nil, nil, err
+		}
+		if len(jobs) > maxBulkJobs {
+			return nil, nil, fmt.Errorf("filter matches more than %d jobs", maxBulkJobs)
+		}
+		return jobs, nil, nil
+	}
+
+	var ids []string
+	seen := make(map[string]bool, len(selection.IDs))
+	for _, id := range selection.IDs {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, fmt.Errorf("ids or filter is required")
+	}
+	if len(ids) > maxBulkJobs {
+		return nil, nil, fmt.Errorf("at most %d ids can be given", maxBulkJobs)
+	}
+
+	var found []models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[string]models.TranscriptionJob, len(found))
+	for _, job := range found {
+		byID[job.ID] = job
+	}
+
+	// Keep the order the IDs were given in
+	var jobs []models.TranscriptionJob
+	var missing []BulkItemResult
+	for _, id := range ids {
+		if job, ok := byID[id]; ok {
+			jobs = append(jobs, job)
+		} else {
+			missing = append(missing, BulkItemResult{ID: id, Status: BulkStatusError, Error: "Job not found"})
+		}
+	}
+	return jobs, missing, nil
+}
+
+// bindBulkRequest binds a bulk request and loads its jobs, writing a response on failure
+func bindBulkRequest(c *gin.Context, req interface{}, selection *BulkSelection) ([]models.TranscriptionJob, []BulkItemResult, bool) {
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return nil, nil, false
+	}
+	jobs, results, err := selectBulkJobs(c, *selection)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+	return jobs, results, true
+}
+
+// BulkDeleteJobs deletes several transcription jobs
+// @Summary Delete transcriptions in bulk
+// @Description Delete the jobs listed in ids, or every job matching filter (at most 1000), with their files. Jobs that are processing are skipped. The outcome is reported per job.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param request body BulkDeleteRequest true "Jobs to delete"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/transcription/bulk/delete [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) BulkDeleteJobs(c *gin.Context) {
+	var req BulkDeleteRequest
+	jobs, results, ok := bindBulkRequest(c, &req, &req.BulkSelection)
+	if !ok {
+		return
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Status == models.StatusProcessing {
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusSkipped, Error: "Cannot delete job that is currently processing"})
+			continue
+		}
+		if err := deleteJob(job); err != nil {
+			logger.Error("Failed to delete job", "job_id", job.ID, "error", err)
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusError, Error: "Failed to delete job from database"})
+			continue
+		}
+		results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusOK})
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// BulkRetranscribeJobs queues several transcription jobs to be transcribed again
+// @Summary Re-transcribe in bulk
+// @Description Queue the jobs listed in ids, or every job matching filter (at most 1000), for a fresh transcription. With profile_id every job uses the profile's parameters, otherwise each job keeps its own. Jobs that are pending or processing are skipped. The outcome is reported per job.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param request body BulkRetranscribeRequest true "Jobs and parameters"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/bulk/retranscribe [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) BulkRetranscribeJobs(c *gin.Context) {
+	var req BulkRetranscribeRequest
+	jobs, results, ok := bindBulkRequest(c, &req, &req.BulkSelection)
+	if !ok {
+		return
+	}
+
+	var profile *models.TranscriptionProfile
+	if req.ProfileID != "" {
+		profile = &models.TranscriptionProfile{}
+		if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", req.ProfileID).First(profile).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get profile"})
+			return
+		}
+	}
+
+	var priority *int
+	if req.Priority != nil {
+		value, err := parsePriority(*req.Priority)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		priority = &value
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if job.Status != models.StatusUploaded && job.Status != models.StatusCompleted && job.Status != models.StatusFailed {
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusSkipped, Error: "Job is currently processing or pending"})
+			continue
+		}
+
+		params := job.Parameters
+		if profile != nil {
+			params = profile.Parameters
+		}
+		if err := validateTranscriptionParams(job, params); err != nil {
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusError, Error: err.Error()})
+			continue
+		}
+		if priority != nil {
+			job.Priority = *priority
+		}
+		if err := h.queueTranscription(job, params); err != nil {
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusError, Error: err.Error()})
+			continue
+		}
+		results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusOK})
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// BulkTagJobs adds tags to and removes tags from several transcription jobs
+// @Summary Tag transcriptions in bulk
+// @Description Add and remove tags, by name, on the jobs listed in ids or every job matching filter (at most 1000). Tags that don't exist yet are created. The outcome is reported per job.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param request body BulkTagRequest true "Jobs and tags"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/transcription/bulk/tags [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) BulkTagJobs(c *gin.Context) {
+	var req BulkTagRequest
+	jobs, results, ok := bindBulkRequest(c, &req, &req.BulkSelection)
+	if !ok {
+		return
+	}
+
+	add, err := normalizeTagNames(req.Add)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	remove, err := normalizeTagNames(req.Remove)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "add or remove is required"})
+		return
+	}
+
+	addTags, err := findTags(currentUserID(c), add, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tags"})
+		return
+	}
+	removeTags, err := findTags(currentUserID(c), remove, false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tags"})
+		return
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if len(addTags) > 0 {
+				if err := tx.Model(job).Association("Tags").Append(addTags); err != nil {
+					return err
+				}
+			}
+			if len(removeTags) > 0 {
+				if err := tx.Model(job).Association("Tags").Delete(removeTags); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to tag job", "job_id", job.ID, "error", err)
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusError, Error: "Failed to update tags"})
+			continue
+		}
+		results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusOK})
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// BulkMoveJobs moves several transcription jobs into a folder
+// @Summary Move transcriptions in bulk
+// @Description Move the jobs listed in ids, or every job matching filter (at most 1000), into a folder, or out of any folder with an empty folder_id. The outcome is reported per job.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param request body BulkMoveRequest true "Jobs and folder"
+// @Success 200 {object} BulkResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/bulk/move [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) BulkMoveJobs(c *gin.Context) {
+	var req BulkMoveRequest
+	jobs, results, ok := bindBulkRequest(c, &req, &req.BulkSelection)
+	if !ok {
+		return
+	}
+
+	var folderID *string
+	if req.FolderID != "" {
+		if _, ok := findOwnedFolder(c, req.FolderID); !ok {
+			return
+		}
+		folderID = &req.FolderID
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if err := database.DB.Model(job).Update("folder_id", folderID).Error; err != nil {
+			logger.Error("Failed to move job", "job_id", job.ID, "error", err)
+			results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusError, Error: "Failed to move job"})
+			continue
+		}
+		results = append(results, BulkItemResult{ID: job.ID, Status: BulkStatusOK})
+	}
+
+	c.JSON(http.StatusOK, newBulkResponse(results))
+}
+
+// BulkExportJobs streams several transcription jobs as a ZIP archive
+// @Summary Export transcriptions in bulk
+// @Description Download the jobs listed in ids, or every job matching filter (at most 1000), as a ZIP archive with one folder per job holding its audio and the requested export formats. Jobs that aren't completed only get their audio. results.json at the root of the archive reports the outcome per job.
+// @Tags transcription
+// @Accept json
+// @Produce application/zip
+// @Param request body BulkExportRequest true "Jobs and formats"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/transcription/bulk/export [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) BulkExportJobs(c *gin.Context) {
+	var req BulkExportRequest
+	jobs, results, ok := bindBulkRequest(c, &req, &req.BulkSelection)
+	if !ok {
+		return
+	}
+
+	formats := make([]string, 0, len(req.Formats))
+	for _, format := range req.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if !export.IsSupported(format) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":             fmt.Sprintf("Unsupported export format: %s", format),
+				"supported_formats": export.SupportedFormats,
+			})
+			return
+		}
+		formats = append(formats, format)
+	}
+	if len(formats) == 0 && !req.IncludeAudio {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "formats or include_audio is required"})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="transcriptions.zip"`)
+	c.Status(http.StatusOK)
+
+	archive := zip.NewWriter(c.Writer)
+	folders := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		// Jobs with the same title get folders of their own
+		folder := exportBaseName(job)
+		if folders[folder] {
+			folder = folder + "-" + job.ID
+		}
+		folders[folder] = true
+
+		results = append(results, writeJobToArchive(archive, folder, job, formats, req.IncludeAudio))
+	}
+
+	manifest, _ := json.MarshalIndent(newBulkResponse(results), "", "  ")
+	if w, err := archive.Create("results.json"); err == nil {
+		w.Write(manifest)
+	}
+	if err := archive.Close(); err != nil {
+		logger.Warn("Failed to finish bulk export archive", "error", err)
+	}
+}
+
+// writeJobToArchive adds the audio and transcript exports of a job to a folder of the archive
+func writeJobToArchive(archive *zip.Writer, folder string, job models.TranscriptionJob, formats []string, includeAudio bool) BulkItemResult {
+	result := BulkItemResult{ID: job.ID, Status: BulkStatusOK}
+	var problems []string
+
+	if includeAudio {
+		audioPath := job.AudioPath
+		if job.IsMultiTrack && job.MergedAudioPath != nil && *job.MergedAudioPath != "" {
+			audioPath = *job.MergedAudioPath
+		}
+		if err := copyFileToArchive(archive, folder+"/"+filepath.Base(audioPath), audioPath); err != nil {
+			problems = append(problems, "audio: "+err.Error())
+		}
+	}
+
+	if len(formats) > 0 {
+		if job.Status != models.StatusCompleted || job.Transcript == nil {
+			if len(problems) == 0 && !includeAudio {
+				result.Status = BulkStatusSkipped
+			}
+			problems = append(problems, fmt.Sprintf("Job not completed, current status: %s", job.Status))
+		} else {
+			var transcript interfaces.TranscriptResult
+			opts, err := exportOptions(job)
+			if err == nil {
+				err = json.Unmarshal([]byte(*job.Transcript), &transcript)
+			}
+			if err != nil {
+				problems = append(problems, "Failed to read transcript")
+			} else {
+				for _, format := range formats {
+					output, err := export.Render(format, &transcript, opts)
+					if err == nil {
+						var w io.Writer
+						if w, err = archive.Create(folder + "/" + exportBaseName(job) + "." + output.Extension); err == nil {
+							_, err = w.Write(output.Data)
+						}
+					}
+					if err != nil {
+						problems = append(problems, format+": "+err.Error())
+					}
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		if result.Status == BulkStatusOK {
+			result.Status = BulkStatusError
+		}
+		result.Error = strings.Join(problems, "; ")
+	}
+	return result
+}
+
+func copyFileToArchive(archive *zip.Writer, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("file not found")
+		}
+		return err
+	}
+	defer file.Close()
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, file)
+	return err
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -10,6 +11,8 @@ import (
 	"synthezia/internal/database"
 	"synthezia/internal/llm"
 	"synthezia/internal/models"
+	"synthezia/internal/retrieval"
+	"synthezia/pkg/logger"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -17,9 +20,12 @@ import (
 
 // ChatCreateRequest represents a request to create a new chat session
 type ChatCreateRequest struct {
-	TranscriptionID string `json:"transcription_id" binding:"required"`
-	Model           string `json:"model" binding:"required"`
-	Title           string `json:"title,omitempty"`
+	TranscriptionID  string   `json:"transcription_id,omitempty"`
+	TranscriptionIDs []string `json:"transcription_ids,omitempty"` // Chat over several transcriptions
+	Model            string   `json:"model" binding:"required"`
+	Title            string   `json:"title,omitempty"`
+	Retrieval        string   `json:"retrieval,omitempty"`       // auto (default), full, bm25 or embeddings
+	EmbeddingModel   string   `json:"embedding_model,omitempty"` // Required for embeddings retrieval
 }
 
 // ChatMessageRequest represents a request to send a message
@@ -29,25 +35,30 @@ type ChatMessageRequest struct {
 
 // ChatSessionResponse represents a chat session response
 type ChatSessionResponse struct {
-	ID              string               `json:"id"`
-	TranscriptionID string               `json:"transcription_id"`
-	Title           string               `json:"title"`
-	Model           string               `json:"model"`
-	Provider        string               `json:"provider"`
-	IsActive        bool                 `json:"is_active"`
-	CreatedAt       time.Time            `json:"created_at"`
-	UpdatedAt       time.Time            `json:"updated_at"`
-	MessageCount    int                  `json:"message_count"`
-	LastActivityAt  *time.Time           `json:"last_activity_at,omitempty"`
-	LastMessage     *ChatMessageResponse `json:"last_message,omitempty"`
+	ID               string               `json:"id"`
+	TranscriptionID  string               `json:"transcription_id"`
+	FolderID         *string              `json:"folder_id,omitempty"`         // Set for sessions over a whole folder
+	TranscriptionIDs []string             `json:"transcription_ids,omitempty"` // Every transcript a session over several covers
+	RetrievalMode    string               `json:"retrieval_mode"`
+	EmbeddingModel   string               `json:"embedding_model,omitempty"`
+	Title            string               `json:"title"`
+	Model            string               `json:"model"`
+	Provider         string               `json:"provider"`
+	IsActive         bool                 `json:"is_active"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+	MessageCount     int                  `json:"message_count"`
+	LastActivityAt   *time.Time           `json:"last_activity_at,omitempty"`
+	LastMessage      *ChatMessageResponse `json:"last_message,omitempty"`
 }
 
 // ChatMessageResponse represents a chat message response
 type ChatMessageResponse struct {
-	ID        uint      `json:"id"`
-	Role      string    `json:"role"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint              `json:"id"`
+	Role      string            `json:"role"`
+	Content   string            `json:"content"`
+	Citations []models.Citation `json:"citations,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
 }
 
 // ChatModelsResponse represents the available chat models
@@ -115,42 +126,143 @@ func (h *Handler) GetChatModels(c *gin.Context) {
 	c.JSON(http.StatusOK, ChatModelsResponse{Models: models})
 }
 
-// replaceSpeakerLabels replaces speaker labels (e.g., "SPEAKER_00", "speaker_00") in the transcript
-// with custom speaker names from the speaker_mappings table
-func replaceSpeakerLabels(transcriptionID string, transcript string) string {
-	// Fetch speaker mappings for this transcription
-	var mappings []models.SpeakerMapping
-	if err := database.DB.Where("transcription_job_id = ?", transcriptionID).Find(&mappings).Error; err != nil {
-		// If no mappings found or error, return original transcript
-		return transcript
-	}
-
-	// If no mappings, return original
-	if len(mappings) == 0 {
-		return transcript
-	}
-
-	// Replace each speaker label with custom name
-	result := transcript
-	for _, mapping := range mappings {
-		// Replace all variations of the speaker label
-		// Handle "SPEAKER_00:", "speaker_00:", "SPEAKER_00 ", etc.
-		original := mapping.OriginalSpeaker
-		custom := mapping.CustomName
-		
-		// Replace with colon (most common in transcripts)
-		result = strings.ReplaceAll(result, original+":", custom+":")
-		// Replace standalone occurrences with space after
-		result = strings.ReplaceAll(result, original+" ", custom+" ")
-		// Replace at end of line
-		result = strings.ReplaceAll(result, original+"\n", custom+"\n")
-	}
-
-	return result
+// maxChatTranscriptions caps how many transcriptions a chat session can be created over
+const maxChatTranscriptions = 50
+
+// chatRetrievalMode validates the retrieval settings of a new chat session and returns the mode to store
+func chatRetrievalMode(mode, embeddingModel string) (string, error) {
+	if mode == "" {
+		mode = retrieval.ModeAuto
+	}
+	if !retrieval.ValidMode(mode) {
+		return "", fmt.Errorf("retrieval must be one of auto, full, bm25 or embeddings")
+	}
+	if mode == retrieval.ModeEmbeddings && embeddingModel == "" {
+		return "", fmt.Errorf("embedding_model is required for embeddings retrieval")
+	}
+	return mode, nil
+}
+
+// newChatSessionResponse builds the response for a chat session
+func newChatSessionResponse(session models.ChatSession, transcriptionIDs []string) ChatSessionResponse {
+	return ChatSessionResponse{
+		ID:               session.ID,
+		TranscriptionID:  session.TranscriptionID,
+		FolderID:         session.FolderID,
+		TranscriptionIDs: transcriptionIDs,
+		RetrievalMode:    session.RetrievalMode,
+		EmbeddingModel:   session.EmbeddingModel,
+		Title:            session.Title,
+		Model:            session.Model,
+		Provider:         session.Provider,
+		IsActive:         session.IsActive,
+		CreatedAt:        session.CreatedAt,
+		UpdatedAt:        session.UpdatedAt,
+		MessageCount:     session.MessageCount,
+		LastActivityAt:   session.LastActivityAt,
+	}
+}
+
+// sessionTranscriptionIDs returns the transcriptions each of the given chat sessions covers
+func sessionTranscriptionIDs(sessionIDs []string) map[string][]string {
+	type row struct {
+		ChatSessionID      string
+		TranscriptionJobID string
+	}
+	var rows []row
+	database.DB.Table("chat_session_transcriptions").
+		Select("chat_session_id, transcription_job_id").
+		Where("chat_session_id IN ?", sessionIDs).
+		Scan(&rows)
+
+	ids := make(map[string][]string, len(sessionIDs))
+	for _, r := range rows {
+		ids[r.ChatSessionID] = append(ids[r.ChatSessionID], r.TranscriptionJobID)
+	}
+	return ids
+}
+
+// chatSessionJobs returns the completed transcriptions a chat session covers: the contents of its
+// folder, the transcriptions it was created over, or for older sessions just the one it references
+func chatSessionJobs(db *gorm.DB, session *models.ChatSession) ([]models.TranscriptionJob, error) {
+	if session.FolderID != nil {
+		return folderChatJobs(db, *session.FolderID)
+	}
+
+	var jobs []models.TranscriptionJob
+	if err := db.Where("id IN (SELECT transcription_job_id FROM chat_session_transcriptions WHERE chat_session_id = ?)", session.ID).
+		Where("status = ? AND transcript IS NOT NULL", models.StatusCompleted).
+		Order("created_at ASC").Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		if err := db.Where("id = ? AND transcript IS NOT NULL", session.TranscriptionID).Find(&jobs).Error; err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// retrievalSources describes transcriptions as retrieval sources, with their custom speaker names
+func retrievalSources(jobs []models.TranscriptionJob) []retrieval.Source {
+	sources := make([]retrieval.Source, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Transcript == nil {
+			continue
+		}
+		title := job.ID
+		if job.Title != nil && *job.Title != "" {
+			title = *job.Title
+		}
+
+		speakerNames := make(map[string]string)
+		var mappings []models.SpeakerMapping
+		if err := database.DB.Where("transcription_job_id = ?", job.ID).Find(&mappings).Error; err == nil {
+			for _, mapping := range mappings {
+				speakerNames[mapping.OriginalSpeaker] = mapping.CustomName
+			}
+		}
+
+		sources = append(sources, retrieval.Source{
+			JobID:        job.ID,
+			Title:        title,
+			CreatedAt:    job.CreatedAt,
+			Transcript:   *job.Transcript,
+			SpeakerNames: speakerNames,
+		})
+	}
+	return sources
+}
+
+// reanchorChatSessions prepares the chat sessions of a job that is being deleted. Sessions
+// anchored to the job move to another transcription they cover, and the job is removed from
+// every session covering it. Sessions left without another transcription stay anchored to the
+// job and are deleted with it.
+func reanchorChatSessions(tx *gorm.DB, jobID string) error {
+	var sessions []models.ChatSession
+	if err := tx.Where("transcription_id = ?", jobID).Find(&sessions).Error; err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		jobs, err := chatSessionJobs(tx, &session)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if job.ID == jobID {
+				continue
+			}
+			if err := tx.Model(&session).Updates(map[string]interface{}{"transcription_id": job.ID, "job_id": job.ID}).Error; err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return tx.Exec("DELETE FROM chat_session_transcriptions WHERE transcription_job_id = ?", jobID).Error
 }
 
 // @Summary Create a new chat session
-// @Description Create a new chat session for a transcription
+// @Description Create a new chat session over one or more transcriptions. Answers are based on transcript passages picked by the retrieval mode: auto sends whole transcripts when they are short enough and uses BM25 keyword retrieval otherwise, full always sends whole transcripts, bm25 always retrieves, and embeddings ranks passages by embedding similarity using embedding_model through the configured LLM provider. Answers cite passages, which are resolved to job IDs and timestamps.
 // @Tags chat
 // @Accept json
 // @Produce json
@@ -169,25 +281,49 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 		return
 	}
 
-	// Verify transcription exists and has completed transcript
-	var transcription models.TranscriptionJob
-	if err := database.DB.Where("id = ?", req.TranscriptionID).First(&transcription).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
-			return
+	// Sessions cover one transcription or several; the first one anchors the session
+	ids := make([]string, 0, len(req.TranscriptionIDs)+1)
+	seen := make(map[string]bool)
+	for _, id := range append([]string{req.TranscriptionID}, req.TranscriptionIDs...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transcription"})
+	}
+	if len(ids) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "transcription_id or transcription_ids is required"})
+		return
+	}
+	if len(ids) > maxChatTranscriptions {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A chat session can cover at most %d transcriptions", maxChatTranscriptions)})
+		return
+	}
+
+	retrievalMode, err := chatRetrievalMode(req.Retrieval, req.EmbeddingModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if transcription.Status != models.StatusCompleted || transcription.Transcript == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Transcription must be completed to create a chat session"})
+	// Verify transcriptions exist and have completed transcripts
+	var transcriptions []models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id IN ?", ids).Find(&transcriptions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transcription"})
+		return
+	}
+	if len(transcriptions) != len(ids) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
 		return
 	}
+	for _, transcription := range transcriptions {
+		if transcription.Status != models.StatusCompleted || transcription.Transcript == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Transcription must be completed to create a chat session"})
+			return
+		}
+	}
 
 	// Verify LLM service is available
-	_, _, err := h.getLLMService()
-	if err != nil {
+	if _, _, err := h.getLLMService(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -200,14 +336,18 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 
 	now := time.Now()
 	chatSession := models.ChatSession{
-		JobID:           req.TranscriptionID, // Use same ID for JobID as TranscriptionID
-		TranscriptionID: req.TranscriptionID,
+		JobID:           ids[0], // Use same ID for JobID as TranscriptionID
+		TranscriptionID: ids[0],
+		UserID:          currentUserID(c),
 		Title:           title,
 		Model:           req.Model,
 		Provider:        "openai",
+		RetrievalMode:   retrievalMode,
+		EmbeddingModel:  req.EmbeddingModel,
 		MessageCount:    0,
 		LastActivityAt:  &now,
 		IsActive:        true,
+		Transcriptions:  transcriptions,
 	}
 
 	if err := database.DB.Create(&chatSession).Error; err != nil {
@@ -215,20 +355,7 @@ func (h *Handler) CreateChatSession(c *gin.Context) {
 		return
 	}
 
-	response := ChatSessionResponse{
-		ID:              chatSession.ID,
-		TranscriptionID: chatSession.TranscriptionID,
-		Title:           chatSession.Title,
-		Model:           chatSession.Model,
-		Provider:        chatSession.Provider,
-		IsActive:        chatSession.IsActive,
-		CreatedAt:       chatSession.CreatedAt,
-		UpdatedAt:       chatSession.UpdatedAt,
-		MessageCount:    chatSession.MessageCount,
-		LastActivityAt:  chatSession.LastActivityAt,
-	}
-
-	c.JSON(http.StatusCreated, response)
+	c.JSON(http.StatusCreated, newChatSessionResponse(chatSession, sessionTranscriptionIDs([]string{chatSession.ID})[chatSession.ID]))
 }
 
 // @Summary Get chat sessions for a transcription
@@ -250,12 +377,19 @@ func (h *Handler) GetChatSessions(c *gin.Context) {
 	}
 
 	var sessions []models.ChatSession
-	if err := database.DB.Where("transcription_id = ?", transcriptionID).
+	if err := database.DB.Scopes(ownedBy(c)).
+		Where("transcription_id = ? OR id IN (SELECT chat_session_id FROM chat_session_transcriptions WHERE transcription_job_id = ?)", transcriptionID, transcriptionID).
+		Where("folder_id IS NULL").
 		Order("updated_at DESC").Find(&sessions).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat sessions"})
 		return
 	}
 
+	c.JSON(http.StatusOK, chatSessionResponses(sessions))
+}
+
+// chatSessionResponses builds the responses for a list of chat sessions with their message counts and last messages
+func chatSessionResponses(sessions []models.ChatSession) []ChatSessionResponse {
 	// Extract session IDs for batch queries
 	sessionIDs := make([]string, len(sessions))
 	for i, session := range sessions {
@@ -303,24 +437,17 @@ func (h *Handler) GetChatSessions(c *gin.Context) {
 		}
 	}
 
+	transcriptionIDs := sessionTranscriptionIDs(sessionIDs)
+
 	var responses []ChatSessionResponse
 	for _, session := range sessions {
-		responses = append(responses, ChatSessionResponse{
-			ID:              session.ID,
-			TranscriptionID: session.TranscriptionID,
-			Title:           session.Title,
-			Model:           session.Model,
-			Provider:        session.Provider,
-			IsActive:        session.IsActive,
-			CreatedAt:       session.CreatedAt,
-			UpdatedAt:       session.UpdatedAt,
-			MessageCount:    int(messageCountMap[session.ID]), // Use batch-loaded count
-			LastActivityAt:  session.LastActivityAt,
-			LastMessage:     lastMessageMap[session.ID], // Use batch-loaded last message
-		})
+		response := newChatSessionResponse(session, transcriptionIDs[session.ID])
+		response.MessageCount = int(messageCountMap[session.ID]) // Use batch-loaded count
+		response.LastMessage = lastMessageMap[session.ID]        // Use batch-loaded last message
+		responses = append(responses, response)
 	}
 
-	c.JSON(http.StatusOK, responses)
+	return responses
 }
 
 // @Summary Get a chat session with messages
@@ -342,7 +469,7 @@ func (h *Handler) GetChatSession(c *gin.Context) {
 	}
 
 	var session models.ChatSession
-	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
 			return
@@ -364,31 +491,22 @@ func (h *Handler) GetChatSession(c *gin.Context) {
 			ID:        msg.ID,
 			Role:      msg.Role,
 			Content:   msg.Content,
+			Citations: msg.Citations,
 			CreatedAt: msg.CreatedAt,
 		})
 	}
 
 	response := ChatSessionWithMessages{
-		ChatSessionResponse: ChatSessionResponse{
-			ID:              session.ID,
-			TranscriptionID: session.TranscriptionID,
-			Title:           session.Title,
-			Model:           session.Model,
-			Provider:        session.Provider,
-			IsActive:        session.IsActive,
-			CreatedAt:       session.CreatedAt,
-			UpdatedAt:       session.UpdatedAt,
-			MessageCount:    len(messageResponses),
-			LastActivityAt:  session.LastActivityAt,
-		},
-		Messages: messageResponses,
+		ChatSessionResponse: newChatSessionResponse(session, sessionTranscriptionIDs([]string{session.ID})[session.ID]),
+		Messages:            messageResponses,
 	}
+	response.MessageCount = len(messageResponses)
 
 	c.JSON(http.StatusOK, response)
 }
 
 // @Summary Send a message to a chat session
-// @Description Send a message to a chat session and get streaming response
+// @Description Send a message to a chat session and get streaming response. The passages sent to the model are listed in the X-Chat-Sources header, and the citations found in the answer are saved on the assistant message.
 // @Tags chat
 // @Accept json
 // @Produce text/plain
@@ -416,7 +534,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 
 	// Get chat session
 	var session models.ChatSession
-	if err := database.DB.Preload("Transcription").Where("id = ?", sessionID).First(&session).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
 			return
@@ -461,14 +579,31 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 	// Build OpenAI messages including transcript context
 	var openaiMessages []llm.ChatMessage
 
-	// Add system message with transcript context
-	if session.Transcription.Transcript != nil {
-		// Replace speaker labels with custom names if available
-		transcriptText := replaceSpeakerLabels(session.TranscriptionID, *session.Transcription.Transcript)
-		systemContent := fmt.Sprintf("You are a helpful assistant analyzing this transcript. Please answer questions and provide insights based on the following transcript:\n\n%s", transcriptText)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// Add system message with the transcript passages relevant to the question
+	jobs, err := chatSessionJobs(database.DB, &session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load transcripts"})
+		return
+	}
+	var passages []retrieval.Passage
+	if sources := retrievalSources(jobs); len(sources) > 0 {
+		embedder, _ := svc.(llm.Embedder)
+		passages, _, err = retrieval.Retrieve(ctx, sources, req.Content, retrieval.Options{
+			Mode:           session.RetrievalMode,
+			EmbeddingModel: session.EmbeddingModel,
+			Embedder:       embedder,
+		})
+		if err != nil {
+			logger.Error("Failed to retrieve transcript passages", "session_id", sessionID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve transcript passages"})
+			return
+		}
 		openaiMessages = append(openaiMessages, llm.ChatMessage{
 			Role:    "system",
-			Content: systemContent,
+			Content: retrieval.SystemPrompt(sources, passages),
 		})
 	}
 
@@ -486,9 +621,16 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 	c.Header("Connection", "keep-alive")
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	// The passages the answer can cite, so that clients can resolve [S1] style labels while streaming
+	sources := make([]models.Citation, 0, len(passages))
+	for _, passage := range passages {
+		sources = append(sources, passage.Citation())
+	}
+	if data, err := json.Marshal(sources); err == nil {
+		c.Header("X-Chat-Sources", string(data))
+	}
+
 	// Stream the response
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
 
 	// Use model defaults: do not set temperature explicitly
 	contentChan, errorChan := svc.ChatCompletionStream(ctx, session.Model, openaiMessages, 0.0)
@@ -505,6 +647,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 						ChatSessionID: sessionID,
 						Role:          "assistant",
 						Content:       assistantResponse.String(),
+						Citations:     retrieval.Citations(assistantResponse.String(), passages),
 					}
 					database.DB.Create(&assistantMessage)
 
@@ -546,6 +689,7 @@ func (h *Handler) SendChatMessage(c *gin.Context) {
 							ChatSessionID: sessionID,
 							Role:          "assistant",
 							Content:       assistantResponse.String(),
+							Citations:     retrieval.Citations(assistantResponse.String(), passages),
 						}
 						database.DB.Create(&assistantMessage)
 
@@ -604,7 +748,7 @@ func (h *Handler) UpdateChatSessionTitle(c *gin.Context) {
 	}
 
 	var session models.ChatSession
-	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
 			return
@@ -619,18 +763,7 @@ func (h *Handler) UpdateChatSessionTitle(c *gin.Context) {
 		return
 	}
 
-	response := ChatSessionResponse{
-		ID:              session.ID,
-		TranscriptionID: session.TranscriptionID,
-		Title:           session.Title,
-		Model:           session.Model,
-		Provider:        session.Provider,
-		IsActive:        session.IsActive,
-		CreatedAt:       session.CreatedAt,
-		UpdatedAt:       session.UpdatedAt,
-		MessageCount:    session.MessageCount,
-		LastActivityAt:  session.LastActivityAt,
-	}
+	response := newChatSessionResponse(session, sessionTranscriptionIDs([]string{session.ID})[session.ID])
 
 	c.JSON(http.StatusOK, response)
 }
@@ -653,11 +786,25 @@ func (h *Handler) DeleteChatSession(c *gin.Context) {
 		return
 	}
 
+	var count int64
+	if err := database.DB.Model(&models.ChatSession{}).Scopes(ownedBy(c)).Where("id = ?", sessionID).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat session"})
+		return
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
+		return
+	}
+
 	// Delete messages first (due to foreign key constraint)
 	if err := database.DB.Where("chat_session_id = ?", sessionID).Delete(&models.ChatMessage{}).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete messages"})
 		return
 	}
+	if err := database.DB.Exec("DELETE FROM chat_session_transcriptions WHERE chat_session_id = ?", sessionID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chat session"})
+		return
+	}
 
 	// Delete session
 	result := database.DB.Where("id = ?", sessionID).Delete(&models.ChatSession{})
@@ -715,7 +862,7 @@ func (h *Handler) AutoGenerateChatTitle(c *gin.Context) {
 
 	// Load session
 	var session models.ChatSession
-	if err := database.DB.Where("id = ?", sessionID).First(&session).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", sessionID).First(&session).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Chat session not found"})
 			return
@@ -739,18 +886,7 @@ func (h *Handler) AutoGenerateChatTitle(c *gin.Context) {
 
 	if !isDefaultTitle {
 		// Respect user-edited titles; return current session response
-		c.JSON(http.StatusOK, ChatSessionResponse{
-			ID:              session.ID,
-			TranscriptionID: session.TranscriptionID,
-			Title:           session.Title,
-			Model:           session.Model,
-			Provider:        session.Provider,
-			IsActive:        session.IsActive,
-			CreatedAt:       session.CreatedAt,
-			UpdatedAt:       session.UpdatedAt,
-			MessageCount:    session.MessageCount,
-			LastActivityAt:  session.LastActivityAt,
-		})
+		c.JSON(http.StatusOK, newChatSessionResponse(session, sessionTranscriptionIDs([]string{session.ID})[session.ID]))
 		return
 	}
 
@@ -853,16 +989,5 @@ Return only the title, nothing else.`
 		return
 	}
 
-	c.JSON(http.StatusOK, ChatSessionResponse{
-		ID:              updated.ID,
-		TranscriptionID: updated.TranscriptionID,
-		Title:           updated.Title,
-		Model:           updated.Model,
-		Provider:        updated.Provider,
-		IsActive:        updated.IsActive,
-		CreatedAt:       updated.CreatedAt,
-		UpdatedAt:       updated.UpdatedAt,
-		MessageCount:    updated.MessageCount,
-		LastActivityAt:  updated.LastActivityAt,
-	})
+	c.JSON(http.StatusOK, newChatSessionResponse(updated, sessionTranscriptionIDs([]string{updated.ID})[updated.ID]))
 }
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/pipeline"
+)
+
+// ReplacementDictionaryRequest is the payload for creating or updating a replacement dictionary
+type ReplacementDictionaryRequest struct {
+	Name        string                   `json:"name" binding:"required"`
+	Description *string                  `json:"description,omitempty"`
+	Rules       []models.ReplacementRule `json:"rules"`
+}
+
+// findOwnedDictionary loads a replacement dictionary owned by the authenticated user, writing a response on failure
+func findOwnedDictionary(c *gin.Context, id string) (*models.ReplacementDictionary, bool) {
+	var dictionary models.ReplacementDictionary
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", id).First(&dictionary).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Replacement dictionary not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get replacement dictionary"})
+		return nil, false
+	}
+	return &dictionary, true
+}
+
+// validateReplacementDictionaries checks that every listed dictionary exists and belongs to the current user
+func validateReplacementDictionaries(c *gin.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	unique := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		unique[id] = true
+	}
+	var count int64
+	if err := database.DB.Model(&models.ReplacementDictionary{}).Scopes(ownedBy(c)).Where("id IN ?", ids).Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check replacement dictionaries")
+	}
+	if int(count) != len(unique) {
+		return fmt.Errorf("unknown replacement dictionary in replacement_dictionaries")
+	}
+	return nil
+}
+
+// ListReplacementDictionaries returns the replacement dictionaries of the current user
+// @Summary List replacement dictionaries
+// @Description Get all replacement dictionaries owned by the current user
+// @Tags dictionaries
+// @Produce json
+// @Success 200 {array} models.ReplacementDictionary
+// @Router /api/v1/dictionaries [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListReplacementDictionaries(c *gin.Context) {
+	var dictionaries []models.ReplacementDictionary
+	if err := database.DB.Scopes(ownedBy(c)).Order("name ASC").Find(&dictionaries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch replacement dictionaries"})
+		return
+	}
+	c.JSON(http.StatusOK, dictionaries)
+}
+
+// CreateReplacementDictionary creates a replacement dictionary
+// @Summary Create replacement dictionary
+// @Description Create a set of replacement rules. Profiles apply dictionaries listed in their replacement_dictionaries parameter to every transcript, to both segments and words.
+// @Tags dictionaries
+// @Accept json
+// @Produce json
+// @Param request body ReplacementDictionaryRequest true "Dictionary data"
+// @Success 201 {object} models.ReplacementDictionary
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/dictionaries [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateReplacementDictionary(c *gin.Context) {
+	var req ReplacementDictionaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := pipeline.ValidateReplacementRules(req.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dictionary := models.ReplacementDictionary{
+		UserID:      currentUserID(c),
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		Rules:       req.Rules,
+	}
+	if err := database.DB.Create(&dictionary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create replacement dictionary"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dictionary)
+}
+
+// GetReplacementDictionary returns a replacement dictionary by ID
+// @Summary Get replacement dictionary
+// @Description Get a replacement dictionary and its rules
+// @Tags dictionaries
+// @Produce json
+// @Param id path string true "Dictionary ID"
+// @Success 200 {object} models.ReplacementDictionary
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dictionaries/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetReplacementDictionary(c *gin.Context) {
+	dictionary, ok := findOwnedDictionary(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, dictionary)
+}
+
+// UpdateReplacementDictionary replaces a dictionary's name, description and rules
+// @Summary Update replacement dictionary
+// @Description Replace a dictionary's name, description and rules
+// @Tags dictionaries
+// @Accept json
+// @Produce json
+// @Param id path string true "Dictionary ID"
+// @Param request body ReplacementDictionaryRequest true "Dictionary data"
+// @Success 200 {object} models.ReplacementDictionary
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dictionaries/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateReplacementDictionary(c *gin.Context) {
+	dictionary, ok := findOwnedDictionary(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req ReplacementDictionaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if err := pipeline.ValidateReplacementRules(req.Rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	dictionary.Name = strings.TrimSpace(req.Name)
+	dictionary.Description = req.Description
+	dictionary.Rules = req.Rules
+	if err := database.DB.Save(dictionary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update replacement dictionary"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dictionary)
+}
+
+// DeleteReplacementDictionary removes a replacement dictionary
+// @Summary Delete replacement dictionary
+// @Description Delete a replacement dictionary. Profiles still listing it skip it.
+// @Tags dictionaries
+// @Produce json
+// @Param id path string true "Dictionary ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/dictionaries/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteReplacementDictionary(c *gin.Context) {
+	dictionary, ok := findOwnedDictionary(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	if err := database.DB.Delete(dictionary).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete replacement dictionary"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Replacement dictionary deleted successfully"})
+}
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/export"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ExportTranscript renders a completed transcript in a downloadable format
+// @Summary Export transcript
+// @Description Download a completed transcript as SRT, VTT, TXT, DOCX, JSON or TSV. Custom speaker names are applied, and subtitle formats honor the job's max_line_width, max_line_count and highlight_words settings (overridable via query parameters).
+// @Tags transcription
+// @Produce octet-stream
+// @Param id path string true "Job ID"
+// @Param format query string true "Export format" Enums(srt, vtt, txt, docx, json, tsv)
+// @Param max_line_width query int false "Override maximum characters per subtitle line"
+// @Param max_line_count query int false "Override maximum lines per subtitle cue"
+// @Param highlight_words query bool false "Override word highlighting in subtitles"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/{id}/export [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ExportTranscript(c *gin.Context) {
+	jobID := c.Param("id")
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatTXT))
+
+	if !export.IsSupported(format) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":             fmt.Sprintf("Unsupported export format: %s", format),
+			"supported_formats": export.SupportedFormats,
+		})
+		return
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	if job.Status != models.StatusCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Job not completed, current status: %s", job.Status),
+		})
+		return
+	}
+
+	if job.Transcript == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcript not available"})
+		return
+	}
+
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(*job.Transcript), &result); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse transcript"})
+		return
+	}
+
+	opts, err := exportOptions(job)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get speaker mappings"})
+		return
+	}
+
+	// Allow per-request overrides of the formatting options stored on the job
+	if v := c.Query("max_line_width"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			opts.MaxLineWidth = n
+		}
+	}
+	if v := c.Query("max_line_count"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			opts.MaxLineCount = n
+		}
+	}
+	if v := c.Query("highlight_words"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			opts.HighlightWords = b
+		}
+	}
+
+	output, err := export.Render(format, &result, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export transcript: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, exportBaseName(job), output.Extension))
+	c.Data(http.StatusOK, output.ContentType, output.Data)
+}
+
+// exportOptions returns the export settings stored on a job, with its custom speaker names
+func exportOptions(job models.TranscriptionJob) (export.Options, error) {
+	var mappings []models.SpeakerMapping
+	if err := database.DB.Where("transcription_job_id = ?", job.ID).Find(&mappings).Error; err != nil {
+		return export.Options{}, err
+	}
+	speakerNames := make(map[string]string, len(mappings))
+	for _, m := range mappings {
+		speakerNames[m.OriginalSpeaker] = m.CustomName
+	}
+
+	opts := export.Options{
+		SpeakerNames:   speakerNames,
+		HighlightWords: job.Parameters.HighlightWords,
+	}
+	if job.Title != nil {
+		opts.Title = *job.Title
+	}
+	if job.Parameters.MaxLineWidth != nil {
+		opts.MaxLineWidth = *job.Parameters.MaxLineWidth
+	}
+	if job.Parameters.MaxLineCount != nil {
+		opts.MaxLineCount = *job.Parameters.MaxLineCount
+	}
+	return opts, nil
+}
+
+// exportBaseName derives a filesystem-safe download name from the job title
+func exportBaseName(job models.TranscriptionJob) string {
+	name := ""
+	if job.Title != nil {
+		name = strings.Trim(unsafeFilenameChars.ReplaceAllString(*job.Title, "_"), "_.")
+	}
+	if name == "" {
+		name = "transcript-" + job.ID
+	}
+	return name
+}
@@ -0,0 +1,469 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+)
+
+// maxFolderNameLength matches the size of the folder name column
+const maxFolderNameLength = 255
+
+// FolderCreateRequest creates a folder
+type FolderCreateRequest struct {
+	Name     string `json:"name" binding:"required"`
+	ParentID string `json:"parent_id,omitempty"` // Empty for a top-level folder
+}
+
+// FolderUpdateRequest renames or moves a folder; fields left out are kept
+type FolderUpdateRequest struct {
+	Name     *string `json:"name,omitempty"`
+	ParentID *string `json:"parent_id,omitempty"` // Empty string moves the folder to the top level
+}
+
+// FolderResponse is a folder with the number of jobs directly in it
+type FolderResponse struct {
+	models.Folder
+	JobCount int64 `json:"job_count"`
+}
+
+// FolderDetailResponse is a folder with its path from the top level and its subfolders
+type FolderDetailResponse struct {
+	FolderResponse
+	Path     []models.Folder  `json:"path"` // Ancestors, top level first
+	Children []FolderResponse `json:"children"`
+}
+
+// JobFolderRequest moves a job into a folder
+type JobFolderRequest struct {
+	FolderID string `json:"folder_id"` // Empty moves the job out of any folder
+}
+
+// FolderChatRequest starts a chat session over the transcripts of a folder
+type FolderChatRequest struct {
+	Model          string `json:"model" binding:"required"`
+	Title          string `json:"title,omitempty"`
+	Retrieval      string `json:"retrieval,omitempty"`       // auto (default), full, bm25 or embeddings
+	EmbeddingModel string `json:"embedding_model,omitempty"` // Required for embeddings retrieval
+}
+
+// folderTreeIDs returns the ID of a folder and of every folder below it
+func folderTreeIDs(db *gorm.DB, rootID string) ([]string, error) {
+	ids := []string{rootID}
+	level := []string{rootID}
+	for len(level) > 0 {
+		var children []string
+		if err := db.Model(&models.Folder{}).Where("parent_id IN ?", level).Pluck("id", &children).Error; err != nil {
+			return nil, fmt.Errorf("failed to list subfolders: %w", err)
+		}
+		ids = append(ids, children...)
+		level = children
+	}
+	return ids, nil
+}
+
+// folderResponses loads the folders matching a query with their job counts
+func folderResponses(query *gorm.DB) ([]FolderResponse, error) {
+	var folders []FolderResponse
+	err := query.Model(&models.Folder{}).
+		Select("folders.*, (SELECT COUNT(*) FROM transcription_jobs j WHERE j.folder_id = folders.id) AS job_count").
+		Order("name ASC").Scan(&folders).Error
+	if folders == nil {
+		folders = []FolderResponse{}
+	}
+	return folders, err
+}
+
+// findOwnedFolder loads a folder owned by the authenticated user, writing a response on failure
+func findOwnedFolder(c *gin.Context, id string) (*models.Folder, bool) {
+	var folder models.Folder
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", id).First(&folder).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Folder not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get folder"})
+		return nil, false
+	}
+	return &folder, true
+}
+
+func validateFolderName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("name is required")
+	}
+	if len(name) > maxFolderNameLength {
+		return "", fmt.Errorf("name is too long")
+	}
+	return name, nil
+}
+
+// ListFolders returns the folders of the current user
+// @Summary List folders
+// @Description Get all folders of the current user as a flat list; parent_id links each folder to the one containing it
+// @Tags folders
+// @Produce json
+// @Success 200 {array} FolderResponse
+// @Router /api/v1/folders [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListFolders(c *gin.Context) {
+	folders, err := folderResponses(database.DB.Scopes(ownedBy(c)))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folders"})
+		return
+	}
+	c.JSON(http.StatusOK, folders)
+}
+
+// CreateFolder creates a folder
+// @Summary Create a folder
+// @Description Create a folder at the top level or inside another folder
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param request body FolderCreateRequest true "Folder"
+// @Success 201 {object} models.Folder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateFolder(c *gin.Context) {
+	var req FolderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name, err := validateFolderName(req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folder := models.Folder{UserID: currentUserID(c), Name: name}
+	if req.ParentID != "" {
+		if _, ok := findOwnedFolder(c, req.ParentID); !ok {
+			return
+		}
+		folder.ParentID = &req.ParentID
+	}
+
+	if err := database.DB.Create(&folder).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create folder"})
+		return
+	}
+	c.JSON(http.StatusCreated, folder)
+}
+
+// GetFolder returns a folder with its path and subfolders
+// @Summary Get a folder
+// @Description Get a folder with its path from the top level and its direct subfolders
+// @Tags folders
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} FolderDetailResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetFolder(c *gin.Context) {
+	folder, ok := findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var response FolderDetailResponse
+	response.Folder = *folder
+	database.DB.Model(&models.TranscriptionJob{}).Where("folder_id = ?", folder.ID).Count(&response.JobCount)
+
+	children, err := folderResponses(database.DB.Where("parent_id = ?", folder.ID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subfolders"})
+		return
+	}
+	response.Children = children
+
+	response.Path = []models.Folder{}
+	for parentID := folder.ParentID; parentID != nil; {
+		var parent models.Folder
+		if err := database.DB.Where("id = ?", *parentID).First(&parent).Error; err != nil {
+			break
+		}
+		response.Path = append([]models.Folder{parent}, response.Path...)
+		parentID = parent.ParentID
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateFolder renames or moves a folder
+// @Summary Update a folder
+// @Description Rename a folder or move it into another folder. A folder can't be moved into itself or one of its subfolders.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param request body FolderUpdateRequest true "Changes"
+// @Success 200 {object} models.Folder
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateFolder(c *gin.Context) {
+	folder, ok := findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req FolderUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	if req.Name != nil {
+		name, err := validateFolderName(*req.Name)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		folder.Name = name
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == "" {
+			folder.ParentID = nil
+		} else {
+			if _, ok := findOwnedFolder(c, *req.ParentID); !ok {
+				return
+			}
+			subtree, err := folderTreeIDs(database.DB, folder.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder"})
+				return
+			}
+			for _, id := range subtree {
+				if id == *req.ParentID {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "A folder can't be moved into itself or one of its subfolders"})
+					return
+				}
+			}
+			folder.ParentID = req.ParentID
+		}
+	}
+
+	if err := database.DB.Model(folder).Select("name", "parent_id").Updates(folder).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update folder"})
+		return
+	}
+	c.JSON(http.StatusOK, folder)
+}
+
+// DeleteFolder deletes a folder
+// @Summary Delete a folder
+// @Description Delete a folder. Its jobs and subfolders move up into the folder containing it, and chat sessions over the folder are deleted.
+// @Tags folders
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteFolder(c *gin.Context) {
+	folder, ok := findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TranscriptionJob{}).Where("folder_id = ?", folder.ID).Update("folder_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Folder{}).Where("parent_id = ?", folder.ID).Update("parent_id", folder.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chat_session_id IN (?)", tx.Model(&models.ChatSession{}).Select("id").Where("folder_id = ?", folder.ID)).Delete(&models.ChatMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("folder_id = ?", folder.ID).Delete(&models.ChatSession{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(folder).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete folder"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
+}
+
+// SetJobFolder moves a transcription job into a folder
+// @Summary Move a transcription into a folder
+// @Description Move a job into a folder, or out of any folder with an empty folder_id
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body JobFolderRequest true "Folder"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/folder [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) SetJobFolder(c *gin.Context) {
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", c.Param("id")).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	var req JobFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	job.FolderID = nil
+	if req.FolderID != "" {
+		if _, ok := findOwnedFolder(c, req.FolderID); !ok {
+			return
+		}
+		job.FolderID = &req.FolderID
+	}
+
+	if err := database.DB.Model(&job).Update("folder_id", job.FolderID).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to move job"})
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// folderChatJobs returns the completed transcriptions in a folder and its subfolders, newest first
+func folderChatJobs(db *gorm.DB, folderID string) ([]models.TranscriptionJob, error) {
+	ids, err := folderTreeIDs(db, folderID)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []models.TranscriptionJob
+	err = db.Where("folder_id IN ? AND status = ? AND transcript IS NOT NULL", ids, models.StatusCompleted).
+		Order("created_at DESC").Find(&jobs).Error
+	return jobs, err
+}
+
+// CreateFolderChatSession starts a chat session over every transcript in a folder
+// @Summary Chat about a folder
+// @Description Create a chat session over the completed transcripts in a folder and its subfolders. Transcripts added to the folder later are included in later messages. Retrieval works as for other chat sessions.
+// @Tags folders
+// @Accept json
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Param request body FolderChatRequest true "Chat session"
+// @Success 201 {object} ChatSessionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders/{id}/chat/sessions [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateFolderChatSession(c *gin.Context) {
+	folder, ok := findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req FolderChatRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	retrievalMode, err := chatRetrievalMode(req.Retrieval, req.EmbeddingModel)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	jobs, err := folderChatJobs(database.DB, folder.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load folder transcripts"})
+		return
+	}
+	if len(jobs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Folder has no completed transcriptions"})
+		return
+	}
+
+	// Verify LLM service is available
+	if _, _, err := h.getLLMService(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	title := req.Title
+	if title == "" {
+		title = folder.Name
+	}
+
+	// Chat sessions reference a transcription, so the session is anchored to the newest
+	// transcript; its context always comes from the whole folder
+	now := time.Now()
+	session := models.ChatSession{
+		JobID:           jobs[0].ID,
+		TranscriptionID: jobs[0].ID,
+		FolderID:        &folder.ID,
+		UserID:          folder.UserID,
+		Title:           title,
+		Model:           req.Model,
+		Provider:        "openai",
+		RetrievalMode:   retrievalMode,
+		EmbeddingModel:  req.EmbeddingModel,
+		LastActivityAt:  &now,
+		IsActive:        true,
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create chat session"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, chatSessionResponses([]models.ChatSession{session})[0])
+}
+
+// ListFolderChatSessions returns the chat sessions over a folder
+// @Summary List chat sessions of a folder
+// @Description Get the chat sessions over a folder, most recently updated first
+// @Tags folders
+// @Produce json
+// @Param id path string true "Folder ID"
+// @Success 200 {array} ChatSessionResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/folders/{id}/chat/sessions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListFolderChatSessions(c *gin.Context) {
+	folder, ok := findOwnedFolder(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var sessions []models.ChatSession
+	if err := database.DB.Scopes(ownedBy(c)).Where("folder_id = ?", folder.ID).Order("updated_at DESC").Find(&sessions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get chat sessions"})
+		return
+	}
+	c.JSON(http.StatusOK, chatSessionResponses(sessions))
+}
@@ -7,6 +7,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,7 +24,9 @@ import (
 	"synthezia/internal/models"
 	"synthezia/internal/processing"
 	"synthezia/internal/queue"
+	"synthezia/internal/search"
 	"synthezia/internal/transcription"
+	"synthezia/internal/transcription/pipeline"
 	"synthezia/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -74,6 +77,7 @@ type LoginResponse struct {
 	User  struct {
 		ID       uint   `json:"id"`
 		Username string `json:"username"`
+		Role     string `json:"role"`
 	} `json:"user"`
 }
 
@@ -82,12 +86,14 @@ type RegisterRequest struct {
 	Username        string `json:"username" binding:"required,min=3,max=50"`
 	Password        string `json:"password" binding:"required,min=6"`
 	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+	InvitationToken string `json:"invitationToken,omitempty"` // Required once the first user exists
 }
 
 // RegistrationStatusResponse represents the registration status
 type RegistrationStatusResponse struct {
 	// Match tests expecting snake_case key
-	RegistrationEnabled bool `json:"registration_enabled"`
+	RegistrationEnabled bool   `json:"registration_enabled"`
+	InvitationRole      string `json:"invitation_role,omitempty"` // Role granted by a valid invitation
 }
 
 // ChangePasswordRequest represents the change password request
@@ -105,22 +111,29 @@ type ChangeUsernameRequest struct {
 
 // CreateAPIKeyRequest represents the create API key request
 type CreateAPIKeyRequest struct {
-	Name        string `json:"name" binding:"required,min=1,max=100"`
-	Description string `json:"description,omitempty"`
+	Name        string     `json:"name" binding:"required,min=1,max=100"`
+	Description string     `json:"description,omitempty"`
+	Scopes      []string   `json:"scopes,omitempty"`     // Defaults to every scope but admin
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // The key never expires when unset
 }
 
-// CreateAPIKeyResponse represents the create API key response
+// CreateAPIKeyResponse represents the create API key response. The key is only shown here.
 type CreateAPIKeyResponse struct {
-	ID          uint   `json:"id"`
-	Key         string `json:"key"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
+	ID          uint       `json:"id"`
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Description string     `json:"description,omitempty"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	IsActive    bool       `json:"is_active"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
 // YouTubeDownloadRequest represents the YouTube download request
 type YouTubeDownloadRequest struct {
-	URL   string  `json:"url" binding:"required"`
-	Title *string `json:"title,omitempty"`
+	URL      string  `json:"url" binding:"required"`
+	Title    *string `json:"title,omitempty"`
+	Priority *int    `json:"priority,omitempty"`
 }
 
 // YouTubeDownloadResponse represents the YouTube download response
@@ -153,14 +166,18 @@ type LLMConfigResponse struct {
 
 // APIKeyListResponse represents an API key in the list (without the actual key)
 type APIKeyListResponse struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	KeyPreview  string `json:"key_preview"`
-	IsActive    bool   `json:"is_active"`
-	CreatedAt   string `json:"created_at"`
-	UpdatedAt   string `json:"updated_at"`
-	LastUsed    string `json:"last_used,omitempty"`
+	ID          uint     `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	KeyPreview  string   `json:"key_preview"`
+	Scopes      []string `json:"scopes"` // null for keys with the default scopes
+	IsActive    bool     `json:"is_active"`
+	Expired     bool     `json:"expired"`
+	ExpiresAt   string   `json:"expires_at,omitempty"`
+	UsageCount  int64    `json:"usage_count"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+	LastUsed    string   `json:"last_used,omitempty"`
 }
 
 // APIKeysWrapper wraps the API keys list response
@@ -170,18 +187,19 @@ type APIKeysWrapper struct {
 
 // transformAPIKeyForList converts a models.APIKey to APIKeyListResponse
 func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
-	keyPreview := ""
-	if len(apiKey.Key) > 8 {
-		keyPreview = apiKey.Key[:8] + "..."
-	} else if apiKey.Key != "" {
-		keyPreview = apiKey.Key + "..."
-	}
+	// Keys are stored hashed, so only their prefix can be shown
+	keyPreview := apiKey.KeyPrefix + "..."
 
 	lastUsed := ""
 	if apiKey.LastUsed != nil {
 		lastUsed = apiKey.LastUsed.Format(time.RFC3339)
 	}
 
+	expiresAt := ""
+	if apiKey.ExpiresAt != nil {
+		expiresAt = apiKey.ExpiresAt.Format(time.RFC3339)
+	}
+
 	description := ""
 	if apiKey.Description != nil {
 		description = *apiKey.Description
@@ -192,7 +210,11 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 		Name:        apiKey.Name,
 		Description: description,
 		KeyPreview:  keyPreview,
+		Scopes:      apiKey.Scopes,
 		IsActive:    apiKey.IsActive,
+		Expired:     apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt),
+		ExpiresAt:   expiresAt,
+		UsageCount:  apiKey.UsageCount,
 		CreatedAt:   apiKey.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   apiKey.UpdatedAt.Format(time.RFC3339),
 		LastUsed:    lastUsed,
@@ -206,6 +228,7 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 // @Produce json
 // @Param audio formData file true "Audio file"
 // @Param title formData string false "Job title"
+// @Param priority formData string false "Queue priority: low, normal, high, urgent or a number from -100 to 100" default(normal)
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -213,6 +236,12 @@ func transformAPIKeyForList(apiKey models.APIKey) APIKeyListResponse {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) UploadAudio(c *gin.Context) {
+	priority, err := formPriority(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("audio")
 	if err != nil {
@@ -250,8 +279,10 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 	// Create job record with "uploaded" status (not queued for transcription)
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    currentUserID(c),
 		AudioPath: filePath,
 		Status:    models.StatusUploaded, // New status for uploaded but not transcribed
+		Priority:  priority,
 	}
 
 	if title := c.PostForm("title"); title != "" {
@@ -274,19 +305,19 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 			var profileFound bool
 
 			if user.DefaultProfileID != nil {
-				err = database.DB.Where("id = ?", *user.DefaultProfileID).First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Where("id = ?", *user.DefaultProfileID).First(&profile).Error
 				profileFound = (err == nil)
 			}
 
 			// If no user default or user default not found, try to find a system default
 			if !profileFound {
-				err = database.DB.Where("is_default = ?", true).First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Where("is_default = ?", true).First(&profile).Error
 				profileFound = (err == nil)
 			}
 
 			// If still no profile found, use the first available profile
 			if !profileFound {
-				err = database.DB.Order("created_at ASC").First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Order("created_at ASC").First(&profile).Error
 				profileFound = (err == nil)
 			}
 
@@ -319,6 +350,7 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 // @Produce json
 // @Param video formData file true "Video file"
 // @Param title formData string false "Job title"
+// @Param priority formData string false "Queue priority: low, normal, high, urgent or a number from -100 to 100" default(normal)
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -326,6 +358,12 @@ func (h *Handler) UploadAudio(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) UploadVideo(c *gin.Context) {
+	priority, err := formPriority(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("video")
 	if err != nil {
@@ -391,8 +429,10 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 	// Create job record with "uploaded" status (not queued for transcription)
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    currentUserID(c),
 		AudioPath: audioPath,
 		Status:    models.StatusUploaded, // Same status as audio uploads
+		Priority:  priority,
 	}
 
 	if title := c.PostForm("title"); title != "" {
@@ -415,19 +455,19 @@ func (h *Handler) UploadVideo(c *gin.Context) {
 			var profileFound bool
 
 			if user.DefaultProfileID != nil {
-				err = database.DB.Where("id = ?", *user.DefaultProfileID).First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Where("id = ?", *user.DefaultProfileID).First(&profile).Error
 				profileFound = (err == nil)
 			}
 
 			// If no user default or user default not found, try to find a system default
 			if !profileFound {
-				err = database.DB.Where("is_default = ?", true).First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Where("is_default = ?", true).First(&profile).Error
 				profileFound = (err == nil)
 			}
 
 			// If still no profile found, use the first available profile
 			if !profileFound {
-				err = database.DB.Order("created_at ASC").First(&profile).Error
+				err = database.DB.Scopes(ownedBy(c)).Order("created_at ASC").First(&profile).Error
 				profileFound = (err == nil)
 			}
 
@@ -475,6 +515,12 @@ func (h *Handler) UploadMultiTrack(c *gin.Context) {
 		return
 	}
 
+	priority, err := formPriority(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Parse multipart form for .aup file
 	aupFile, aupHeader, err := c.Request.FormFile("aup")
 	if err != nil {
@@ -596,10 +642,12 @@ func (h *Handler) UploadMultiTrack(c *gin.Context) {
 	// Create transcription job record
 	job := models.TranscriptionJob{
 		ID:               jobID,
+		UserID:           currentUserID(c),
 		Title:            &title,
 		AudioPath:        firstTrackPath, // Point to first track initially
 		Status:           models.StatusUploaded,
 		IsMultiTrack:     true,
+		Priority:         priority,
 		AupFilePath:      &aupFilePath,
 		MultiTrackFolder: &multiTrackFolder,
 		MergeStatus:      "none", // No merge processing yet
@@ -655,6 +703,11 @@ func (h *Handler) UploadMultiTrack(c *gin.Context) {
 func (h *Handler) GetMergeStatus(c *gin.Context) {
 	jobID := c.Param("id")
 
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
 	status, errorMsg, err := h.multiTrackProcessor.GetMergeStatus(jobID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
@@ -687,7 +740,7 @@ func (h *Handler) GetTrackProgress(c *gin.Context) {
 
 	// Get the main job details
 	var job models.TranscriptionJob
-	if err := database.DB.Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 		return
 	}
@@ -787,6 +840,18 @@ func (h *Handler) GetTrackProgress(c *gin.Context) {
 // @Param vad_offset formData number false "VAD offset" default(0.363)
 // @Param min_speakers formData int false "Minimum speakers for diarization"
 // @Param max_speakers formData int false "Maximum speakers for diarization"
+// @Param remove_silence formData boolean false "Cut long silences before transcribing; timestamps still refer to the original audio"
+// @Param silence_threshold_db formData number false "Level below which audio counts as silence, in dBFS" default(-35)
+// @Param silence_min_duration formData number false "Shortest silence to cut, in seconds" default(2)
+// @Param highpass_frequency formData int false "High-pass filter cutoff in Hz, 0 to disable" default(0)
+// @Param denoise formData string false "Denoiser: afftdn or arnndn"
+// @Param denoise_model formData string false "RNNoise model file for arnndn"
+// @Param loudnorm formData boolean false "Normalize loudness (EBU R128)"
+// @Param loudnorm_target formData number false "Integrated loudness target in LUFS" default(-23)
+// @Param replacement_dictionaries formData string false "Comma-separated replacement dictionary IDs to apply"
+// @Param normalize_numbers formData boolean false "Write spelled-out numbers as digits"
+// @Param mask_profanity formData boolean false "Mask profanity in the transcript"
+// @Param priority formData string false "Queue priority: low, normal, high, urgent or a number from -100 to 100" default(normal)
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -794,6 +859,12 @@ func (h *Handler) GetTrackProgress(c *gin.Context) {
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) SubmitJob(c *gin.Context) {
+	priority, err := formPriority(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Parse multipart form
 	file, header, err := c.Request.FormFile("audio")
 	if err != nil {
@@ -843,6 +914,20 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 		VadOnset:    getFormFloatWithDefault(c, "vad_onset", 0.500),
 		VadOffset:   getFormFloatWithDefault(c, "vad_offset", 0.363),
 		Diarize:     diarize,
+
+		RemoveSilence:      getFormBoolWithDefault(c, "remove_silence", false),
+		SilenceThresholdDB: getFormFloatWithDefault(c, "silence_threshold_db", -35),
+		SilenceMinDuration: getFormFloatWithDefault(c, "silence_min_duration", 2),
+
+		HighpassFrequency: getFormIntWithDefault(c, "highpass_frequency", 0),
+		Denoise:           c.PostForm("denoise"),
+		DenoiseModel:      c.PostForm("denoise_model"),
+		Loudnorm:          getFormBoolWithDefault(c, "loudnorm", false),
+		LoudnormTarget:    getFormFloatWithDefault(c, "loudnorm_target", pipeline.DefaultLoudnormTarget),
+
+		ReplacementDictionaries: formList(c, "replacement_dictionaries"),
+		NormalizeNumbers:        getFormBoolWithDefault(c, "normalize_numbers", false),
+		MaskProfanity:           getFormBoolWithDefault(c, "mask_profanity", false),
 	}
 
 	if lang := c.PostForm("language"); lang != "" {
@@ -873,12 +958,25 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 	}
 	params.DiarizeModel = diarizeModel
 
+	if err := validatePreprocessing(params); err != nil {
+		os.Remove(filePath)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateReplacementDictionaries(c, params.ReplacementDictionaries); err != nil {
+		os.Remove(filePath)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Create job
 	job := models.TranscriptionJob{
 		ID:          jobID,
+		UserID:      currentUserID(c),
 		AudioPath:   filePath,
 		Status:      models.StatusPending,
 		Diarization: diarize,
+		Priority:    priority,
 		Parameters:  params,
 	}
 
@@ -915,6 +1013,11 @@ func (h *Handler) SubmitJob(c *gin.Context) {
 func (h *Handler) GetJobStatus(c *gin.Context) {
 	jobID := c.Param("id")
 
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
 	job, err := h.taskQueue.GetJobStatus(jobID)
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -943,7 +1046,7 @@ func (h *Handler) GetTranscript(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -979,6 +1082,144 @@ func (h *Handler) GetTranscript(c *gin.Context) {
 	})
 }
 
+// JobListFilter selects the jobs ListJobs returns and bulk actions run on
+type JobListFilter struct {
+	Status            string     `json:"status,omitempty"`
+	Query             string     `json:"q,omitempty"`    // Search in title and audio filename
+	Tags              []string   `json:"tags,omitempty"` // Tag names; a job must carry all of them
+	FolderID          string     `json:"folder_id,omitempty"` // Folder ID, or "root" for jobs outside any folder
+	IncludeSubfolders bool       `json:"include_subfolders,omitempty"`
+	CreatedAfter      *time.Time `json:"created_after,omitempty"`
+	CreatedBefore     *time.Time `json:"created_before,omitempty"`
+	MinDuration       *float64   `json:"min_duration,omitempty"` // Seconds of audio
+	MaxDuration       *float64   `json:"max_duration,omitempty"`
+	Model             string     `json:"model,omitempty"`
+	Language          string     `json:"language,omitempty"` // Detected language, or the requested one
+}
+
+// rootFolder is the FolderID filter value that selects jobs outside any folder
+const rootFolder = "root"
+
+// scope restricts a TranscriptionJob query to the jobs matching the filter
+func (f JobListFilter) scope(db *gorm.DB) *gorm.DB {
+	// Filter out temporary track jobs (they have IDs starting with "track_")
+	db = db.Where("id NOT LIKE 'track_%'")
+
+	// Apply status filter
+	if f.Status != "" {
+		db = db.Where("status = ?", f.Status)
+	}
+
+	// Apply search filter - search in title and audio_path
+	if f.Query != "" {
+		searchPattern := "%" + f.Query + "%"
+		db = db.Where(database.ILike("title")+" OR "+database.ILike("audio_path"), searchPattern, searchPattern)
+	}
+
+	for _, tag := range f.Tags {
+		db = db.Where("id IN (SELECT jt.transcription_job_id FROM transcription_job_tags jt JOIN tags t ON t.id = jt.tag_id WHERE LOWER(t.name) = LOWER(?))", tag)
+	}
+
+	switch {
+	case f.FolderID == rootFolder && !f.IncludeSubfolders:
+		db = db.Where("folder_id IS NULL")
+	case f.FolderID == rootFolder:
+		// Every folder sits below the root
+	case f.FolderID != "" && f.IncludeSubfolders:
+		ids, err := folderTreeIDs(database.DB, f.FolderID)
+		if err != nil {
+			db.AddError(err)
+		}
+		db = db.Where("folder_id IN ?", ids)
+	case f.FolderID != "":
+		db = db.Where("folder_id = ?", f.FolderID)
+	}
+
+	if f.CreatedAfter != nil {
+		db = db.Where("created_at >= ?", *f.CreatedAfter)
+	}
+	if f.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *f.CreatedBefore)
+	}
+	if f.MinDuration != nil {
+		db = db.Where("audio_duration >= ?", *f.MinDuration)
+	}
+	if f.MaxDuration != nil {
+		db = db.Where("audio_duration <= ?", *f.MaxDuration)
+	}
+	if f.Model != "" {
+		db = db.Where("model = ?", f.Model)
+	}
+	if f.Language != "" {
+		db = db.Where("LOWER(COALESCE(detected_language, language)) = LOWER(?)", f.Language)
+	}
+	return db
+}
+
+// parseFilterTime reads an RFC 3339 time or a YYYY-MM-DD date. A date given as an upper bound
+// covers the whole day.
+func parseFilterTime(value string, upperBound bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC 3339", value)
+	}
+	if upperBound {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseFilterSeconds(name, value string) (*float64, error) {
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative number of seconds", name)
+	}
+	return &seconds, nil
+}
+
+// jobListFilterFromQuery reads a JobListFilter from the ListJobs query parameters
+func jobListFilterFromQuery(c *gin.Context) (JobListFilter, error) {
+	filter := JobListFilter{
+		Status:            c.Query("status"),
+		Query:             c.Query("q"),
+		FolderID:          c.Query("folder_id"),
+		IncludeSubfolders: c.Query("include_subfolders") == "true",
+		Model:             c.Query("model"),
+		Language:          c.Query("language"),
+	}
+	for _, value := range c.QueryArray("tag") {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	var err error
+	if filter.CreatedAfter, err = parseFilterTime(c.Query("created_after"), false); err != nil {
+		return filter, err
+	}
+	if filter.CreatedBefore, err = parseFilterTime(c.Query("created_before"), true); err != nil {
+		return filter, err
+	}
+	if filter.MinDuration, err = parseFilterSeconds("min_duration", c.Query("min_duration")); err != nil {
+		return filter, err
+	}
+	if filter.MaxDuration, err = parseFilterSeconds("max_duration", c.Query("max_duration")); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
+
 // @Summary List all transcription records
 // @Description Get a list of all transcription jobs with optional search and filtering
 // @Tags transcription
@@ -987,15 +1228,29 @@ func (h *Handler) GetTranscript(c *gin.Context) {
 // @Param limit query int false "Items per page" default(10)
 // @Param status query string false "Filter by status"
 // @Param q query string false "Search in title and audio filename"
+// @Param tag query []string false "Only jobs carrying all of these tags" collectionFormat(multi)
+// @Param folder_id query string false "Only jobs in this folder, or root for jobs outside any folder"
+// @Param include_subfolders query bool false "Include jobs in subfolders of folder_id"
+// @Param created_after query string false "Created at or after this date (YYYY-MM-DD or RFC 3339)"
+// @Param created_before query string false "Created before this time, or on or before this date"
+// @Param min_duration query number false "Minimum audio duration in seconds"
+// @Param max_duration query number false "Maximum audio duration in seconds"
+// @Param model query string false "Transcription model"
+// @Param language query string false "Detected or requested language"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Router /api/v1/transcription/list [get]
 // @Security ApiKeyAuth
 // @Security BearerAuth
 func (h *Handler) ListJobs(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	status := c.Query("status")
-	search := c.Query("q") // Add search parameter
+	filter, err := jobListFilterFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	search := filter.Query
 
 	if page < 1 {
 		page = 1
@@ -1006,21 +1261,7 @@ func (h *Handler) ListJobs(c *gin.Context) {
 
 	offset := (page - 1) * limit
 
-	query := database.DB.Model(&models.TranscriptionJob{})
-
-	// Filter out temporary track jobs (they have IDs starting with "track_")
-	query = query.Where("id NOT LIKE 'track_%'")
-
-	// Apply status filter
-	if status != "" {
-		query = query.Where("status = ?", status)
-	}
-
-	// Apply search filter - search in title and audio_path
-	if search != "" {
-		searchPattern := "%" + search + "%"
-		query = query.Where("title LIKE ? COLLATE NOCASE OR audio_path LIKE ? COLLATE NOCASE", searchPattern, searchPattern)
-	}
+	query := database.DB.Model(&models.TranscriptionJob{}).Scopes(ownedBy(c), filter.scope)
 
 	var jobs []models.TranscriptionJob
 	var total int64
@@ -1029,7 +1270,7 @@ func (h *Handler) ListJobs(c *gin.Context) {
 	query.Count(&total)
 
 	// Apply pagination and ordering
-	if err := query.Preload("MultiTrackFiles").Offset(offset).Limit(limit).Order("created_at DESC").Find(&jobs).Error; err != nil {
+	if err := query.Preload("MultiTrackFiles").Preload("Tags").Offset(offset).Limit(limit).Order("created_at DESC").Find(&jobs).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list jobs"})
 		return
 	}
@@ -1053,6 +1294,7 @@ func (h *Handler) ListJobs(c *gin.Context) {
 // @Produce json
 // @Param id path string true "Job ID"
 // @Param parameters body models.WhisperXParams true "Transcription parameters"
+// @Param priority query string false "Queue priority: low, normal, high, urgent or a number from -100 to 100; keeps the job's current priority when omitted"
 // @Success 200 {object} models.TranscriptionJob
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -1063,7 +1305,7 @@ func (h *Handler) StartTranscription(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1078,6 +1320,15 @@ func (h *Handler) StartTranscription(c *gin.Context) {
 		return
 	}
 
+	if value := c.Query("priority"); value != "" {
+		priority, err := parsePriority(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		job.Priority = priority
+	}
+
 	// Parse transcription parameters from request body
 	var requestParams models.WhisperXParams
 
@@ -1139,72 +1390,216 @@ func (h *Handler) StartTranscription(c *gin.Context) {
 		"diarize_model", requestParams.DiarizeModel,
 		"language", requestParams.Language)
 
+	if err := validateTranscriptionParams(&job, requestParams); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.queueTranscription(&job, requestParams); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Log job started
+	params := make(map[string]any)
+	params["model"] = requestParams.Model
+	params["model_family"] = requestParams.ModelFamily
+	params["diarization"] = requestParams.Diarize
+	if requestParams.Diarize && requestParams.DiarizeModel != "" {
+		params["diarize_model"] = requestParams.DiarizeModel
+	}
+	params["language"] = requestParams.Language
+	params["device"] = requestParams.Device
+	
+	filename := filepath.Base(job.AudioPath)
+	logger.JobStarted(jobID, filename, requestParams.ModelFamily, params)
+
+	c.JSON(http.StatusOK, job)
+}
+
+// validateTranscriptionParams checks that parameters can be used to transcribe a job
+func validateTranscriptionParams(job *models.TranscriptionJob, params models.WhisperXParams) error {
 	// Validate NVIDIA-specific constraints
-	if requestParams.ModelFamily == "nvidia_parakeet" || requestParams.ModelFamily == "nvidia_canary" {
+	if params.ModelFamily == "nvidia_parakeet" || params.ModelFamily == "nvidia_canary" {
 		// Both NVIDIA models support multiple European languages
 		// No language restriction needed - models support auto-detection
 
 		// NVIDIA models support diarization via Pyannote integration or NVIDIA Sortformer
-		if requestParams.Diarize && requestParams.DiarizeModel == "pyannote" && (requestParams.HfToken == nil || *requestParams.HfToken == "") {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Hugging Face token (hf_token) is required for Pyannote diarization"})
-			return
+		if params.Diarize && params.DiarizeModel == "pyannote" && (params.HfToken == nil || *params.HfToken == "") {
+			return fmt.Errorf("Hugging Face token (hf_token) is required for Pyannote diarization")
 		}
 	}
 
 	// Validate multi-track compatibility
-	if job.IsMultiTrack && !requestParams.IsMultiTrackEnabled {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-track audio requires multi-track transcription to be enabled in the parameters"})
-		return
+	if job.IsMultiTrack && !params.IsMultiTrackEnabled {
+		return fmt.Errorf("Multi-track audio requires multi-track transcription to be enabled in the parameters")
 	}
 
-	if !job.IsMultiTrack && requestParams.IsMultiTrackEnabled {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-track transcription cannot be used with single-track audio files"})
-		return
+	if !job.IsMultiTrack && params.IsMultiTrackEnabled {
+		return fmt.Errorf("Multi-track transcription cannot be used with single-track audio files")
 	}
 
 	// Multi-track transcription should automatically disable diarization
-	if requestParams.IsMultiTrackEnabled && requestParams.Diarize {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Diarization must be disabled when using multi-track transcription"})
-		return
+	if params.IsMultiTrackEnabled && params.Diarize {
+		return fmt.Errorf("Diarization must be disabled when using multi-track transcription")
 	}
+	return validateSpeakerEmbeddings(params)
+}
 
+// queueTranscription resets a job for a fresh transcription with the given parameters and enqueues it
+func (h *Handler) queueTranscription(job *models.TranscriptionJob, params models.WhisperXParams) error {
 	// Update job with parameters
-	job.Parameters = requestParams
-	job.Diarization = requestParams.Diarize
+	job.Parameters = params
+	job.Diarization = params.Diarize
 	job.Status = models.StatusPending
+	job.JobType = models.JobTypeTranscribe
 
 	// Clear previous results for re-transcription
 	job.Transcript = nil
 	job.Summary = nil
 	job.ErrorMessage = nil
 
+	// A manual start gets a fresh set of automatic retries
+	job.Attempts = 0
+	job.NextAttemptAt = nil
+
 	// Save updated job
+	if err := database.DB.Save(job).Error; err != nil {
+		return fmt.Errorf("Failed to update job")
+	}
+
+	// Enqueue job for transcription
+	if err := h.taskQueue.EnqueueJob(job.ID); err != nil {
+		logger.Error("Failed to enqueue job", "job_id", job.ID, "error", err)
+		return fmt.Errorf("Failed to enqueue job")
+	}
+	return nil
+}
+
+// RediarizeRequest selects the diarization model and speaker bounds for a diarization-only run.
+// Fields left out keep the job's current parameters.
+type RediarizeRequest struct {
+	DiarizeModel *string `json:"diarize_model,omitempty"` // pyannote or nvidia_sortformer
+	MinSpeakers  *int    `json:"min_speakers,omitempty"`
+	MaxSpeakers  *int    `json:"max_speakers,omitempty"`
+	HfToken      *string `json:"hf_token,omitempty"` // Required for pyannote unless the job already has one
+	// SpeakerEmbeddings keeps the voice embedding of each speaker so it can be matched against enrolled voices
+	SpeakerEmbeddings *bool `json:"speaker_embeddings,omitempty"`
+}
+
+// RediarizeJob re-runs speaker diarization on a transcribed job
+// @Summary Re-run diarization
+// @Description Queue a diarization-only run of an already transcribed job. The transcript is not re-transcribed: speakers from the new diarization are assigned to its stored words and segments, and the previous transcript is kept as a revision. If the run fails the job keeps its transcript and returns to the status it had before (completed or failed), and the failure is reported in the job's error_message, on its execution record and as a job.failed webhook.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body RediarizeRequest false "Diarization settings"
+// @Success 200 {object} models.TranscriptionJob
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/rediarize [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) RediarizeJob(c *gin.Context) {
+	jobID := c.Param("id")
+
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	// Failed jobs that kept their transcript may be diarized as well
+	if job.Status != models.StatusCompleted && job.Status != models.StatusFailed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot re-run diarization: job is not finished"})
+		return
+	}
+	if job.Transcript == nil || *job.Transcript == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot re-run diarization: job has no transcript"})
+		return
+	}
+	if job.IsMultiTrack {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Multi-track jobs take their speakers from the tracks and cannot be diarized"})
+		return
+	}
+
+	var req RediarizeRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+			return
+		}
+	}
+
+	params := job.Parameters
+	params.Diarize = true
+	if req.DiarizeModel != nil {
+		params.DiarizeModel = *req.DiarizeModel
+	}
+	if req.MinSpeakers != nil {
+		params.MinSpeakers = req.MinSpeakers
+	}
+	if req.MaxSpeakers != nil {
+		params.MaxSpeakers = req.MaxSpeakers
+	}
+	if req.HfToken != nil {
+		params.HfToken = req.HfToken
+	}
+	if req.SpeakerEmbeddings != nil {
+		params.SpeakerEmbeddings = *req.SpeakerEmbeddings
+	}
+
+	switch params.DiarizeModel {
+	case "nvidia_sortformer":
+	case "pyannote", "pyannote/speaker-diarization-3.1":
+		if params.HfToken == nil || *params.HfToken == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Hugging Face token (hf_token) is required for Pyannote diarization"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "diarize_model must be pyannote or nvidia_sortformer"})
+		return
+	}
+	if err := validateSpeakerEmbeddings(params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if (params.MinSpeakers != nil && *params.MinSpeakers < 1) || (params.MaxSpeakers != nil && *params.MaxSpeakers < 1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_speakers and max_speakers must be at least 1"})
+		return
+	}
+	if params.MinSpeakers != nil && params.MaxSpeakers != nil && *params.MinSpeakers > *params.MaxSpeakers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "min_speakers cannot be greater than max_speakers"})
+		return
+	}
+
+	job.Parameters = params
+	job.Diarization = true
+	job.JobType = models.JobTypeRediarize
+	previousStatus := job.Status
+	job.PreviousStatus = &previousStatus
+	job.Status = models.StatusPending
+	job.ErrorMessage = nil
+	job.Attempts = 0
+	job.NextAttemptAt = nil
+
 	if err := database.DB.Save(&job).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update job"})
 		return
 	}
 
-	// Enqueue job for transcription
 	if err := h.taskQueue.EnqueueJob(jobID); err != nil {
 		logger.Error("Failed to enqueue job", "job_id", jobID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enqueue job"})
 		return
 	}
 
-	// Log job started
-	params := make(map[string]any)
-	params["model"] = requestParams.Model
-	params["model_family"] = requestParams.ModelFamily
-	params["diarization"] = requestParams.Diarize
-	if requestParams.Diarize && requestParams.DiarizeModel != "" {
-		params["diarize_model"] = requestParams.DiarizeModel
-	}
-	params["language"] = requestParams.Language
-	params["device"] = requestParams.Device
-	
-	filename := filepath.Base(job.AudioPath)
-	logger.JobStarted(jobID, filename, requestParams.ModelFamily, params)
-
+	logger.Info("Queued diarization re-run", "job_id", jobID, "diarize_model", params.DiarizeModel)
 	c.JSON(http.StatusOK, job)
 }
 
@@ -1223,7 +1618,7 @@ func (h *Handler) KillJob(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1277,7 +1672,7 @@ func (h *Handler) UpdateTranscriptionTitle(c *gin.Context) {
 	}
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1301,6 +1696,75 @@ func (h *Handler) UpdateTranscriptionTitle(c *gin.Context) {
 	})
 }
 
+// UpdateJobPriority changes the queue priority of a job that hasn't started yet
+// @Summary Update job priority
+// @Description Change the queue priority of a job that is uploaded or waiting in the queue. Higher priorities are picked first.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body map[string]int true "Priority update request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/transcription/{id}/priority [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateJobPriority(c *gin.Context) {
+	jobID := c.Param("id")
+
+	var body struct {
+		Priority *int `json:"priority" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !validPriority(*body.Priority) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": priorityRangeError})
+		return
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	if job.Status != models.StatusPending && job.Status != models.StatusUploaded {
+		c.JSON(http.StatusConflict, gin.H{"error": "Priority can only be changed before the job starts processing"})
+		return
+	}
+
+	// Only touch jobs still waiting, in case a worker picked this one up meanwhile
+	result := database.DB.Model(&models.TranscriptionJob{}).
+		Where("id = ? AND status IN ?", job.ID, []models.JobStatus{models.StatusPending, models.StatusUploaded}).
+		Update("priority", *body.Priority)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update priority"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Priority can only be changed before the job starts processing"})
+		return
+	}
+
+	response := gin.H{
+		"id":       job.ID,
+		"status":   job.Status,
+		"priority": *body.Priority,
+	}
+	if h.taskQueue != nil && job.Status == models.StatusPending {
+		response["queue_position"] = h.taskQueue.QueuePosition(job.ID)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
 // @Summary Delete transcription job
 // @Description Delete a transcription job and its associated files
 // @Tags transcription
@@ -1316,7 +1780,7 @@ func (h *Handler) DeleteJob(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1331,6 +1795,19 @@ func (h *Handler) DeleteJob(c *gin.Context) {
 		return
 	}
 
+	if err := deleteJob(&job); err != nil {
+		logger.Error("Failed to delete job", "job_id", jobID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job from database"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job deleted successfully"})
+}
+
+// deleteJob removes a job, its files and every record that belongs to it
+func deleteJob(job *models.TranscriptionJob) error {
+	jobID := job.ID
+
 	// Delete the audio file from filesystem
 	if job.AudioPath != "" {
 		if err := os.Remove(job.AudioPath); err != nil && !os.IsNotExist(err) {
@@ -1362,76 +1839,68 @@ func (h *Handler) DeleteJob(c *gin.Context) {
 		}
 	}
 
-	// Delete all related records first to avoid foreign key constraint failures
-	// Start a transaction to ensure atomicity
-	tx := database.DB.Begin()
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
+	// Delete all related records first to avoid foreign key constraint failures,
+	// children first, in one transaction to ensure atomicity
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptionJobExecution{}).Error; err != nil {
+			return fmt.Errorf("failed to delete job execution records: %w", err)
 		}
-	}()
-
-	// Delete related records in order (children first)
-	if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptionJobExecution{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job execution records"})
-		return
-	}
-
-	if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.SpeakerMapping{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete speaker mappings"})
-		return
-	}
-
-	if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.MultiTrackFile{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete multi-track files"})
-		return
-	}
-
-	if err := tx.Where("transcription_id = ?", jobID).Delete(&models.Note{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete notes"})
-		return
-	}
-
-	// Delete chat sessions and their messages
-	var chatSessions []models.ChatSession
-	if err := tx.Where("transcription_id = ?", jobID).Find(&chatSessions).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to find chat sessions"})
-		return
-	}
-
-	for _, session := range chatSessions {
-		if err := tx.Where("chat_session_id = ?", session.ID).Delete(&models.ChatMessage{}).Error; err != nil {
-			tx.Rollback()
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chat messages"})
-			return
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.SpeakerMapping{}).Error; err != nil {
+			return fmt.Errorf("failed to delete speaker mappings: %w", err)
+		}
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.MultiTrackFile{}).Error; err != nil {
+			return fmt.Errorf("failed to delete multi-track files: %w", err)
+		}
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptRevision{}).Error; err != nil {
+			return fmt.Errorf("failed to delete transcript revisions: %w", err)
+		}
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.SpeakerEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete speaker embeddings: %w", err)
+		}
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptChunkEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to delete transcript chunk embeddings: %w", err)
+		}
+		if err := tx.Model(job).Association("Tags").Clear(); err != nil {
+			return fmt.Errorf("failed to remove tags: %w", err)
+		}
+		if err := tx.Where("transcription_id = ?", jobID).Delete(&models.Note{}).Error; err != nil {
+			return fmt.Errorf("failed to delete notes: %w", err)
 		}
-	}
 
-	if err := tx.Where("transcription_id = ?", jobID).Delete(&models.ChatSession{}).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete chat sessions"})
-		return
-	}
+		// Delete chat sessions and their messages, keeping sessions that can move to another transcript
+		if err := reanchorChatSessions(tx, jobID); err != nil {
+			return fmt.Errorf("failed to move chat sessions: %w", err)
+		}
+		var chatSessions []models.ChatSession
+		if err := tx.Where("transcription_id = ?", jobID).Find(&chatSessions).Error; err != nil {
+			return fmt.Errorf("failed to find chat sessions: %w", err)
+		}
+		for _, session := range chatSessions {
+			if err := tx.Where("chat_session_id = ?", session.ID).Delete(&models.ChatMessage{}).Error; err != nil {
+				return fmt.Errorf("failed to delete chat messages: %w", err)
+			}
+			if err := tx.Exec("DELETE FROM chat_session_transcriptions WHERE chat_session_id = ?", session.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete chat session transcriptions: %w", err)
+			}
+		}
+		if err := tx.Where("transcription_id = ?", jobID).Delete(&models.ChatSession{}).Error; err != nil {
+			return fmt.Errorf("failed to delete chat sessions: %w", err)
+		}
 
-	// Finally delete the main job record
-	if err := tx.Delete(&job).Error; err != nil {
-		tx.Rollback()
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete job from database"})
-		return
+		// Finally delete the main job record
+		if err := tx.Delete(job).Error; err != nil {
+			return fmt.Errorf("failed to delete job: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	// Commit the transaction
-	if err := tx.Commit().Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit deletion transaction"})
-		return
+	if err := search.RemoveJob(jobID); err != nil {
+		logger.Warn("Failed to remove job from search index", "job_id", jobID, "error", err)
 	}
-
-	c.JSON(http.StatusOK, gin.H{"message": "Job deleted successfully"})
+	return nil
 }
 
 // @Summary Get transcription record by ID
@@ -1449,7 +1918,7 @@ func (h *Handler) GetJobByID(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1461,6 +1930,35 @@ func (h *Handler) GetJobByID(c *gin.Context) {
 	c.JSON(http.StatusOK, job)
 }
 
+// @Summary List transcription job attempts
+// @Description List every execution of a transcription job, oldest first, including failed attempts and why they failed
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {array} models.TranscriptionJobExecution
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/executions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListJobExecutions(c *gin.Context) {
+	jobID := c.Param("id")
+
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
+		return
+	}
+
+	var executions []models.TranscriptionJobExecution
+	if err := database.DB.Where("transcription_job_id = ?", jobID).
+		Order("started_at ASC").
+		Find(&executions).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get executions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, executions)
+}
+
 // @Summary Get transcription job execution data
 // @Description Get execution parameters and timing for a transcription job
 // @Tags transcription
@@ -1476,7 +1974,7 @@ func (h *Handler) GetJobExecutionData(c *gin.Context) {
 
 	// Get the transcription job to check if it's multi-track
 	var job models.TranscriptionJob
-	if err := database.DB.Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Preload("MultiTrackFiles").Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
 			return
@@ -1499,17 +1997,19 @@ func (h *Handler) GetJobExecutionData(c *gin.Context) {
 
 	// Create enhanced response with multi-track data
 	response := gin.H{
-		"id":                   execution.ID,
-		"transcription_job_id": execution.TranscriptionJobID,
-		"started_at":           execution.StartedAt,
-		"completed_at":         execution.CompletedAt,
-		"processing_duration":  execution.ProcessingDuration,
-		"actual_parameters":    execution.ActualParameters,
-		"status":               execution.Status,
-		"error_message":        execution.ErrorMessage,
-		"created_at":           execution.CreatedAt,
-		"updated_at":           execution.UpdatedAt,
-		"is_multi_track":       job.IsMultiTrack,
+		"id":                    execution.ID,
+		"transcription_job_id":  execution.TranscriptionJobID,
+		"started_at":            execution.StartedAt,
+		"completed_at":          execution.CompletedAt,
+		"processing_duration":   execution.ProcessingDuration,
+		"actual_parameters":     execution.ActualParameters,
+		"status":                execution.Status,
+		"error_message":         execution.ErrorMessage,
+		"attempt":               execution.Attempt,
+		"preprocessing_filters": execution.PreprocessingFilters,
+		"created_at":            execution.CreatedAt,
+		"updated_at":            execution.UpdatedAt,
+		"is_multi_track":        job.IsMultiTrack,
 	}
 
 	// Add multi-track specific data if available
@@ -1545,7 +2045,7 @@ func (h *Handler) GetAudioFile(c *gin.Context) {
 	jobID := c.Param("id")
 
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
 			return
@@ -1641,6 +2141,12 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
+	if user.Disabled {
+		logger.AuthEvent("login", req.Username, c.ClientIP(), false, "account_disabled")
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
+
 	token, err := h.authService.GenerateToken(&user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -1656,6 +2162,7 @@ func (h *Handler) Login(c *gin.Context) {
 	response := LoginResponse{Token: token}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
+	response.User.Role = user.Role
 
 	logger.AuthEvent("login", req.Username, c.ClientIP(), true)
 	c.JSON(http.StatusOK, response)
@@ -1687,9 +2194,10 @@ func (h *Handler) Logout(c *gin.Context) {
 }
 
 // @Summary Check registration status
-// @Description Check if the application requires initial user registration
+// @Description Check if the application requires initial user registration, or whether an invitation token allows registering
 // @Tags auth
 // @Produce json
+// @Param invitation query string false "Invitation token"
 // @Success 200 {object} RegistrationStatusResponse
 // @Router /api/v1/auth/registration-status [get]
 func (h *Handler) GetRegistrationStatus(c *gin.Context) {
@@ -1702,18 +2210,27 @@ func (h *Handler) GetRegistrationStatus(c *gin.Context) {
 	response := RegistrationStatusResponse{
 		RegistrationEnabled: userCount == 0,
 	}
+	if userCount > 0 {
+		if token := c.Query("invitation"); token != "" {
+			if invitation, err := findOpenInvitation(database.DB, token); err == nil {
+				response.RegistrationEnabled = true
+				response.InvitationRole = invitation.Role
+			}
+		}
+	}
 
 	c.JSON(http.StatusOK, response)
 }
 
-// @Summary Register initial admin user
-// @Description Register the initial admin user (only allowed when no users exist)
+// @Summary Register a user
+// @Description Register the initial admin user when no users exist. Once they do, registering requires an invitation token and the new user gets the invitation's role.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body RegisterRequest true "Registration details"
 // @Success 201 {object} LoginResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Router /api/v1/auth/register [post]
 func (h *Handler) Register(c *gin.Context) {
@@ -1724,23 +2241,43 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
-	if userCount > 0 {
-		c.JSON(http.StatusConflict, gin.H{"error": "Registration is not allowed. Admin user already exists"})
-		return
-	}
-
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
+	// The first user is the admin; everyone after them needs an invitation
+	role := models.RoleAdmin
+	var invitation *models.Invitation
+	if userCount > 0 {
+		if req.InvitationToken == "" {
+			c.JSON(http.StatusConflict, gin.H{"error": "Registration is not allowed. Admin user already exists"})
+			return
+		}
+		found, err := findOpenInvitation(database.DB, req.InvitationToken)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired invitation"})
+			return
+		}
+		invitation = found
+		role = found.Role
+	}
+
 	// Validate password confirmation
 	if req.Password != req.ConfirmPassword {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Passwords do not match"})
 		return
 	}
 
+	if taken, err := usernameTaken(database.DB, req.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing users"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
 	// Hash password
 	hashedPassword, err := auth.HashPassword(req.Password)
 	if err != nil {
@@ -1752,17 +2289,34 @@ func (h *Handler) Register(c *gin.Context) {
 	user := models.User{
 		Username: req.Username,
 		Password: hashedPassword,
+		Role:     role,
 	}
 
-	if err := database.DB.Create(&user).Error; err != nil {
-		if database.DB.Error.Error() == "UNIQUE constraint failed: users.username" {
-			c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		if invitation != nil {
+			return acceptInvitation(tx, invitation, user.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errInvitationUnavailable) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired invitation"})
 			return
 		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
 		return
 	}
 
+	// The first user takes ownership of anything created before registration
+	if userCount == 0 {
+		if err := database.AssignUnownedRecords(user.ID); err != nil {
+			logger.Warn("Failed to assign existing records to new user", "user_id", user.ID, "error", err)
+		}
+	}
+
 	// Generate token for immediate login
 	token, err := h.authService.GenerateToken(&user)
 	if err != nil {
@@ -1777,6 +2331,7 @@ func (h *Handler) Register(c *gin.Context) {
 	response := LoginResponse{Token: token}
 	response.User.ID = user.ID
 	response.User.Username = user.Username
+	response.User.Role = user.Role
 
 	c.JSON(http.StatusCreated, response)
 }
@@ -1809,6 +2364,10 @@ func (h *Handler) Refresh(c *gin.Context) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
 		return
 	}
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		return
+	}
 	token, err := h.authService.GenerateToken(&user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
@@ -1997,7 +2556,7 @@ func (h *Handler) ChangeUsername(c *gin.Context) {
 // @Router /api/v1/api-keys [get]
 func (h *Handler) ListAPIKeys(c *gin.Context) {
 	var apiKeys []models.APIKey
-	if err := database.DB.Where("is_active = ?", true).Find(&apiKeys).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("is_active = ?", true).Find(&apiKeys).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API keys"})
 		return
 	}
@@ -2012,13 +2571,14 @@ func (h *Handler) ListAPIKeys(c *gin.Context) {
 }
 
 // @Summary Create API key
-// @Description Create a new API key for external API access
+// @Description Create a new API key for external API access. The key is stored hashed and only returned by this call. Scopes limit what the key can do: transcripts:read, transcribe:write, chat and admin (admins only).
 // @Tags api-keys
 // @Accept json
 // @Produce json
 // @Param request body CreateAPIKeyRequest true "API key creation details"
-// @Success 201 {object} CreateAPIKeyResponse
+// @Success 200 {object} CreateAPIKeyResponse
 // @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
 // @Security BearerAuth
 // @Router /api/v1/api-keys [post]
 func (h *Handler) CreateAPIKey(c *gin.Context) {
@@ -2028,14 +2588,35 @@ func (h *Handler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
+	scopes, err := normalizeAPIKeyScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	for _, scope := range scopes {
+		if scope == models.ScopeAdmin && c.GetString("role") != models.RoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can create keys with the admin scope"})
+			return
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_at must be in the future"})
+		return
+	}
+
 	// Generate a secure API key
 	apiKey := generateSecureAPIKey(32)
 
-	// Create the API key record
+	// Create the API key record; only the hash of the key is stored
 	newKey := models.APIKey{
-		Key:         apiKey,
+		UserID:      currentUserID(c),
+		Key:         auth.HashAPIKey(apiKey),
+		KeyPrefix:   auth.APIKeyPrefix(apiKey),
+		Hashed:      true,
 		Name:        req.Name,
 		Description: &req.Description,
+		Scopes:      scopes,
+		ExpiresAt:   req.ExpiresAt,
 		IsActive:    true,
 	}
 
@@ -2044,8 +2625,38 @@ func (h *Handler) CreateAPIKey(c *gin.Context) {
 		return
 	}
 
-	// Return full model with 200 to match tests
-	c.JSON(http.StatusOK, newKey)
+	// Return with 200 to match tests
+	c.JSON(http.StatusOK, CreateAPIKeyResponse{
+		ID:          newKey.ID,
+		Key:         apiKey,
+		Name:        newKey.Name,
+		Description: req.Description,
+		Scopes:      newKey.Scopes,
+		ExpiresAt:   newKey.ExpiresAt,
+		IsActive:    newKey.IsActive,
+		CreatedAt:   newKey.CreatedAt,
+	})
+}
+
+// normalizeAPIKeyScopes validates requested API key scopes and drops repeated ones. No scopes
+// means every scope but admin.
+func normalizeAPIKeyScopes(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return models.DefaultAPIKeyScopes(), nil
+	}
+	var scopes []string
+	seen := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if !models.ValidScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q; use transcripts:read, transcribe:write, chat or admin", scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
 }
 
 // @Summary Delete API key
@@ -2067,7 +2678,7 @@ func (h *Handler) DeleteAPIKey(c *gin.Context) {
 
 	// Check if the API key exists
 	var apiKey models.APIKey
-	if err := database.DB.First(&apiKey, uint(id)).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).First(&apiKey, uint(id)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
 		return
 	}
@@ -2254,6 +2865,80 @@ func (h *Handler) HealthCheck(c *gin.Context) {
 }
 
 // Helper functions
+// priorityRangeError is returned for priorities outside MinPriority..MaxPriority
+var priorityRangeError = fmt.Sprintf("priority must be between %d and %d", models.MinPriority, models.MaxPriority)
+
+// namedPriorities maps the accepted priority names to their values
+var namedPriorities = map[string]int{
+	"low":    models.PriorityLow,
+	"normal": models.PriorityNormal,
+	"high":   models.PriorityHigh,
+	"urgent": models.PriorityUrgent,
+}
+
+func validPriority(priority int) bool {
+	return priority >= models.MinPriority && priority <= models.MaxPriority
+}
+
+// parsePriority accepts a priority name (low, normal, high, urgent) or a number
+func parsePriority(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if priority, ok := namedPriorities[value]; ok {
+		return priority, nil
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("priority must be low, normal, high, urgent or a number")
+	}
+	if !validPriority(priority) {
+		return 0, fmt.Errorf("%s", priorityRangeError)
+	}
+	return priority, nil
+}
+
+// formPriority reads the optional "priority" form field, defaulting to normal
+func formPriority(c *gin.Context) (int, error) {
+	value := c.PostForm("priority")
+	if value == "" {
+		return models.PriorityNormal, nil
+	}
+	return parsePriority(value)
+}
+
+// validatePreprocessing checks the audio enhancement settings of a job or profile
+func validatePreprocessing(params models.WhisperXParams) error {
+	if err := pipeline.ValidateDenoise(params.Denoise, params.DenoiseModel); err != nil {
+		return err
+	}
+	if params.HighpassFrequency < 0 || params.HighpassFrequency > 1000 {
+		return fmt.Errorf("highpass_frequency must be between 0 and 1000 Hz")
+	}
+	if params.Loudnorm && params.LoudnormTarget != 0 && (params.LoudnormTarget < -70 || params.LoudnormTarget > -5) {
+		return fmt.Errorf("loudnorm_target must be between -70 and -5 LUFS")
+	}
+	return nil
+}
+
+// validateSpeakerEmbeddings rejects voice identification with a diarization model that produces
+// no voice embeddings
+func validateSpeakerEmbeddings(params models.WhisperXParams) error {
+	if params.SpeakerEmbeddings && params.DiarizeModel == "nvidia_sortformer" {
+		return fmt.Errorf("speaker_embeddings requires pyannote diarization: nvidia_sortformer does not produce voice embeddings")
+	}
+	return nil
+}
+
+// formList reads a comma-separated form field, dropping empty entries
+func formList(c *gin.Context, key string) []string {
+	var values []string
+	for _, value := range strings.Split(c.PostForm(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getFormValueWithDefault(c *gin.Context, key, defaultValue string) string {
 	if value := c.PostForm(key); value != "" {
 		return value
@@ -2300,7 +2985,7 @@ func getFormBoolWithDefault(c *gin.Context, key string, defaultValue bool) bool
 // @Security BearerAuth
 func (h *Handler) ListProfiles(c *gin.Context) {
 	var profiles []models.TranscriptionProfile
-	if err := database.DB.Order("created_at DESC").Find(&profiles).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Order("created_at DESC").Find(&profiles).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch profiles"})
 		return
 	}
@@ -2330,10 +3015,23 @@ func (h *Handler) CreateProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile name is required"})
 		return
 	}
+	if err := validatePreprocessing(profile.Parameters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSpeakerEmbeddings(profile.Parameters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateReplacementDictionaries(c, profile.Parameters.ReplacementDictionaries); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Check if profile name already exists
+	profile.UserID = currentUserID(c)
 	var existingProfile models.TranscriptionProfile
-	if err := database.DB.Where("name = ?", profile.Name).First(&existingProfile).Error; err == nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("name = ?", profile.Name).First(&existingProfile).Error; err == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile name already exists"})
 		return
 	}
@@ -2361,7 +3059,7 @@ func (h *Handler) GetProfile(c *gin.Context) {
 	profileID := c.Param("id")
 
 	var profile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", profileID).First(&profile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", profileID).First(&profile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
@@ -2390,7 +3088,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 	profileID := c.Param("id")
 
 	var existingProfile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", profileID).First(&existingProfile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", profileID).First(&existingProfile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
@@ -2410,16 +3108,29 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile name is required"})
 		return
 	}
+	if err := validatePreprocessing(updatedProfile.Parameters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateSpeakerEmbeddings(updatedProfile.Parameters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateReplacementDictionaries(c, updatedProfile.Parameters.ReplacementDictionaries); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Check if profile name already exists (excluding current profile)
 	var nameCheck models.TranscriptionProfile
-	if err := database.DB.Where("name = ? AND id != ?", updatedProfile.Name, profileID).First(&nameCheck).Error; err == nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("name = ? AND id != ?", updatedProfile.Name, profileID).First(&nameCheck).Error; err == nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Profile name already exists"})
 		return
 	}
 
 	// Update the profile
 	updatedProfile.ID = profileID // Ensure ID doesn't change
+	updatedProfile.UserID = existingProfile.UserID
 	if err := database.DB.Save(&updatedProfile).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update profile"})
 		return
@@ -2442,7 +3153,7 @@ func (h *Handler) DeleteProfile(c *gin.Context) {
 	profileID := c.Param("id")
 
 	var profile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", profileID).First(&profile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", profileID).First(&profile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
@@ -2481,7 +3192,7 @@ func (h *Handler) SetDefaultProfile(c *gin.Context) {
 
 	// Find the profile
 	var profile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", profileID).First(&profile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", profileID).First(&profile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
@@ -2535,7 +3246,7 @@ func (h *Handler) SubmitQuickTranscription(c *gin.Context) {
 	if profileName := c.PostForm("profile_name"); profileName != "" {
 		// Load parameters from profile
 		var profile models.TranscriptionProfile
-		if err := database.DB.Where("name = ?", profileName).First(&profile).Error; err != nil {
+		if err := database.DB.Scopes(ownedBy(c)).Where("name = ?", profileName).First(&profile).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Profile '%s' not found", profileName)})
 				return
@@ -2669,6 +3380,15 @@ func (h *Handler) DownloadFromYouTube(c *gin.Context) {
 		return
 	}
 
+	priority := models.PriorityNormal
+	if req.Priority != nil {
+		if !validPriority(*req.Priority) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": priorityRangeError})
+			return
+		}
+		priority = *req.Priority
+	}
+
 	// Create upload directory
 	uploadDir := h.config.UploadDir
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
@@ -2733,8 +3453,10 @@ func (h *Handler) DownloadFromYouTube(c *gin.Context) {
 	// Create transcription record
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    currentUserID(c),
 		AudioPath: actualFilePath,
 		Status:    models.StatusPending, // Automatically start pending
+		Priority:  priority,
 	}
 
 	// Set title
@@ -2840,7 +3562,7 @@ func (h *Handler) GetUserDefaultProfile(c *gin.Context) {
 	// If user has no default profile set, return the first available profile or no profile
 	if user.DefaultProfileID == nil {
 		var firstProfile models.TranscriptionProfile
-		if err := database.DB.Order("created_at ASC").First(&firstProfile).Error; err != nil {
+		if err := database.DB.Scopes(ownedBy(c)).Order("created_at ASC").First(&firstProfile).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "No profiles available"})
 				return
@@ -2854,11 +3576,11 @@ func (h *Handler) GetUserDefaultProfile(c *gin.Context) {
 
 	// Get the user's default profile
 	var profile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", *user.DefaultProfileID).First(&profile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", *user.DefaultProfileID).First(&profile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Default profile no longer exists, fall back to first available
 			var firstProfile models.TranscriptionProfile
-			if err := database.DB.Order("created_at ASC").First(&firstProfile).Error; err != nil {
+			if err := database.DB.Scopes(ownedBy(c)).Order("created_at ASC").First(&firstProfile).Error; err != nil {
 				if err == gorm.ErrRecordNotFound {
 					c.JSON(http.StatusNotFound, gin.H{"error": "No profiles available"})
 					return
@@ -2907,7 +3629,7 @@ func (h *Handler) SetUserDefaultProfile(c *gin.Context) {
 
 	// Verify the profile exists
 	var profile models.TranscriptionProfile
-	if err := database.DB.Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", req.ProfileID).First(&profile).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
 			return
@@ -2930,6 +3652,7 @@ type UserSettingsResponse struct {
 	AutoTranscriptionEnabled bool    `json:"auto_transcription_enabled"`
 	FastFinalizeEnabled      bool    `json:"fast_finalize_enabled"`
 	DefaultProfileID         *string `json:"default_profile_id,omitempty"`
+	Role                     string  `json:"role"` // Read-only; changed by admins
 }
 
 // UpdateUserSettingsRequest represents the request to update user settings
@@ -2964,6 +3687,7 @@ func (h *Handler) GetUserSettings(c *gin.Context) {
 		AutoTranscriptionEnabled: user.AutoTranscriptionEnabled,
 		FastFinalizeEnabled:      user.FastFinalizeEnabled,
 		DefaultProfileID:         user.DefaultProfileID,
+		Role:                     user.Role,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -3018,6 +3742,7 @@ func (h *Handler) UpdateUserSettings(c *gin.Context) {
 		AutoTranscriptionEnabled: user.AutoTranscriptionEnabled,
 		FastFinalizeEnabled:      user.FastFinalizeEnabled,
 		DefaultProfileID:         user.DefaultProfileID,
+		Role:                     user.Role,
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -3036,9 +3761,10 @@ type SpeakerMappingsUpdateRequest struct {
 
 // SpeakerMappingResponse represents a speaker mapping response
 type SpeakerMappingResponse struct {
-	ID              uint   `json:"id"`
-	OriginalSpeaker string `json:"original_speaker"`
-	CustomName      string `json:"custom_name"`
+	ID              uint    `json:"id"`
+	OriginalSpeaker string  `json:"original_speaker"`
+	CustomName      string  `json:"custom_name"`
+	VoiceProfileID  *string `json:"voice_profile_id,omitempty"` // Set when the name came from a matched voice profile
 }
 
 // GetSpeakerMappings retrieves all speaker mappings for a transcription
@@ -3060,7 +3786,7 @@ func (h *Handler) GetSpeakerMappings(c *gin.Context) {
 
 	// Verify the transcription job exists and has diarization enabled
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
 			return
@@ -3089,6 +3815,7 @@ func (h *Handler) GetSpeakerMappings(c *gin.Context) {
 			ID:              mapping.ID,
 			OriginalSpeaker: mapping.OriginalSpeaker,
 			CustomName:      mapping.CustomName,
+			VoiceProfileID:  mapping.VoiceProfileID,
 		}
 	}
 
@@ -3121,7 +3848,7 @@ func (h *Handler) UpdateSpeakerMappings(c *gin.Context) {
 
 	// Verify the transcription job exists and has diarization enabled
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", jobID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
 			return
@@ -3170,7 +3897,10 @@ func (h *Handler) UpdateSpeakerMappings(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query speaker mapping"})
 			return
 		} else {
-			// Update existing mapping
+			// Update existing mapping; a new name no longer comes from a voice profile
+			if speakerMapping.CustomName != mapping.CustomName {
+				speakerMapping.VoiceProfileID = nil
+			}
 			speakerMapping.CustomName = mapping.CustomName
 			if err := tx.Save(&speakerMapping).Error; err != nil {
 				tx.Rollback()
@@ -3191,6 +3921,7 @@ func (h *Handler) UpdateSpeakerMappings(c *gin.Context) {
 			ID:              mapping.ID,
 			OriginalSpeaker: mapping.OriginalSpeaker,
 			CustomName:      mapping.CustomName,
+			VoiceProfileID:  mapping.VoiceProfileID,
 		}
 	}
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // CreateLiveSessionRequest models the payload to bootstrap a live transcription session.
@@ -35,6 +36,7 @@ func (h *Handler) CreateLiveSession(c *gin.Context) {
 	}
 
 	session, err := h.liveTranscription.CreateSession(c.Request.Context(), transcription.CreateLiveSessionInput{
+		UserID:     currentUserID(c),
 		Title:      req.Title,
 		Parameters: req.Parameters,
 	})
@@ -46,12 +48,24 @@ func (h *Handler) CreateLiveSession(c *gin.Context) {
 	c.JSON(http.StatusOK, session)
 }
 
+// findOwnedLiveSession loads a live session of the current user, writing the error response if it can't
+func findOwnedLiveSession(c *gin.Context, id string) (*models.LiveTranscriptionSession, bool) {
+	var session models.LiveTranscriptionSession
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", id).First(&session).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Live session not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get live session"})
+		return nil, false
+	}
+	return &session, true
+}
+
 // GetLiveSession returns the current state of a live session.
 func (h *Handler) GetLiveSession(c *gin.Context) {
-	sessionID := c.Param("session_id")
-	session, err := h.liveTranscription.GetSession(c.Request.Context(), sessionID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	session, ok := findOwnedLiveSession(c, c.Param("session_id"))
+	if !ok {
 		return
 	}
 	c.JSON(http.StatusOK, session)
@@ -59,6 +73,11 @@ func (h *Handler) GetLiveSession(c *gin.Context) {
 
 // UploadLiveChunk ingests a single audio chunk for live processing.
 func (h *Handler) UploadLiveChunk(c *gin.Context) {
+	sessionID := c.Param("session_id")
+	if _, ok := findOwnedLiveSession(c, sessionID); !ok {
+		return
+	}
+
 	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to parse form data"})
 		return
@@ -87,7 +106,6 @@ func (h *Handler) UploadLiveChunk(c *gin.Context) {
 		endOffset = 0
 	}
 
-	sessionID := c.Param("session_id")
 	result, err := h.liveTranscription.AppendChunk(c.Request.Context(), sessionID, transcription.ChunkMetadata{
 		Sequence:    seq,
 		StartOffset: startOffset,
@@ -115,6 +133,9 @@ func (h *Handler) StreamLiveSession(c *gin.Context) {
 	}
 
 	sessionID := c.Param("session_id")
+	if _, ok := findOwnedLiveSession(c, sessionID); !ok {
+		return
+	}
 	snapshots, stream, cancel, err := h.liveTranscription.Subscribe(c.Request.Context(), sessionID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -164,6 +185,9 @@ func (h *Handler) StreamLiveSession(c *gin.Context) {
 // FinalizeLiveSession closes the live session, merges audio, and enqueues a traditional job.
 func (h *Handler) FinalizeLiveSession(c *gin.Context) {
 	sessionID := c.Param("session_id")
+	if _, ok := findOwnedLiveSession(c, sessionID); !ok {
+		return
+	}
 	skipReprocessing := c.Query("skip_reprocessing") == "true"
 
 	finalizeResult, err := h.liveTranscription.FinalizeSession(c.Request.Context(), sessionID)
@@ -177,6 +201,7 @@ func (h *Handler) FinalizeLiveSession(c *gin.Context) {
 	title := session.Title
 	job := &models.TranscriptionJob{
 		ID:         jobID,
+		UserID:     currentUserID(c),
 		AudioPath:  finalizeResult.MergedAudio,
 		Status:     models.StatusPending,
 		Parameters: session.Parameters,
@@ -256,6 +281,9 @@ func (h *Handler) FinalizeLiveSession(c *gin.Context) {
 // CancelLiveSession aborts a live session.
 func (h *Handler) CancelLiveSession(c *gin.Context) {
 	sessionID := c.Param("session_id")
+	if _, ok := findOwnedLiveSession(c, sessionID); !ok {
+		return
+	}
 	session, err := h.liveTranscription.CancelSession(c.Request.Context(), sessionID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -50,7 +50,7 @@ func (h *Handler) ListNotes(c *gin.Context) {
 
 	// Ensure transcription exists
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", transcriptionID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", transcriptionID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
 			return
@@ -111,7 +111,7 @@ func (h *Handler) CreateNote(c *gin.Context) {
 
 	// Ensure transcription exists
 	var job models.TranscriptionJob
-	if err := database.DB.Where("id = ?", transcriptionID).First(&job).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", transcriptionID).First(&job).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			log.Printf("notes.CreateNote: transcription %s not found", transcriptionID)
 			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
@@ -125,6 +125,7 @@ func (h *Handler) CreateNote(c *gin.Context) {
 	n := models.Note{
 		ID:              uuid.New().String(),
 		TranscriptionID: transcriptionID,
+		UserID:          job.UserID,
 		StartWordIndex:  req.StartWordIndex,
 		EndWordIndex:    req.EndWordIndex,
 		StartTime:       req.StartTime,
@@ -160,7 +161,7 @@ func (h *Handler) CreateNote(c *gin.Context) {
 func (h *Handler) GetNote(c *gin.Context) {
 	noteID := c.Param("note_id")
 	var n models.Note
-	if err := database.DB.Where("id = ?", noteID).First(&n).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", noteID).First(&n).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
 			return
@@ -194,7 +195,7 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 	}
 
 	var n models.Note
-	if err := database.DB.Where("id = ?", noteID).First(&n).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", noteID).First(&n).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Note not found"})
 			return
@@ -226,7 +227,7 @@ func (h *Handler) UpdateNote(c *gin.Context) {
 // @Router /api/v1/notes/{note_id} [delete]
 func (h *Handler) DeleteNote(c *gin.Context) {
 	noteID := c.Param("note_id")
-	if err := database.DB.Delete(&models.Note{}, "id = ?", noteID).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Delete(&models.Note{}, "id = ?", noteID).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete note"})
 		return
 	}
@@ -0,0 +1,181 @@
+package api
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/whisperapi"
+	"synthezia/pkg/logger"
+)
+
+// openAIError writes an error body in the shape OpenAI clients parse
+func openAIError(c *gin.Context, status int, message, param string) {
+	errType := "invalid_request_error"
+	if status >= 500 {
+		errType = "server_error"
+	}
+	body := gin.H{"message": message, "type": errType, "param": nil, "code": nil}
+	if param != "" {
+		body["param"] = param
+	}
+	c.JSON(status, gin.H{"error": body})
+}
+
+// CreateOpenAITranscription transcribes audio using the OpenAI Whisper API contract
+// @Summary OpenAI-compatible transcription
+// @Description Synchronously transcribe an audio file. Accepts the same multipart form as OpenAI's /v1/audio/transcriptions. The model may be whisper-1 (your default profile), a WhisperX size such as large-v3, parakeet, canary, or the name of one of your transcription profiles. Authenticate with an API key as a Bearer token.
+// @Tags openai
+// @Accept multipart/form-data
+// @Produce json
+// @Produce plain
+// @Param file formData file true "Audio file"
+// @Param model formData string true "Model name"
+// @Param language formData string false "ISO-639-1 language of the audio"
+// @Param prompt formData string false "Text to guide the model's style"
+// @Param response_format formData string false "json, verbose_json, text, srt or vtt" default(json)
+// @Param temperature formData number false "Sampling temperature"
+// @Param timestamp_granularities[] formData []string false "segment and/or word (verbose_json only)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /v1/audio/transcriptions [post]
+// @Security BearerAuth
+func (h *Handler) CreateOpenAITranscription(c *gin.Context) {
+	h.handleOpenAIAudio(c, false)
+}
+
+// CreateOpenAITranslation translates audio into English using the OpenAI Whisper API contract
+// @Summary OpenAI-compatible translation
+// @Description Synchronously translate speech into English text. Accepts the same multipart form as OpenAI's /v1/audio/translations. Only Whisper models can translate. Authenticate with an API key as a Bearer token.
+// @Tags openai
+// @Accept multipart/form-data
+// @Produce json
+// @Produce plain
+// @Param file formData file true "Audio file"
+// @Param model formData string true "Model name"
+// @Param prompt formData string false "Text to guide the model's style"
+// @Param response_format formData string false "json, verbose_json, text, srt or vtt" default(json)
+// @Param temperature formData number false "Sampling temperature"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /v1/audio/translations [post]
+// @Security BearerAuth
+func (h *Handler) CreateOpenAITranslation(c *gin.Context) {
+	h.handleOpenAIAudio(c, true)
+}
+
+// handleOpenAIAudio parses the OpenAI form, runs the transcription and renders the response
+func (h *Handler) handleOpenAIAudio(c *gin.Context, translate bool) {
+	req := whisperapi.Request{
+		Model:          strings.TrimSpace(c.PostForm("model")),
+		Prompt:         c.PostForm("prompt"),
+		ResponseFormat: strings.ToLower(c.DefaultPostForm("response_format", whisperapi.FormatJSON)),
+		Translate:      translate,
+	}
+	if !translate {
+		// Translations always produce English, so the source language is detected
+		req.Language = strings.TrimSpace(c.PostForm("language"))
+		req.TimestampGranularities = append(c.PostFormArray("timestamp_granularities[]"), c.PostFormArray("timestamp_granularities")...)
+	}
+
+	if req.Model == "" {
+		openAIError(c, http.StatusBadRequest, "you must provide a model parameter", "model")
+		return
+	}
+	if !whisperapi.IsSupportedFormat(req.ResponseFormat) {
+		openAIError(c, http.StatusBadRequest, "response_format must be one of: "+strings.Join(whisperapi.SupportedFormats, ", "), "response_format")
+		return
+	}
+	if v := c.PostForm("temperature"); v != "" {
+		t, err := strconv.ParseFloat(v, 64)
+		if err != nil || t < 0 || t > 1 {
+			openAIError(c, http.StatusBadRequest, "temperature must be a number between 0 and 1", "temperature")
+			return
+		}
+		req.Temperature = &t
+	}
+	for _, g := range req.TimestampGranularities {
+		if g != whisperapi.GranularitySegment && g != whisperapi.GranularityWord {
+			openAIError(c, http.StatusBadRequest, "timestamp_granularities must contain only segment or word", "timestamp_granularities")
+			return
+		}
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		openAIError(c, http.StatusBadRequest, "you must provide a file parameter", "file")
+		return
+	}
+
+	params, ok := h.openAIModelParams(c, req.Model)
+	if !ok {
+		openAIError(c, http.StatusBadRequest, "The model '"+req.Model+"' does not exist", "model")
+		return
+	}
+	if translate && !whisperapi.SupportsTranslation(params) {
+		openAIError(c, http.StatusBadRequest, "The model '"+req.Model+"' does not support translation", "model")
+		return
+	}
+	params = whisperapi.ApplyRequest(params, req)
+
+	if h.unifiedProcessor == nil {
+		openAIError(c, http.StatusServiceUnavailable, "Transcription service is not available", "")
+		return
+	}
+
+	tempDir := filepath.Join(h.config.UploadDir, "openai")
+	if err := os.MkdirAll(tempDir, 0755); err != nil {
+		openAIError(c, http.StatusInternalServerError, "Failed to store uploaded file", "")
+		return
+	}
+	audioPath := filepath.Join(tempDir, uuid.New().String()+filepath.Ext(header.Filename))
+	if err := c.SaveUploadedFile(header, audioPath); err != nil {
+		openAIError(c, http.StatusInternalServerError, "Failed to store uploaded file", "")
+		return
+	}
+	defer os.Remove(audioPath)
+
+	result, err := h.unifiedProcessor.GetUnifiedService().TranscribeFile(c.Request.Context(), audioPath, params, "openai-api")
+	if err != nil {
+		logger.Error("OpenAI-compatible transcription failed", "model", req.Model, "translate", translate, "error", err)
+		openAIError(c, http.StatusInternalServerError, "Transcription failed: "+err.Error(), "")
+		return
+	}
+
+	output, err := whisperapi.Render(result, req)
+	if err != nil {
+		openAIError(c, http.StatusInternalServerError, "Failed to render transcript", "")
+		return
+	}
+	c.Data(http.StatusOK, output.ContentType, output.Data)
+}
+
+// openAIModelParams resolves the requested model: whisper-1 uses the caller's default profile,
+// other names match one of the caller's profiles or a built-in model
+func (h *Handler) openAIModelParams(c *gin.Context, model string) (models.WhisperXParams, bool) {
+	var profile models.TranscriptionProfile
+
+	if model == whisperapi.DefaultModel {
+		var user models.User
+		if err := database.DB.First(&user, currentUserID(c)).Error; err == nil && user.DefaultProfileID != nil {
+			if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", *user.DefaultProfileID).First(&profile).Error; err == nil {
+				return profile.Parameters, true
+			}
+		}
+		return whisperapi.DefaultParams(), true
+	}
+
+	if err := database.DB.Scopes(ownedBy(c)).Where("name = ?", model).First(&profile).Error; err == nil {
+		return profile.Parameters, true
+	}
+	return whisperapi.ModelParams(model)
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+)
+
+// currentUserID returns the ID of the authenticated user set by the auth middleware.
+// API keys resolve to the user that owns them.
+func currentUserID(c *gin.Context) uint {
+	if v, exists := c.Get("user_id"); exists {
+		if id, ok := v.(uint); ok {
+			return id
+		}
+	}
+	return 0
+}
+
+// ownedBy scopes a query to rows owned by the authenticated user
+func ownedBy(c *gin.Context) func(*gorm.DB) *gorm.DB {
+	userID := currentUserID(c)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ?", userID)
+	}
+}
+
+// userOwnsJob reports whether the authenticated user owns the transcription job
+func userOwnsJob(c *gin.Context, jobID string) bool {
+	var count int64
+	database.DB.Model(&models.TranscriptionJob{}).Scopes(ownedBy(c)).Where("id = ?", jobID).Count(&count)
+	return count > 0
+}
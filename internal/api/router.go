@@ -2,6 +2,7 @@ package api
 
 import (
 	"synthezia/internal/auth"
+	"synthezia/internal/models"
 	"synthezia/internal/web"
 	"synthezia/pkg/logger"
 	"synthezia/pkg/middleware"
@@ -49,6 +50,19 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 	// Swagger documentation
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Middleware of route groups holding user content: viewers may only read, and API keys
+	// need the scopes matching the request
+	contentAccess := []gin.HandlerFunc{
+		middleware.AuthMiddleware(authService),
+		middleware.WriteAccessMiddleware(),
+		middleware.ReadWriteScopeMiddleware(models.ScopeTranscriptsRead, models.ScopeTranscribeWrite),
+	}
+	chatAccess := []gin.HandlerFunc{
+		middleware.AuthMiddleware(authService),
+		middleware.WriteAccessMiddleware(),
+		middleware.ScopeMiddleware(models.ScopeChat),
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
@@ -81,9 +95,29 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 			apiKeys.DELETE("/:id", handler.DeleteAPIKey)
 		}
 
+		// User management routes (admins only)
+		users := v1.Group("/users")
+		users.Use(middleware.JWTOnlyMiddleware(authService), middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			users.GET("/", handler.ListUsers)
+			users.POST("/", handler.CreateUser)
+			users.PUT("/:id/role", handler.UpdateUserRole)
+			users.POST("/:id/disable", handler.DisableUser)
+			users.POST("/:id/enable", handler.EnableUser)
+		}
+
+		// Invitation routes (admins only)
+		invitations := v1.Group("/invitations")
+		invitations.Use(middleware.JWTOnlyMiddleware(authService), middleware.RoleMiddleware(models.RoleAdmin))
+		{
+			invitations.GET("/", handler.ListInvitations)
+			invitations.POST("/", handler.CreateInvitation)
+			invitations.DELETE("/:id", handler.DeleteInvitation)
+		}
+
 		// Transcription routes (require authentication)
 		transcription := v1.Group("/transcription")
-		transcription.Use(middleware.AuthMiddleware(authService))
+		transcription.Use(contentAccess...)
 		{
 			// File upload routes - disable compression for these
 			uploadRoutes := transcription.Group("")
@@ -98,18 +132,30 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 			// Regular API routes with compression
 			transcription.POST("/youtube", handler.DownloadFromYouTube)
 			transcription.POST("/submit", handler.SubmitJob)
+			transcription.POST("/bulk/delete", handler.BulkDeleteJobs)
+			transcription.POST("/bulk/retranscribe", handler.BulkRetranscribeJobs)
+			transcription.POST("/bulk/tags", handler.BulkTagJobs)
+			transcription.POST("/bulk/move", handler.BulkMoveJobs)
+			transcription.POST("/bulk/export", handler.BulkExportJobs)
 			transcription.POST("/:id/start", handler.StartTranscription)
+			transcription.POST("/:id/rediarize", handler.RediarizeJob)
 			transcription.POST("/:id/kill", handler.KillJob)
 			transcription.GET("/:id/status", handler.GetJobStatus)
 			transcription.GET("/:id/transcript", handler.GetTranscript)
+			transcription.GET("/:id/export", handler.ExportTranscript)
 			transcription.GET("/:id/execution", handler.GetJobExecutionData)
+			transcription.GET("/:id/executions", handler.ListJobExecutions)
 			transcription.GET("/:id/merge-status", handler.GetMergeStatus)
 			transcription.GET("/:id/track-progress", handler.GetTrackProgress)
 			transcription.PUT("/:id/title", handler.UpdateTranscriptionTitle)
+			transcription.PUT("/:id/priority", handler.UpdateJobPriority)
+			transcription.PUT("/:id/tags", handler.SetJobTags)
+			transcription.PUT("/:id/folder", handler.SetJobFolder)
 			transcription.GET("/:id/summary", handler.GetSummaryForTranscription)
 			transcription.GET("/:id", handler.GetJobByID)
 			transcription.DELETE("/:id", handler.DeleteJob)
 			transcription.GET("/list", handler.ListJobs)
+			transcription.GET("/search", handler.SearchTranscripts)
 			transcription.GET("/models", handler.GetSupportedModels)
 			// Notes for a transcription
 			transcription.GET("/:id/notes", handler.ListNotes)
@@ -118,6 +164,16 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 			// Speaker mappings for a transcription
 			transcription.GET("/:id/speakers", handler.GetSpeakerMappings)
 			transcription.POST("/:id/speakers", handler.UpdateSpeakerMappings)
+			transcription.GET("/:id/speakers/suggestions", handler.GetSpeakerSuggestions)
+
+			// Transcript editing and revision history
+			transcription.PATCH("/:id/segments/:index", handler.UpdateSegmentText)
+			transcription.POST("/:id/segments/:index/split", handler.SplitSegment)
+			transcription.POST("/:id/segments/:index/merge", handler.MergeSegments)
+			transcription.PUT("/:id/speaker", handler.ReassignSpeaker)
+			transcription.GET("/:id/revisions", handler.ListTranscriptRevisions)
+			transcription.GET("/:id/revisions/:revision", handler.GetTranscriptRevision)
+			transcription.POST("/:id/revisions/:revision/restore", handler.RestoreTranscriptRevision)
 
 			// Quick transcription endpoints
 			transcription.POST("/quick", handler.SubmitQuickTranscription)
@@ -141,7 +197,7 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 
 		// Profile routes (require authentication)
 		profiles := v1.Group("/profiles")
-		profiles.Use(middleware.AuthMiddleware(authService))
+		profiles.Use(contentAccess...)
 		{
 			profiles.GET("/", handler.ListProfiles)
 			profiles.POST("/", handler.CreateProfile)
@@ -163,25 +219,27 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 
 		// Admin routes (require authentication)
 		admin := v1.Group("/admin")
-		admin.Use(middleware.AuthMiddleware(authService))
+		admin.Use(middleware.AuthMiddleware(authService), middleware.RoleMiddleware(models.RoleAdmin), middleware.ScopeMiddleware(models.ScopeAdmin))
 		{
 			queue := admin.Group("/queue")
 			{
 				queue.GET("/stats", handler.GetQueueStats)
 			}
+			admin.POST("/backup", handler.CreateBackup)
+			admin.POST("/restore", handler.RestoreBackup)
 		}
 
 		// LLM configuration routes (require authentication)
 		llm := v1.Group("/llm")
-		llm.Use(middleware.AuthMiddleware(authService))
+		llm.Use(middleware.AuthMiddleware(authService), middleware.ScopeMiddleware(models.ScopeAdmin))
 		{
 			llm.GET("/config", handler.GetLLMConfig)
-			llm.POST("/config", handler.SaveLLMConfig)
+			llm.POST("/config", middleware.RoleMiddleware(models.RoleAdmin), handler.SaveLLMConfig)
 		}
 
 		// Summarization templates routes (require authentication)
 		summaries := v1.Group("/summaries")
-		summaries.Use(middleware.AuthMiddleware(authService))
+		summaries.Use(contentAccess...)
 		{
 			summaries.GET("/", handler.ListSummaryTemplates)
 			summaries.POST("/", handler.CreateSummaryTemplate)
@@ -189,12 +247,12 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 			summaries.PUT("/:id", handler.UpdateSummaryTemplate)
 			summaries.DELETE("/:id", handler.DeleteSummaryTemplate)
 			summaries.GET("/settings", handler.GetSummarySettings)
-			summaries.POST("/settings", handler.SaveSummarySettings)
+			summaries.POST("/settings", middleware.RoleMiddleware(models.RoleAdmin), handler.SaveSummarySettings)
 		}
 
 		// Chat routes (require authentication)
 		chat := v1.Group("/chat")
-		chat.Use(middleware.AuthMiddleware(authService))
+		chat.Use(chatAccess...)
 		{
 			chat.GET("/models", handler.GetChatModels)
 			chat.POST("/sessions", handler.CreateChatSession)
@@ -208,21 +266,88 @@ func SetupRoutes(handler *Handler, authService *auth.AuthService) *gin.Engine {
 
 		// Notes routes (require authentication)
 		notes := v1.Group("/notes")
-		notes.Use(middleware.AuthMiddleware(authService))
+		notes.Use(contentAccess...)
 		{
 			notes.GET("/:note_id", handler.GetNote)
 			notes.PUT("/:note_id", handler.UpdateNote)
 			notes.DELETE("/:note_id", handler.DeleteNote)
 		}
 
+		// Webhook routes (require authentication)
+		hooks := v1.Group("/webhooks")
+		hooks.Use(middleware.AuthMiddleware(authService), middleware.WriteAccessMiddleware(), middleware.ScopeMiddleware(models.ScopeAdmin))
+		{
+			hooks.GET("/", handler.ListWebhooks)
+			hooks.POST("/", handler.CreateWebhook)
+			hooks.GET("/:id", handler.GetWebhook)
+			hooks.PUT("/:id", handler.UpdateWebhook)
+			hooks.DELETE("/:id", handler.DeleteWebhook)
+			hooks.GET("/:id/deliveries", handler.ListWebhookDeliveries)
+			hooks.POST("/:id/deliveries/:delivery_id/replay", handler.ReplayWebhookDelivery)
+		}
+
+		// Replacement dictionary routes (require authentication)
+		dictionaries := v1.Group("/dictionaries")
+		dictionaries.Use(contentAccess...)
+		{
+			dictionaries.GET("/", handler.ListReplacementDictionaries)
+			dictionaries.POST("/", handler.CreateReplacementDictionary)
+			dictionaries.GET("/:id", handler.GetReplacementDictionary)
+			dictionaries.PUT("/:id", handler.UpdateReplacementDictionary)
+			dictionaries.DELETE("/:id", handler.DeleteReplacementDictionary)
+		}
+
+		// Voice profile routes (require authentication)
+		voiceProfiles := v1.Group("/voices")
+		voiceProfiles.Use(contentAccess...)
+		{
+			voiceProfiles.GET("/", handler.ListVoiceProfiles)
+			voiceProfiles.POST("/enroll", handler.EnrollVoice)
+			voiceProfiles.GET("/:id", handler.GetVoiceProfile)
+			voiceProfiles.PUT("/:id", handler.UpdateVoiceProfile)
+			voiceProfiles.DELETE("/:id", handler.DeleteVoiceProfile)
+			voiceProfiles.DELETE("/:id/samples/:sample_id", handler.DeleteVoiceSample)
+		}
+
+		// Tag routes (require authentication)
+		tags := v1.Group("/tags")
+		tags.Use(contentAccess...)
+		{
+			tags.GET("/", handler.ListTags)
+			tags.POST("/", handler.CreateTag)
+			tags.PUT("/:id", handler.UpdateTag)
+			tags.DELETE("/:id", handler.DeleteTag)
+		}
+
+		// Folder routes (require authentication)
+		folders := v1.Group("/folders")
+		folders.Use(contentAccess...)
+		{
+			folders.GET("/", handler.ListFolders)
+			folders.POST("/", handler.CreateFolder)
+			folders.GET("/:id", handler.GetFolder)
+			folders.PUT("/:id", handler.UpdateFolder)
+			folders.DELETE("/:id", handler.DeleteFolder)
+			folders.GET("/:id/chat/sessions", handler.ListFolderChatSessions)
+			folders.POST("/:id/chat/sessions", handler.CreateFolderChatSession)
+		}
+
 		// Summarization route (require authentication)
 		summarize := v1.Group("/summarize")
-		summarize.Use(middleware.AuthMiddleware(authService))
+		summarize.Use(chatAccess...)
 		{
 			summarize.POST("/", handler.Summarize)
 		}
 	}
 
+	// OpenAI-compatible audio endpoints (API key as Bearer token)
+	openai := router.Group("/v1/audio")
+	openai.Use(middleware.BearerAPIKeyMiddleware(), middleware.WriteAccessMiddleware(), middleware.ScopeMiddleware(models.ScopeTranscribeWrite))
+	{
+		openai.POST("/transcriptions", handler.CreateOpenAITranscription)
+		openai.POST("/translations", handler.CreateOpenAITranslation)
+	}
+
 	// Set up static file serving for React app
 	web.SetupStaticRoutes(router, authService)
 
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"synthezia/internal/search"
+)
+
+// SearchTranscripts performs a full-text search over transcript contents
+// @Summary Search transcript contents
+// @Description Full-text search across the caller's transcripts. Returns matching jobs, each with highlighted snippets (matched terms wrapped in <mark></mark>) and the start time of the matching segment so playback can seek straight to it. Use double quotes for phrases and a trailing * for prefix matches.
+// @Tags transcription
+// @Produce json
+// @Param q query string true "Search query"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Jobs per page" default(10)
+// @Param hits_per_job query int false "Maximum snippets per job" default(5)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/v1/transcription/search [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) SearchTranscripts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if search.BuildMatchQuery(query) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	hitsPerJob, _ := strconv.Atoi(c.DefaultQuery("hits_per_job", "5"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	if hitsPerJob < 1 || hitsPerJob > 50 {
+		hitsPerJob = 5
+	}
+
+	userID := currentUserID(c)
+	results, total, err := search.Search(query, search.Options{
+		Limit:      limit,
+		Offset:     (page - 1) * limit,
+		HitsPerJob: hitsPerJob,
+		UserID:     &userID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search transcripts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":   query,
+		"results": results,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"synthezia/internal/database"
 	"synthezia/internal/llm"
 	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -43,6 +44,12 @@ func (h *Handler) Summarize(c *gin.Context) {
 		return
 	}
 
+	if !userOwnsJob(c, req.TranscriptionID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcription not found"})
+		return
+	}
+	userID := currentUserID(c)
+
 	svc, provider, err := h.getLLMService()
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -78,6 +85,7 @@ func (h *Handler) Summarize(c *gin.Context) {
 		}
 		sum := models.Summary{
 			TranscriptionID: req.TranscriptionID,
+			UserID:          userID,
 			TemplateID:      req.TemplateID,
 			Model:           req.Model,
 			Content:         finalText,
@@ -88,6 +96,7 @@ func (h *Handler) Summarize(c *gin.Context) {
 		} else {
 			// Also cache on the transcription job for quick access
 			_ = database.DB.Model(&models.TranscriptionJob{}).Where("id = ?", req.TranscriptionID).Update("summary", finalText).Error
+			webhooks.EmitSummaryEvent(&sum)
 		}
 	}
 	for {
@@ -184,11 +193,11 @@ func (h *Handler) GetSummaryForTranscription(c *gin.Context) {
 		return
 	}
 	var s models.Summary
-	if err := database.DB.Where("transcription_id = ?", tid).Order("created_at DESC").First(&s).Error; err != nil {
+	if err := database.DB.Scopes(ownedBy(c)).Where("transcription_id = ?", tid).Order("created_at DESC").First(&s).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			// Fallback: check if summary is cached on the job record
 			var job models.TranscriptionJob
-			if err2 := database.DB.Where("id = ?", tid).First(&job).Error; err2 == nil && job.Summary != nil && *job.Summary != "" {
+			if err2 := database.DB.Scopes(ownedBy(c)).Where("id = ?", tid).First(&job).Error; err2 == nil && job.Summary != nil && *job.Summary != "" {
 				c.JSON(http.StatusOK, gin.H{
 					"transcription_id": tid,
 					"template_id":      nil,
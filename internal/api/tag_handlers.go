@@ -0,0 +1,286 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+)
+
+// maxTagNameLength matches the size of the tag name column
+const maxTagNameLength = 100
+
+// TagRequest creates or renames a tag
+type TagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// TagResponse is a tag with the number of jobs carrying it
+type TagResponse struct {
+	models.Tag
+	JobCount int64 `json:"job_count"`
+}
+
+// JobTagsRequest replaces the tags of a job
+type JobTagsRequest struct {
+	Tags []string `json:"tags"` // Tag names; missing tags are created
+}
+
+// normalizeTagNames trims tag names and drops empty and repeated ones
+func normalizeTagNames(names []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		if len(name) > maxTagNameLength {
+			return nil, fmt.Errorf("tag name %q is longer than %d characters", name, maxTagNameLength)
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+	return normalized, nil
+}
+
+// findTags returns the tags of a user with the given names, creating missing ones if create is set.
+// Names match regardless of case.
+func findTags(userID uint, names []string, create bool) ([]models.Tag, error) {
+	var tags []models.Tag
+	for _, name := range names {
+		var tag models.Tag
+		if err := database.DB.Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name).Limit(1).Find(&tag).Error; err != nil {
+			return nil, err
+		}
+		if tag.ID == "" {
+			if !create {
+				continue
+			}
+			tag = models.Tag{UserID: userID, Name: name}
+			if err := database.DB.Create(&tag).Error; err != nil {
+				return nil, err
+			}
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// tagNameTaken reports whether the user has another tag with the given name
+func tagNameTaken(userID uint, name, exceptID string) bool {
+	var count int64
+	database.DB.Model(&models.Tag{}).Where("user_id = ? AND LOWER(name) = LOWER(?) AND id <> ?", userID, name, exceptID).Count(&count)
+	return count > 0
+}
+
+// bindTagName binds a TagRequest and validates the name, writing a response on failure
+func bindTagName(c *gin.Context) (string, bool) {
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return "", false
+	}
+	names, err := normalizeTagNames([]string{req.Name})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if len(names) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return "", false
+	}
+	return names[0], true
+}
+
+// ListTags returns the tags of the current user
+// @Summary List tags
+// @Description Get all tags of the current user with the number of jobs carrying each
+// @Tags tags
+// @Produce json
+// @Success 200 {array} TagResponse
+// @Router /api/v1/tags [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListTags(c *gin.Context) {
+	var tags []TagResponse
+	err := database.DB.Model(&models.Tag{}).Scopes(ownedBy(c)).
+		Select("tags.*, (SELECT COUNT(*) FROM transcription_job_tags jt WHERE jt.tag_id = tags.id) AS job_count").
+		Order("name ASC").Scan(&tags).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+	if tags == nil {
+		tags = []TagResponse{}
+	}
+	c.JSON(http.StatusOK, tags)
+}
+
+// CreateTag creates a tag
+// @Summary Create a tag
+// @Description Create a tag. Names are unique per user regardless of case.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param request body TagRequest true "Tag"
+// @Success 201 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/tags [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateTag(c *gin.Context) {
+	name, ok := bindTagName(c)
+	if !ok {
+		return
+	}
+	if tagNameTaken(currentUserID(c), name, "") {
+		c.JSON(http.StatusConflict, gin.H{"error": "A tag with this name already exists"})
+		return
+	}
+
+	tag := models.Tag{UserID: currentUserID(c), Name: name}
+	if err := database.DB.Create(&tag).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tag"})
+		return
+	}
+	c.JSON(http.StatusCreated, tag)
+}
+
+// findOwnedTag loads a tag owned by the authenticated user, writing a response on failure
+func findOwnedTag(c *gin.Context) (*models.Tag, bool) {
+	var tag models.Tag
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", c.Param("id")).First(&tag).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Tag not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get tag"})
+		return nil, false
+	}
+	return &tag, true
+}
+
+// UpdateTag renames a tag
+// @Summary Rename a tag
+// @Description Rename a tag on every job carrying it
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param request body TagRequest true "New name"
+// @Success 200 {object} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/tags/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateTag(c *gin.Context) {
+	tag, ok := findOwnedTag(c)
+	if !ok {
+		return
+	}
+	name, ok := bindTagName(c)
+	if !ok {
+		return
+	}
+	if tagNameTaken(tag.UserID, name, tag.ID) {
+		c.JSON(http.StatusConflict, gin.H{"error": "A tag with this name already exists"})
+		return
+	}
+
+	tag.Name = name
+	if err := database.DB.Model(tag).Update("name", name).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tag"})
+		return
+	}
+	c.JSON(http.StatusOK, tag)
+}
+
+// DeleteTag deletes a tag
+// @Summary Delete a tag
+// @Description Delete a tag and remove it from every job carrying it
+// @Tags tags
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/tags/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteTag(c *gin.Context) {
+	tag, ok := findOwnedTag(c)
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM transcription_job_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(tag).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete tag"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Tag deleted successfully"})
+}
+
+// SetJobTags replaces the tags of a transcription job
+// @Summary Set the tags of a transcription
+// @Description Replace the tags of a job with the given tag names. Tags that don't exist yet are created.
+// @Tags tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body JobTagsRequest true "Tag names"
+// @Success 200 {array} models.Tag
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/tags [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) SetJobTags(c *gin.Context) {
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", c.Param("id")).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return
+	}
+
+	var req JobTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	names, err := normalizeTagNames(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tags, err := findTags(currentUserID(c), names, true)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create tags"})
+		return
+	}
+	if err := database.DB.Model(&job).Association("Tags").Replace(tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update tags"})
+		return
+	}
+	if tags == nil {
+		tags = []models.Tag{}
+	}
+	c.JSON(http.StatusOK, tags)
+}
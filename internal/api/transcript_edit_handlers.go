@@ -0,0 +1,314 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/editor"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+)
+
+// SegmentTextRequest is the payload for replacing the text of a segment
+type SegmentTextRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// SplitSegmentRequest is the payload for splitting a segment
+type SplitSegmentRequest struct {
+	WordOffset int `json:"word_offset" binding:"required"` // Words kept in the first half
+}
+
+// ReassignSpeakerRequest selects either a segment or an inclusive word range to attribute to a speaker
+type ReassignSpeakerRequest struct {
+	Speaker        string `json:"speaker" binding:"required"`
+	SegmentIndex   *int   `json:"segment_index,omitempty"`
+	StartWordIndex *int   `json:"start_word_index,omitempty"`
+	EndWordIndex   *int   `json:"end_word_index,omitempty"`
+}
+
+// TranscriptEditResponse is returned by every transcript edit
+type TranscriptEditResponse struct {
+	Revision   models.TranscriptRevision    `json:"revision"`
+	Transcript *interfaces.TranscriptResult `json:"transcript"`
+}
+
+// findEditableJob checks that the job is owned by the current user and has a finished transcript,
+// writing a response on failure
+func findEditableJob(c *gin.Context, jobID string) bool {
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Select("id", "status").Where("id = ?", jobID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+			return false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get job"})
+		return false
+	}
+	if job.Status != models.StatusCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Job not completed, current status: %s", job.Status)})
+		return false
+	}
+	return true
+}
+
+// respondToEdit writes the outcome of an editor operation
+func respondToEdit(c *gin.Context, revision *models.TranscriptRevision, transcript *interfaces.TranscriptResult, err error) {
+	switch {
+	case err == nil:
+		revision.Transcript = "" // the edited transcript is returned alongside
+		c.JSON(http.StatusOK, TranscriptEditResponse{Revision: *revision, Transcript: transcript})
+	case errors.Is(err, editor.ErrInvalidEdit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, editor.ErrNoTranscript):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcript not available"})
+	case errors.Is(err, editor.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit transcript"})
+	}
+}
+
+// editTranscript applies an edit to the transcript of the job in the path
+func editTranscript(c *gin.Context, operation, description string, edit editor.EditFunc) {
+	jobID := c.Param("id")
+	if !findEditableJob(c, jobID) {
+		return
+	}
+	change := editor.Change{UserID: currentUserID(c), Operation: operation, Description: description}
+	revision, transcript, err := editor.Apply(jobID, change, edit)
+	respondToEdit(c, revision, transcript, err)
+}
+
+// segmentIndexParam parses the segment index in the path, writing a response on failure
+func segmentIndexParam(c *gin.Context) (int, bool) {
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid segment index"})
+		return 0, false
+	}
+	return index, true
+}
+
+// UpdateSegmentText replaces the text of a transcript segment
+// @Summary Edit segment text
+// @Description Replace the text of a segment. Words that survive the edit keep their timing, and notes stay attached to their words. The edit is recorded as a new revision.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param index path int true "Segment index"
+// @Param request body SegmentTextRequest true "New segment text"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/segments/{index} [patch]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateSegmentText(c *gin.Context) {
+	index, ok := segmentIndexParam(c)
+	if !ok {
+		return
+	}
+	var req SegmentTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	editTranscript(c, editor.OpEditText, fmt.Sprintf("Edited segment %d", index),
+		func(t *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+			return editor.EditSegmentText(t, index, req.Text)
+		})
+}
+
+// SplitSegment splits a transcript segment in two
+// @Summary Split segment
+// @Description Split a segment after its first word_offset words. The edit is recorded as a new revision.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param index path int true "Segment index"
+// @Param request body SplitSegmentRequest true "Split position"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/segments/{index}/split [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) SplitSegment(c *gin.Context) {
+	index, ok := segmentIndexParam(c)
+	if !ok {
+		return
+	}
+	var req SplitSegmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	editTranscript(c, editor.OpSplit, fmt.Sprintf("Split segment %d after word %d", index, req.WordOffset),
+		func(t *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+			return editor.SplitSegment(t, index, req.WordOffset)
+		})
+}
+
+// MergeSegments joins a transcript segment with the next one
+// @Summary Merge segments
+// @Description Merge a segment with the segment after it, keeping the first segment's speaker. The edit is recorded as a new revision.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param index path int true "Segment index"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/segments/{index}/merge [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) MergeSegments(c *gin.Context) {
+	index, ok := segmentIndexParam(c)
+	if !ok {
+		return
+	}
+
+	editTranscript(c, editor.OpMerge, fmt.Sprintf("Merged segments %d and %d", index, index+1),
+		func(t *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+			return editor.MergeSegments(t, index)
+		})
+}
+
+// ReassignSpeaker attributes a segment or word range to another speaker
+// @Summary Reassign speaker
+// @Description Attribute a segment, or an inclusive range of word indices, to another speaker. Segments a word range only partly covers are split at its edges. The edit is recorded as a new revision.
+// @Tags transcription
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param request body ReassignSpeakerRequest true "Speaker and selection"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/speaker [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ReassignSpeaker(c *gin.Context) {
+	var req ReassignSpeakerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	switch {
+	case req.SegmentIndex != nil && req.StartWordIndex == nil && req.EndWordIndex == nil:
+		index := *req.SegmentIndex
+		editTranscript(c, editor.OpReassignSpeaker, fmt.Sprintf("Assigned segment %d to %s", index, req.Speaker),
+			func(t *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+				return editor.ReassignSegmentSpeaker(t, index, req.Speaker)
+			})
+	case req.SegmentIndex == nil && req.StartWordIndex != nil && req.EndWordIndex != nil:
+		start, end := *req.StartWordIndex, *req.EndWordIndex
+		editTranscript(c, editor.OpReassignSpeaker, fmt.Sprintf("Assigned words %d-%d to %s", start, end, req.Speaker),
+			func(t *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+				return editor.ReassignWordSpeaker(t, start, end, req.Speaker)
+			})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Provide either segment_index or both start_word_index and end_word_index"})
+	}
+}
+
+// ListTranscriptRevisions lists the revisions of a transcript
+// @Summary List transcript revisions
+// @Description List the recorded revisions of a transcript, oldest first, with their diffs but without snapshots. Revision 1 holds the model output; it is recorded on the first edit.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {array} models.TranscriptRevision
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/revisions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListTranscriptRevisions(c *gin.Context) {
+	jobID := c.Param("id")
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+
+	revisions, err := editor.ListRevisions(jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list revisions"})
+		return
+	}
+	c.JSON(http.StatusOK, revisions)
+}
+
+// GetTranscriptRevision returns one revision of a transcript
+// @Summary Get transcript revision
+// @Description Get a revision of a transcript including the full transcript as it was after that revision
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} models.TranscriptRevision
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/revisions/{revision} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetTranscriptRevision(c *gin.Context) {
+	jobID := c.Param("id")
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job not found"})
+		return
+	}
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+
+	revision, err := editor.GetRevision(jobID, number)
+	if err != nil {
+		if errors.Is(err, editor.ErrRevisionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Revision not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get revision"})
+		return
+	}
+	c.JSON(http.StatusOK, revision)
+}
+
+// RestoreTranscriptRevision makes an earlier revision the current transcript
+// @Summary Restore transcript revision
+// @Description Restore the transcript as it was after the given revision. Restoring revision 1 brings back the model output. The restore is itself recorded as a new revision.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Job ID"
+// @Param revision path int true "Revision number"
+// @Success 200 {object} TranscriptEditResponse
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/revisions/{revision}/restore [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) RestoreTranscriptRevision(c *gin.Context) {
+	jobID := c.Param("id")
+	number, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision number"})
+		return
+	}
+	if !findEditableJob(c, jobID) {
+		return
+	}
+
+	revision, transcript, err := editor.Restore(jobID, currentUserID(c), number)
+	respondToEdit(c, revision, transcript, err)
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/auth"
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/pkg/logger"
+)
+
+// Lifetime of invitations
+const (
+	defaultInvitationHours = 72
+	maxInvitationHours     = 30 * 24
+)
+
+// errInvitationUnavailable is returned when an invitation was accepted or expired in the meantime
+var errInvitationUnavailable = errors.New("invitation is no longer available")
+
+// CreateUserRequest creates a user directly
+type CreateUserRequest struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Password string `json:"password" binding:"required,min=6"`
+	Role     string `json:"role" binding:"required"` // admin, editor or viewer
+}
+
+// UserRoleRequest changes the role of a user
+type UserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// InvitationRequest creates an invitation
+type InvitationRequest struct {
+	Role           string `json:"role" binding:"required"`
+	ExpiresInHours int    `json:"expires_in_hours,omitempty"` // Defaults to 72, at most 720
+}
+
+// InvitationResponse is an invitation. The token is only returned when the invitation is created.
+type InvitationResponse struct {
+	models.Invitation
+	Token string `json:"token,omitempty"`
+}
+
+// usernameTaken reports whether a user with the given name exists
+func usernameTaken(db *gorm.DB, username string) (bool, error) {
+	var count int64
+	if err := db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// findOpenInvitation returns the invitation with the given token if it is neither accepted nor expired
+func findOpenInvitation(db *gorm.DB, token string) (*models.Invitation, error) {
+	var invitation models.Invitation
+	if err := db.Where("hashed = ? AND accepted_at IS NULL AND expires_at > ?", sha256Hex(token), time.Now()).
+		First(&invitation).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// acceptInvitation marks an invitation as used by a user, failing if someone else used it first
+func acceptInvitation(tx *gorm.DB, invitation *models.Invitation, userID uint) error {
+	now := time.Now()
+	result := tx.Model(&models.Invitation{}).Where("id = ? AND accepted_at IS NULL", invitation.ID).
+		Updates(map[string]interface{}{"accepted_at": now, "accepted_by": userID})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvitationUnavailable
+	}
+	return nil
+}
+
+// findUser loads the user named by the id path parameter, writing a response on failure
+func findUser(c *gin.Context) (*models.User, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return nil, false
+	}
+	var user models.User
+	if err := database.DB.First(&user, uint(id)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return nil, false
+	}
+	return &user, true
+}
+
+// isLastAdmin reports whether user is the only enabled admin
+func isLastAdmin(user *models.User) bool {
+	if user.Role != models.RoleAdmin || user.Disabled {
+		return false
+	}
+	var others int64
+	database.DB.Model(&models.User{}).Where("role = ? AND disabled = ? AND id <> ?", models.RoleAdmin, false, user.ID).Count(&others)
+	return others == 0
+}
+
+// ListUsers returns all users
+// @Summary List users
+// @Description Get all users with their roles. Admins only.
+// @Tags users
+// @Produce json
+// @Success 200 {array} models.User
+// @Failure 403 {object} map[string]string
+// @Router /api/v1/users [get]
+// @Security BearerAuth
+func (h *Handler) ListUsers(c *gin.Context) {
+	var users []models.User
+	if err := database.DB.Order("id ASC").Find(&users).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// CreateUser creates a user with a role
+// @Summary Create a user
+// @Description Create a user with a password and role. Admins only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body CreateUserRequest true "User"
+// @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/users [post]
+// @Security BearerAuth
+func (h *Handler) CreateUser(c *gin.Context) {
+	var req CreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !models.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be admin, editor or viewer"})
+		return
+	}
+
+	if taken, err := usernameTaken(database.DB, req.Username); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check existing users"})
+		return
+	} else if taken {
+		c.JSON(http.StatusConflict, gin.H{"error": "Username already exists"})
+		return
+	}
+
+	hashedPassword, err := auth.HashPassword(req.Password)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to secure password"})
+		return
+	}
+
+	user := models.User{Username: req.Username, Password: hashedPassword, Role: req.Role}
+	if err := database.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	logger.Info("User created", "user_id", user.ID, "username", user.Username, "role", user.Role, "by", currentUserID(c))
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUserRole changes the role of a user
+// @Summary Change a user's role
+// @Description Change the role of a user. The last enabled admin can't be demoted. Admins only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "User ID"
+// @Param request body UserRoleRequest true "Role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/users/{id}/role [put]
+// @Security BearerAuth
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	var req UserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if !models.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be admin, editor or viewer"})
+		return
+	}
+
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if req.Role != models.RoleAdmin && isLastAdmin(user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The last admin can't be demoted"})
+		return
+	}
+
+	if err := database.DB.Model(user).Update("role", req.Role).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// DisableUser disables a user
+// @Summary Disable a user
+// @Description Disable a user. They can no longer log in, their sessions end and their API keys stop working. Their data is kept. Admins only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /api/v1/users/{id}/disable [post]
+// @Security BearerAuth
+func (h *Handler) DisableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if user.ID == currentUserID(c) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You can't disable your own account"})
+		return
+	}
+	if isLastAdmin(user) {
+		c.JSON(http.StatusConflict, gin.H{"error": "The last admin can't be disabled"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("disabled", true).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Update("revoked", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disable user"})
+		return
+	}
+
+	logger.Info("User disabled", "user_id", user.ID, "username", user.Username, "by", currentUserID(c))
+	c.JSON(http.StatusOK, user)
+}
+
+// EnableUser enables a disabled user
+// @Summary Enable a user
+// @Description Enable a disabled user so that they can log in again. Admins only.
+// @Tags users
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/users/{id}/enable [post]
+// @Security BearerAuth
+func (h *Handler) EnableUser(c *gin.Context) {
+	user, ok := findUser(c)
+	if !ok {
+		return
+	}
+	if err := database.DB.Model(user).Update("disabled", false).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enable user"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// ListInvitations returns the invitations
+// @Summary List invitations
+// @Description Get all invitations, newest first, including accepted and expired ones. Admins only.
+// @Tags users
+// @Produce json
+// @Success 200 {array} InvitationResponse
+// @Router /api/v1/invitations [get]
+// @Security BearerAuth
+func (h *Handler) ListInvitations(c *gin.Context) {
+	var invitations []models.Invitation
+	if err := database.DB.Order("created_at DESC").Find(&invitations).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch invitations"})
+		return
+	}
+	responses := make([]InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = InvitationResponse{Invitation: invitation}
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+// CreateInvitation creates an invitation
+// @Summary Create an invitation
+// @Description Create a single-use invitation granting a role. The returned token is shown only once; pass it to /auth/register as invitationToken. Admins only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body InvitationRequest true "Invitation"
+// @Success 201 {object} InvitationResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/invitations [post]
+// @Security BearerAuth
+func (h *Handler) CreateInvitation(c *gin.Context) {
+	var req InvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	req.Role = strings.TrimSpace(req.Role)
+	if !models.ValidRole(req.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be admin, editor or viewer"})
+		return
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = defaultInvitationHours
+	}
+	if req.ExpiresInHours < 0 || req.ExpiresInHours > maxInvitationHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_hours must be between 1 and 720"})
+		return
+	}
+
+	token := generateSecureAPIKey(48)
+	invitation := models.Invitation{
+		Hashed:    sha256Hex(token),
+		Role:      req.Role,
+		CreatedBy: currentUserID(c),
+		ExpiresAt: time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+	}
+	if err := database.DB.Create(&invitation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create invitation"})
+		return
+	}
+	c.JSON(http.StatusCreated, InvitationResponse{Invitation: invitation, Token: token})
+}
+
+// DeleteInvitation revokes an invitation
+// @Summary Delete an invitation
+// @Description Delete an invitation so that it can no longer be used. Admins only.
+// @Tags users
+// @Produce json
+// @Param id path int true "Invitation ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/invitations/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteInvitation(c *gin.Context) {
+	result := database.DB.Where("id = ?", c.Param("id")).Delete(&models.Invitation{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete invitation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invitation not found"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Invitation deleted successfully"})
+}
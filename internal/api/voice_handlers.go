@@ -0,0 +1,311 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/voices"
+)
+
+// VoiceEnrollRequest enrolls the voice of a diarized speaker, picked by label or by one of its segments
+type VoiceEnrollRequest struct {
+	TranscriptionID string `json:"transcription_id" binding:"required"`
+	Speaker         string `json:"speaker,omitempty"`
+	SegmentIndex    *int   `json:"segment_index,omitempty"`
+	ProfileID       string `json:"profile_id,omitempty"` // Add the voice to this profile instead of one picked by name
+	Name            string `json:"name,omitempty"`       // Defaults to the speaker's current custom name
+}
+
+// VoiceProfileRequest renames a voice profile
+type VoiceProfileRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// maxVoiceNameLength matches the size of speaker mapping names, which voice profiles are applied as
+const maxVoiceNameLength = 100
+
+// findOwnedVoiceProfile loads a voice profile owned by the authenticated user, writing a response on failure
+func findOwnedVoiceProfile(c *gin.Context, id string) (*models.VoiceProfile, bool) {
+	var profile models.VoiceProfile
+	if err := database.DB.Scopes(ownedBy(c)).Preload("Samples").Where("id = ?", id).First(&profile).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Voice profile not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get voice profile"})
+		return nil, false
+	}
+	return &profile, true
+}
+
+// ListVoiceProfiles returns the voice profiles of the current user
+// @Summary List voice profiles
+// @Description Get all enrolled voice profiles of the current user with their samples
+// @Tags voices
+// @Produce json
+// @Success 200 {array} models.VoiceProfile
+// @Router /api/v1/voices [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListVoiceProfiles(c *gin.Context) {
+	var profiles []models.VoiceProfile
+	if err := database.DB.Scopes(ownedBy(c)).Preload("Samples").Order("name ASC").Find(&profiles).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch voice profiles"})
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// EnrollVoice adds the voice of a diarized speaker to the voice library
+// @Summary Enroll a voice
+// @Description Store the voice embedding of a speaker in a transcription as a sample of a named voice profile, and name the speaker after it. The speaker is given by label or by the index of one of its segments. Without profile_id, the voice is added to the profile with the given name, which is created if needed. Speakers of later diarized jobs that sound like the profile are suggested or named automatically. Jobs diarized with pyannote keep embeddings when their owner has a voice profile or speaker_embeddings is enabled.
+// @Tags voices
+// @Accept json
+// @Produce json
+// @Param request body VoiceEnrollRequest true "Speaker to enroll"
+// @Success 200 {object} models.VoiceProfile
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/voices/enroll [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) EnrollVoice(c *gin.Context) {
+	var req VoiceEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", req.TranscriptionID).First(&job).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transcription job"})
+		return
+	}
+
+	speaker := strings.TrimSpace(req.Speaker)
+	if req.SegmentIndex != nil {
+		var transcript interfaces.TranscriptResult
+		if job.Transcript == nil || json.Unmarshal([]byte(*job.Transcript), &transcript) != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Transcription has no transcript"})
+			return
+		}
+		if *req.SegmentIndex < 0 || *req.SegmentIndex >= len(transcript.Segments) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "segment_index is out of range"})
+			return
+		}
+		segment := transcript.Segments[*req.SegmentIndex]
+		if segment.Speaker == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Segment has no speaker"})
+			return
+		}
+		speaker = *segment.Speaker
+	}
+	if speaker == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "speaker or segment_index is required"})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if req.ProfileID == "" && name == "" {
+		// Enrolling a speaker that was already named by hand keeps that name
+		var mapping models.SpeakerMapping
+		database.DB.Where("transcription_job_id = ? AND original_speaker = ?", job.ID, speaker).Limit(1).Find(&mapping)
+		name = mapping.CustomName
+	}
+	if req.ProfileID == "" && name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required for a speaker without a custom name"})
+		return
+	}
+	if len(name) > maxVoiceNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is too long"})
+		return
+	}
+
+	profile, err := voices.Enroll(voices.Enrollment{
+		UserID:    currentUserID(c),
+		JobID:     job.ID,
+		Speaker:   speaker,
+		ProfileID: req.ProfileID,
+		Name:      name,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, voices.ErrNoEmbedding):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No voice embedding stored for this speaker; re-run diarization with pyannote and speaker_embeddings enabled"})
+		case errors.Is(err, voices.ErrProfileNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Voice profile not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enroll voice"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// GetVoiceProfile returns a voice profile by ID
+// @Summary Get voice profile
+// @Description Get a voice profile and its enrolled samples
+// @Tags voices
+// @Produce json
+// @Param id path string true "Voice profile ID"
+// @Success 200 {object} models.VoiceProfile
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/voices/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetVoiceProfile(c *gin.Context) {
+	profile, ok := findOwnedVoiceProfile(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// UpdateVoiceProfile renames a voice profile
+// @Summary Rename voice profile
+// @Description Rename a voice profile. Speakers named after the profile are renamed with it.
+// @Tags voices
+// @Accept json
+// @Produce json
+// @Param id path string true "Voice profile ID"
+// @Param request body VoiceProfileRequest true "New name"
+// @Success 200 {object} models.VoiceProfile
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/voices/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateVoiceProfile(c *gin.Context) {
+	profile, ok := findOwnedVoiceProfile(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req VoiceProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > maxVoiceNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be between 1 and 100 characters"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(profile).Update("name", name).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.SpeakerMapping{}).Where("voice_profile_id = ?", profile.ID).Update("custom_name", name).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update voice profile"})
+		return
+	}
+	profile.Name = name
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteVoiceProfile deletes a voice profile and its samples
+// @Summary Delete voice profile
+// @Description Delete a voice profile and its samples. Speakers named after it keep their names.
+// @Tags voices
+// @Param id path string true "Voice profile ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/voices/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteVoiceProfile(c *gin.Context) {
+	profile, ok := findOwnedVoiceProfile(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("voice_profile_id = ?", profile.ID).Delete(&models.VoiceSample{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.SpeakerMapping{}).Where("voice_profile_id = ?", profile.ID).Update("voice_profile_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.VoiceProfile{}, "id = ?", profile.ID).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete voice profile"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Voice profile deleted successfully"})
+}
+
+// DeleteVoiceSample removes one enrolled sample from a voice profile
+// @Summary Delete voice sample
+// @Description Remove a sample from a voice profile, e.g. one enrolled from the wrong speaker
+// @Tags voices
+// @Param id path string true "Voice profile ID"
+// @Param sample_id path int true "Sample ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/voices/{id}/samples/{sample_id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteVoiceSample(c *gin.Context) {
+	profile, ok := findOwnedVoiceProfile(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	result := database.DB.Where("id = ? AND voice_profile_id = ?", c.Param("sample_id"), profile.ID).Delete(&models.VoiceSample{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete voice sample"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Voice sample not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Voice sample deleted successfully"})
+}
+
+// GetSpeakerSuggestions matches the speakers of a transcription against the voice library
+// @Summary Suggest speaker names
+// @Description Match the speakers of a transcription against the current user's voice profiles. Each speaker gets at most one suggestion; applied is true when the speaker is already named after the profile.
+// @Tags transcription
+// @Produce json
+// @Param id path string true "Transcription Job ID"
+// @Success 200 {array} voices.Match
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/transcription/{id}/speakers/suggestions [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetSpeakerSuggestions(c *gin.Context) {
+	jobID := c.Param("id")
+	if !userOwnsJob(c, jobID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transcription job not found"})
+		return
+	}
+
+	matches, err := voices.Suggest(currentUserID(c), jobID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match speakers"})
+		return
+	}
+
+	c.JSON(http.StatusOK, matches)
+}
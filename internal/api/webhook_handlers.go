@@ -0,0 +1,303 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
+)
+
+// WebhookRequest is the payload for creating or updating a webhook
+type WebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description *string  `json:"description,omitempty"`
+	Events      []string `json:"events,omitempty"` // empty subscribes to every event
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// CreateWebhookResponse includes the signing secret, which is only returned once
+type CreateWebhookResponse struct {
+	models.Webhook
+	Secret string `json:"secret"`
+}
+
+// validateWebhookRequest checks the target URL and event names
+func validateWebhookRequest(req *WebhookRequest) string {
+	if err := webhooks.ValidateTargetURL(req.URL); err != nil {
+		return err.Error()
+	}
+	for _, event := range req.Events {
+		if !webhooks.IsSupportedEvent(event) {
+			return "Unsupported event: " + event
+		}
+	}
+	return ""
+}
+
+// findOwnedWebhook loads a webhook owned by the authenticated user, writing a response on failure
+func findOwnedWebhook(c *gin.Context, id string) (*models.Webhook, bool) {
+	var hook models.Webhook
+	if err := database.DB.Scopes(ownedBy(c)).Where("id = ?", id).First(&hook).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook"})
+		return nil, false
+	}
+	return &hook, true
+}
+
+// ListWebhooks returns the webhooks registered by the current user
+// @Summary List webhooks
+// @Description Get all webhooks registered by the current user
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} models.Webhook
+// @Router /api/v1/webhooks [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListWebhooks(c *gin.Context) {
+	var hooks []models.Webhook
+	if err := database.DB.Scopes(ownedBy(c)).Order("created_at DESC").Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch webhooks"})
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// CreateWebhook registers a new webhook
+// @Summary Create webhook
+// @Description Register an endpoint to receive job lifecycle events. Each request carries an X-Synthezia-Signature header holding "sha256=" followed by the hex HMAC-SHA256 of "<X-Synthezia-Timestamp>.<body>", keyed with the secret returned here (only once). URLs pointing to loopback, private or link-local addresses are refused unless the server sets WEBHOOK_ALLOW_PRIVATE_TARGETS=true.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param request body WebhookRequest true "Webhook data"
+// @Success 201 {object} CreateWebhookResponse
+// @Failure 400 {object} map[string]string
+// @Router /api/v1/webhooks [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if msg := validateWebhookRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate webhook secret"})
+		return
+	}
+
+	hook := models.Webhook{
+		UserID:      currentUserID(c),
+		URL:         strings.TrimSpace(req.URL),
+		Description: req.Description,
+		Secret:      secret,
+		Events:      req.Events,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := database.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create webhook"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreateWebhookResponse{Webhook: hook, Secret: secret})
+}
+
+// GetWebhook returns a webhook by ID
+// @Summary Get webhook
+// @Description Get a webhook by ID
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) GetWebhook(c *gin.Context) {
+	hook, ok := findOwnedWebhook(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, hook)
+}
+
+// UpdateWebhook changes a webhook's URL, events or active state
+// @Summary Update webhook
+// @Description Update a webhook's URL, description, events or active state. The signing secret is kept.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook data"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [put]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	hook, ok := findOwnedWebhook(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if msg := validateWebhookRequest(&req); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	hook.URL = strings.TrimSpace(req.URL)
+	hook.Description = req.Description
+	hook.Events = req.Events
+	if req.IsActive != nil {
+		hook.IsActive = *req.IsActive
+	}
+	if err := database.DB.Save(hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hook)
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+// @Summary Delete webhook
+// @Description Delete a webhook together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id} [delete]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	hook, ok := findOwnedWebhook(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", hook.ID).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(hook).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// ListWebhookDeliveries returns the delivery log of a webhook
+// @Summary List webhook deliveries
+// @Description Get the delivery log of a webhook, newest first
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param status query string false "Filter by status (pending, succeeded, failed)"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ListWebhookDeliveries(c *gin.Context) {
+	hook, ok := findOwnedWebhook(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	query := database.DB.Model(&models.WebhookDelivery{}).Where("webhook_id = ?", hook.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var total int64
+	query.Count(&total)
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Order("created_at DESC").Offset((page - 1) * limit).Limit(limit).Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"deliveries": deliveries,
+		"pagination": gin.H{
+			"page":  page,
+			"limit": limit,
+			"total": total,
+			"pages": (total + int64(limit) - 1) / int64(limit),
+		},
+	})
+}
+
+// ReplayWebhookDelivery re-sends the payload of an earlier delivery
+// @Summary Replay webhook delivery
+// @Description Queue a new delivery with the same payload (and event ID) as an earlier one
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 404 {object} map[string]string
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+// @Security ApiKeyAuth
+// @Security BearerAuth
+func (h *Handler) ReplayWebhookDelivery(c *gin.Context) {
+	hook, ok := findOwnedWebhook(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := database.DB.Where("id = ? AND webhook_id = ?", c.Param("delivery_id"), hook.ID).First(&original).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Delivery not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get delivery"})
+		return
+	}
+
+	delivery, err := webhooks.Replay(&original)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay delivery"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -72,3 +74,20 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// apiKeyPrefixLength is the number of leading characters of an API key kept in plaintext to tell keys apart
+const apiKeyPrefixLength = 8
+
+// HashAPIKey returns the hash under which an API key is stored
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// APIKeyPrefix returns the leading characters of an API key that are stored in plaintext
+func APIKeyPrefix(key string) string {
+	if len(key) > apiKeyPrefixLength {
+		return key[:apiKeyPrefixLength]
+	}
+	return key
+}
@@ -0,0 +1,426 @@
+package backup
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"synthezia/internal/database"
+	"synthezia/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Layout of a backup archive
+const (
+	Format        = "synthezia-backup"
+	FormatVersion = 1
+
+	ManifestName  = "manifest.json"
+	DatabaseName  = "database.sqlite"
+	UploadsPrefix = "uploads/"
+)
+
+// ErrInvalidArchive is returned for archives that are not backups or fail verification
+var ErrInvalidArchive = errors.New("invalid backup archive")
+
+// Manifest describes the content of a backup archive
+type Manifest struct {
+	Format        string      `json:"format"`
+	FormatVersion int         `json:"format_version"`
+	ID            string      `json:"id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	AppVersion    string      `json:"app_version,omitempty"`
+	SchemaVersion int         `json:"schema_version"` // Version of the database snapshot
+	SourceDialect string      `json:"source_dialect"` // Database the snapshot was taken from
+	UploadDir     string      `json:"upload_dir"`     // Upload directory as configured, the prefix of stored file paths
+	Files         []FileEntry `json:"files"`
+	Missing       []string    `json:"missing,omitempty"` // Files the database refers to that weren't found
+}
+
+// FileEntry is a file of the archive with its checksum
+type FileEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Options controls what a backup contains
+type Options struct {
+	UploadDir  string
+	AppVersion string
+}
+
+// Snapshot is a consistent copy of the database, taken while the server keeps running, that
+// is written to an archive together with the uploaded files it refers to
+type Snapshot struct {
+	opts     Options
+	manifest Manifest
+	dir      string
+	paths    []string // Paths of uploaded files the snapshot refers to
+}
+
+// Prepare snapshots the database. SQLite databases are copied with VACUUM INTO; PostgreSQL
+// databases are copied into a SQLite file within a single repeatable read transaction, so
+// that a backup restores on either database.
+func Prepare(opts Options) (*Snapshot, error) {
+	dir, err := os.MkdirTemp("", "synthezia-backup-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	snapshot := &Snapshot{
+		opts: opts,
+		dir:  dir,
+		manifest: Manifest{
+			Format:        Format,
+			FormatVersion: FormatVersion,
+			ID:            uuid.New().String(),
+			CreatedAt:     time.Now().UTC(),
+			AppVersion:    opts.AppVersion,
+			SourceDialect: database.Dialect(),
+			UploadDir:     opts.UploadDir,
+		},
+	}
+
+	if err := snapshot.copyDatabase(); err != nil {
+		snapshot.Close()
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// Manifest returns the manifest of the snapshot; its files are known once it was written
+func (s *Snapshot) Manifest() Manifest {
+	return s.manifest
+}
+
+// Close removes the database snapshot
+func (s *Snapshot) Close() error {
+	return os.RemoveAll(s.dir)
+}
+
+func (s *Snapshot) databasePath() string {
+	return filepath.Join(s.dir, DatabaseName)
+}
+
+func (s *Snapshot) copyDatabase() error {
+	if !database.IsPostgres() {
+		if err := database.DB.Exec("VACUUM INTO ?", s.databasePath()).Error; err != nil {
+			return fmt.Errorf("failed to snapshot database: %w", err)
+		}
+	}
+
+	snapshot, err := database.OpenSQLiteFile(s.databasePath())
+	if err != nil {
+		return err
+	}
+	defer database.CloseConnection(snapshot)
+
+	if database.IsPostgres() {
+		if _, err := database.MigrateDatabase(snapshot, database.MigrateOptions{}); err != nil {
+			return fmt.Errorf("failed to create snapshot schema: %w", err)
+		}
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return copyTables(tx, snapshot)
+		}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+		if err != nil {
+			return fmt.Errorf("failed to snapshot database: %w", err)
+		}
+	}
+
+	// Fold the write-ahead log into the file before it is archived
+	if err := snapshot.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return fmt.Errorf("failed to checkpoint snapshot: %w", err)
+	}
+	if s.manifest.SchemaVersion, err = database.DatabaseSchemaVersion(snapshot); err != nil {
+		return err
+	}
+	if s.paths, err = referencedPaths(snapshot); err != nil {
+		return err
+	}
+	return nil
+}
+
+// referencedPaths returns the paths of uploaded files the rows of a database refer to, from
+// the path columns of the restored tables
+func referencedPaths(db *gorm.DB) ([]string, error) {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, t := range tables {
+		for _, column := range t.paths {
+			var values []string
+			if err := db.Table(t.name).Where(column+" IS NOT NULL AND "+column+" <> ''").Distinct().Pluck(column, &values).Error; err != nil {
+				return nil, fmt.Errorf("failed to read file paths of %s: %w", t.name, err)
+			}
+			for _, value := range values {
+				if !seen[value] {
+					seen[value] = true
+					paths = append(paths, value)
+				}
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Write writes the archive: the database snapshot, the uploaded files it refers to and, last,
+// the manifest with their checksums. Referenced files that don't exist, or are removed while
+// the archive is written, are listed in the manifest as missing.
+func (s *Snapshot) Write(w io.Writer) (*Manifest, error) {
+	archive := zip.NewWriter(w)
+	s.manifest.Files = nil
+	s.manifest.Missing = nil
+
+	if err := s.addFile(archive, DatabaseName, s.databasePath(), zip.Deflate); err != nil {
+		return nil, err
+	}
+
+	added := make(map[string]bool)
+	for _, path := range s.paths {
+		if err := s.addUpload(archive, path, added); err != nil {
+			return nil, fmt.Errorf("failed to archive uploaded files: %w", err)
+		}
+	}
+	if len(s.manifest.Missing) > 0 {
+		logger.Warn("Files referenced by the database are missing from the backup",
+			"count", len(s.manifest.Missing), "paths", s.manifest.Missing)
+	}
+
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	writer, err := archive.Create(ManifestName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	manifest := s.manifest
+	return &manifest, nil
+}
+
+// WriteFile writes the archive to a file, replacing it only once the archive is complete
+func (s *Snapshot) WriteFile(path string) (*Manifest, error) {
+	tmp := path + ".tmp"
+	file, err := os.Create(tmp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	manifest, err := s.Write(file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// addUpload adds a file the database refers to, or every file of a folder it refers to, below
+// the uploads folder of the archive. Files outside the upload directory are left out.
+func (s *Snapshot) addUpload(archive *zip.Writer, path string, added map[string]bool) error {
+	if s.opts.UploadDir == "" {
+		return nil
+	}
+	rel, err := filepath.Rel(filepath.Clean(s.opts.UploadDir), filepath.Clean(path))
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		logger.Debug("File outside of the upload directory left out of backup", "path", path)
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		s.manifest.Missing = append(s.manifest.Missing, path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return s.addUploadFile(archive, path, rel, added)
+	}
+	return filepath.WalkDir(path, func(file string, entry os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		fileRel, err := filepath.Rel(filepath.Clean(s.opts.UploadDir), file)
+		if err != nil {
+			return err
+		}
+		return s.addUploadFile(archive, file, fileRel, added)
+	})
+}
+
+// addUploadFile adds an uploaded file once, noting it as missing if it is gone by now
+func (s *Snapshot) addUploadFile(archive *zip.Writer, path, rel string, added map[string]bool) error {
+	name := UploadsPrefix + filepath.ToSlash(rel)
+	if added[name] {
+		return nil
+	}
+	added[name] = true
+	// Audio is compressed already
+	err := s.addFile(archive, name, path, zip.Store)
+	if os.IsNotExist(err) {
+		s.manifest.Missing = append(s.manifest.Missing, path)
+		return nil
+	}
+	return err
+}
+
+// addFile copies a file into the archive and records its checksum
+func (s *Snapshot) addFile(archive *zip.Writer, name, path string, method uint16) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	header.Method = method
+	writer, err := archive.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(writer, hash), file)
+	if err != nil {
+		return fmt.Errorf("failed to archive %s: %w", path, err)
+	}
+	s.manifest.Files = append(s.manifest.Files, FileEntry{Path: name, Size: size, SHA256: hex.EncodeToString(hash.Sum(nil))})
+	return nil
+}
+
+// Verify checks that an archive is a backup this build can read and that every file matches
+// its checksum in the manifest
+func Verify(archivePath string) (*Manifest, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer archive.Close()
+
+	manifest, err := verifyArchive(&archive.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// verifyArchive reads the manifest and checks the archive holds exactly the files it lists,
+// with their size and checksum
+func verifyArchive(archive *zip.Reader) (*Manifest, error) {
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		entries[file.Name] = file
+	}
+
+	manifestFile, ok := entries[ManifestName]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, ManifestName)
+	}
+	var manifest Manifest
+	reader, err := manifestFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	err = json.NewDecoder(reader).Decode(&manifest)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read manifest: %v", ErrInvalidArchive, err)
+	}
+	if manifest.Format != Format {
+		return nil, fmt.Errorf("%w: not a SynthezIA backup", ErrInvalidArchive)
+	}
+	if manifest.FormatVersion > FormatVersion {
+		return nil, fmt.Errorf("%w: format version %d is newer than this build supports (%d)", ErrInvalidArchive, manifest.FormatVersion, FormatVersion)
+	}
+
+	listed := make(map[string]bool, len(manifest.Files))
+	for _, entry := range manifest.Files {
+		if !validEntryName(entry.Path) {
+			return nil, fmt.Errorf("%w: unexpected file %q", ErrInvalidArchive, entry.Path)
+		}
+		file, ok := entries[entry.Path]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, entry.Path)
+		}
+		if err := verifyEntry(file, entry); err != nil {
+			return nil, err
+		}
+		listed[entry.Path] = true
+	}
+	if !listed[DatabaseName] {
+		return nil, fmt.Errorf("%w: %s is missing", ErrInvalidArchive, DatabaseName)
+	}
+	for name := range entries {
+		if name != ManifestName && !listed[name] {
+			return nil, fmt.Errorf("%w: %s is not in the manifest", ErrInvalidArchive, name)
+		}
+	}
+	return &manifest, nil
+}
+
+// validEntryName accepts the database and clean relative paths below the uploads folder
+func validEntryName(name string) bool {
+	if name == DatabaseName {
+		return true
+	}
+	rel := strings.TrimPrefix(name, UploadsPrefix)
+	return rel != name && rel != "" && path.Clean(rel) == rel && !path.IsAbs(rel) &&
+		rel != ".." && !strings.HasPrefix(rel, "../") && !strings.Contains(rel, "\\")
+}
+
+func verifyEntry(file *zip.File, entry FileEntry) error {
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer reader.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(hash, reader)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read %s: %v", ErrInvalidArchive, entry.Path, err)
+	}
+	if size != entry.Size || hex.EncodeToString(hash.Sum(nil)) != entry.SHA256 {
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrInvalidArchive, entry.Path)
+	}
+	return nil
+}
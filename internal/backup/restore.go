@@ -0,0 +1,509 @@
+package backup
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+	"synthezia/pkg/logger"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// RestoreOptions controls where a backup is restored
+type RestoreOptions struct {
+	UploadDir string
+}
+
+// RestoreResult summarizes what a restore added to the instance
+type RestoreResult struct {
+	BackupID    string         `json:"backup_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UploadRoot  string         `json:"upload_root"`  // Directory the uploaded files were restored to
+	Files       int            `json:"files"`        // Uploaded files restored
+	Records     map[string]int `json:"records"`      // Rows added per table
+	MergedUsers []string       `json:"merged_users"` // Users of the backup that already existed
+	RenamedIDs  int            `json:"renamed_ids"`  // Rows given a new ID because theirs was taken
+	SkippedRows int            `json:"skipped_rows"` // Rows left out because what they refer to isn't restored
+	IndexedJobs int            `json:"indexed_jobs"` // Transcripts added to the search index
+}
+
+// Restore merges a backup into the instance. Archives are verified before anything changes
+// and the rows are added in one transaction. Rows keep their IDs unless the instance already
+// uses them, users and tags merge with the existing ones of the same name, and uploaded files
+// go below a folder of their own if any of their paths is taken.
+func Restore(archivePath string, opts RestoreOptions) (*RestoreResult, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer archive.Close()
+
+	manifest, err := verifyArchive(&archive.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "synthezia-restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	entries := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		entries[file.Name] = file
+	}
+	snapshotPath := filepath.Join(dir, DatabaseName)
+	if err := extractFile(entries[DatabaseName], snapshotPath); err != nil {
+		return nil, err
+	}
+
+	src, err := database.OpenSQLiteFile(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	defer database.CloseConnection(src)
+
+	// Backups of older versions are brought up to the schema of this build first
+	if _, err := database.MigrateDatabase(src, database.MigrateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to upgrade backup database: %w", err)
+	}
+
+	result := &RestoreResult{
+		BackupID:   manifest.ID,
+		CreatedAt:  manifest.CreatedAt,
+		UploadRoot: uploadRoot(manifest, opts.UploadDir),
+		Records:    make(map[string]int),
+	}
+
+	var restored []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		imp := &importer{
+			src:      src,
+			dst:      tx,
+			manifest: manifest,
+			root:     result.UploadRoot,
+			result:   result,
+			ids:      make(map[string]map[string]string),
+			merged:   make(map[string]map[string]bool),
+		}
+		if err := imp.importAll(); err != nil {
+			return err
+		}
+
+		// Files are written last so that a failed import leaves none behind
+		for _, entry := range manifest.Files {
+			if !strings.HasPrefix(entry.Path, UploadsPrefix) {
+				continue
+			}
+			target := filepath.Join(result.UploadRoot, filepath.FromSlash(strings.TrimPrefix(entry.Path, UploadsPrefix)))
+			if err := extractFile(entries[entry.Path], target); err != nil {
+				return err
+			}
+			restored = append(restored, target)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, path := range restored {
+			os.Remove(path)
+		}
+		return nil, err
+	}
+	result.Files = len(restored)
+
+	if result.IndexedJobs, err = search.BackfillIndex(); err != nil {
+		logger.Warn("Failed to index restored transcripts", "error", err)
+	}
+
+	logger.Info("Restored backup",
+		"backup_id", manifest.ID, "files", result.Files, "jobs", result.Records["transcription_jobs"],
+		"merged_users", len(result.MergedUsers), "renamed_ids", result.RenamedIDs)
+	return result, nil
+}
+
+// uploadRoot returns the directory the uploaded files of a backup are restored to: the upload
+// directory itself, unless one of the files would overwrite an existing file there
+func uploadRoot(manifest *Manifest, uploadDir string) string {
+	taken := func(root string) bool {
+		for _, entry := range manifest.Files {
+			if rel := strings.TrimPrefix(entry.Path, UploadsPrefix); rel != entry.Path {
+				if _, err := os.Stat(filepath.Join(root, filepath.FromSlash(rel))); err == nil {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	root := uploadDir
+	for i := 1; taken(root); i++ {
+		root = filepath.Join(uploadDir, "restored", manifest.ID)
+		if i > 1 {
+			root += "-" + strconv.Itoa(i)
+		}
+	}
+	return root
+}
+
+// extractFile writes an archive entry to a new file
+func extractFile(file *zip.File, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", target, err)
+	}
+	reader, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer reader.Close()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	_, err = io.Copy(out, reader)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return fmt.Errorf("failed to restore %s: %w", target, err)
+	}
+	return nil
+}
+
+// importer adds the rows of a backup database to the instance, remapping the references
+// between them to the IDs the rows get
+type importer struct {
+	src, dst *gorm.DB
+	manifest *Manifest
+	root     string // Directory uploaded files are restored to
+	result   *RestoreResult
+
+	ids    map[string]map[string]string // Table, ID in the backup, ID in the instance
+	merged map[string]map[string]bool   // Table, IDs of rows merged into existing rows
+}
+
+func (imp *importer) importAll() error {
+	defaultProfiles, err := imp.importUsers()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range tables {
+		if err := imp.importTable(t); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", t.name, err)
+		}
+
+		// Profiles of new users can be set once the profiles are restored
+		if t.name == "transcription_profiles" {
+			for userID, profileID := range defaultProfiles {
+				if id, ok := imp.lookup("transcription_profiles", profileID); ok {
+					if err := imp.dst.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("default_profile_id", id).Error; err != nil {
+						return fmt.Errorf("failed to restore default profile: %w", err)
+					}
+				}
+			}
+		}
+	}
+
+	for _, join := range joinTables {
+		if err := imp.importJoinTable(join); err != nil {
+			return fmt.Errorf("failed to restore %s: %w", join.name, err)
+		}
+	}
+	return nil
+}
+
+// importUsers maps users of the backup to the existing users of the same name and adds the
+// others. It returns the default profiles of the added users, by their new ID.
+func (imp *importer) importUsers() (map[uint]string, error) {
+	s, err := parseSchema(imp.dst, &models.User{})
+	if err != nil {
+		return nil, err
+	}
+
+	defaultProfiles := make(map[uint]string)
+	err = eachRow(imp.src, &models.User{}, func(row interface{}) error {
+		user := row.(*models.User)
+		oldID := strconv.FormatUint(uint64(user.ID), 10)
+
+		var existing models.User
+		if err := imp.dst.Where("username = ?", user.Username).Limit(1).Find(&existing).Error; err != nil {
+			return err
+		}
+		if existing.ID != 0 {
+			imp.setID("users", oldID, strconv.FormatUint(uint64(existing.ID), 10))
+			imp.result.MergedUsers = append(imp.result.MergedUsers, user.Username)
+			return nil
+		}
+
+		profileID := user.DefaultProfileID
+		user.ID = 0
+		user.DefaultProfileID = nil
+		if err := insertRow(imp.dst, s, user); err != nil {
+			return err
+		}
+		if profileID != nil {
+			defaultProfiles[user.ID] = *profileID
+		}
+		imp.setID("users", oldID, strconv.FormatUint(uint64(user.ID), 10))
+		imp.result.Records["users"]++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore users: %w", err)
+	}
+	return defaultProfiles, nil
+}
+
+// importTable adds the rows of a table. Rows with a string ID are given their IDs in a first
+// pass, so that rows may refer to others of the same table.
+func (imp *importer) importTable(t table) error {
+	s, err := parseSchema(imp.dst, t.model)
+	if err != nil {
+		return err
+	}
+	primaryKey := s.PrioritizedPrimaryField
+	stringKey := primaryKey.FieldType.Kind() == reflect.String
+	ctx := context.Background()
+
+	if t.intoEmpty {
+		var count int64
+		if err := imp.dst.Model(t.model).Count(&count).Error; err != nil || count > 0 {
+			return err
+		}
+	}
+
+	if stringKey {
+		if err := eachRow(imp.src, t.model, func(row interface{}) error {
+			return imp.assignID(t, s, row)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return eachRow(imp.src, t.model, func(row interface{}) error {
+		value := reflect.ValueOf(row)
+		key := primaryKey.ReflectValueOf(ctx, value)
+		if stringKey {
+			newID, ok := imp.lookup(t.name, key.String())
+			if !ok || imp.merged[t.name][key.String()] {
+				return nil
+			}
+			key.SetString(newID)
+		} else {
+			if ok, err := imp.keep(t, s, row); err != nil || !ok {
+				return err
+			}
+			if t.match != nil {
+				if _, found, err := t.match(imp, row); err != nil || found {
+					return err
+				}
+			}
+			// Nothing refers to rows with a numeric ID, the instance numbers them
+			key.SetUint(0)
+		}
+
+		imp.remap(t, s, value)
+		if t.adjust != nil {
+			t.adjust(imp, row)
+		}
+		if err := insertRow(imp.dst, s, row); err != nil {
+			return err
+		}
+		imp.result.Records[t.name]++
+		return nil
+	})
+}
+
+// assignID decides the ID a row with a string ID gets: its own unless the instance uses it
+func (imp *importer) assignID(t table, s *schema.Schema, row interface{}) error {
+	oldID := s.PrioritizedPrimaryField.ReflectValueOf(context.Background(), reflect.ValueOf(row)).String()
+	if ok, err := imp.keep(t, s, row); err != nil || !ok {
+		return err
+	}
+
+	if t.match != nil {
+		id, found, err := t.match(imp, row)
+		if err != nil {
+			return err
+		}
+		if found {
+			imp.setID(t.name, oldID, id)
+			if imp.merged[t.name] == nil {
+				imp.merged[t.name] = make(map[string]bool)
+			}
+			imp.merged[t.name][oldID] = true
+			return nil
+		}
+	}
+
+	var count int64
+	if err := imp.dst.Model(t.model).Where("id = ?", oldID).Count(&count).Error; err != nil {
+		return err
+	}
+	newID := oldID
+	if count > 0 {
+		newID = uuid.New().String()
+		imp.result.RenamedIDs++
+	}
+	imp.setID(t.name, oldID, newID)
+	return nil
+}
+
+// keep reports whether a row is restored: it isn't skipped and what it requires is restored
+func (imp *importer) keep(t table, s *schema.Schema, row interface{}) (bool, error) {
+	if t.skip != nil && t.skip(row) {
+		return false, nil
+	}
+	value := reflect.ValueOf(row)
+	for _, r := range t.refs {
+		if !r.required {
+			continue
+		}
+		field, ok := s.FieldsByDBName[r.column]
+		if !ok {
+			return false, fmt.Errorf("unknown column %s", r.column)
+		}
+		if _, found := imp.lookup(r.table, referenceValue(field.ReflectValueOf(context.Background(), value))); !found {
+			imp.result.SkippedRows++
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// remap points the references and file paths of a row at the restored rows and files
+func (imp *importer) remap(t table, s *schema.Schema, value reflect.Value) {
+	ctx := context.Background()
+	for _, r := range t.refs {
+		field := s.FieldsByDBName[r.column].ReflectValueOf(ctx, value)
+		old := referenceValue(field)
+		if old == "" {
+			continue
+		}
+		id, ok := imp.lookup(r.table, old)
+		if !ok {
+			// Optional reference to a row that isn't restored
+			if field.Kind() == reflect.Ptr {
+				field.Set(reflect.Zero(field.Type()))
+			} else if r.table == "users" {
+				field.SetUint(0)
+			}
+			continue
+		}
+		if field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.String {
+			field.SetString(id)
+		} else {
+			n, _ := strconv.ParseUint(id, 10, 64)
+			field.SetUint(n)
+		}
+	}
+
+	for _, column := range t.paths {
+		field := s.FieldsByDBName[column].ReflectValueOf(ctx, value)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		field.SetString(imp.restoredPath(field.String()))
+	}
+}
+
+// restoredPath returns where a file of the backup's upload directory is restored to. Paths
+// outside of it are kept as they are.
+func (imp *importer) restoredPath(path string) string {
+	if path == "" || imp.manifest.UploadDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(filepath.Clean(imp.manifest.UploadDir), filepath.Clean(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.Join(imp.root, rel)
+}
+
+// importJoinTable adds the pairs of a many-to-many table whose rows were both restored
+func (imp *importer) importJoinTable(join joinTable) error {
+	var rows []map[string]interface{}
+	if err := imp.src.Table(join.name).Find(&rows).Error; err != nil {
+		return err
+	}
+	for _, row := range rows {
+		left, leftOK := imp.lookup(join.left.table, fmt.Sprint(row[join.left.column]))
+		right, rightOK := imp.lookup(join.right.table, fmt.Sprint(row[join.right.column]))
+		if !leftOK || !rightOK {
+			imp.result.SkippedRows++
+			continue
+		}
+		pair := map[string]interface{}{join.left.column: left, join.right.column: right}
+		if err := imp.dst.Table(join.name).Clauses(clause.OnConflict{DoNothing: true}).Create(pair).Error; err != nil {
+			return err
+		}
+		imp.result.Records[join.name]++
+	}
+	return nil
+}
+
+func (imp *importer) setID(table, oldID, newID string) {
+	if imp.ids[table] == nil {
+		imp.ids[table] = make(map[string]string)
+	}
+	imp.ids[table][oldID] = newID
+}
+
+// lookup returns the ID a row of the backup has in the instance
+func (imp *importer) lookup(table, oldID string) (string, bool) {
+	id, ok := imp.ids[table][oldID]
+	return id, ok
+}
+
+// lookupUser returns the ID a user of the backup has in the instance, 0 for no user
+func (imp *importer) lookupUser(oldID uint) (uint, bool) {
+	id, ok := imp.lookup("users", strconv.FormatUint(uint64(oldID), 10))
+	if !ok {
+		return 0, false
+	}
+	n, _ := strconv.ParseUint(id, 10, 64)
+	return uint(n), true
+}
+
+// referenceValue returns a reference column as a string, empty for no reference
+func referenceValue(field reflect.Value) string {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+	switch field.Kind() {
+	case reflect.String:
+		return field.String()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if field.Uint() == 0 {
+			return ""
+		}
+		return strconv.FormatUint(field.Uint(), 10)
+	}
+	return fmt.Sprint(field.Interface())
+}
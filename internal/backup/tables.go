@@ -0,0 +1,256 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"synthezia/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// batchSize is the number of rows read at a time
+const batchSize = 200
+
+// table describes how the rows of a model are carried over by a restore
+type table struct {
+	name  string
+	model interface{}
+	refs  []ref
+	paths []string // Columns holding paths of uploaded files
+
+	// intoEmpty restores the rows only into an instance that has none, for settings that only
+	// make sense once
+	intoEmpty bool
+	// skip leaves out a row of the backup
+	skip func(row interface{}) bool
+	// match returns the ID of an existing row the backup row is merged into instead of being added
+	match func(imp *importer, row interface{}) (string, bool, error)
+	// adjust rewrites references kept outside of columns, once the columns are remapped
+	adjust func(imp *importer, row interface{})
+}
+
+// ref is a column referencing the primary key of another table
+type ref struct {
+	column   string
+	table    string
+	required bool // Rows whose reference isn't restored are left out; optional references are cleared
+}
+
+// joinTable is a many-to-many table without a model of its own
+type joinTable struct {
+	name        string
+	left, right ref
+}
+
+var ownerRef = ref{column: "user_id", table: "users"}
+
+// tables lists the restored tables so that every table comes after the tables it references.
+// Users are restored before all of them, matching existing users by username. Refresh tokens,
+// invitations, webhook deliveries, live sessions and the dropzone's records of ingested files
+// are not restored; the search index is rebuilt from the transcripts.
+var tables = []table{
+	{name: "transcription_profiles", model: &models.TranscriptionProfile{}, refs: []ref{ownerRef}},
+	{name: "folders", model: &models.Folder{}, refs: []ref{ownerRef, {column: "parent_id", table: "folders"}}},
+	{name: "tags", model: &models.Tag{}, refs: []ref{ownerRef}, match: matchTag},
+	{name: "summary_templates", model: &models.SummaryTemplate{}},
+	{name: "voice_profiles", model: &models.VoiceProfile{}, refs: []ref{ownerRef}},
+	{name: "replacement_dictionaries", model: &models.ReplacementDictionary{}, refs: []ref{ownerRef}},
+	{name: "webhooks", model: &models.Webhook{}, refs: []ref{ownerRef}},
+	{name: "api_keys", model: &models.APIKey{}, refs: []ref{ownerRef}, match: matchAPIKey},
+	{name: "llm_configs", model: &models.LLMConfig{}, intoEmpty: true},
+	{name: "summary_settings", model: &models.SummarySetting{}, intoEmpty: true},
+	{
+		name:  "transcription_jobs",
+		model: &models.TranscriptionJob{},
+		refs:  []ref{ownerRef, {column: "folder_id", table: "folders"}},
+		paths: []string{"audio_path", "aup_file_path", "multi_track_folder", "merged_audio_path"},
+		skip:  isTrackJob,
+	},
+	{
+		name:  "transcription_job_executions",
+		model: &models.TranscriptionJobExecution{},
+		refs:  []ref{{column: "transcription_job_id", table: "transcription_jobs", required: true}},
+		paths: []string{"preprocessed_audio"},
+	},
+	{
+		name:  "multi_track_files",
+		model: &models.MultiTrackFile{},
+		refs:  []ref{{column: "transcription_job_id", table: "transcription_jobs", required: true}},
+		paths: []string{"file_path"},
+	},
+	{name: "speaker_mappings", model: &models.SpeakerMapping{}, refs: []ref{
+		{column: "transcription_job_id", table: "transcription_jobs", required: true},
+		{column: "voice_profile_id", table: "voice_profiles"},
+	}},
+	{name: "notes", model: &models.Note{}, refs: []ref{ownerRef, {column: "transcription_id", table: "transcription_jobs", required: true}}},
+	{name: "transcript_revisions", model: &models.TranscriptRevision{}, refs: []ref{ownerRef, {column: "transcription_job_id", table: "transcription_jobs", required: true}}},
+	{name: "summaries", model: &models.Summary{}, refs: []ref{
+		ownerRef,
+		{column: "transcription_id", table: "transcription_jobs", required: true},
+		{column: "template_id", table: "summary_templates"},
+	}},
+	{name: "chat_sessions", model: &models.ChatSession{}, refs: []ref{
+		ownerRef,
+		{column: "job_id", table: "transcription_jobs", required: true},
+		{column: "transcription_id", table: "transcription_jobs", required: true},
+		{column: "folder_id", table: "folders"},
+	}},
+	{
+		name:  "chat_messages",
+		model: &models.ChatMessage{},
+		refs: []ref{
+			{column: "chat_session_id", table: "chat_sessions", required: true},
+			{column: "session_id", table: "chat_sessions", required: true},
+		},
+		adjust: remapCitations,
+	},
+	{name: "transcript_chunk_embeddings", model: &models.TranscriptChunkEmbedding{}, refs: []ref{{column: "transcription_job_id", table: "transcription_jobs", required: true}}},
+	{name: "voice_samples", model: &models.VoiceSample{}, refs: []ref{
+		{column: "voice_profile_id", table: "voice_profiles", required: true},
+		{column: "transcription_job_id", table: "transcription_jobs", required: true},
+	}},
+	{name: "speaker_embeddings", model: &models.SpeakerEmbedding{}, refs: []ref{{column: "transcription_job_id", table: "transcription_jobs", required: true}}},
+}
+
+// joinTables are restored after all tables
+var joinTables = []joinTable{
+	{
+		name:  "transcription_job_tags",
+		left:  ref{column: "transcription_job_id", table: "transcription_jobs", required: true},
+		right: ref{column: "tag_id", table: "tags", required: true},
+	},
+	{
+		name:  "chat_session_transcriptions",
+		left:  ref{column: "chat_session_id", table: "chat_sessions", required: true},
+		right: ref{column: "transcription_job_id", table: "transcription_jobs", required: true},
+	},
+}
+
+// isTrackJob reports whether a row is one of the temporary per-track jobs of a multi-track job
+func isTrackJob(row interface{}) bool {
+	return strings.HasPrefix(row.(*models.TranscriptionJob).ID, "track_")
+}
+
+// matchTag merges a tag into the tag of the same name its owner already has
+func matchTag(imp *importer, row interface{}) (string, bool, error) {
+	tag := row.(*models.Tag)
+	userID, _ := imp.lookupUser(tag.UserID)
+	var existing models.Tag
+	err := imp.dst.Where("user_id = ? AND name = ?", userID, tag.Name).Limit(1).Find(&existing).Error
+	return existing.ID, existing.ID != "", err
+}
+
+// matchAPIKey leaves out keys the instance already knows
+func matchAPIKey(imp *importer, row interface{}) (string, bool, error) {
+	var count int64
+	err := imp.dst.Model(&models.APIKey{}).Where("key = ?", row.(*models.APIKey).Key).Count(&count).Error
+	return "", count > 0, err
+}
+
+// remapCitations points the citations of a chat answer at the restored transcripts
+func remapCitations(imp *importer, row interface{}) {
+	message := row.(*models.ChatMessage)
+	for i := range message.Citations {
+		if id, ok := imp.lookup("transcription_jobs", message.Citations[i].JobID); ok {
+			message.Citations[i].JobID = id
+		}
+	}
+}
+
+// parseSchema returns the gorm schema of a model
+func parseSchema(db *gorm.DB, model interface{}) (*schema.Schema, error) {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(model); err != nil {
+		return nil, fmt.Errorf("failed to parse %T: %w", model, err)
+	}
+	return statement.Schema, nil
+}
+
+// eachRow calls fn with a copy of every row of the model's table, reading them in batches.
+// fn may change the row, the batch keeps the primary key the next batch starts after.
+func eachRow(db *gorm.DB, model interface{}, fn func(row interface{}) error) error {
+	rowType := reflect.TypeOf(model).Elem()
+	rows := reflect.New(reflect.SliceOf(rowType))
+	return db.Model(model).FindInBatches(rows.Interface(), batchSize, func(tx *gorm.DB, batch int) error {
+		slice := rows.Elem()
+		for i := 0; i < slice.Len(); i++ {
+			row := reflect.New(rowType)
+			row.Elem().Set(slice.Index(i))
+			if err := fn(row.Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// insertRow inserts a row as it is: hooks don't run, associations are left alone and zero
+// values are kept even where the column has a default
+func insertRow(db *gorm.DB, s *schema.Schema, row interface{}) error {
+	ctx := context.Background()
+	value := reflect.ValueOf(row)
+
+	// gorm replaces zero values of columns with a default by that default
+	var zeroDefaults []string
+	for _, field := range s.Fields {
+		if field.DBName == "" || field.DefaultValueInterface == nil || field.PrimaryKey {
+			continue
+		}
+		if _, zero := field.ValueOf(ctx, value); zero {
+			zeroDefaults = append(zeroDefaults, field.DBName)
+		}
+	}
+
+	session := db.Session(&gorm.Session{SkipHooks: true})
+	if err := session.Omit(clause.Associations).Create(row).Error; err != nil {
+		return err
+	}
+	if len(zeroDefaults) == 0 {
+		return nil
+	}
+	for _, column := range zeroDefaults {
+		if err := s.FieldsByDBName[column].Set(ctx, value, reflect.Zero(s.FieldsByDBName[column].FieldType).Interface()); err != nil {
+			return err
+		}
+	}
+	return session.Model(row).Select(zeroDefaults).UpdateColumns(row).Error
+}
+
+// copyTables copies users and every restored table, keeping their IDs, into an empty database
+func copyTables(src, dst *gorm.DB) error {
+	copyModel := func(model interface{}) error {
+		s, err := parseSchema(dst, model)
+		if err != nil {
+			return err
+		}
+		return eachRow(src, model, func(row interface{}) error {
+			return insertRow(dst, s, row)
+		})
+	}
+
+	if err := copyModel(&models.User{}); err != nil {
+		return fmt.Errorf("failed to copy users: %w", err)
+	}
+	for _, t := range tables {
+		if err := copyModel(t.model); err != nil {
+			return fmt.Errorf("failed to copy %s: %w", t.name, err)
+		}
+	}
+	for _, join := range joinTables {
+		var rows []map[string]interface{}
+		if err := src.Table(join.name).Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to copy %s: %w", join.name, err)
+		}
+		if len(rows) > 0 {
+			if err := dst.Table(join.name).Create(&rows).Error; err != nil {
+				return fmt.Errorf("failed to copy %s: %w", join.name, err)
+			}
+		}
+	}
+	return nil
+}
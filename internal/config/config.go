@@ -22,6 +22,7 @@ type Config struct {
 
 	// Database configuration
 	DatabasePath string
+	DatabaseURL  string // PostgreSQL connection URL; when empty SQLite at DatabasePath is used
 
 	// JWT configuration
 	JWTSecret string
@@ -40,8 +41,46 @@ type Config struct {
 
 	// YouTube configuration
 	YoutubeCookiesPath string
+
+	// Dropzone configuration
+	DropzoneEnabled      bool
+	DropzonePath         string
+	DropzoneRecursive    bool
+	DropzoneAfterProcess string            // delete, move or leave
+	DropzoneProfiles     map[string]string // subfolder -> profile name or ID
+
+	// Crash recovery configuration
+	RecoveryPolicy      string // requeue or fail jobs interrupted by a restart
+	RecoveryMaxRequeues int    // interrupted runs after which a job is failed instead of requeued
+
+	// Automatic retry configuration
+	RetryMaxAttempts       int  // total attempts per job, 1 disables retries
+	RetryBackoffSeconds    int  // delay before the first retry, doubled for each further one
+	RetryMaxBackoffSeconds int
+	RetryOOMFallback       bool // retry out-of-memory failures with a smaller batch size or int8
+
+	// Memory budget running jobs are admitted against, in MB; 0 means unlimited
+	ResourceRAMBudgetMB  int
+	ResourceVRAMBudgetMB int
+
+	// Cosine similarity at which a diarized speaker is matched to an enrolled voice profile
+	VoiceSuggestThreshold   float64 // offered as a suggested name
+	VoiceAutoApplyThreshold float64 // applied as the speaker name without asking
 }
 
+// Dropzone post-processing actions
+const (
+	DropzoneActionDelete = "delete"
+	DropzoneActionMove   = "move"
+	DropzoneActionLeave  = "leave"
+)
+
+// Crash recovery policies for jobs left in processing by a restart
+const (
+	RecoveryPolicyRequeue = "requeue"
+	RecoveryPolicyFail    = "fail"
+)
+
 // Load loads configuration from environment variables and .env file
 func Load() *Config {
 	// Load .env file if it exists
@@ -53,6 +92,7 @@ func Load() *Config {
 		Port:               getEnv("PORT", "8080"),
 		Host:               getEnv("HOST", "localhost"),
 		DatabasePath:       getEnv("DATABASE_PATH", "data/synthezia.db"),
+		DatabaseURL:        getEnv("DATABASE_URL", ""),
 		JWTSecret:          getJWTSecret(),
 		UploadDir:          getEnv("UPLOAD_DIR", "data/uploads"),
 		UVPath:             findUVPath(),
@@ -63,6 +103,26 @@ func Load() *Config {
 		OpenAIAPIKey:  		getEnv("OPENAI_API_KEY", ""),
 
 		YoutubeCookiesPath: getEnv("YOUTUBE_COOKIES_PATH", ""),
+
+		DropzoneEnabled:      getEnvAsBool("DROPZONE_ENABLED", true),
+		DropzonePath:         getEnv("DROPZONE_PATH", "data/dropzone"),
+		DropzoneRecursive:    getEnvAsBool("DROPZONE_RECURSIVE", true),
+		DropzoneAfterProcess: getDropzoneAction(),
+		DropzoneProfiles:     parseDropzoneProfiles(os.Getenv("DROPZONE_PROFILES")),
+
+		RecoveryPolicy:      getRecoveryPolicy(),
+		RecoveryMaxRequeues: getEnvAsInt("RECOVERY_MAX_REQUEUES", 2),
+
+		RetryMaxAttempts:       getEnvAsInt("RETRY_MAX_ATTEMPTS", 3),
+		RetryBackoffSeconds:    getEnvAsInt("RETRY_BACKOFF_SECONDS", 30),
+		RetryMaxBackoffSeconds: getEnvAsInt("RETRY_MAX_BACKOFF_SECONDS", 600),
+		RetryOOMFallback:       getEnvAsBool("RETRY_OOM_FALLBACK", true),
+
+		ResourceRAMBudgetMB:  getEnvAsInt("RESOURCE_RAM_BUDGET_MB", 0),
+		ResourceVRAMBudgetMB: getEnvAsInt("RESOURCE_VRAM_BUDGET_MB", 0),
+
+		VoiceSuggestThreshold:   getEnvAsFloat("VOICE_SUGGEST_THRESHOLD", 0.6),
+		VoiceAutoApplyThreshold: getEnvAsFloat("VOICE_AUTO_APPLY_THRESHOLD", 0.75),
 	}
 }
 
@@ -84,6 +144,16 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvAsFloat gets an environment variable as float with a default value
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvAsBool gets an environment variable as bool with a default value
 func getEnvAsBool(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
@@ -94,6 +164,43 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getDropzoneAction gets the dropzone post-processing action, falling back to delete
+func getDropzoneAction() string {
+	action := strings.ToLower(getEnv("DROPZONE_AFTER_PROCESS", DropzoneActionDelete))
+	switch action {
+	case DropzoneActionDelete, DropzoneActionMove, DropzoneActionLeave:
+		return action
+	}
+	logger.Warn("Unknown dropzone post-processing action, using delete", "action", action)
+	return DropzoneActionDelete
+}
+
+// getRecoveryPolicy gets the crash recovery policy, falling back to requeue
+func getRecoveryPolicy() string {
+	policy := strings.ToLower(getEnv("RECOVERY_POLICY", RecoveryPolicyRequeue))
+	if policy != RecoveryPolicyRequeue && policy != RecoveryPolicyFail {
+		logger.Warn("Unknown recovery policy, using requeue", "policy", policy)
+		return RecoveryPolicyRequeue
+	}
+	return policy
+}
+
+// parseDropzoneProfiles parses "folder=profile" pairs separated by commas,
+// e.g. "interviews=Interview,calls/support=Phone Calls"
+func parseDropzoneProfiles(value string) map[string]string {
+	profiles := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		folder, profile, ok := strings.Cut(pair, "=")
+		folder = strings.Trim(strings.TrimSpace(folder), "/")
+		profile = strings.TrimSpace(profile)
+		if !ok || folder == "" || profile == "" {
+			continue
+		}
+		profiles[folder] = profile
+	}
+	return profiles
+}
+
 // getJWTSecret gets JWT secret from env or generates a secure random one
 func getJWTSecret() string {
 	if secret := os.Getenv("JWT_SECRET"); secret != "" {
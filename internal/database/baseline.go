@@ -0,0 +1,311 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// migrateBaselineSchema creates the schema of the first release, which predates versioned
+// migrations. The models are frozen copies of the ones of that release, declared in an order
+// that lets each refer to the ones before it, so later changes to the models don't reach here.
+func migrateBaselineSchema(tx *gorm.DB) error {
+	type WhisperXParams struct {
+		ModelFamily                    string  `gorm:"type:varchar(20);default:'whisper'"`
+		Model                          string  `gorm:"type:varchar(50);default:'small'"`
+		ModelCacheOnly                 bool    `gorm:"type:boolean;default:false"`
+		ModelDir                       *string `gorm:"type:text"`
+		Device                         string  `gorm:"type:varchar(20);default:'cpu'"`
+		DeviceIndex                    int     `gorm:"type:int;default:0"`
+		BatchSize                      int     `gorm:"type:int;default:8"`
+		ComputeType                    string  `gorm:"type:varchar(20);default:'float32'"`
+		Threads                        int     `gorm:"type:int;default:0"`
+		OutputFormat                   string  `gorm:"type:varchar(20);default:'all'"`
+		Verbose                        bool    `gorm:"type:boolean;default:true"`
+		Task                           string  `gorm:"type:varchar(20);default:'transcribe'"`
+		Language                       *string `gorm:"type:varchar(10)"`
+		AlignModel                     *string `gorm:"type:varchar(100)"`
+		InterpolateMethod              string  `gorm:"type:varchar(20);default:'nearest'"`
+		NoAlign                        bool    `gorm:"type:boolean;default:false"`
+		ReturnCharAlignments           bool    `gorm:"type:boolean;default:false"`
+		VadMethod                      string  `gorm:"type:varchar(20);default:'pyannote'"`
+		VadOnset                       float64 `gorm:"type:real;default:0.5"`
+		VadOffset                      float64 `gorm:"type:real;default:0.363"`
+		ChunkSize                      int     `gorm:"type:int;default:30"`
+		Diarize                        bool    `gorm:"type:boolean;default:false"`
+		MinSpeakers                    *int    `gorm:"type:int"`
+		MaxSpeakers                    *int    `gorm:"type:int"`
+		DiarizeModel                   string  `gorm:"type:varchar(50);default:'pyannote'"`
+		SpeakerEmbeddings              bool    `gorm:"type:boolean;default:false"`
+		Temperature                    float64 `gorm:"type:real;default:0"`
+		BestOf                         int     `gorm:"type:int;default:5"`
+		BeamSize                       int     `gorm:"type:int;default:5"`
+		Patience                       float64 `gorm:"type:real;default:1.0"`
+		LengthPenalty                  float64 `gorm:"type:real;default:1.0"`
+		SuppressTokens                 *string `gorm:"type:text"`
+		SuppressNumerals               bool    `gorm:"type:boolean;default:false"`
+		InitialPrompt                  *string `gorm:"type:text"`
+		ConditionOnPreviousText        bool    `gorm:"type:boolean;default:false"`
+		Fp16                           bool    `gorm:"type:boolean;default:true"`
+		TemperatureIncrementOnFallback float64 `gorm:"type:real;default:0.2"`
+		CompressionRatioThreshold      float64 `gorm:"type:real;default:2.4"`
+		LogprobThreshold               float64 `gorm:"type:real;default:-1.0"`
+		NoSpeechThreshold              float64 `gorm:"type:real;default:0.6"`
+		MaxLineWidth                   *int    `gorm:"type:int"`
+		MaxLineCount                   *int    `gorm:"type:int"`
+		HighlightWords                 bool    `gorm:"type:boolean;default:false"`
+		SegmentResolution              string  `gorm:"type:varchar(20);default:'sentence'"`
+		HfToken                        *string `gorm:"type:text"`
+		PrintProgress                  bool    `gorm:"type:boolean;default:false"`
+		AttentionContextLeft           int     `gorm:"type:int;default:256"`
+		AttentionContextRight          int     `gorm:"type:int;default:256"`
+		IsMultiTrackEnabled            bool    `gorm:"type:boolean;default:false"`
+	}
+
+	type MultiTrackFile struct {
+		ID                 uint      `gorm:"primaryKey;autoIncrement"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;index"`
+		FileName           string    `gorm:"type:varchar(255);not null"`
+		FilePath           string    `gorm:"type:text;not null"`
+		TrackIndex         int       `gorm:"type:int;not null"`
+		Offset             float64   `gorm:"type:real;default:0"`
+		Gain               float64   `gorm:"type:real;default:1.0"`
+		Pan                float64   `gorm:"type:real;default:0.0"`
+		Mute               bool      `gorm:"type:boolean;default:false"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+		UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type TranscriptionJob struct {
+		ID                    string    `gorm:"primaryKey;type:varchar(36)"`
+		Title                 *string   `gorm:"type:text"`
+		Status                string    `gorm:"type:varchar(20);not null;default:'pending'"`
+		AudioPath             string    `gorm:"type:text;not null"`
+		Transcript            *string   `gorm:"type:text"`
+		Diarization           bool      `gorm:"type:boolean;default:false"`
+		Summary               *string   `gorm:"type:text"`
+		ErrorMessage          *string   `gorm:"type:text"`
+		IsMultiTrack          bool      `gorm:"type:boolean;default:false"`
+		AupFilePath           *string   `gorm:"type:text"`
+		MultiTrackFolder      *string   `gorm:"type:text"`
+		MergedAudioPath       *string   `gorm:"type:text"`
+		MergeStatus           string    `gorm:"type:varchar(20);default:'none'"`
+		MergeError            *string   `gorm:"type:text"`
+		IndividualTranscripts *string   `gorm:"type:text"`
+		CreatedAt             time.Time `gorm:"autoCreateTime"`
+		UpdatedAt             time.Time `gorm:"autoUpdateTime"`
+
+		Parameters WhisperXParams `gorm:"embedded"`
+
+		MultiTrackFiles []MultiTrackFile `gorm:"foreignKey:TranscriptionJobID"`
+	}
+
+	type TranscriptionJobExecution struct {
+		ID                 string    `gorm:"primaryKey;type:varchar(36)"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;index"`
+		StartedAt          time.Time `gorm:"not null"`
+		CompletedAt        *time.Time
+		ProcessingDuration *int64
+		MultiTrackTimings  *string `gorm:"type:text"`
+		MergeStartTime     *time.Time
+		MergeEndTime       *time.Time
+		MergeDuration      *int64
+
+		ActualParameters WhisperXParams `gorm:"embedded;embeddedPrefix:actual_"`
+
+		Status       string    `gorm:"type:varchar(20);not null"`
+		ErrorMessage *string   `gorm:"type:text"`
+		CreatedAt    time.Time `gorm:"autoCreateTime"`
+		UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+		TranscriptionJob TranscriptionJob `gorm:"foreignKey:TranscriptionJobID"`
+	}
+
+	type SpeakerMapping struct {
+		ID                 uint      `gorm:"primaryKey;autoIncrement"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;index"`
+		OriginalSpeaker    string    `gorm:"type:varchar(50);not null"`
+		CustomName         string    `gorm:"type:varchar(100);not null"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+		UpdatedAt          time.Time `gorm:"autoUpdateTime"`
+
+		TranscriptionJob TranscriptionJob `gorm:"foreignKey:TranscriptionJobID"`
+	}
+
+	type User struct {
+		ID                       uint      `gorm:"primaryKey"`
+		Username                 string    `gorm:"uniqueIndex;not null;type:varchar(50)"`
+		Password                 string    `gorm:"not null;type:varchar(255)"`
+		DefaultProfileID         *string   `gorm:"type:varchar(36)"`
+		AutoTranscriptionEnabled bool      `gorm:"not null;default:false"`
+		FastFinalizeEnabled      bool      `gorm:"not null;default:true"`
+		CreatedAt                time.Time `gorm:"autoCreateTime"`
+		UpdatedAt                time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type APIKey struct {
+		ID          uint    `gorm:"primaryKey"`
+		Key         string  `gorm:"uniqueIndex;not null;type:varchar(255)"`
+		Name        string  `gorm:"not null;type:varchar(100)"`
+		Description *string `gorm:"type:text"`
+		IsActive    bool    `gorm:"type:boolean;not null"`
+		LastUsed    *time.Time
+		CreatedAt   time.Time `gorm:"autoCreateTime"`
+		UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type TranscriptionProfile struct {
+		ID          string         `gorm:"primaryKey;type:varchar(36)"`
+		Name        string         `gorm:"type:varchar(255);not null"`
+		Description *string        `gorm:"type:text"`
+		IsDefault   bool           `gorm:"type:boolean;default:false"`
+		Parameters  WhisperXParams `gorm:"embedded"`
+		CreatedAt   time.Time      `gorm:"autoCreateTime"`
+		UpdatedAt   time.Time      `gorm:"autoUpdateTime"`
+	}
+
+	type LLMConfig struct {
+		ID        uint      `gorm:"primaryKey"`
+		Provider  string    `gorm:"not null;type:varchar(50)"`
+		BaseURL   *string   `gorm:"type:text"`
+		APIKey    *string   `gorm:"type:text"`
+		IsActive  bool      `gorm:"type:boolean;default:false"`
+		CreatedAt time.Time `gorm:"autoCreateTime"`
+		UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type ChatMessage struct {
+		ID            uint      `gorm:"primaryKey;autoIncrement"`
+		SessionID     string    `gorm:"type:varchar(36);not null;index"`
+		ChatSessionID string    `gorm:"type:varchar(36);not null;index"`
+		Role          string    `gorm:"type:varchar(20);not null"`
+		Content       string    `gorm:"type:text;not null"`
+		TokensUsed    *int      `gorm:"type:integer"`
+		CreatedAt     time.Time `gorm:"autoCreateTime"`
+	}
+
+	type ChatSession struct {
+		ID              string  `gorm:"primaryKey;type:varchar(36)"`
+		JobID           string  `gorm:"type:varchar(36);not null"`
+		TranscriptionID string  `gorm:"type:varchar(36);not null;index"`
+		Title           string  `gorm:"type:varchar(255);not null"`
+		Model           string  `gorm:"type:varchar(100);not null"`
+		Provider        string  `gorm:"type:varchar(50);not null;default:'openai'"`
+		SystemContext   *string `gorm:"type:text"`
+		MessageCount    int     `gorm:"type:integer;default:0"`
+		LastActivityAt  *time.Time
+		IsActive        bool      `gorm:"type:boolean;default:true"`
+		CreatedAt       time.Time `gorm:"autoCreateTime"`
+		UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+
+		Transcription TranscriptionJob `gorm:"foreignKey:TranscriptionID"`
+		Job           TranscriptionJob `gorm:"foreignKey:JobID"`
+		Messages      []ChatMessage    `gorm:"foreignKey:ChatSessionID"`
+	}
+
+	type SummaryTemplate struct {
+		ID          string    `gorm:"primaryKey;type:varchar(36)"`
+		Name        string    `gorm:"type:varchar(255);not null"`
+		Description *string   `gorm:"type:text"`
+		Model       string    `gorm:"type:varchar(255);not null;default:''"`
+		Prompt      string    `gorm:"type:text;not null"`
+		CreatedAt   time.Time `gorm:"autoCreateTime"`
+		UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type SummarySetting struct {
+		ID           uint      `gorm:"primaryKey"`
+		DefaultModel string    `gorm:"type:varchar(255);not null;default:''"`
+		UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type Summary struct {
+		ID              string    `gorm:"primaryKey;type:varchar(36)"`
+		TranscriptionID string    `gorm:"type:varchar(36);index;not null"`
+		TemplateID      *string   `gorm:"type:varchar(36)"`
+		Model           string    `gorm:"type:varchar(255);not null"`
+		Content         string    `gorm:"type:text;not null"`
+		CreatedAt       time.Time `gorm:"autoCreateTime"`
+		UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type Note struct {
+		ID              string    `gorm:"primaryKey;type:varchar(36)"`
+		TranscriptionID string    `gorm:"type:varchar(36);not null;index"`
+		StartWordIndex  int       `gorm:"type:int;not null"`
+		EndWordIndex    int       `gorm:"type:int;not null"`
+		StartTime       float64   `gorm:"type:real;not null"`
+		EndTime         float64   `gorm:"type:real;not null"`
+		Quote           string    `gorm:"type:text;not null"`
+		Content         string    `gorm:"type:text;not null"`
+		CreatedAt       time.Time `gorm:"autoCreateTime"`
+		UpdatedAt       time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type RefreshToken struct {
+		ID        uint      `gorm:"primaryKey"`
+		UserID    uint      `gorm:"not null;index"`
+		Hashed    string    `gorm:"not null;uniqueIndex;type:varchar(128)"`
+		ExpiresAt time.Time `gorm:"not null;index"`
+		Revoked   bool      `gorm:"not null;default:false;index"`
+		CreatedAt time.Time `gorm:"autoCreateTime"`
+		UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	}
+
+	type LiveTranscriptionChunk struct {
+		ID             uint      `gorm:"primaryKey;autoIncrement"`
+		SessionID      string    `gorm:"type:varchar(36);index;not null"`
+		Sequence       int       `gorm:"not null"`
+		StartOffset    float64   `gorm:"type:real"`
+		EndOffset      float64   `gorm:"type:real"`
+		AudioPath      string    `gorm:"type:text;not null"`
+		TranscriptJSON *string   `gorm:"type:text"`
+		CreatedAt      time.Time `gorm:"autoCreateTime"`
+	}
+
+	type LiveTranscriptionSession struct {
+		ID                    string         `gorm:"primaryKey;type:varchar(36)"`
+		Title                 *string        `gorm:"type:text"`
+		Status                string         `gorm:"type:varchar(20);not null;default:'active'"`
+		Parameters            WhisperXParams `gorm:"embedded"`
+		ChunkCount            int            `gorm:"not null;default:0"`
+		LastSequence          int            `gorm:"not null;default:0"`
+		AccumulatedTranscript *string        `gorm:"type:text"`
+		OutputAudioPath       *string        `gorm:"type:text"`
+		FinalJobID            *string        `gorm:"type:varchar(36)"`
+		CreatedAt             time.Time      `gorm:"autoCreateTime"`
+		UpdatedAt             time.Time      `gorm:"autoUpdateTime"`
+		CompletedAt           *time.Time
+
+		Chunks []LiveTranscriptionChunk `gorm:"foreignKey:SessionID"`
+	}
+
+	if err := tx.AutoMigrate(
+		&TranscriptionJob{},
+		&TranscriptionJobExecution{},
+		&SpeakerMapping{},
+		&MultiTrackFile{},
+		&User{},
+		&APIKey{},
+		&TranscriptionProfile{},
+		&LLMConfig{},
+		&ChatSession{},
+		&ChatMessage{},
+		&SummaryTemplate{},
+		&SummarySetting{},
+		&Summary{},
+		&Note{},
+		&RefreshToken{},
+		&LiveTranscriptionSession{},
+		&LiveTranscriptionChunk{},
+	); err != nil {
+		return fmt.Errorf("failed to auto migrate: %v", err)
+	}
+
+	// Add unique constraint for speaker mappings (transcription_job_id + original_speaker)
+	if err := tx.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_speaker_mappings_unique ON speaker_mappings(transcription_job_id, original_speaker)").Error; err != nil {
+		return fmt.Errorf("failed to create unique constraint for speaker mappings: %v", err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"synthezia/internal/models"
 
 	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
@@ -17,28 +18,25 @@ import (
 // DB is the global database instance
 var DB *gorm.DB
 
-// Initialize initializes the database connection with optimized settings
-func Initialize(cfg *config.Config) error {
+// Open connects to the database with optimized settings, leaving its schema as it is.
+// PostgreSQL is used when a database URL is configured, SQLite otherwise.
+func Open(cfg *config.Config) error {
 	var err error
+	var dialector gorm.Dialector
 
-	// Create database directory if it doesn't exist
-	if err := os.MkdirAll("data", 0755); err != nil {
-		return fmt.Errorf("failed to create data directory: %v", err)
-	}
+	if cfg.DatabaseURL != "" {
+		dialector = postgres.Open(cfg.DatabaseURL)
+	} else {
+		// Create database directory if it doesn't exist
+		if err := os.MkdirAll("data", 0755); err != nil {
+			return fmt.Errorf("failed to create data directory: %v", err)
+		}
 
-	// SQLite connection string with performance optimizations
-	dsn := fmt.Sprintf("%s?"+
-		"_pragma=foreign_keys(1)&"+          // Enable foreign keys
-		"_pragma=journal_mode(WAL)&"+        // Use WAL mode for better concurrency
-		"_pragma=synchronous(NORMAL)&"+      // Balance between safety and performance
-		"_pragma=cache_size(-64000)&"+       // 64MB cache size
-		"_pragma=temp_store(MEMORY)&"+       // Store temp tables in memory
-		"_pragma=mmap_size(268435456)&"+     // 256MB mmap size
-		"_timeout=30000",                     // 30 second timeout
-		cfg.DatabasePath)
+		dialector = sqlite.Open(sqliteDSN(cfg.DatabasePath))
+	}
 
 	// Open database connection with optimized config
-	DB, err = gorm.Open(sqlite.Open(dsn), &gorm.Config{
+	DB, err = gorm.Open(dialector, &gorm.Config{
 		Logger:          logger.Default.LogMode(logger.Warn), // Reduce logging overhead
 		CreateBatchSize: 100,                                 // Optimize batch inserts
 	})
@@ -58,32 +56,52 @@ func Initialize(cfg *config.Config) error {
 	sqlDB.SetConnMaxLifetime(30 * time.Minute) // Reset connections every 30 minutes
 	sqlDB.SetConnMaxIdleTime(5 * time.Minute)  // Close idle connections after 5 minutes
 
-	// Auto migrate the schema
-	if err := DB.AutoMigrate(
-		&models.TranscriptionJob{},
-		&models.TranscriptionJobExecution{},
-		&models.SpeakerMapping{},
-		&models.MultiTrackFile{},
-		&models.User{},
-		&models.APIKey{},
-		&models.TranscriptionProfile{},
-		&models.LLMConfig{},
-		&models.ChatSession{},
-		&models.ChatMessage{},
-		&models.SummaryTemplate{},
-		&models.SummarySetting{},
-		&models.Summary{},
-		&models.Note{},
-		&models.RefreshToken{},
-		&models.LiveTranscriptionSession{},
-		&models.LiveTranscriptionChunk{},
-	); err != nil {
-		return fmt.Errorf("failed to auto migrate: %v", err)
-	}
-
-	// Add unique constraint for speaker mappings (transcription_job_id + original_speaker)
-	if err := DB.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_speaker_mappings_unique ON speaker_mappings(transcription_job_id, original_speaker)").Error; err != nil {
-		return fmt.Errorf("failed to create unique constraint for speaker mappings: %v", err)
+	return nil
+}
+
+// sqliteDSN returns the SQLite connection string for path with performance optimizations
+func sqliteDSN(path string) string {
+	return fmt.Sprintf("%s?"+
+		"_pragma=foreign_keys(1)&"+      // Enable foreign keys
+		"_pragma=journal_mode(WAL)&"+    // Use WAL mode for better concurrency
+		"_pragma=synchronous(NORMAL)&"+  // Balance between safety and performance
+		"_pragma=cache_size(-64000)&"+   // 64MB cache size
+		"_pragma=temp_store(MEMORY)&"+   // Store temp tables in memory
+		"_pragma=mmap_size(268435456)&"+ // 256MB mmap size
+		"_timeout=30000",                // 30 second timeout
+		path)
+}
+
+// OpenSQLiteFile opens a SQLite database file as a connection of its own, leaving DB alone.
+// Backups use it for the database snapshot they carry.
+func OpenSQLiteFile(path string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open(sqliteDSN(path)), &gorm.Config{
+		Logger:          logger.Default.LogMode(logger.Warn),
+		CreateBatchSize: 100,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	return db, nil
+}
+
+// CloseConnection closes a connection opened with OpenSQLiteFile
+func CloseConnection(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// Initialize connects to the database, applies pending migrations and seeds the defaults
+func Initialize(cfg *config.Config) error {
+	if err := Open(cfg); err != nil {
+		return err
+	}
+
+	if _, err := Migrate(MigrateOptions{}); err != nil {
+		return err
 	}
 
 	// Create default transcription profile if none exists
@@ -99,6 +117,51 @@ func Initialize(cfg *config.Config) error {
 	return nil
 }
 
+// ownedModels lists the models that carry a user_id owner column. Transcript revisions are left
+// out because user_id 0 marks a revision written by the model, and refresh tokens always belong
+// to the user they were issued to.
+var ownedModels = []interface{}{
+	&models.TranscriptionJob{},
+	&models.TranscriptionProfile{},
+	&models.Note{},
+	&models.ChatSession{},
+	&models.Summary{},
+	&models.APIKey{},
+	&models.Webhook{},
+	&models.Folder{},
+	&models.Tag{},
+	&models.VoiceProfile{},
+	&models.ReplacementDictionary{},
+	&models.LiveTranscriptionSession{},
+}
+
+// FirstUserID returns the ID of the earliest registered user, or 0 if there are no users
+func FirstUserID() (uint, error) {
+	return firstUserID(DB)
+}
+
+func firstUserID(db *gorm.DB) (uint, error) {
+	// Migrations use this as well, so it goes by the table rather than the current User model
+	var ids []uint
+	if err := db.Table("users").Order("id ASC").Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, fmt.Errorf("failed to find first user: %v", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return ids[0], nil
+}
+
+// AssignUnownedRecords sets the owner of every row without one to userID
+func AssignUnownedRecords(userID uint) error {
+	for _, model := range ownedModels {
+		if err := DB.Model(model).Where("user_id = 0 OR user_id IS NULL").UpdateColumn("user_id", userID).Error; err != nil {
+			return fmt.Errorf("failed to assign owner for %T: %v", model, err)
+		}
+	}
+	return nil
+}
+
 // seedLLMConfig seeds the LLM configuration from environment variables if not present
 func seedLLMConfig(cfg *config.Config) error {
 	if cfg.LLMProvider == "" {
@@ -138,9 +201,14 @@ func ensureDefaultProfile() error {
 		return fmt.Errorf("failed to count profiles: %v", err)
 	}
 
-	// If no profiles exist, create a default one
+	// If no profiles exist, create a default one, owned by the first user once there is one
 	if count == 0 {
+		userID, err := FirstUserID()
+		if err != nil {
+			return err
+		}
 		defaultProfile := models.TranscriptionProfile{
+			UserID:      userID,
 			Name:        "Default Profile",
 			Description: stringPtr("Default transcription profile with balanced settings"),
 			IsDefault:   false, // Will be used as fallback automatically
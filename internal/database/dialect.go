@@ -0,0 +1,28 @@
+package database
+
+// Names of the supported database dialects
+const (
+	DialectSQLite   = "sqlite"
+	DialectPostgres = "postgres"
+)
+
+// Dialect returns the name of the dialect of the open database
+func Dialect() string {
+	if DB == nil {
+		return ""
+	}
+	return DB.Dialector.Name()
+}
+
+// IsPostgres reports whether the open database is PostgreSQL rather than SQLite
+func IsPostgres() bool {
+	return Dialect() == DialectPostgres
+}
+
+// ILike returns a condition matching column against a LIKE pattern regardless of case
+func ILike(column string) string {
+	if IsPostgres() {
+		return column + " ILIKE ?"
+	}
+	return column + " LIKE ? COLLATE NOCASE"
+}
@@ -0,0 +1,605 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"synthezia/internal/auth"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a numbered schema or data change. Pending migrations run in version order, each
+// in its own transaction, and are recorded in schema_migrations once applied.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(tx *gorm.DB) error
+}
+
+// SchemaMigration records an applied migration
+type SchemaMigration struct {
+	Version   int       `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(100);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// MigrateOptions controls how Migrate runs
+type MigrateOptions struct {
+	DryRun bool // Report the pending migrations without applying them
+}
+
+// migrations lists every migration in version order. Versions are never reused or reordered.
+//
+// Schema migrations declare the models they change as they were at the time, so every database
+// ends up with the same schema whichever version it was migrated from. A change to a model
+// needs a migration of its own; AutoMigrate only ever runs on these frozen declarations.
+var migrations = []Migration{
+	{Version: 1, Name: "baseline_schema", Up: migrateBaselineSchema},
+	{Version: 2, Name: "create_transcript_search", Up: createTranscriptSearch},
+	{Version: 3, Name: "add_record_owners", Up: addRecordOwners},
+	{Version: 4, Name: "assign_unowned_records", Up: assignUnownedRecords},
+	{Version: 5, Name: "create_webhooks", Up: createWebhooks},
+	{Version: 6, Name: "add_execution_recovery", Up: addExecutionRecovery},
+	{Version: 7, Name: "add_job_priority", Up: addJobPriority},
+	{Version: 8, Name: "add_job_retries", Up: addJobRetries},
+	{Version: 9, Name: "add_silence_removal", Up: addSilenceRemoval},
+	{Version: 10, Name: "add_audio_preprocessing", Up: addAudioPreprocessing},
+	{Version: 11, Name: "add_transcript_postprocessing", Up: addTranscriptPostprocessing},
+	{Version: 12, Name: "create_transcript_revisions", Up: createTranscriptRevisions},
+	{Version: 13, Name: "add_job_type", Up: addJobType},
+	{Version: 14, Name: "create_voice_profiles", Up: createVoiceProfiles},
+	{Version: 15, Name: "create_tags", Up: createTags},
+	{Version: 16, Name: "create_folders", Up: createFolders},
+	{Version: 17, Name: "add_chat_retrieval", Up: addChatRetrieval},
+	{Version: 18, Name: "add_user_roles", Up: addUserRoles},
+	{Version: 19, Name: "promote_first_user_to_admin", Up: ensureAdminUser},
+	{Version: 20, Name: "add_api_key_scopes", Up: addAPIKeyScopes},
+	{Version: 21, Name: "hash_plaintext_api_keys", Up: hashPlaintextAPIKeys},
+	{Version: 22, Name: "create_dropzone_files", Up: createDropzoneFiles},
+	{Version: 23, Name: "add_job_previous_status", Up: addJobPreviousStatus},
+	{Version: 24, Name: "add_live_session_owner", Up: addLiveSessionOwner},
+}
+
+// LatestSchemaVersion returns the schema version this build migrates databases to
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// SchemaVersion returns the version of the last migration applied to the database, or 0 if
+// it predates versioned migrations
+func SchemaVersion() (int, error) {
+	return schemaVersion(DB)
+}
+
+// DatabaseSchemaVersion is SchemaVersion for a connection other than DB
+func DatabaseSchemaVersion(db *gorm.DB) (int, error) {
+	return schemaVersion(db)
+}
+
+func schemaVersion(db *gorm.DB) (int, error) {
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+	var version int
+	if err := db.Model(&SchemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error; err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %v", err)
+	}
+	return version, nil
+}
+
+// PendingMigrations returns the migrations not yet applied to the database
+func PendingMigrations() ([]Migration, error) {
+	return pendingMigrations(DB)
+}
+
+func pendingMigrations(db *gorm.DB) ([]Migration, error) {
+	version, err := schemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if version > LatestSchemaVersion() {
+		return nil, fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, LatestSchemaVersion())
+	}
+
+	var pending []Migration
+	for _, migration := range migrations {
+		if migration.Version > version {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Migrate applies the pending migrations and returns them. In a dry run the database is left
+// untouched and the migrations that would be applied are returned.
+func Migrate(opts MigrateOptions) ([]Migration, error) {
+	return MigrateDatabase(DB, opts)
+}
+
+// MigrateDatabase is Migrate for a connection other than DB, such as a backup snapshot
+func MigrateDatabase(db *gorm.DB, opts MigrateOptions) ([]Migration, error) {
+	pending, err := pendingMigrations(db)
+	if err != nil || opts.DryRun || len(pending) == 0 {
+		return pending, err
+	}
+
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %v", err)
+	}
+
+	for i, migration := range pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return pending[:i], fmt.Errorf("migration %d (%s) failed: %v", migration.Version, migration.Name, err)
+		}
+	}
+	return pending, nil
+}
+
+// migrateModels creates the tables of new models and adds the missing columns and indexes of
+// changed ones
+func migrateModels(tx *gorm.DB, models ...interface{}) error {
+	if err := tx.AutoMigrate(models...); err != nil {
+		return fmt.Errorf("failed to auto migrate: %v", err)
+	}
+	return nil
+}
+
+// createTranscriptSearch adds the full-text index over transcript segments
+func createTranscriptSearch(tx *gorm.DB) error {
+	for _, statement := range searchIndexStatements(tx) {
+		if err := tx.Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create transcript search index: %v", err)
+		}
+	}
+	return nil
+}
+
+// searchIndexStatements creates the full-text index over transcript segments: an FTS5 table on
+// SQLite, and a table with an indexed tsvector column on PostgreSQL
+func searchIndexStatements(db *gorm.DB) []string {
+	if db.Dialector.Name() == DialectPostgres {
+		return []string{
+			"CREATE TABLE IF NOT EXISTS transcript_search (" +
+				"job_id varchar(36) NOT NULL, segment_index integer NOT NULL, start_time double precision, " +
+				"end_time double precision, speaker text, text text NOT NULL, " +
+				"document tsvector GENERATED ALWAYS AS (to_tsvector('simple', text)) STORED)",
+			"CREATE INDEX IF NOT EXISTS idx_transcript_search_document ON transcript_search USING GIN (document)",
+			"CREATE INDEX IF NOT EXISTS idx_transcript_search_job_id ON transcript_search (job_id)",
+		}
+	}
+	return []string{
+		"CREATE VIRTUAL TABLE IF NOT EXISTS transcript_search USING fts5(" +
+			"job_id UNINDEXED, segment_index UNINDEXED, start_time UNINDEXED, end_time UNINDEXED, speaker UNINDEXED, text, " +
+			"tokenize = 'unicode61 remove_diacritics 2')",
+	}
+}
+
+// addRecordOwners adds the owner of transcriptions and everything users create around them
+func addRecordOwners(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	type TranscriptionProfile struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	type Note struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	type ChatSession struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	type Summary struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	type APIKey struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	return migrateModels(tx, &TranscriptionJob{}, &TranscriptionProfile{}, &Note{}, &ChatSession{}, &Summary{}, &APIKey{})
+}
+
+// assignUnownedRecords gives the records created before per-user ownership to the first user,
+// if one exists. Otherwise the first user to register gets them.
+func assignUnownedRecords(tx *gorm.DB) error {
+	userID, err := firstUserID(tx)
+	if err != nil || userID == 0 {
+		return err
+	}
+	for _, table := range []string{"transcription_jobs", "transcription_profiles", "notes", "chat_sessions", "summaries", "api_keys"} {
+		if err := tx.Table(table).Where("user_id = 0 OR user_id IS NULL").UpdateColumn("user_id", userID).Error; err != nil {
+			return fmt.Errorf("failed to assign owner for %s: %v", table, err)
+		}
+	}
+	return nil
+}
+
+// createWebhooks adds webhooks and the deliveries sent to them
+func createWebhooks(tx *gorm.DB) error {
+	type Webhook struct {
+		ID          string    `gorm:"primaryKey;type:varchar(36)"`
+		UserID      uint      `gorm:"not null;default:0;index"`
+		URL         string    `gorm:"type:text;not null"`
+		Description *string   `gorm:"type:text"`
+		Secret      string    `gorm:"type:varchar(128);not null"`
+		Events      []string  `gorm:"type:text;serializer:json"`
+		IsActive    bool      `gorm:"type:boolean"`
+		CreatedAt   time.Time `gorm:"autoCreateTime"`
+		UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	}
+	type WebhookDelivery struct {
+		ID             string     `gorm:"primaryKey;type:varchar(36)"`
+		WebhookID      string     `gorm:"type:varchar(36);not null;index"`
+		Event          string     `gorm:"type:varchar(50);not null;index"`
+		Payload        string     `gorm:"type:text;not null"`
+		Status         string     `gorm:"type:varchar(20);not null;default:'pending';index"`
+		Attempts       int        `gorm:"type:int;not null;default:0"`
+		NextAttemptAt  *time.Time `gorm:"index"`
+		ResponseStatus *int
+		ResponseBody   *string `gorm:"type:text"`
+		Error          *string `gorm:"type:text"`
+		DeliveredAt    *time.Time
+		ReplayOf       *string   `gorm:"type:varchar(36)"`
+		CreatedAt      time.Time `gorm:"autoCreateTime"`
+		UpdatedAt      time.Time `gorm:"autoUpdateTime"`
+
+		Webhook Webhook `gorm:"foreignKey:WebhookID"`
+	}
+	return migrateModels(tx, &Webhook{}, &WebhookDelivery{})
+}
+
+// addExecutionRecovery adds what restart recovery needs to know about executions
+func addExecutionRecovery(tx *gorm.DB) error {
+	type TranscriptionJobExecution struct {
+		ProcessPID      *int    `gorm:"column:process_pid"`
+		RecoveryAction  *string `gorm:"type:varchar(20)"`
+		RecoveryDetails *string `gorm:"type:text"`
+	}
+	return migrateModels(tx, &TranscriptionJobExecution{})
+}
+
+// addJobPriority adds the priority the queue schedules jobs by
+func addJobPriority(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		Priority int `gorm:"not null;default:0;index"`
+	}
+	return migrateModels(tx, &TranscriptionJob{})
+}
+
+// addJobRetries adds the attempt counts and failure classes retries are decided by
+func addJobRetries(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		Attempts      int `gorm:"not null;default:0"`
+		NextAttemptAt *time.Time
+	}
+	type TranscriptionJobExecution struct {
+		Attempt      int     `gorm:"not null;default:0"`
+		FailureClass *string `gorm:"type:varchar(30)"`
+	}
+	return migrateModels(tx, &TranscriptionJob{}, &TranscriptionJobExecution{})
+}
+
+// addSilenceRemoval adds the silence removal parameters
+func addSilenceRemoval(tx *gorm.DB) error {
+	type parameters struct {
+		RemoveSilence      bool    `gorm:"type:boolean;default:false"`
+		SilenceThresholdDB float64 `gorm:"column:silence_threshold_db;type:real;default:-35"`
+		SilenceMinDuration float64 `gorm:"type:real;default:2"`
+	}
+	type TranscriptionJob struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionProfile struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type LiveTranscriptionSession struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionJobExecution struct {
+		ActualParameters parameters `gorm:"embedded;embeddedPrefix:actual_"`
+	}
+	return migrateModels(tx, &TranscriptionJob{}, &TranscriptionProfile{}, &LiveTranscriptionSession{}, &TranscriptionJobExecution{})
+}
+
+// addAudioPreprocessing adds the audio preprocessing parameters and what executions applied
+func addAudioPreprocessing(tx *gorm.DB) error {
+	type parameters struct {
+		HighpassFrequency int     `gorm:"type:int;default:0"`
+		Denoise           string  `gorm:"type:varchar(20);default:''"`
+		DenoiseModel      string  `gorm:"type:varchar(100)"`
+		Loudnorm          bool    `gorm:"type:boolean;default:false"`
+		LoudnormTarget    float64 `gorm:"type:real;default:-23"`
+	}
+	type TranscriptionJob struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionProfile struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type LiveTranscriptionSession struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionJobExecution struct {
+		ActualParameters     parameters `gorm:"embedded;embeddedPrefix:actual_"`
+		PreprocessingFilters *string    `gorm:"type:text"`
+		PreprocessedAudio    *string    `gorm:"type:text"`
+	}
+	return migrateModels(tx, &TranscriptionJob{}, &TranscriptionProfile{}, &LiveTranscriptionSession{}, &TranscriptionJobExecution{})
+}
+
+// addTranscriptPostprocessing adds replacement dictionaries and the postprocessing parameters
+func addTranscriptPostprocessing(tx *gorm.DB) error {
+	type ReplacementDictionary struct {
+		ID          string    `gorm:"primaryKey;type:varchar(36)"`
+		UserID      uint      `gorm:"not null;default:0;index"`
+		Name        string    `gorm:"type:varchar(255);not null"`
+		Description *string   `gorm:"type:text"`
+		Rules       string    `gorm:"type:text"`
+		CreatedAt   time.Time `gorm:"autoCreateTime"`
+		UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	}
+	type parameters struct {
+		ReplacementDictionaries string `gorm:"type:text"`
+		NormalizeNumbers        bool   `gorm:"type:boolean;default:false"`
+		MaskProfanity           bool   `gorm:"type:boolean;default:false"`
+	}
+	type TranscriptionJob struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionProfile struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type LiveTranscriptionSession struct {
+		Parameters parameters `gorm:"embedded"`
+	}
+	type TranscriptionJobExecution struct {
+		ActualParameters parameters `gorm:"embedded;embeddedPrefix:actual_"`
+	}
+	return migrateModels(tx, &ReplacementDictionary{}, &TranscriptionJob{}, &TranscriptionProfile{}, &LiveTranscriptionSession{}, &TranscriptionJobExecution{})
+}
+
+// createTranscriptRevisions adds the revision history of transcripts
+func createTranscriptRevisions(tx *gorm.DB) error {
+	type TranscriptRevision struct {
+		ID                 string    `gorm:"primaryKey;type:varchar(36)"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;uniqueIndex:idx_transcript_revision"`
+		Revision           int       `gorm:"not null;uniqueIndex:idx_transcript_revision"`
+		UserID             uint      `gorm:"not null;default:0"`
+		Author             string    `gorm:"type:varchar(50)"`
+		Operation          string    `gorm:"type:varchar(30);not null"`
+		Description        string    `gorm:"type:text"`
+		Diff               string    `gorm:"type:text"`
+		Transcript         string    `gorm:"type:text"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+	}
+	return migrateModels(tx, &TranscriptRevision{})
+}
+
+// addJobType adds the type of job the queue runs
+func addJobType(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		JobType string `gorm:"type:varchar(20);not null;default:'transcribe'"`
+	}
+	return migrateModels(tx, &TranscriptionJob{})
+}
+
+// createVoiceProfiles adds voice profiles, their samples and the speaker embeddings of jobs
+func createVoiceProfiles(tx *gorm.DB) error {
+	type VoiceSample struct {
+		ID                 uint      `gorm:"primaryKey;autoIncrement"`
+		VoiceProfileID     string    `gorm:"type:varchar(36);not null;index"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null"`
+		Speaker            string    `gorm:"type:varchar(50);not null"`
+		Model              string    `gorm:"type:varchar(100)"`
+		Vector             string    `gorm:"type:text"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+	}
+	type VoiceProfile struct {
+		ID        string        `gorm:"primaryKey;type:varchar(36)"`
+		UserID    uint          `gorm:"not null;default:0;index"`
+		Name      string        `gorm:"type:varchar(100);not null"`
+		CreatedAt time.Time     `gorm:"autoCreateTime"`
+		UpdatedAt time.Time     `gorm:"autoUpdateTime"`
+		Samples   []VoiceSample `gorm:"foreignKey:VoiceProfileID"`
+	}
+	type SpeakerEmbedding struct {
+		ID                 uint      `gorm:"primaryKey;autoIncrement"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;index"`
+		Speaker            string    `gorm:"type:varchar(50);not null"`
+		Model              string    `gorm:"type:varchar(100)"`
+		Vector             string    `gorm:"type:text"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+	}
+	type SpeakerMapping struct {
+		VoiceProfileID *string `gorm:"type:varchar(36);index"`
+	}
+	return migrateModels(tx, &VoiceProfile{}, &VoiceSample{}, &SpeakerEmbedding{}, &SpeakerMapping{})
+}
+
+// createTags adds tags and the tags of jobs
+func createTags(tx *gorm.DB) error {
+	type Tag struct {
+		ID        string    `gorm:"primaryKey;type:varchar(36)"`
+		UserID    uint      `gorm:"not null;default:0;uniqueIndex:idx_tag_user_name"`
+		Name      string    `gorm:"type:varchar(100);not null;uniqueIndex:idx_tag_user_name"`
+		CreatedAt time.Time `gorm:"autoCreateTime"`
+	}
+	type TranscriptionJob struct {
+		ID   string `gorm:"primaryKey;type:varchar(36)"`
+		Tags []Tag  `gorm:"many2many:transcription_job_tags"`
+	}
+	return migrateModels(tx, &Tag{}, &TranscriptionJob{})
+}
+
+// createFolders adds folders, the folders of jobs and chat sessions and what jobs learn from
+// transcription
+func createFolders(tx *gorm.DB) error {
+	type Folder struct {
+		ID        string    `gorm:"primaryKey;type:varchar(36)"`
+		UserID    uint      `gorm:"not null;default:0;index"`
+		ParentID  *string   `gorm:"type:varchar(36);index"`
+		Name      string    `gorm:"type:varchar(255);not null"`
+		CreatedAt time.Time `gorm:"autoCreateTime"`
+		UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	}
+	type TranscriptionJob struct {
+		FolderID         *string `gorm:"type:varchar(36);index"`
+		AudioDuration    *float64
+		DetectedLanguage *string `gorm:"type:varchar(10)"`
+	}
+	type ChatSession struct {
+		FolderID *string `gorm:"type:varchar(36);index"`
+	}
+	return migrateModels(tx, &Folder{}, &TranscriptionJob{}, &ChatSession{})
+}
+
+// addChatRetrieval adds chat sessions over several transcripts, citations and the transcript
+// embeddings retrieval uses
+func addChatRetrieval(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		ID string `gorm:"primaryKey;type:varchar(36)"`
+	}
+	type ChatSession struct {
+		ID             string             `gorm:"primaryKey;type:varchar(36)"`
+		RetrievalMode  string             `gorm:"type:varchar(20);default:'auto'"`
+		EmbeddingModel string             `gorm:"type:varchar(100)"`
+		Transcriptions []TranscriptionJob `gorm:"many2many:chat_session_transcriptions"`
+	}
+	type ChatMessage struct {
+		Citations string `gorm:"type:text"`
+	}
+	type TranscriptChunkEmbedding struct {
+		ID                 uint      `gorm:"primaryKey;autoIncrement"`
+		TranscriptionJobID string    `gorm:"type:varchar(36);not null;index"`
+		Model              string    `gorm:"type:varchar(100);not null"`
+		TranscriptHash     string    `gorm:"type:varchar(64);not null"`
+		ChunkIndex         int       `gorm:"not null"`
+		Vector             string    `gorm:"type:text"`
+		CreatedAt          time.Time `gorm:"autoCreateTime"`
+	}
+	return migrateModels(tx, &ChatSession{}, &ChatMessage{}, &TranscriptChunkEmbedding{})
+}
+
+// addUserRoles adds user roles, disabled accounts and invitations
+func addUserRoles(tx *gorm.DB) error {
+	type User struct {
+		Role     string `gorm:"type:varchar(20);not null;default:'editor'"`
+		Disabled bool   `gorm:"not null;default:false"`
+	}
+	type Invitation struct {
+		ID         uint      `gorm:"primaryKey"`
+		Hashed     string    `gorm:"not null;uniqueIndex;type:varchar(128)"`
+		Role       string    `gorm:"type:varchar(20);not null"`
+		CreatedBy  uint      `gorm:"not null;index"`
+		ExpiresAt  time.Time `gorm:"not null"`
+		AcceptedAt *time.Time
+		AcceptedBy *uint
+		CreatedAt  time.Time `gorm:"autoCreateTime"`
+	}
+	return migrateModels(tx, &User{}, &Invitation{})
+}
+
+// ensureAdminUser makes the first user an admin when no user has that role, as is the case
+// for databases created before user roles existed
+func ensureAdminUser(tx *gorm.DB) error {
+	var admins int64
+	if err := tx.Table("users").Where("role = ?", "admin").Count(&admins).Error; err != nil {
+		return fmt.Errorf("failed to count admins: %v", err)
+	}
+	if admins > 0 {
+		return nil
+	}
+	userID, err := firstUserID(tx)
+	if err != nil || userID == 0 {
+		return err
+	}
+	return tx.Table("users").Where("id = ?", userID).Update("role", "admin").Error
+}
+
+// addAPIKeyScopes adds what hashed keys, scopes, expiry and usage counts need
+func addAPIKeyScopes(tx *gorm.DB) error {
+	type APIKey struct {
+		KeyPrefix  string `gorm:"type:varchar(16)"`
+		Hashed     bool   `gorm:"not null;default:false"`
+		Scopes     string `gorm:"type:text"`
+		ExpiresAt  *time.Time
+		UsageCount int64 `gorm:"not null;default:0"`
+	}
+	return migrateModels(tx, &APIKey{})
+}
+
+// hashPlaintextAPIKeys hashes the API keys stored before keys were hashed, keeping their prefix
+// so that users can still tell them apart, and gives them the scopes new keys get by default
+func hashPlaintextAPIKeys(tx *gorm.DB) error {
+	// Read, write and chat, stored the way the scopes column serializes them
+	const defaultScopes = `["transcripts:read","transcribe:write","chat"]`
+
+	var keys []struct {
+		ID  uint
+		Key string
+	}
+	if err := tx.Table("api_keys").Select("id", "key").Where("hashed = ?", false).Find(&keys).Error; err != nil {
+		return fmt.Errorf("failed to load API keys: %v", err)
+	}
+	for _, key := range keys {
+		if err := tx.Table("api_keys").Where("id = ?", key.ID).Updates(map[string]interface{}{
+			"key":        auth.HashAPIKey(key.Key),
+			"key_prefix": auth.APIKeyPrefix(key.Key),
+			"hashed":     true,
+			"scopes":     defaultScopes,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to hash API key %d: %v", key.ID, err)
+		}
+	}
+	return nil
+}
+
+// createDropzoneFiles adds the table recording the files the dropzone left in place
+func createDropzoneFiles(tx *gorm.DB) error {
+	type DropzoneFile struct {
+		ID         uint      `gorm:"primaryKey"`
+		Path       string    `gorm:"type:varchar(1024);not null;uniqueIndex"`
+		Size       int64     `gorm:"not null"`
+		ModifiedAt int64     `gorm:"not null"`
+		JobID      string    `gorm:"type:varchar(36)"`
+		CreatedAt  time.Time `gorm:"autoCreateTime"`
+		UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+	}
+	return migrateModels(tx, &DropzoneFile{})
+}
+
+// addJobPreviousStatus adds the status a job returns to when its re-diarization fails
+func addJobPreviousStatus(tx *gorm.DB) error {
+	type TranscriptionJob struct {
+		PreviousStatus *string `gorm:"type:varchar(20)"`
+	}
+	return migrateModels(tx, &TranscriptionJob{})
+}
+
+// addLiveSessionOwner gives live sessions an owner. Sessions started before go to the first user,
+// as the other records did when ownership was added.
+func addLiveSessionOwner(tx *gorm.DB) error {
+	type LiveTranscriptionSession struct {
+		UserID uint `gorm:"not null;default:0;index"`
+	}
+	if err := migrateModels(tx, &LiveTranscriptionSession{}); err != nil {
+		return err
+	}
+	userID, err := firstUserID(tx)
+	if err != nil || userID == 0 {
+		return err
+	}
+	if err := tx.Table("live_transcription_sessions").Where("user_id = 0 OR user_id IS NULL").UpdateColumn("user_id", userID).Error; err != nil {
+		return fmt.Errorf("failed to assign owner for live_transcription_sessions: %v", err)
+	}
+	return nil
+}
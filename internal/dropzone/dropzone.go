@@ -15,6 +15,7 @@ import (
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
 )
 
 // TaskQueue interface for enqueueing transcription jobs
@@ -22,6 +23,9 @@ type TaskQueue interface {
 	EnqueueJob(jobID string) error
 }
 
+// processedDirName is the folder inside the dropzone that processed files are moved to
+const processedDirName = "processed"
+
 // Service manages the dropzone file monitoring
 type Service struct {
 	config       *config.Config
@@ -32,13 +36,22 @@ type Service struct {
 
 // NewService creates a new dropzone service
 func NewService(cfg *config.Config, taskQueue TaskQueue) *Service {
+	dropzonePath := cfg.DropzonePath
+	if dropzonePath == "" {
+		dropzonePath = filepath.Join("data", "dropzone")
+	}
 	return &Service{
 		config:       cfg,
 		taskQueue:    taskQueue,
-		dropzonePath: filepath.Join("data", "dropzone"),
+		dropzonePath: dropzonePath,
 	}
 }
 
+// Path returns the directory being monitored
+func (s *Service) Path() string {
+	return s.dropzonePath
+}
+
 // Start initializes the dropzone directory and starts file monitoring
 func (s *Service) Start() error {
 	log.Printf("Starting dropzone service...")
@@ -57,13 +70,14 @@ func (s *Service) Start() error {
 	}
 	s.watcher = watcher
 
-	// Add dropzone directory and all subdirectories to watcher recursively
+	// Add dropzone directory (and subdirectories, if recursive) to watcher
 	if err := s.addDirectoryRecursively(s.dropzonePath); err != nil {
 		s.watcher.Close()
 		return fmt.Errorf("failed to add directories to watcher: %v", err)
 	}
 
-	// Process existing files recursively on startup
+	// Process existing files on startup. Files left in place by a previous run are
+	// recognized by their records and skipped.
 	if err := s.processExistingFiles(); err != nil {
 		log.Printf("Warning: failed to process some existing files: %v", err)
 	}
@@ -71,7 +85,8 @@ func (s *Service) Start() error {
 	// Start monitoring in a goroutine
 	go s.watchFiles()
 
-	log.Printf("Dropzone service started, monitoring recursively: %s", s.dropzonePath)
+	log.Printf("Dropzone service started, monitoring: %s (recursive: %t, after processing: %s)",
+		s.dropzonePath, s.config.DropzoneRecursive, s.afterProcessAction())
 	return nil
 }
 
@@ -84,7 +99,8 @@ func (s *Service) Stop() error {
 	return nil
 }
 
-// addDirectoryRecursively adds a directory and all its subdirectories to the watcher
+// addDirectoryRecursively adds a directory and all its subdirectories to the watcher.
+// Subdirectories are skipped when recursive watching is disabled.
 func (s *Service) addDirectoryRecursively(root string) error {
 	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
@@ -94,6 +110,9 @@ func (s *Service) addDirectoryRecursively(root string) error {
 
 		// Only add directories to the watcher
 		if info.IsDir() {
+			if skip := s.skipDirectory(path); skip != nil {
+				return skip
+			}
 			if err := s.watcher.Add(path); err != nil {
 				log.Printf("Warning: failed to watch directory %s: %v", path, err)
 				return nil // Continue despite individual directory failures
@@ -113,13 +132,15 @@ func (s *Service) processExistingFiles() error {
 			return nil // Continue walking despite errors
 		}
 
+		if info.IsDir() {
+			return s.skipDirectory(path)
+		}
+
 		// Only process files, not directories
-		if !info.IsDir() {
-			filename := filepath.Base(path)
-			if s.isAudioFile(filename) {
-				log.Printf("Processing existing audio file: %s", path)
-				s.processFile(path)
-			}
+		filename := filepath.Base(path)
+		if s.isAudioFile(filename) {
+			log.Printf("Processing existing audio file: %s", path)
+			s.processFile(path)
 		}
 
 		return nil
@@ -138,7 +159,13 @@ func (s *Service) watchFiles() {
 			// Handle creation events for both files and directories
 			if event.Op&fsnotify.Create == fsnotify.Create {
 				// Check if the created item is a directory
+				if s.isProcessedPath(event.Name) {
+					continue
+				}
 				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if !s.config.DropzoneRecursive {
+						continue
+					}
 					log.Printf("Detected new directory in dropzone: %s", event.Name)
 					// Add the new directory to the watcher recursively
 					if err := s.addDirectoryRecursively(event.Name); err != nil {
@@ -159,6 +186,35 @@ func (s *Service) watchFiles() {
 	}
 }
 
+// skipDirectory returns filepath.SkipDir for directories that must not be watched or scanned:
+// the processed folder, and every subdirectory when recursive watching is disabled
+func (s *Service) skipDirectory(path string) error {
+	if filepath.Clean(path) == filepath.Clean(s.dropzonePath) {
+		return nil
+	}
+	if !s.config.DropzoneRecursive || s.isProcessedPath(path) {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// isProcessedPath reports whether path is inside the processed folder (only used by the move action)
+func (s *Service) isProcessedPath(path string) bool {
+	if s.afterProcessAction() != config.DropzoneActionMove {
+		return false
+	}
+	rel, err := filepath.Rel(filepath.Join(s.dropzonePath, processedDirName), path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// afterProcessAction returns what to do with a file once it has been uploaded
+func (s *Service) afterProcessAction() string {
+	if s.config.DropzoneAfterProcess == "" {
+		return config.DropzoneActionDelete
+	}
+	return s.config.DropzoneAfterProcess
+}
+
 // isAudioFile checks if the file is a valid audio file based on extension
 func (s *Service) isAudioFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -200,28 +256,122 @@ func (s *Service) processFile(filePath string) {
 		return
 	}
 
+	// Files left in place stay in the dropzone, so skip the ones uploaded before
+	leave := s.afterProcessAction() == config.DropzoneActionLeave
+	if leave && s.alreadyIngested(filePath, fileInfo) {
+		log.Printf("Skipping already processed file: %s", filePath)
+		return
+	}
+
 	log.Printf("Processing audio file: %s", filename)
 
 	// Upload the file using the same logic as the API handler
-	if err := s.uploadFile(filePath, filename); err != nil {
+	jobID, err := s.uploadFile(filePath, filename)
+	if err != nil {
 		log.Printf("Failed to upload file %s: %v", filename, err)
 		return
 	}
 
-	// Delete the original file from dropzone after successful upload
-	if err := os.Remove(filePath); err != nil {
-		log.Printf("Warning: Failed to delete file from dropzone %s: %v", filePath, err)
-	} else {
-		log.Printf("Successfully processed and removed file: %s", filename)
+	if leave {
+		s.recordIngested(filePath, fileInfo, jobID)
+	}
+	s.finishFile(filePath)
+}
+
+// recordKey returns the path a dropzone file is recorded under, relative to the dropzone
+func (s *Service) recordKey(filePath string) string {
+	rel, err := filepath.Rel(s.dropzonePath, filePath)
+	if err != nil {
+		rel = filePath
 	}
+	return filepath.ToSlash(rel)
 }
 
-// uploadFile uploads the file using the existing pipeline logic
-func (s *Service) uploadFile(sourcePath, originalFilename string) error {
+// alreadyIngested reports whether the file was uploaded before and hasn't changed since
+func (s *Service) alreadyIngested(filePath string, info os.FileInfo) bool {
+	var count int64
+	err := database.DB.Model(&models.DropzoneFile{}).
+		Where("path = ? AND size = ? AND modified_at = ?", s.recordKey(filePath), info.Size(), info.ModTime().UnixNano()).
+		Count(&count).Error
+	if err != nil {
+		log.Printf("Error checking dropzone record for %s: %v", filePath, err)
+		return false
+	}
+	return count > 0
+}
+
+// recordIngested records an uploaded file left in place, replacing the record of an earlier
+// version of the file
+func (s *Service) recordIngested(filePath string, info os.FileInfo, jobID string) {
+	record := models.DropzoneFile{
+		Path:       s.recordKey(filePath),
+		Size:       info.Size(),
+		ModifiedAt: info.ModTime().UnixNano(),
+		JobID:      jobID,
+	}
+	err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size", "modified_at", "job_id", "updated_at"}),
+	}).Create(&record).Error
+	if err != nil {
+		log.Printf("Warning: Failed to record processed file %s: %v", filePath, err)
+	}
+}
+
+// finishFile applies the configured post-processing action to an uploaded file
+func (s *Service) finishFile(filePath string) {
+	switch s.afterProcessAction() {
+	case config.DropzoneActionLeave:
+		log.Printf("Successfully processed file, leaving it in place: %s", filePath)
+
+	case config.DropzoneActionMove:
+		// Keep the subfolder structure below processed/
+		rel, err := filepath.Rel(s.dropzonePath, filePath)
+		if err != nil {
+			rel = filepath.Base(filePath)
+		}
+		dest := uniquePath(filepath.Join(s.dropzonePath, processedDirName, rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			log.Printf("Warning: Failed to create processed directory for %s: %v", filePath, err)
+			return
+		}
+		if err := os.Rename(filePath, dest); err != nil {
+			log.Printf("Warning: Failed to move file to processed directory %s: %v", filePath, err)
+		} else {
+			log.Printf("Successfully processed and moved file: %s -> %s", filePath, dest)
+		}
+
+	default:
+		// Delete the original file from dropzone after successful upload
+		if err := os.Remove(filePath); err != nil {
+			log.Printf("Warning: Failed to delete file from dropzone %s: %v", filePath, err)
+		} else {
+			log.Printf("Successfully processed and removed file: %s", filepath.Base(filePath))
+		}
+	}
+}
+
+// uniquePath appends a counter to the file name until it doesn't collide with an existing file
+func uniquePath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s_%d%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// uploadFile uploads the file using the existing pipeline logic and returns the job's ID
+func (s *Service) uploadFile(sourcePath, originalFilename string) (string, error) {
 	// Create upload directory
 	uploadDir := s.config.UploadDir
 	if err := os.MkdirAll(uploadDir, 0755); err != nil {
-		return fmt.Errorf("failed to create upload directory: %v", err)
+		return "", fmt.Errorf("failed to create upload directory: %v", err)
 	}
 
 	// Generate unique filename
@@ -232,21 +382,35 @@ func (s *Service) uploadFile(sourcePath, originalFilename string) error {
 
 	// Copy file from dropzone to upload directory
 	if err := s.copyFile(sourcePath, destPath); err != nil {
-		return fmt.Errorf("failed to copy file: %v", err)
+		return "", fmt.Errorf("failed to copy file: %v", err)
+	}
+
+	// Files dropped on disk have no requesting user; they belong to the first user
+	ownerID, err := database.FirstUserID()
+	if err != nil {
+		log.Printf("Failed to resolve dropzone file owner: %v", err)
 	}
 
 	// Create job record with "uploaded" status
 	job := models.TranscriptionJob{
 		ID:        jobID,
+		UserID:    ownerID,
 		AudioPath: destPath,
 		Status:    models.StatusUploaded,
 		Title:     &originalFilename, // Use original filename as title
 	}
 
+	// Use the profile mapped to the file's subfolder, or the owner's default profile
+	if profile := s.resolveProfile(sourcePath, ownerID); profile != nil {
+		log.Printf("Using profile %q for %s", profile.Name, originalFilename)
+		job.Parameters = profile.Parameters
+		job.Diarization = profile.Parameters.Diarize
+	}
+
 	// Save to database
 	if err := database.DB.Create(&job).Error; err != nil {
 		os.Remove(destPath) // Clean up file on database error
-		return fmt.Errorf("failed to create job record: %v", err)
+		return "", fmt.Errorf("failed to create job record: %v", err)
 	}
 
 	// Check if auto-transcription is enabled
@@ -272,6 +436,48 @@ func (s *Service) uploadFile(sourcePath, originalFilename string) error {
 	}
 
 	log.Printf("Successfully uploaded file %s as job %s", originalFilename, jobID)
+	return jobID, nil
+}
+
+// profileNameForPath returns the profile mapped to the file's folder. The deepest mapped
+// folder wins, so "interviews/remote" can override "interviews".
+func (s *Service) profileNameForPath(filePath string) string {
+	if len(s.config.DropzoneProfiles) == 0 {
+		return ""
+	}
+	rel, err := filepath.Rel(s.dropzonePath, filepath.Dir(filePath))
+	if err != nil {
+		return ""
+	}
+	for dir := filepath.ToSlash(rel); dir != "." && dir != "" && dir != "/"; dir = filepath.ToSlash(filepath.Dir(dir)) {
+		if profile, ok := s.config.DropzoneProfiles[dir]; ok {
+			return profile
+		}
+	}
+	return ""
+}
+
+// resolveProfile finds the transcription profile for a dropped file, owned by ownerID
+func (s *Service) resolveProfile(filePath string, ownerID uint) *models.TranscriptionProfile {
+	var profile models.TranscriptionProfile
+
+	if name := s.profileNameForPath(filePath); name != "" {
+		err := database.DB.Where("user_id = ? AND (id = ? OR name = ?)", ownerID, name, name).First(&profile).Error
+		if err == nil {
+			return &profile
+		}
+		log.Printf("Warning: dropzone profile %q for %s not found: %v", name, filePath, err)
+	}
+
+	var owner models.User
+	if err := database.DB.First(&owner, ownerID).Error; err == nil && owner.DefaultProfileID != nil {
+		if err := database.DB.Where("id = ? AND user_id = ?", *owner.DefaultProfileID, ownerID).First(&profile).Error; err == nil {
+			return &profile
+		}
+	}
+	if err := database.DB.Where("user_id = ? AND is_default = ?", ownerID, true).First(&profile).Error; err == nil {
+		return &profile
+	}
 	return nil
 }
 
@@ -0,0 +1,228 @@
+package editor
+
+import (
+	"strings"
+	"unicode"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+// maxAlignWords bounds the changed stretch aligned word by word; longer stretches are treated
+// as entirely replaced so alignment stays cheap on long transcripts
+const maxAlignWords = 2000
+
+// WordMap maps each word index of the previous transcript to its index after an edit, or -1
+// if the word was removed
+type WordMap []int
+
+// RemapRange moves an inclusive word range onto the edited transcript. Removed words at the
+// edges are skipped; if the whole range was removed it collapses onto the word that now
+// sits where it was. ok is false when the edited transcript has no words left.
+func (m WordMap) RemapRange(start, end, wordCount int) (newStart, newEnd int, ok bool) {
+	if wordCount == 0 {
+		return 0, 0, false
+	}
+	if start < 0 {
+		start = 0
+	}
+	if end >= len(m) {
+		end = len(m) - 1
+	}
+
+	newStart, newEnd = -1, -1
+	for i := start; i <= end; i++ {
+		if m[i] >= 0 {
+			if newStart < 0 {
+				newStart = m[i]
+			}
+			newEnd = m[i]
+		}
+	}
+	if newStart < 0 {
+		// Everything was removed: anchor on the first surviving word after the range
+		position := wordCount - 1
+		for i := end + 1; i < len(m); i++ {
+			if m[i] >= 0 {
+				position = m[i]
+				break
+			}
+		}
+		newStart, newEnd = position, position
+	}
+	return newStart, newEnd, true
+}
+
+// normalizeWord reduces a word to lower-case letters and digits so punctuation and case fixes
+// still count as the same word
+func normalizeWord(word string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, word)
+}
+
+// commonEnds returns how many leading and trailing items two sequences share
+func commonEnds(n, m int, equal func(i, j int) bool) (prefix, suffix int) {
+	for prefix < n && prefix < m && equal(prefix, prefix) {
+		prefix++
+	}
+	for suffix < n-prefix && suffix < m-prefix && equal(n-1-suffix, m-1-suffix) {
+		suffix++
+	}
+	return prefix, suffix
+}
+
+// lcsPairs returns the index pairs of a longest common subsequence of a and b
+func lcsPairs(a, b []string) [][2]int {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 || n > maxAlignWords || m > maxAlignWords {
+		return nil
+	}
+	// lengths[i][j] is the LCS length of a[i:] and b[j:]
+	lengths := make([][]int32, n+1)
+	for i := range lengths {
+		lengths[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else if lengths[i+1][j] >= lengths[i][j+1] {
+				lengths[i][j] = lengths[i+1][j]
+			} else {
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
+
+// AlignWords works out where each word of before ended up in after
+func AlignWords(before, after []interfaces.TranscriptWord) WordMap {
+	m := make(WordMap, len(before))
+	for i := range m {
+		m[i] = -1
+	}
+
+	prefix, suffix := commonEnds(len(before), len(after), func(i, j int) bool {
+		return before[i].Word == after[j].Word && before[i].Start == after[j].Start
+	})
+	for i := 0; i < prefix; i++ {
+		m[i] = i
+	}
+	for k := 0; k < suffix; k++ {
+		m[len(before)-1-k] = len(after) - 1 - k
+	}
+
+	a := make([]string, 0, len(before)-prefix-suffix)
+	for _, w := range before[prefix : len(before)-suffix] {
+		a = append(a, normalizeWord(w.Word))
+	}
+	b := make([]string, 0, len(after)-prefix-suffix)
+	for _, w := range after[prefix : len(after)-suffix] {
+		b = append(b, normalizeWord(w.Word))
+	}
+	for _, pair := range lcsPairs(a, b) {
+		m[prefix+pair[0]] = prefix + pair[1]
+	}
+	return m
+}
+
+// SegmentHunk is the run of segments that changed between two versions
+type SegmentHunk struct {
+	Index  int                            `json:"index"` // position of the first changed segment
+	Before []interfaces.TranscriptSegment `json:"before"`
+	After  []interfaces.TranscriptSegment `json:"after"`
+}
+
+// WordHunk is the run of words that changed between two versions
+type WordHunk struct {
+	Index  int                         `json:"index"` // word index of the first changed word
+	Before []interfaces.TranscriptWord `json:"before"`
+	After  []interfaces.TranscriptWord `json:"after"`
+}
+
+// Diff records what a revision changed. Edits are local, so a single hunk of segments and
+// one of words is enough to describe each.
+type Diff struct {
+	Segments *SegmentHunk `json:"segments,omitempty"`
+	Words    *WordHunk    `json:"words,omitempty"`
+}
+
+// Empty reports whether the diff records no change
+func (d Diff) Empty() bool {
+	return d.Segments == nil && d.Words == nil
+}
+
+// DiffTranscripts compares two versions of a transcript
+func DiffTranscripts(before, after *interfaces.TranscriptResult) Diff {
+	var diff Diff
+
+	prefix, suffix := commonEnds(len(before.Segments), len(after.Segments), func(i, j int) bool {
+		return segmentsEqual(before.Segments[i], after.Segments[j])
+	})
+	if prefix+suffix < len(before.Segments) || prefix+suffix < len(after.Segments) {
+		diff.Segments = &SegmentHunk{
+			Index:  prefix,
+			Before: before.Segments[prefix : len(before.Segments)-suffix],
+			After:  after.Segments[prefix : len(after.Segments)-suffix],
+		}
+	}
+
+	prefix, suffix = commonEnds(len(before.WordSegments), len(after.WordSegments), func(i, j int) bool {
+		return wordsEqual(before.WordSegments[i], after.WordSegments[j])
+	})
+	if prefix+suffix < len(before.WordSegments) || prefix+suffix < len(after.WordSegments) {
+		diff.Words = &WordHunk{
+			Index:  prefix,
+			Before: before.WordSegments[prefix : len(before.WordSegments)-suffix],
+			After:  after.WordSegments[prefix : len(after.WordSegments)-suffix],
+		}
+	}
+	return diff
+}
+
+func segmentsEqual(a, b interfaces.TranscriptSegment) bool {
+	return a.Start == b.Start && a.End == b.End && a.Text == b.Text && stringPtrEqual(a.Speaker, b.Speaker) &&
+		stringsEqual(a.OverlapSpeakers, b.OverlapSpeakers)
+}
+
+func wordsEqual(a, b interfaces.TranscriptWord) bool {
+	return a.Start == b.Start && a.End == b.End && a.Word == b.Word && stringPtrEqual(a.Speaker, b.Speaker) &&
+		stringsEqual(a.OverlapSpeakers, b.OverlapSpeakers)
+}
+
+func stringPtrEqual(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
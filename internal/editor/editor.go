@@ -0,0 +1,299 @@
+package editor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+// ErrInvalidEdit is returned when an edit does not fit the transcript, such as an
+// out-of-range segment index
+var ErrInvalidEdit = errors.New("invalid edit")
+
+// timeTolerance absorbs rounding when matching words to the segment that contains them
+const timeTolerance = 0.01
+
+func invalidEdit(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidEdit, fmt.Sprintf(format, args...))
+}
+
+// Clone copies a transcript so it can be edited without touching the original
+func Clone(t *interfaces.TranscriptResult) *interfaces.TranscriptResult {
+	c := *t
+	c.Segments = append([]interfaces.TranscriptSegment(nil), t.Segments...)
+	c.WordSegments = append([]interfaces.TranscriptWord(nil), t.WordSegments...)
+	if t.Metadata != nil {
+		c.Metadata = make(map[string]string, len(t.Metadata))
+		for k, v := range t.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	return &c
+}
+
+// SegmentWords returns the range words[lo:hi] of words that fall inside a segment
+func SegmentWords(t *interfaces.TranscriptResult, segIdx int) (lo, hi int) {
+	seg := t.Segments[segIdx]
+	words := t.WordSegments
+	for lo < len(words) && words[lo].Start < seg.Start-timeTolerance {
+		lo++
+	}
+	hi = lo
+	for hi < len(words) && words[hi].End <= seg.End+timeTolerance {
+		hi++
+	}
+	return lo, hi
+}
+
+func checkSegment(t *interfaces.TranscriptResult, segIdx int) error {
+	if segIdx < 0 || segIdx >= len(t.Segments) {
+		return invalidEdit("segment %d does not exist", segIdx)
+	}
+	return nil
+}
+
+// withLeadingSpace keeps the leading space Whisper puts before segment text
+func withLeadingSpace(original, text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(original, " ") {
+		return " " + text
+	}
+	return text
+}
+
+// rebuildText regenerates the full transcript text from its segments
+func rebuildText(t *interfaces.TranscriptResult) {
+	parts := make([]string, 0, len(t.Segments))
+	for _, seg := range t.Segments {
+		if text := strings.TrimSpace(seg.Text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	t.Text = strings.Join(parts, " ")
+}
+
+func roundTime(seconds float64) float64 {
+	return math.Round(seconds*1000) / 1000
+}
+
+// EditSegmentText replaces the text of a segment. Words that survive the edit keep their
+// timing; new words are spread evenly over the gap they were inserted into.
+func EditSegmentText(t *interfaces.TranscriptResult, segIdx int, text string) (*interfaces.TranscriptResult, error) {
+	if err := checkSegment(t, segIdx); err != nil {
+		return nil, err
+	}
+	tokens := strings.Fields(text)
+	if len(tokens) == 0 {
+		return nil, invalidEdit("segment text cannot be empty")
+	}
+
+	result := Clone(t)
+	seg := &result.Segments[segIdx]
+	seg.Text = withLeadingSpace(seg.Text, strings.Join(tokens, " "))
+	rebuildText(result)
+	if len(t.WordSegments) == 0 {
+		return result, nil
+	}
+
+	lo, hi := SegmentWords(t, segIdx)
+	old := t.WordSegments[lo:hi]
+	a := make([]string, len(old))
+	for i, w := range old {
+		a[i] = normalizeWord(w.Word)
+	}
+	b := make([]string, len(tokens))
+	for i, token := range tokens {
+		b[i] = normalizeWord(token)
+	}
+	matched := make([]int, len(tokens)) // index into old, or -1 for inserted words
+	for i := range matched {
+		matched[i] = -1
+	}
+	for _, pair := range lcsPairs(a, b) {
+		matched[pair[1]] = pair[0]
+	}
+
+	words := make([]interfaces.TranscriptWord, len(tokens))
+	for j := 0; j < len(tokens); {
+		if matched[j] >= 0 {
+			words[j] = old[matched[j]]
+			words[j].Word = tokens[j]
+			j++
+			continue
+		}
+
+		// Spread a run of inserted words over the time between its neighbours
+		end := j
+		for end < len(tokens) && matched[end] < 0 {
+			end++
+		}
+		gapStart, gapEnd := seg.Start, seg.End
+		if j > 0 {
+			gapStart = words[j-1].End
+		}
+		if end < len(tokens) {
+			gapEnd = old[matched[end]].Start
+		}
+		gapEnd = math.Max(gapEnd, gapStart)
+		step := (gapEnd - gapStart) / float64(end-j)
+		for k := j; k < end; k++ {
+			start := gapStart + step*float64(k-j)
+			words[k] = interfaces.TranscriptWord{
+				Start:   roundTime(start),
+				End:     roundTime(start + step),
+				Word:    tokens[k],
+				Speaker: seg.Speaker,
+			}
+		}
+		j = end
+	}
+
+	result.WordSegments = append(append(append([]interfaces.TranscriptWord(nil), t.WordSegments[:lo]...), words...), t.WordSegments[hi:]...)
+	return result, nil
+}
+
+// splitSegmentAt splits a segment in place after its first k text tokens
+func splitSegmentAt(t *interfaces.TranscriptResult, segIdx, k int) {
+	seg := t.Segments[segIdx]
+	tokens := strings.Fields(seg.Text)
+	first := strings.Join(tokens[:k], " ")
+	second := strings.Join(tokens[k:], " ")
+
+	// Cut between the words when they line up with the text, otherwise by text length
+	lo, hi := SegmentWords(t, segIdx)
+	firstEnd, secondStart := 0.0, 0.0
+	if hi-lo == len(tokens) {
+		firstEnd = t.WordSegments[lo+k-1].End
+		secondStart = t.WordSegments[lo+k].Start
+	} else {
+		share := float64(len(first)) / float64(len(first)+len(second))
+		firstEnd = roundTime(seg.Start + (seg.End-seg.Start)*share)
+		secondStart = firstEnd
+	}
+
+	head, tail := seg, seg
+	head.End, head.Text = firstEnd, withLeadingSpace(seg.Text, first)
+	tail.Start, tail.Text = secondStart, withLeadingSpace(seg.Text, second)
+
+	segments := make([]interfaces.TranscriptSegment, 0, len(t.Segments)+1)
+	segments = append(segments, t.Segments[:segIdx]...)
+	segments = append(segments, head, tail)
+	t.Segments = append(segments, t.Segments[segIdx+1:]...)
+}
+
+// SplitSegment splits a segment in two after its first wordOffset words
+func SplitSegment(t *interfaces.TranscriptResult, segIdx, wordOffset int) (*interfaces.TranscriptResult, error) {
+	if err := checkSegment(t, segIdx); err != nil {
+		return nil, err
+	}
+	tokens := strings.Fields(t.Segments[segIdx].Text)
+	if wordOffset < 1 || wordOffset >= len(tokens) {
+		return nil, invalidEdit("word_offset must be between 1 and %d", len(tokens)-1)
+	}
+
+	result := Clone(t)
+	splitSegmentAt(result, segIdx, wordOffset)
+	return result, nil
+}
+
+// MergeSegments joins a segment with the one after it, keeping the first segment's speaker
+func MergeSegments(t *interfaces.TranscriptResult, segIdx int) (*interfaces.TranscriptResult, error) {
+	if err := checkSegment(t, segIdx); err != nil {
+		return nil, err
+	}
+	if segIdx+1 >= len(t.Segments) {
+		return nil, invalidEdit("segment %d is the last segment", segIdx)
+	}
+
+	result := Clone(t)
+	first, second := result.Segments[segIdx], result.Segments[segIdx+1]
+	first.End = math.Max(first.End, second.End)
+	first.Text = withLeadingSpace(first.Text, strings.TrimSpace(first.Text)+" "+strings.TrimSpace(second.Text))
+	result.Segments[segIdx] = first
+	result.Segments = append(result.Segments[:segIdx+1], result.Segments[segIdx+2:]...)
+	return result, nil
+}
+
+func checkSpeaker(speaker string) error {
+	if strings.TrimSpace(speaker) == "" {
+		return invalidEdit("speaker is required")
+	}
+	return nil
+}
+
+// withoutSpeaker returns a copy of an overlap list without speaker, who can't talk over themselves
+func withoutSpeaker(speakers []string, speaker string) []string {
+	var rest []string
+	for _, s := range speakers {
+		if s != speaker {
+			rest = append(rest, s)
+		}
+	}
+	return rest
+}
+
+// ReassignSegmentSpeaker attributes a segment and its words to another speaker
+func ReassignSegmentSpeaker(t *interfaces.TranscriptResult, segIdx int, speaker string) (*interfaces.TranscriptResult, error) {
+	if err := checkSegment(t, segIdx); err != nil {
+		return nil, err
+	}
+	if err := checkSpeaker(speaker); err != nil {
+		return nil, err
+	}
+
+	result := Clone(t)
+	label := strings.TrimSpace(speaker)
+	result.Segments[segIdx].Speaker = &label
+	result.Segments[segIdx].OverlapSpeakers = withoutSpeaker(result.Segments[segIdx].OverlapSpeakers, label)
+	lo, hi := SegmentWords(result, segIdx)
+	for i := lo; i < hi; i++ {
+		result.WordSegments[i].Speaker = &label
+		result.WordSegments[i].OverlapSpeakers = withoutSpeaker(result.WordSegments[i].OverlapSpeakers, label)
+	}
+	return result, nil
+}
+
+// ReassignWordSpeaker attributes the inclusive word range start..end to another speaker.
+// Segments the range only partly covers are split at its edges where their text lines up
+// with their words, so the range ends up in segments of its own.
+func ReassignWordSpeaker(t *interfaces.TranscriptResult, start, end int, speaker string) (*interfaces.TranscriptResult, error) {
+	if start < 0 || end < start || end >= len(t.WordSegments) {
+		return nil, invalidEdit("word range %d-%d is out of range", start, end)
+	}
+	if err := checkSpeaker(speaker); err != nil {
+		return nil, err
+	}
+
+	result := Clone(t)
+	label := strings.TrimSpace(speaker)
+	for i := start; i <= end; i++ {
+		result.WordSegments[i].Speaker = &label
+		result.WordSegments[i].OverlapSpeakers = withoutSpeaker(result.WordSegments[i].OverlapSpeakers, label)
+	}
+
+	// Walk backwards so splitting a segment leaves the indices of earlier ones alone
+	for segIdx := len(result.Segments) - 1; segIdx >= 0; segIdx-- {
+		lo, hi := SegmentWords(result, segIdx)
+		if hi <= start || lo > end || hi-lo != len(strings.Fields(result.Segments[segIdx].Text)) {
+			continue
+		}
+		if end+1 < hi {
+			splitSegmentAt(result, segIdx, end+1-lo)
+		}
+		if start > lo {
+			splitSegmentAt(result, segIdx, start-lo)
+		}
+	}
+
+	for segIdx := range result.Segments {
+		lo, hi := SegmentWords(result, segIdx)
+		if hi > lo && lo >= start && hi-1 <= end {
+			result.Segments[segIdx].Speaker = &label
+			result.Segments[segIdx].OverlapSpeakers = withoutSpeaker(result.Segments[segIdx].OverlapSpeakers, label)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,249 @@
+package editor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+
+	"gorm.io/gorm"
+)
+
+// Revision operations
+const (
+	OpTranscribed     = "transcribed"
+	OpEditText        = "edit_text"
+	OpSplit           = "split"
+	OpMerge           = "merge"
+	OpReassignSpeaker = "reassign_speaker"
+	OpRestore         = "restore"
+	OpRediarize       = "rediarize"
+)
+
+var (
+	// ErrNoTranscript is returned when a job has no transcript to edit yet
+	ErrNoTranscript = errors.New("transcription job has no transcript")
+	// ErrRevisionNotFound is returned when a job has no revision with the requested number
+	ErrRevisionNotFound = errors.New("revision not found")
+)
+
+// editMu serializes edits so concurrent requests can't both claim the next revision number
+var editMu sync.Mutex
+
+// EditFunc produces the edited version of a transcript. It must not modify current.
+type EditFunc func(current *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error)
+
+// Change describes an edit to record as a revision
+type Change struct {
+	UserID      uint
+	Operation   string
+	Description string
+}
+
+// Apply edits the transcript of a job and records the result as a new revision. The first
+// edit of a transcript also records the model output as revision 1, so it can be restored.
+// Notes are moved to follow their words, and the job is reindexed for search. If the edit
+// changes nothing, the latest revision is returned and nothing is recorded.
+func Apply(jobID string, change Change, edit EditFunc) (*models.TranscriptRevision, *interfaces.TranscriptResult, error) {
+	return apply(jobID, change, func(tx *gorm.DB, current *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+		return edit(current)
+	})
+}
+
+// Restore makes an earlier revision the current transcript by recording it as a new revision
+func Restore(jobID string, userID uint, revision int) (*models.TranscriptRevision, *interfaces.TranscriptResult, error) {
+	change := Change{UserID: userID, Operation: OpRestore, Description: fmt.Sprintf("Restored revision %d", revision)}
+	return apply(jobID, change, func(tx *gorm.DB, current *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+		target, err := getRevision(tx, jobID, revision)
+		if err != nil {
+			return nil, err
+		}
+		var restored interfaces.TranscriptResult
+		if err := json.Unmarshal([]byte(target.Transcript), &restored); err != nil {
+			return nil, fmt.Errorf("failed to parse revision %d: %w", revision, err)
+		}
+		return &restored, nil
+	})
+}
+
+func apply(jobID string, change Change, edit func(tx *gorm.DB, current *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error)) (*models.TranscriptRevision, *interfaces.TranscriptResult, error) {
+	editMu.Lock()
+	defer editMu.Unlock()
+
+	var revision models.TranscriptRevision
+	var edited *interfaces.TranscriptResult
+	changed := false
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var job models.TranscriptionJob
+		if err := tx.Select("id", "transcript").Where("id = ?", jobID).First(&job).Error; err != nil {
+			return fmt.Errorf("failed to load transcription job %s: %w", jobID, err)
+		}
+		if job.Transcript == nil || *job.Transcript == "" {
+			return ErrNoTranscript
+		}
+		var current interfaces.TranscriptResult
+		if err := json.Unmarshal([]byte(*job.Transcript), &current); err != nil {
+			return fmt.Errorf("failed to parse transcript of job %s: %w", jobID, err)
+		}
+
+		latest, err := latestRevision(tx, jobID)
+		if err != nil {
+			return err
+		}
+		// Record the transcript as it stands if no revision holds it yet: the model output
+		// on first edit, or a fresh transcript after the job was transcribed again
+		if latest == nil || latest.Transcript != *job.Transcript {
+			baseline := models.TranscriptRevision{
+				TranscriptionJobID: jobID,
+				Revision:           1,
+				Operation:          OpTranscribed,
+				Description:        "Model output",
+				Transcript:         *job.Transcript,
+			}
+			if latest != nil {
+				baseline.Revision = latest.Revision + 1
+				var previous interfaces.TranscriptResult
+				if err := json.Unmarshal([]byte(latest.Transcript), &previous); err == nil {
+					baseline.Diff = encodeDiff(DiffTranscripts(&previous, &current))
+				}
+			}
+			if err := tx.Create(&baseline).Error; err != nil {
+				return fmt.Errorf("failed to record model output of job %s: %w", jobID, err)
+			}
+			latest = &baseline
+		}
+
+		edited, err = edit(tx, &current)
+		if err != nil {
+			return err
+		}
+		diff := DiffTranscripts(&current, edited)
+		if diff.Empty() {
+			revision = *latest
+			return nil
+		}
+
+		encoded, err := json.Marshal(edited)
+		if err != nil {
+			return fmt.Errorf("failed to encode transcript: %w", err)
+		}
+		if err := tx.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Update("transcript", string(encoded)).Error; err != nil {
+			return fmt.Errorf("failed to save transcript of job %s: %w", jobID, err)
+		}
+
+		var author models.User
+		tx.Select("username").Where("id = ?", change.UserID).Limit(1).Find(&author)
+		revision = models.TranscriptRevision{
+			TranscriptionJobID: jobID,
+			Revision:           latest.Revision + 1,
+			UserID:             change.UserID,
+			Author:             author.Username,
+			Operation:          change.Operation,
+			Description:        change.Description,
+			Diff:               encodeDiff(diff),
+			Transcript:         string(encoded),
+		}
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to record revision of job %s: %w", jobID, err)
+		}
+
+		changed = true
+		return remapNotes(tx, jobID, current.WordSegments, edited.WordSegments)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if changed {
+		if err := search.IndexTranscript(jobID, edited); err != nil {
+			logger.Warn("Failed to reindex edited transcript", "job_id", jobID, "error", err)
+		}
+	}
+	return &revision, edited, nil
+}
+
+func encodeDiff(diff Diff) string {
+	encoded, err := json.Marshal(diff)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+// remapNotes moves note selections onto the words they pointed at before the edit. Notes whose
+// words kept their place and timing are left alone.
+func remapNotes(tx *gorm.DB, jobID string, before, after []interfaces.TranscriptWord) error {
+	var notes []models.Note
+	if err := tx.Where("transcription_id = ?", jobID).Find(&notes).Error; err != nil {
+		return fmt.Errorf("failed to load notes of job %s: %w", jobID, err)
+	}
+	if len(notes) == 0 {
+		return nil
+	}
+
+	wordMap := AlignWords(before, after)
+	for _, note := range notes {
+		oldStart, oldEnd := note.StartWordIndex, min(note.EndWordIndex, len(before)-1)
+		if oldStart < 0 || oldEnd < oldStart {
+			continue
+		}
+		start, end, ok := wordMap.RemapRange(oldStart, oldEnd, len(after))
+		if !ok || (start == oldStart && end == oldEnd &&
+			after[start].Start == before[oldStart].Start && after[end].End == before[oldEnd].End) {
+			continue
+		}
+		updates := map[string]interface{}{
+			"start_word_index": start,
+			"end_word_index":   end,
+			"start_time":       after[start].Start,
+			"end_time":         after[end].End,
+		}
+		if err := tx.Model(&models.Note{}).Where("id = ?", note.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to move note %s: %w", note.ID, err)
+		}
+	}
+	return nil
+}
+
+func latestRevision(tx *gorm.DB, jobID string) (*models.TranscriptRevision, error) {
+	var revisions []models.TranscriptRevision
+	if err := tx.Where("transcription_job_id = ?", jobID).Order("revision DESC").Limit(1).Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load revisions of job %s: %w", jobID, err)
+	}
+	if len(revisions) == 0 {
+		return nil, nil
+	}
+	return &revisions[0], nil
+}
+
+func getRevision(tx *gorm.DB, jobID string, revision int) (*models.TranscriptRevision, error) {
+	var rev models.TranscriptRevision
+	if err := tx.Where("transcription_job_id = ? AND revision = ?", jobID, revision).First(&rev).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, fmt.Errorf("failed to load revision %d of job %s: %w", revision, jobID, err)
+	}
+	return &rev, nil
+}
+
+// ListRevisions returns the revisions of a job, oldest first, without their snapshots
+func ListRevisions(jobID string) ([]models.TranscriptRevision, error) {
+	var revisions []models.TranscriptRevision
+	if err := database.DB.Omit("transcript").Where("transcription_job_id = ?", jobID).Order("revision ASC").Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revisions of job %s: %w", jobID, err)
+	}
+	return revisions, nil
+}
+
+// GetRevision returns one revision of a job including its transcript snapshot
+func GetRevision(jobID string, revision int) (*models.TranscriptRevision, error) {
+	return getRevision(database.DB, jobID, revision)
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// renderDOCX builds a minimal WordprocessingML document with one paragraph per segment
+func renderDOCX(result *interfaces.TranscriptResult, opts Options) ([]byte, error) {
+	var body strings.Builder
+
+	if opts.Title != "" {
+		body.WriteString(`<w:p><w:r><w:rPr><w:b/><w:sz w:val="32"/></w:rPr>`)
+		body.WriteString(docxText(opts.Title))
+		body.WriteString(`</w:r></w:p>`)
+	}
+
+	if len(result.Segments) == 0 {
+		body.WriteString(`<w:p><w:r>` + docxText(strings.TrimSpace(result.Text)) + `</w:r></w:p>`)
+	}
+
+	for _, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		body.WriteString(`<w:p>`)
+		body.WriteString(`<w:r><w:rPr><w:color w:val="808080"/></w:rPr>`)
+		body.WriteString(docxText("[" + formatTimestamp(seg.Start, ".")[:8] + "] "))
+		body.WriteString(`</w:r>`)
+		if speaker := speakerOf(seg); speaker != "" {
+			body.WriteString(`<w:r><w:rPr><w:b/></w:rPr>` + docxText(speaker+": ") + `</w:r>`)
+		}
+		body.WriteString(`<w:r>` + docxText(text) + `</w:r>`)
+		body.WriteString(`</w:p>`)
+	}
+
+	document := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` +
+		body.String() +
+		`</w:body></w:document>`
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/document.xml", document},
+	}
+	for _, part := range parts {
+		w, err := zw.Create(part.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create docx part %s: %w", part.name, err)
+		}
+		if _, err := w.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write docx part %s: %w", part.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize docx: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// docxText returns an escaped text run element preserving whitespace
+func docxText(s string) string {
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(s))
+	return `<w:t xml:space="preserve">` + escaped.String() + `</w:t>`
+}
@@ -0,0 +1,179 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+// Supported export formats
+const (
+	FormatSRT  = "srt"
+	FormatVTT  = "vtt"
+	FormatTXT  = "txt"
+	FormatDOCX = "docx"
+	FormatJSON = "json"
+	FormatTSV  = "tsv"
+)
+
+// SupportedFormats lists every format accepted by Render
+var SupportedFormats = []string{FormatSRT, FormatVTT, FormatTXT, FormatDOCX, FormatJSON, FormatTSV}
+
+// Options controls how a transcript is rendered
+type Options struct {
+	Title          string
+	SpeakerNames   map[string]string // original speaker label -> custom name
+	MaxLineWidth   int               // 0 disables line wrapping
+	MaxLineCount   int               // 0 means unlimited lines per subtitle cue
+	HighlightWords bool              // underline the active word in subtitle formats
+}
+
+// Output is a rendered transcript ready to be written to a response
+type Output struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+}
+
+// IsSupported reports whether format can be rendered
+func IsSupported(format string) bool {
+	for _, f := range SupportedFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// Render converts a transcript into the requested format
+func Render(format string, result *interfaces.TranscriptResult, opts Options) (*Output, error) {
+	if result == nil {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+
+	named := applySpeakerNames(result, opts.SpeakerNames)
+
+	switch format {
+	case FormatSRT:
+		return &Output{Data: []byte(renderSRT(named, opts)), ContentType: "application/x-subrip; charset=utf-8", Extension: "srt"}, nil
+	case FormatVTT:
+		return &Output{Data: []byte(renderVTT(named, opts)), ContentType: "text/vtt; charset=utf-8", Extension: "vtt"}, nil
+	case FormatTXT:
+		return &Output{Data: []byte(renderTXT(named)), ContentType: "text/plain; charset=utf-8", Extension: "txt"}, nil
+	case FormatTSV:
+		return &Output{Data: []byte(renderTSV(named)), ContentType: "text/tab-separated-values; charset=utf-8", Extension: "tsv"}, nil
+	case FormatJSON:
+		data, err := json.MarshalIndent(named, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal transcript: %w", err)
+		}
+		return &Output{Data: data, ContentType: "application/json; charset=utf-8", Extension: "json"}, nil
+	case FormatDOCX:
+		data, err := renderDOCX(named, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Output{Data: data, ContentType: "application/vnd.openxmlformats-officedocument.wordprocessingml.document", Extension: "docx"}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// applySpeakerNames returns a copy of result with speaker labels replaced by custom names
+func applySpeakerNames(result *interfaces.TranscriptResult, names map[string]string) *interfaces.TranscriptResult {
+	out := *result
+	out.Segments = make([]interfaces.TranscriptSegment, len(result.Segments))
+	copy(out.Segments, result.Segments)
+	out.WordSegments = make([]interfaces.TranscriptWord, len(result.WordSegments))
+	copy(out.WordSegments, result.WordSegments)
+
+	if len(names) == 0 {
+		return &out
+	}
+
+	lookup := make(map[string]string, len(names))
+	for original, custom := range names {
+		lookup[strings.ToLower(original)] = custom
+	}
+	rename := func(speaker *string) *string {
+		if speaker == nil {
+			return nil
+		}
+		if custom, ok := names[*speaker]; ok {
+			return &custom
+		}
+		if custom, ok := lookup[strings.ToLower(*speaker)]; ok {
+			return &custom
+		}
+		return speaker
+	}
+
+	for i := range out.Segments {
+		out.Segments[i].Speaker = rename(out.Segments[i].Speaker)
+	}
+	for i := range out.WordSegments {
+		out.WordSegments[i].Speaker = rename(out.WordSegments[i].Speaker)
+	}
+	return &out
+}
+
+// speakerOf returns the speaker label of a segment or an empty string
+func speakerOf(seg interfaces.TranscriptSegment) string {
+	if seg.Speaker == nil {
+		return ""
+	}
+	return *seg.Speaker
+}
+
+// renderTXT writes one line per segment, prefixed with the speaker when known
+func renderTXT(result *interfaces.TranscriptResult) string {
+	if len(result.Segments) == 0 {
+		return strings.TrimSpace(result.Text) + "\n"
+	}
+
+	var b strings.Builder
+	for _, seg := range result.Segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		if speaker := speakerOf(seg); speaker != "" {
+			b.WriteString(speaker + ": ")
+		}
+		b.WriteString(text + "\n")
+	}
+	return b.String()
+}
+
+// renderTSV writes start/end in milliseconds, speaker and text per segment
+func renderTSV(result *interfaces.TranscriptResult) string {
+	var b strings.Builder
+	b.WriteString("start\tend\tspeaker\ttext\n")
+	for _, seg := range result.Segments {
+		text := strings.Join(strings.Fields(seg.Text), " ")
+		if text == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%d\t%d\t%s\t%s\n",
+			int64(seg.Start*1000+0.5), int64(seg.End*1000+0.5),
+			strings.ReplaceAll(speakerOf(seg), "\t", " "), text)
+	}
+	return b.String()
+}
+
+// formatTimestamp formats seconds as HH:MM:SS<sep>mmm
+func formatTimestamp(seconds float64, sep string) string {
+	if seconds < 0 {
+		seconds = 0
+	}
+	ms := int64(seconds*1000 + 0.5)
+	h := ms / 3600000
+	ms -= h * 3600000
+	m := ms / 60000
+	ms -= m * 60000
+	s := ms / 1000
+	ms -= s * 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, sep, ms)
+}
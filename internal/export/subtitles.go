@@ -0,0 +1,241 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+// timeTolerance absorbs rounding differences between segment and word timestamps
+const timeTolerance = 0.05
+
+// token is a single word positioned in time
+type token struct {
+	text  string
+	start float64
+	end   float64
+}
+
+// cue is one subtitle entry made of one or more wrapped lines
+type cue struct {
+	start   float64
+	end     float64
+	speaker string
+	lines   [][]token
+	timed   bool // true when token times come from word-level alignment
+}
+
+// buildCues splits segments into subtitle cues honoring the line width and count limits
+func buildCues(result *interfaces.TranscriptResult, opts Options) []cue {
+	var cues []cue
+	words := result.WordSegments
+	wi := 0
+
+	for _, seg := range result.Segments {
+		// Collect the aligned words that belong to this segment
+		for wi < len(words) && midpoint(words[wi]) < seg.Start-timeTolerance {
+			wi++
+		}
+		var tokens []token
+		for wi < len(words) && midpoint(words[wi]) <= seg.End+timeTolerance {
+			if text := strings.TrimSpace(words[wi].Word); text != "" {
+				tokens = append(tokens, token{text: text, start: words[wi].Start, end: words[wi].End})
+			}
+			wi++
+		}
+
+		timed := len(tokens) > 0
+		if !timed {
+			tokens = interpolateTokens(seg)
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+
+		lines := wrapTokens(tokens, opts.MaxLineWidth)
+		perCue := len(lines)
+		if opts.MaxLineWidth > 0 && opts.MaxLineCount > 0 {
+			perCue = opts.MaxLineCount
+		}
+
+		for i := 0; i < len(lines); i += perCue {
+			end := i + perCue
+			if end > len(lines) {
+				end = len(lines)
+			}
+			group := lines[i:end]
+
+			c := cue{
+				start:   group[0][0].start,
+				end:     group[len(group)-1][len(group[len(group)-1])-1].end,
+				speaker: speakerOf(seg),
+				lines:   group,
+				timed:   timed,
+			}
+			if i == 0 {
+				c.start = seg.Start
+			}
+			if end == len(lines) {
+				c.end = seg.End
+			}
+			if c.end < c.start {
+				c.end = c.start
+			}
+			cues = append(cues, c)
+		}
+	}
+
+	return cues
+}
+
+// midpoint returns the center time of a word
+func midpoint(w interfaces.TranscriptWord) float64 {
+	return (w.Start + w.End) / 2
+}
+
+// interpolateTokens spreads segment text over its duration proportionally to word length
+func interpolateTokens(seg interfaces.TranscriptSegment) []token {
+	fields := strings.Fields(seg.Text)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	total := 0
+	for _, f := range fields {
+		total += len([]rune(f))
+	}
+
+	duration := seg.End - seg.Start
+	cursor := seg.Start
+	tokens := make([]token, 0, len(fields))
+	for _, f := range fields {
+		share := duration * float64(len([]rune(f))) / float64(total)
+		tokens = append(tokens, token{text: f, start: cursor, end: cursor + share})
+		cursor += share
+	}
+	return tokens
+}
+
+// wrapTokens greedily packs tokens into lines no wider than maxWidth characters
+func wrapTokens(tokens []token, maxWidth int) [][]token {
+	if maxWidth <= 0 {
+		return [][]token{tokens}
+	}
+
+	var lines [][]token
+	var current []token
+	width := 0
+	for _, t := range tokens {
+		w := len([]rune(t.text))
+		if len(current) > 0 && width+1+w > maxWidth {
+			lines = append(lines, current)
+			current = nil
+			width = 0
+		}
+		if len(current) > 0 {
+			width++
+		}
+		current = append(current, t)
+		width += w
+	}
+	if len(current) > 0 {
+		lines = append(lines, current)
+	}
+	return lines
+}
+
+// subtitleEntry is a fully rendered cue ready to be written
+type subtitleEntry struct {
+	start float64
+	end   float64
+	text  string
+}
+
+// expandCues renders cue text, producing per-word highlighted entries when requested
+func expandCues(cues []cue, opts Options, escape func(string) string, speakerPrefix func(string) string) []subtitleEntry {
+	var entries []subtitleEntry
+	for _, c := range cues {
+		prefix := ""
+		if c.speaker != "" {
+			prefix = speakerPrefix(c.speaker)
+		}
+
+		if !opts.HighlightWords || !c.timed {
+			entries = append(entries, subtitleEntry{start: c.start, end: c.end, text: prefix + cueText(c, -1, escape)})
+			continue
+		}
+
+		cursor := c.start
+		index := 0
+		for _, line := range c.lines {
+			for _, t := range line {
+				if t.start > cursor {
+					entries = append(entries, subtitleEntry{start: cursor, end: t.start, text: prefix + cueText(c, -1, escape)})
+				}
+				if t.end > t.start {
+					entries = append(entries, subtitleEntry{start: t.start, end: t.end, text: prefix + cueText(c, index, escape)})
+				}
+				if t.end > cursor {
+					cursor = t.end
+				}
+				index++
+			}
+		}
+		if cursor < c.end {
+			entries = append(entries, subtitleEntry{start: cursor, end: c.end, text: prefix + cueText(c, -1, escape)})
+		}
+	}
+	return entries
+}
+
+// cueText joins cue lines, underlining the token at highlight (-1 for none)
+func cueText(c cue, highlight int, escape func(string) string) string {
+	lines := make([]string, 0, len(c.lines))
+	index := 0
+	for _, line := range c.lines {
+		words := make([]string, 0, len(line))
+		for _, t := range line {
+			text := escape(t.text)
+			if index == highlight {
+				text = "<u>" + text + "</u>"
+			}
+			words = append(words, text)
+			index++
+		}
+		lines = append(lines, strings.Join(words, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderSRT renders the transcript as SubRip subtitles
+func renderSRT(result *interfaces.TranscriptResult, opts Options) string {
+	entries := expandCues(buildCues(result, opts), opts,
+		func(s string) string { return s },
+		func(speaker string) string { return speaker + ": " })
+
+	var b strings.Builder
+	for i, e := range entries {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, formatTimestamp(e.start, ","), formatTimestamp(e.end, ","), e.text)
+	}
+	return b.String()
+}
+
+// renderVTT renders the transcript as WebVTT subtitles
+func renderVTT(result *interfaces.TranscriptResult, opts Options) string {
+	entries := expandCues(buildCues(result, opts), opts,
+		escapeVTT,
+		func(speaker string) string { return "<v " + escapeVTT(speaker) + ">" })
+
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%s --> %s\n%s\n\n", formatTimestamp(e.start, "."), formatTimestamp(e.end, "."), e.text)
+	}
+	return b.String()
+}
+
+// escapeVTT escapes characters that have meaning in WebVTT cue text
+func escapeVTT(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
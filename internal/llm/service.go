@@ -8,3 +8,9 @@ type Service interface {
 	ChatCompletion(ctx context.Context, model string, messages []ChatMessage, temperature float64) (*ChatResponse, error)
 	ChatCompletionStream(ctx context.Context, model string, messages []ChatMessage, temperature float64) (<-chan string, <-chan error)
 }
+
+// Embedder is implemented by services that can turn text into embedding vectors
+type Embedder interface {
+	// Embed returns one vector per input, in input order
+	Embed(ctx context.Context, model string, inputs []string) ([][]float64, error)
+}
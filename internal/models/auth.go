@@ -14,3 +14,67 @@ type RefreshToken struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
+
+// User roles
+const (
+	RoleAdmin  = "admin"  // Manages users, invitations and server-wide settings
+	RoleEditor = "editor" // Creates and changes their own transcriptions
+	RoleViewer = "viewer" // Read-only access
+)
+
+// ValidRole reports whether role is a known user role
+func ValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
+}
+
+// API key scopes
+const (
+	ScopeTranscriptsRead = "transcripts:read" // Read transcriptions and everything attached to them
+	ScopeTranscribeWrite = "transcribe:write" // Submit, change and delete transcriptions
+	ScopeChat            = "chat"             // Chat and summarize with the LLM
+	ScopeAdmin           = "admin"            // Server-wide settings, webhooks and queue administration
+)
+
+// ValidScope reports whether scope is a known API key scope
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeTranscriptsRead, ScopeTranscribeWrite, ScopeChat, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// DefaultAPIKeyScopes returns the scopes of keys created without any: every scope but admin
+func DefaultAPIKeyScopes() []string {
+	return []string{ScopeTranscriptsRead, ScopeTranscribeWrite, ScopeChat}
+}
+
+// ScopesAllow reports whether the scopes of an API key allow acting within scope. Keys without
+// scopes get the default ones, so they never reach admin endpoints.
+func ScopesAllow(scopes []string, scope string) bool {
+	if scopes == nil {
+		scopes = DefaultAPIKeyScopes()
+	}
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Invitation lets someone register an account with a preset role
+type Invitation struct {
+	ID         uint       `json:"id" gorm:"primaryKey"`
+	Hashed     string     `json:"-" gorm:"not null;uniqueIndex;type:varchar(128)"` // SHA-256 of the invitation token
+	Role       string     `json:"role" gorm:"type:varchar(20);not null"`
+	CreatedBy  uint       `json:"created_by" gorm:"not null;index"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"not null"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	AcceptedBy *uint      `json:"accepted_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReplacementRule rewrites matching transcript text, e.g. a misheard product name
+type ReplacementRule struct {
+	Pattern       string `json:"pattern"`
+	Replacement   string `json:"replacement"`
+	Regex         bool   `json:"regex"`          // Pattern is a regular expression; Replacement may use $1 etc.
+	CaseSensitive bool   `json:"case_sensitive"` // Match case exactly instead of ignoring it
+	WholeWord     bool   `json:"whole_word"`     // Only match the pattern as a whole word
+}
+
+// ReplacementDictionary is a user-managed set of replacement rules that profiles can apply to transcripts
+type ReplacementDictionary struct {
+	ID          string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      uint              `json:"user_id" gorm:"not null;default:0;index"` // Owner of the dictionary
+	Name        string            `json:"name" gorm:"type:varchar(255);not null"`
+	Description *string           `json:"description,omitempty" gorm:"type:text"`
+	Rules       []ReplacementRule `json:"rules" gorm:"type:text;serializer:json"` // Applied in order
+	CreatedAt   time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate ensures ReplacementDictionary has a UUID primary key
+func (d *ReplacementDictionary) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// DropzoneFile records a file the dropzone uploaded while leaving it in place, so that files
+// already ingested are told apart from files dropped while the server was down
+type DropzoneFile struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Path       string    `json:"path" gorm:"type:varchar(1024);not null;uniqueIndex"` // Relative to the dropzone
+	Size       int64     `json:"size" gorm:"not null"`
+	ModifiedAt int64     `json:"modified_at" gorm:"not null"` // Modification time in Unix nanoseconds
+	JobID      string    `json:"job_id" gorm:"type:varchar(36)"`
+	CreatedAt  time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
@@ -20,6 +20,7 @@ const (
 // LiveTranscriptionSession persists metadata for progressive transcription jobs
 type LiveTranscriptionSession struct {
 	ID                    string            `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID                uint              `json:"user_id" gorm:"not null;default:0;index"` // Owner of the session
 	Title                 *string           `json:"title,omitempty" gorm:"type:text"`
 	Status                LiveSessionStatus `json:"status" gorm:"type:varchar(20);not null;default:'active'"`
 	Parameters            WhisperXParams    `json:"parameters" gorm:"embedded"`
@@ -2,11 +2,15 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // Note represents an annotation attached to a transcription
 type Note struct {
 	ID              string `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID          uint   `json:"user_id" gorm:"not null;default:0;index"` // Owner of the note
 	TranscriptionID string `json:"transcription_id" gorm:"type:varchar(36);not null;index"`
 
 	// Indexed selection into transcript by word positions
@@ -26,3 +30,26 @@ type Note struct {
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 }
+
+// TranscriptRevision is one version of a transcript. Revision 1 holds the model output, and every
+// edit or restore adds the next revision with a full snapshot so any version can be restored.
+type TranscriptRevision struct {
+	ID                 string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;uniqueIndex:idx_transcript_revision"`
+	Revision           int       `json:"revision" gorm:"not null;uniqueIndex:idx_transcript_revision"`
+	UserID             uint      `json:"user_id" gorm:"not null;default:0"` // Author, 0 for model output
+	Author             string    `json:"author" gorm:"type:varchar(50)"`
+	Operation          string    `json:"operation" gorm:"type:varchar(30);not null"` // transcribed, edit_text, split, merge, reassign_speaker, restore, rediarize
+	Description        string    `json:"description" gorm:"type:text"`
+	Diff               string    `json:"diff,omitempty" gorm:"type:text"`       // JSON-serialized editor.Diff against the previous revision
+	Transcript         string    `json:"transcript,omitempty" gorm:"type:text"` // Full transcript JSON after this revision
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate ensures TranscriptRevision has a UUID primary key
+func (r *TranscriptRevision) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -34,6 +34,7 @@ type SummarySetting struct {
 // Summary stores a generated summary linked to a transcription
 type Summary struct {
 	ID              string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID          uint      `json:"user_id" gorm:"not null;default:0;index"` // Owner of the summary
 	TranscriptionID string    `json:"transcription_id" gorm:"type:varchar(36);index;not null"`
 	TemplateID      *string   `json:"template_id,omitempty" gorm:"type:varchar(36)"`
 	Model           string    `json:"model" gorm:"type:varchar(255);not null"`
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a user-defined label for organizing transcriptions
+type Tag struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    uint      `json:"user_id" gorm:"not null;default:0;uniqueIndex:idx_tag_user_name"` // Owner of the tag
+	Name      string    `json:"name" gorm:"type:varchar(100);not null;uniqueIndex:idx_tag_user_name"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// BeforeCreate ensures Tag has a UUID primary key
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Folder groups transcriptions into a hierarchy; a folder with no parent sits at the top level
+type Folder struct {
+	ID        string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    uint      `json:"user_id" gorm:"not null;default:0;index"` // Owner of the folder
+	ParentID  *string   `json:"parent_id,omitempty" gorm:"type:varchar(36);index"`
+	Name      string    `json:"name" gorm:"type:varchar(255);not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate ensures Folder has a UUID primary key
+func (f *Folder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == "" {
+		f.ID = uuid.New().String()
+	}
+	return nil
+}
@@ -10,11 +10,17 @@ import (
 // TranscriptionJob represents a transcription job record
 type TranscriptionJob struct {
 	ID               string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID           uint      `json:"user_id" gorm:"not null;default:0;index"` // Owner of the job
 	Title            *string   `json:"title,omitempty" gorm:"type:text"`
 	Status           JobStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending'"`
+	JobType          JobType   `json:"job_type" gorm:"type:varchar(20);not null;default:'transcribe'"` // What the queue runs for the job
+	PreviousStatus   *JobStatus `json:"-" gorm:"type:varchar(20)"` // Status a re-diarized job returns to if the run fails
 	AudioPath        string    `json:"audio_path" gorm:"type:text;not null"`
 	Transcript       *string   `json:"transcript,omitempty" gorm:"type:text"`
 	Diarization      bool      `json:"diarization" gorm:"type:boolean;default:false"`
+	Priority         int       `json:"priority" gorm:"not null;default:0;index"` // Higher runs first, see PriorityLow..PriorityUrgent
+	Attempts         int       `json:"attempts" gorm:"not null;default:0"` // Times the queue has started the job since it was last submitted
+	NextAttemptAt    *time.Time `json:"next_attempt_at,omitempty"`         // Earliest time a scheduled retry may run
 	Summary          *string   `json:"summary,omitempty" gorm:"type:text"`
 	ErrorMessage     *string   `json:"error_message,omitempty" gorm:"type:text"`
 	IsMultiTrack     bool      `json:"is_multi_track" gorm:"type:boolean;default:false"`
@@ -24,6 +30,9 @@ type TranscriptionJob struct {
 	MergeStatus           string `json:"merge_status" gorm:"type:varchar(20);default:'none'"` // none, pending, processing, completed, failed
 	MergeError            *string `json:"merge_error,omitempty" gorm:"type:text"`
 	IndividualTranscripts *string `json:"individual_transcripts,omitempty" gorm:"type:text"` // JSON-serialized map[string]*string
+	FolderID         *string   `json:"folder_id,omitempty" gorm:"type:varchar(36);index"`
+	AudioDuration    *float64  `json:"audio_duration,omitempty"`                        // Seconds of audio, known once transcribed
+	DetectedLanguage *string   `json:"detected_language,omitempty" gorm:"type:varchar(10)"` // Language reported by the transcription model
 	CreatedAt        time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt        time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
@@ -32,6 +41,7 @@ type TranscriptionJob struct {
 
 	// Relationships
 	MultiTrackFiles []MultiTrackFile `json:"multi_track_files,omitempty" gorm:"foreignKey:TranscriptionJobID"`
+	Tags            []Tag            `json:"tags,omitempty" gorm:"many2many:transcription_job_tags"`
 }
 
 // JobStatus represents the status of a transcription job
@@ -45,6 +55,25 @@ const (
 	StatusFailed     JobStatus = "failed"
 )
 
+// JobType selects the work the queue does when it runs a job
+type JobType string
+
+const (
+	JobTypeTranscribe JobType = "transcribe" // full transcription, with diarization if enabled
+	JobTypeRediarize  JobType = "rediarize"  // diarization only, merged into the stored transcript
+)
+
+// Named job priorities. Any value between MinPriority and MaxPriority is accepted.
+const (
+	PriorityLow    = -10
+	PriorityNormal = 0
+	PriorityHigh   = 10
+	PriorityUrgent = 20
+
+	MinPriority = -100
+	MaxPriority = 100
+)
+
 // WhisperXParams contains parameters for WhisperX transcription
 type WhisperXParams struct {
 	// Model family (whisper or nvidia)
@@ -82,6 +111,23 @@ type WhisperXParams struct {
 	VadOffset float64 `json:"vad_offset" gorm:"type:real;default:0.363"`
 	ChunkSize int     `json:"chunk_size" gorm:"type:int;default:30"`
 
+	// Silence removal before transcription; timestamps are mapped back to the original audio
+	RemoveSilence      bool    `json:"remove_silence" gorm:"type:boolean;default:false"`
+	SilenceThresholdDB float64 `json:"silence_threshold_db" gorm:"column:silence_threshold_db;type:real;default:-35"` // dBFS below which audio counts as silence
+	SilenceMinDuration float64 `json:"silence_min_duration" gorm:"type:real;default:2"`                               // seconds; shorter pauses are kept
+
+	// Audio enhancement before transcription, applied in order: high-pass, denoise, loudness normalization
+	HighpassFrequency int     `json:"highpass_frequency" gorm:"type:int;default:0"`     // Hz, 0 disables
+	Denoise           string  `json:"denoise" gorm:"type:varchar(20);default:''"`       // '', 'afftdn', 'arnndn'
+	DenoiseModel      string  `json:"denoise_model,omitempty" gorm:"type:varchar(100)"` // RNNoise model file for arnndn
+	Loudnorm          bool    `json:"loudnorm" gorm:"type:boolean;default:false"`       // EBU R128 loudness normalization
+	LoudnormTarget    float64 `json:"loudnorm_target" gorm:"type:real;default:-23"`     // integrated loudness in LUFS
+
+	// Transcript text postprocessing, applied to segments and words
+	ReplacementDictionaries []string `json:"replacement_dictionaries,omitempty" gorm:"type:text;serializer:json"` // IDs of ReplacementDictionary, applied in order
+	NormalizeNumbers        bool     `json:"normalize_numbers" gorm:"type:boolean;default:false"`                 // "twenty one" -> "21"
+	MaskProfanity           bool     `json:"mask_profanity" gorm:"type:boolean;default:false"`
+
 	// Diarization settings
 	Diarize           bool   `json:"diarize" gorm:"type:boolean;default:false"`
 	MinSpeakers       *int   `json:"min_speakers,omitempty" gorm:"type:int"`
@@ -136,6 +182,8 @@ type User struct {
 	ID                       uint      `json:"id" gorm:"primaryKey"`
 	Username                 string    `json:"username" gorm:"uniqueIndex;not null;type:varchar(50)"`
 	Password                 string    `json:"-" gorm:"not null;type:varchar(255)"`
+	Role                     string    `json:"role" gorm:"type:varchar(20);not null;default:'editor'"`
+	Disabled                 bool      `json:"disabled" gorm:"not null;default:false"`
 	DefaultProfileID         *string   `json:"default_profile_id,omitempty" gorm:"type:varchar(36)"`
 	AutoTranscriptionEnabled bool      `json:"auto_transcription_enabled" gorm:"not null;default:false"`
 	FastFinalizeEnabled      bool      `json:"fast_finalize_enabled" gorm:"not null;default:true"`
@@ -146,9 +194,15 @@ type User struct {
 // APIKey represents an API key for external authentication
 type APIKey struct {
 	ID          uint       `json:"id" gorm:"primaryKey"`
-	Key         string     `json:"key" gorm:"uniqueIndex;not null;type:varchar(255)"`
+	UserID      uint       `json:"user_id" gorm:"not null;default:0;index"`           // User the key acts on behalf of
+	Key         string     `json:"key" gorm:"uniqueIndex;not null;type:varchar(255)"` // SHA-256 of the key once Hashed is set
+	KeyPrefix   string     `json:"key_prefix" gorm:"type:varchar(16)"`                // Leading characters of the key, to tell keys apart
+	Hashed      bool       `json:"-" gorm:"not null;default:false"`
 	Name        string     `json:"name" gorm:"not null;type:varchar(100)"`
 	Description *string    `json:"description,omitempty" gorm:"type:text"`
+	Scopes      []string   `json:"scopes" gorm:"type:text;serializer:json"` // nil means the default scopes
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	UsageCount  int64      `json:"usage_count" gorm:"not null;default:0"`
 	// IsActive should persist explicit false values; avoid default tag to prevent
 	// GORM from overriding false with DB defaults during inserts.
 	IsActive  bool       `json:"is_active" gorm:"type:boolean;not null"`
@@ -168,6 +222,7 @@ func (ak *APIKey) BeforeCreate(tx *gorm.DB) error {
 // TranscriptionProfile represents a saved transcription configuration profile
 type TranscriptionProfile struct {
 	ID          string         `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      uint           `json:"user_id" gorm:"not null;default:0;index"` // Owner of the profile
 	Name        string         `json:"name" gorm:"type:varchar(255);not null"`
 	Description *string        `json:"description,omitempty" gorm:"type:text"`
 	IsDefault   bool           `json:"is_default" gorm:"type:boolean;default:false"`
@@ -184,11 +239,11 @@ func (tp *TranscriptionProfile) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// BeforeSave ensures only one profile can be default
+// BeforeSave ensures each user has at most one default profile
 func (tp *TranscriptionProfile) BeforeSave(tx *gorm.DB) error {
 	if tp.IsDefault {
 		// Set all other profiles to not default
-		if err := tx.Model(&TranscriptionProfile{}).Where("id != ?", tp.ID).Update("is_default", false).Error; err != nil {
+		if err := tx.Model(&TranscriptionProfile{}).Where("id != ? AND user_id = ?", tp.ID, tp.UserID).Update("is_default", false).Error; err != nil {
 			return err
 		}
 	}
@@ -220,14 +275,18 @@ func (lc *LLMConfig) BeforeSave(tx *gorm.DB) error {
 // ChatSession represents a chat session with a transcript
 type ChatSession struct {
 	ID              string     `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID          uint       `json:"user_id" gorm:"not null;default:0;index"` // Owner of the session
 	JobID           string     `json:"job_id" gorm:"type:varchar(36);not null"`
+	FolderID        *string    `json:"folder_id,omitempty" gorm:"type:varchar(36);index"`     // Set for sessions over a whole folder
+	RetrievalMode   string     `json:"retrieval_mode" gorm:"type:varchar(20);default:'auto'"` // auto, full, bm25 or embeddings
+	EmbeddingModel  string     `json:"embedding_model,omitempty" gorm:"type:varchar(100)"`    // Used by the embeddings retrieval mode
 	TranscriptionID string     `json:"transcription_id" gorm:"type:varchar(36);not null;index"`
 	Title           string     `json:"title" gorm:"type:varchar(255);not null"`
 	Model           string     `json:"model" gorm:"type:varchar(100);not null"`
 	Provider        string     `json:"provider" gorm:"type:varchar(50);not null;default:'openai'"`
 	SystemContext   *string    `json:"system_context,omitempty" gorm:"type:text"`
 	MessageCount    int        `json:"message_count" gorm:"type:integer;default:0"`
-	LastActivityAt  *time.Time `json:"last_activity_at,omitempty" gorm:"type:datetime"`
+	LastActivityAt  *time.Time `json:"last_activity_at,omitempty"`
 	IsActive        bool       `json:"is_active" gorm:"type:boolean;default:true"`
 	CreatedAt       time.Time  `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt       time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
@@ -236,6 +295,8 @@ type ChatSession struct {
 	Transcription TranscriptionJob `json:"transcription,omitempty" gorm:"foreignKey:TranscriptionID"`
 	Job           TranscriptionJob `json:"job,omitempty" gorm:"foreignKey:JobID"`
 	Messages      []ChatMessage    `json:"messages,omitempty" gorm:"foreignKey:ChatSessionID"`
+	// Transcripts the session covers; sessions without any cover just Transcription
+	Transcriptions []TranscriptionJob `json:"-" gorm:"many2many:chat_session_transcriptions"`
 }
 
 // BeforeCreate sets the ID if not already set
@@ -257,12 +318,36 @@ type ChatMessage struct {
 	Role          string    `json:"role" gorm:"type:varchar(20);not null"` // "user" or "assistant"
 	Content       string    `json:"content" gorm:"type:text;not null"`
 	TokensUsed    *int      `json:"tokens_used,omitempty" gorm:"type:integer"`
+	Citations     []Citation `json:"citations,omitempty" gorm:"type:text;serializer:json"` // Transcript passages an assistant answer cites
 	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
 
 	// Relationships
 	ChatSession ChatSession `json:"chat_session,omitempty" gorm:"foreignKey:ChatSessionID"`
 }
 
+// Citation points from a chat answer to the transcript passage it was based on
+type Citation struct {
+	Label        string  `json:"label"` // Source label used in the answer, such as S1
+	JobID        string  `json:"job_id"`
+	Title        string  `json:"title,omitempty"`
+	SegmentStart int     `json:"segment_start"` // Index of the first segment of the passage
+	SegmentEnd   int     `json:"segment_end"`   // Index of the last segment of the passage
+	Start        float64 `json:"start"`         // Seconds
+	End          float64 `json:"end"`
+}
+
+// TranscriptChunkEmbedding is the embedding of one retrieval chunk of a transcript. Chunks are
+// recomputed from the transcript, so only their index and the hash of the transcript are stored.
+type TranscriptChunkEmbedding struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	Model              string    `json:"model" gorm:"type:varchar(100);not null"`
+	TranscriptHash     string    `json:"transcript_hash" gorm:"type:varchar(64);not null"`
+	ChunkIndex         int       `json:"chunk_index" gorm:"not null"`
+	Vector             []float64 `json:"-" gorm:"type:text;serializer:json"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
 // BeforeCreate sets both session IDs to the same value for compatibility
 func (cm *ChatMessage) BeforeCreate(tx *gorm.DB) error {
 	if cm.SessionID == "" {
@@ -294,8 +379,8 @@ type TranscriptionJobExecution struct {
 
 	// Multi-track specific timing data
 	MultiTrackTimings *string    `json:"multi_track_timings,omitempty" gorm:"type:text"` // JSON-serialized []MultiTrackTiming
-	MergeStartTime    *time.Time `json:"merge_start_time,omitempty" gorm:"type:datetime"`
-	MergeEndTime      *time.Time `json:"merge_end_time,omitempty" gorm:"type:datetime"`
+	MergeStartTime    *time.Time `json:"merge_start_time,omitempty"`
+	MergeEndTime      *time.Time `json:"merge_end_time,omitempty"`
 	MergeDuration     *int64     `json:"merge_duration,omitempty"` // Merge phase duration in milliseconds
 
 	// Parameters used for this execution (may differ from job parameters due to profiles)
@@ -305,6 +390,21 @@ type TranscriptionJobExecution struct {
 	Status       JobStatus `json:"status" gorm:"type:varchar(20);not null"`
 	ErrorMessage *string   `json:"error_message,omitempty" gorm:"type:text"`
 
+	// Automatic retries: which attempt this was and why it failed
+	Attempt      int     `json:"attempt" gorm:"not null;default:0"`
+	FailureClass *string `json:"failure_class,omitempty" gorm:"type:varchar(30)"` // out_of_memory, process_killed, model_download, ...
+
+	// Audio preprocessing: the ffmpeg filters applied and the audio the model was given
+	PreprocessingFilters *string `json:"preprocessing_filters,omitempty" gorm:"type:text"`
+	PreprocessedAudio    *string `json:"preprocessed_audio,omitempty" gorm:"type:text"`
+
+	// Process group of the running model, used to clean up after a crash
+	ProcessPID *int `json:"process_pid,omitempty" gorm:"column:process_pid"`
+
+	// Crash recovery: what was done with the job at startup and what was cleaned up
+	RecoveryAction  *string `json:"recovery_action,omitempty" gorm:"type:varchar(20)"` // requeued, failed
+	RecoveryDetails *string `json:"recovery_details,omitempty" gorm:"type:text"`
+
 	// Metadata
 	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
@@ -334,8 +434,9 @@ func (tje *TranscriptionJobExecution) CalculateProcessingDuration() {
 type SpeakerMapping struct {
 	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
-	OriginalSpeaker    string    `json:"original_speaker" gorm:"type:varchar(50);not null"` // e.g., "speaker_00"
-	CustomName         string    `json:"custom_name" gorm:"type:varchar(100);not null"`     // e.g., "John Doe"
+	OriginalSpeaker    string    `json:"original_speaker" gorm:"type:varchar(50);not null"`        // e.g., "speaker_00"
+	CustomName         string    `json:"custom_name" gorm:"type:varchar(100);not null"`            // e.g., "John Doe"
+	VoiceProfileID     *string   `json:"voice_profile_id,omitempty" gorm:"type:varchar(36);index"` // Set when the name came from a matched voice profile
 	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
 	UpdatedAt          time.Time `json:"updated_at" gorm:"autoUpdateTime"`
 
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// VoiceProfile is a named person whose voice is recognized across recordings
+type VoiceProfile struct {
+	ID        string        `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID    uint          `json:"user_id" gorm:"not null;default:0;index"` // Owner of the profile
+	Name      string        `json:"name" gorm:"type:varchar(100);not null"`
+	CreatedAt time.Time     `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time     `json:"updated_at" gorm:"autoUpdateTime"`
+	Samples   []VoiceSample `json:"samples,omitempty" gorm:"foreignKey:VoiceProfileID"`
+}
+
+// BeforeCreate ensures VoiceProfile has a UUID primary key
+func (v *VoiceProfile) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == "" {
+		v.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// VoiceSample is one enrolled embedding of a voice profile, taken from a diarized speaker of a job
+type VoiceSample struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	VoiceProfileID     string    `json:"voice_profile_id" gorm:"type:varchar(36);not null;index"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null"`
+	Speaker            string    `json:"speaker" gorm:"type:varchar(50);not null"`
+	Model              string    `json:"model" gorm:"type:varchar(100)"` // Embeddings are only comparable within a model
+	Vector             []float64 `json:"-" gorm:"type:text;serializer:json"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// SpeakerEmbedding is the voice embedding the diarization model produced for one speaker of a job
+type SpeakerEmbedding struct {
+	ID                 uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	TranscriptionJobID string    `json:"transcription_job_id" gorm:"type:varchar(36);not null;index"`
+	Speaker            string    `json:"speaker" gorm:"type:varchar(50);not null"`
+	Model              string    `json:"model" gorm:"type:varchar(100)"`
+	Vector             []float64 `json:"-" gorm:"type:text;serializer:json"`
+	CreatedAt          time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook is an outbound HTTP endpoint notified about job lifecycle events
+type Webhook struct {
+	ID          string    `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	UserID      uint      `json:"user_id" gorm:"not null;default:0;index"` // Owner of the webhook
+	URL         string    `json:"url" gorm:"type:text;not null"`
+	Description *string   `json:"description,omitempty" gorm:"type:text"`
+	Secret      string    `json:"-" gorm:"type:varchar(128);not null"` // HMAC key used to sign payloads
+	Events      []string  `json:"events" gorm:"type:text;serializer:json"`
+	IsActive    bool      `json:"is_active" gorm:"type:boolean"` // No default tag, GORM would turn an explicit false into it
+	CreatedAt   time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// BeforeCreate ensures Webhook has a UUID primary key
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == "" {
+		w.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// Subscribes reports whether the webhook should receive the given event.
+// A webhook without an event list receives every event.
+func (w *Webhook) Subscribes(event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event || e == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus represents the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	DeliveryPending   WebhookDeliveryStatus = "pending"
+	DeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	DeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery records one event sent (or to be sent) to a webhook
+type WebhookDelivery struct {
+	ID             string                `json:"id" gorm:"primaryKey;type:varchar(36)"`
+	WebhookID      string                `json:"webhook_id" gorm:"type:varchar(36);not null;index"`
+	Event          string                `json:"event" gorm:"type:varchar(50);not null;index"`
+	Payload        string                `json:"payload" gorm:"type:text;not null"`
+	Status         WebhookDeliveryStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index"`
+	Attempts       int                   `json:"attempts" gorm:"type:int;not null;default:0"`
+	NextAttemptAt  *time.Time            `json:"next_attempt_at,omitempty" gorm:"index"`
+	ResponseStatus *int                  `json:"response_status,omitempty"`
+	ResponseBody   *string               `json:"response_body,omitempty" gorm:"type:text"`
+	Error          *string               `json:"error,omitempty" gorm:"type:text"`
+	DeliveredAt    *time.Time            `json:"delivered_at,omitempty"`
+	ReplayOf       *string               `json:"replay_of,omitempty" gorm:"type:varchar(36)"` // Delivery this one re-sends
+	CreatedAt      time.Time             `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt      time.Time             `json:"updated_at" gorm:"autoUpdateTime"`
+
+	// Relationship
+	Webhook Webhook `json:"-" gorm:"foreignKey:WebhookID"`
+}
+
+// BeforeCreate ensures WebhookDelivery has a UUID primary key
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == "" {
+		d.ID = uuid.New().String()
+	}
+	return nil
+}
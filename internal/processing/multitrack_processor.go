@@ -8,6 +8,7 @@ import (
 	"synthezia/internal/audio"
 	"synthezia/internal/database"
 	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
 	"synthezia/pkg/logger"
 
 	"gorm.io/gorm"
@@ -117,6 +118,7 @@ func (p *MultiTrackProcessor) ProcessMultiTrackJob(ctx context.Context, jobID st
 	}
 
 	logger.Info("Successfully completed multi-track processing", "job_id", jobID, "output_path", outputPath)
+	webhooks.EmitJobEvent(webhooks.EventMultiTrackMergeCompleted, jobID)
 	return nil
 }
 
@@ -132,7 +134,14 @@ func (p *MultiTrackProcessor) updateMergeStatus(jobID, status string, errorMsg *
 		updates["merge_error"] = nil
 	}
 
-	return p.db.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Updates(updates).Error
+	if err := p.db.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	if status == "failed" {
+		webhooks.EmitJobEvent(webhooks.EventMultiTrackMergeFailed, jobID)
+	}
+	return nil
 }
 
 // updateTrackOffsets updates the MultiTrackFile records with information from .aup file
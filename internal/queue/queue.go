@@ -2,11 +2,13 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"os/exec"
 	"runtime"
+	"sort"
 	"strconv"
 	"sync"
 	"sync/atomic"
@@ -14,13 +16,45 @@ import (
 
 	"synthezia/internal/database"
 	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
 	"synthezia/pkg/logger"
+
+	"gorm.io/gorm"
 )
 
 // RunningJob tracks both context cancellation and OS process
 type RunningJob struct {
 	Cancel  context.CancelFunc
 	Process *exec.Cmd
+	UserID  uint // owner, used for per-user fairness
+
+	// Estimated memory held while the job runs, counted against the resource budget
+	MemoryMB int
+	GPU      bool
+}
+
+// queueCapacity is the maximum number of jobs waiting for a worker
+const queueCapacity = 200
+
+// queuedJob is a job waiting for a worker, with the fields scheduling orders by
+type queuedJob struct {
+	ID       string
+	UserID   uint
+	Priority  int
+	QueuedAt  time.Time
+	NotBefore time.Time // set while a retry is backing off
+	MemoryMB  int
+	GPU       bool
+}
+
+// QueuedJobInfo describes a waiting job and where it currently stands in the queue
+type QueuedJobInfo struct {
+	JobID    string    `json:"job_id"`
+	UserID   uint      `json:"user_id"`
+	Priority int       `json:"priority"`
+	Position int        `json:"position"` // 1 is the next job a free worker picks
+	QueuedAt time.Time  `json:"queued_at"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"` // set while a retry is backing off
 }
 
 // TaskQueue manages transcription job processing
@@ -28,7 +62,9 @@ type TaskQueue struct {
 	minWorkers    int
 	maxWorkers    int
 	currentWorkers int64 // Use atomic for thread-safe access
-	jobChannel    chan string
+	queued        map[string]time.Time // waiting job IDs and when they were enqueued
+	queueMutex    sync.Mutex
+	wake          chan struct{} // signalled when a job is enqueued
 	ctx           context.Context
 	cancel        context.CancelFunc
 	wg            sync.WaitGroup
@@ -38,6 +74,8 @@ type TaskQueue struct {
 	workerMutex   sync.Mutex
 	autoScale     bool
 	lastScaleTime time.Time
+	retryPolicy   RetryPolicy
+	budget        ResourceBudget
 }
 
 // JobProcessor defines the interface for processing jobs
@@ -46,6 +84,10 @@ type JobProcessor interface {
 	ProcessJobWithProcess(ctx context.Context, jobID string, registerProcess func(*exec.Cmd)) error
 }
 
+// ErrRediarizeFailed is returned, wrapping the cause, by processors whose re-diarization run failed.
+// The job keeps its stored transcript, so it returns to the status it had before the run.
+var ErrRediarizeFailed = errors.New("rediarization failed")
+
 // MultiTrackJobProcessor extends JobProcessor with multi-track specific methods
 type MultiTrackJobProcessor interface {
 	JobProcessor
@@ -98,14 +140,24 @@ func NewTaskQueue(legacyWorkers int, processor JobProcessor) *TaskQueue {
 		minWorkers:     min,
 		maxWorkers:     max,
 		currentWorkers: int64(min),
-		jobChannel:     make(chan string, 200), // Increased buffer for better throughput
+		queued:         make(map[string]time.Time),
+		wake:           make(chan struct{}, 1),
 		ctx:            ctx,
 		cancel:         cancel,
 		processor:      processor,
 		runningJobs:    make(map[string]*RunningJob),
 		autoScale:      autoScale,
 		lastScaleTime:  time.Now(),
+		retryPolicy:    DefaultRetryPolicy(),
+	}
+}
+
+// SetRetryPolicy replaces the automatic retry policy. Call it before Start.
+func (tq *TaskQueue) SetRetryPolicy(policy RetryPolicy) {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
 	}
+	tq.retryPolicy = policy
 }
 
 // Start starts the task queue workers
@@ -138,99 +190,403 @@ func (tq *TaskQueue) Start() {
 func (tq *TaskQueue) Stop() {
 	logger.Debug("Stopping task queue")
 	tq.cancel()
-	close(tq.jobChannel)
 	tq.wg.Wait()
 	logger.Debug("Task queue stopped")
 }
 
-// EnqueueJob adds a job to the queue
+// EnqueueJob adds a job to the queue. Enqueueing a job that is already waiting is a no-op.
 func (tq *TaskQueue) EnqueueJob(jobID string) error {
-	// Check if queue is stopped first to avoid panic on closed channel
 	if tq.ctx.Err() != nil {
 		return fmt.Errorf("queue is shutting down")
 	}
 
-	select {
-	case tq.jobChannel <- jobID:
+	tq.queueMutex.Lock()
+	defer tq.queueMutex.Unlock()
+
+	if _, exists := tq.queued[jobID]; exists {
 		return nil
-	case <-tq.ctx.Done():
-		return fmt.Errorf("queue is shutting down")
-	default:
+	}
+	if len(tq.queued) >= queueCapacity {
 		return fmt.Errorf("queue is full")
 	}
+	tq.queued[jobID] = time.Now()
+	tq.signal()
+	return nil
 }
 
-// worker processes jobs from the channel
+// signal wakes one idle worker without blocking
+func (tq *TaskQueue) signal() {
+	select {
+	case tq.wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker processes jobs picked from the queue
 func (tq *TaskQueue) worker(id int) {
 	defer tq.wg.Done()
 
 	logger.Debug("Worker started", "worker_id", id)
 
 	for {
-		select {
-		case jobID, ok := <-tq.jobChannel:
-			if !ok {
-				logger.Debug("Worker stopped", "worker_id", id)
+		if tq.ctx.Err() != nil {
+			logger.Debug("Worker stopped", "worker_id", id, "reason", "context_cancelled")
+			return
+		}
+
+		job, ok := tq.nextJob()
+		if !ok {
+			select {
+			case <-tq.wake:
+				continue
+			case <-tq.ctx.Done():
+				logger.Debug("Worker stopped", "worker_id", id, "reason", "context_cancelled")
 				return
 			}
+		}
 
-			logger.WorkerOperation(id, jobID, "start")
+		tq.processJob(id, job)
+	}
+}
 
-			// Update job status to processing
-			if err := tq.updateJobStatus(jobID, models.StatusProcessing); err != nil {
-				logger.Error("Failed to update job status", "worker_id", id, "job_id", jobID, "error", err)
-				continue
-			}
+// processJob runs a single job picked by a worker
+func (tq *TaskQueue) processJob(id int, job queuedJob) {
+	jobID := job.ID
+	logger.WorkerOperation(id, jobID, "start")
+
+	// Update job status to processing and count the attempt
+	attempt, err := tq.startAttempt(jobID)
+	if err != nil {
+		logger.Error("Failed to update job status", "worker_id", id, "job_id", jobID, "error", err)
+		return
+	}
+	webhooks.EmitJobEvent(webhooks.EventJobProcessing, jobID)
+
+	// Create context for this job and track it
+	jobCtx, jobCancel := context.WithCancel(tq.ctx)
+	defer jobCancel()
+	runningJob := &RunningJob{
+		Cancel:   jobCancel,
+		Process:  nil, // Will be set by registerProcess callback
+		UserID:   job.UserID,
+		MemoryMB: job.MemoryMB,
+		GPU:      job.GPU,
+	}
 
-			// Create context for this job and track it
-			jobCtx, jobCancel := context.WithCancel(tq.ctx)
-			runningJob := &RunningJob{
-				Cancel:  jobCancel,
-				Process: nil, // Will be set by registerProcess callback
+	tq.jobsMutex.Lock()
+	tq.runningJobs[jobID] = runningJob
+	tq.jobsMutex.Unlock()
+
+	// Register process callback
+	registerProcess := func(cmd *exec.Cmd) {
+		tq.jobsMutex.Lock()
+		if job, exists := tq.runningJobs[jobID]; exists {
+			job.Process = cmd
+		}
+		tq.jobsMutex.Unlock()
+	}
+
+	// Process the job with process registration
+	err = tq.processor.ProcessJobWithProcess(jobCtx, jobID, registerProcess)
+
+	// Remove job from running jobs; its memory may let a waiting job in
+	tq.jobsMutex.Lock()
+	delete(tq.runningJobs, jobID)
+	tq.jobsMutex.Unlock()
+	tq.signal()
+
+	// Handle result
+	if err != nil {
+		if jobCtx.Err() == context.Canceled {
+			logger.Info("Job cancelled", "worker_id", id, "job_id", jobID)
+			tq.failJob(jobID, err, "Job was cancelled by user")
+		} else {
+			class := ClassifyFailure(err)
+			tq.recordFailureClass(jobID, attempt, class)
+			if tq.scheduleRetry(jobID, attempt, class, err) {
+				return
 			}
+			logger.Error("Job processing failed", "worker_id", id, "job_id", jobID, "attempt", attempt, "failure_class", class, "error", err)
+			tq.failJob(jobID, err, err.Error())
+		}
+		webhooks.EmitJobEvent(webhooks.EventJobFailed, jobID)
+	} else {
+		logger.Debug("Job processed successfully", "worker_id", id, "job_id", jobID)
+		tq.updateJobStatus(jobID, models.StatusCompleted)
+		webhooks.EmitJobEvent(webhooks.EventJobCompleted, jobID)
+	}
+}
+
+// failJob records a failure that won't be retried. A job whose re-diarization failed still has
+// its transcript, so it goes back to the status it had before the run instead of failing.
+func (tq *TaskQueue) failJob(jobID string, cause error, message string) {
+	status := models.StatusFailed
+	if errors.Is(cause, ErrRediarizeFailed) {
+		var job models.TranscriptionJob
+		if err := database.DB.Select("previous_status").Where("id = ?", jobID).First(&job).Error; err == nil && job.PreviousStatus != nil {
+			status = *job.PreviousStatus
+		} else {
+			status = models.StatusCompleted
+		}
+	}
+	tq.updateJobStatus(jobID, status)
+	tq.updateJobError(jobID, message)
+}
+
+// startAttempt marks a job as processing and returns its attempt number
+func (tq *TaskQueue) startAttempt(jobID string) (int, error) {
+	err := database.DB.Model(&models.TranscriptionJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{
+			"status":          models.StatusProcessing,
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nil,
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Select("attempts").Where("id = ?", jobID).First(&job).Error; err != nil || job.Attempts < 1 {
+		return 1, nil
+	}
+	return job.Attempts, nil
+}
+
+// recordFailureClass stores why an attempt failed on its execution record
+func (tq *TaskQueue) recordFailureClass(jobID string, attempt int, class string) {
+	database.DB.Model(&models.TranscriptionJobExecution{}).
+		Where("transcription_job_id = ? AND attempt = ? AND status = ?", jobID, attempt, models.StatusFailed).
+		Update("failure_class", class)
+}
 
-			tq.jobsMutex.Lock()
-			tq.runningJobs[jobID] = runningJob
-			tq.jobsMutex.Unlock()
-
-			// Register process callback
-			registerProcess := func(cmd *exec.Cmd) {
-				tq.jobsMutex.Lock()
-				if job, exists := tq.runningJobs[jobID]; exists {
-					job.Process = cmd
-				}
-				tq.jobsMutex.Unlock()
+// scheduleRetry puts a failed job back in the queue after a backoff when the failure is transient
+// and attempts remain. Out-of-memory failures retry with lighter parameters if the policy allows.
+func (tq *TaskQueue) scheduleRetry(jobID string, attempt int, class string, cause error) bool {
+	policy := tq.retryPolicy
+	if !IsTransientFailure(class) || attempt >= policy.MaxAttempts || tq.ctx.Err() != nil {
+		return false
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		return false
+	}
+
+	delay := policy.Delay(attempt)
+	retryAt := time.Now().Add(delay)
+	message := fmt.Sprintf("Attempt %d of %d failed (%s), retrying at %s: %v",
+		attempt, policy.MaxAttempts, class, retryAt.Format(time.RFC3339), cause)
+	updates := map[string]interface{}{
+		"status":          models.StatusPending,
+		"error_message":   message,
+		"next_attempt_at": retryAt,
+	}
+
+	if class == FailureOutOfMemory && policy.OOMFallback {
+		if params, ok := ReduceMemory(job.Parameters); ok {
+			updates["batch_size"] = params.BatchSize
+			updates["compute_type"] = params.ComputeType
+			logger.Info("Retrying with reduced memory settings", "job_id", jobID,
+				"batch_size", params.BatchSize, "compute_type", params.ComputeType)
+		}
+	}
+
+	if err := database.DB.Model(&job).Updates(updates).Error; err != nil {
+		logger.Error("Failed to schedule retry", "job_id", jobID, "error", err)
+		return false
+	}
+
+	logger.Warn("Job attempt failed, retry scheduled", "job_id", jobID, "attempt", attempt,
+		"max_attempts", policy.MaxAttempts, "failure_class", class, "retry_in", delay, "error", cause)
+	webhooks.EmitJobEvent(webhooks.EventJobRetrying, jobID)
+
+	// Wait in the queue until the backoff has passed; the scanner requeues it if the queue is full
+	if err := tq.EnqueueJob(jobID); err == nil {
+		time.AfterFunc(delay, tq.signal)
+	}
+	return true
+}
+
+// nextJob removes and returns the job a free worker should run next. When that job doesn't fit
+// in the resource budget nothing is returned: smaller jobs don't overtake it, so it can't starve.
+func (tq *TaskQueue) nextJob() (queuedJob, bool) {
+	tq.queueMutex.Lock()
+	defer tq.queueMutex.Unlock()
+
+	jobs := dueJobs(tq.waitingJobs(), time.Now())
+	if len(jobs) == 0 {
+		return queuedJob{}, false
+	}
+
+	next := jobs[pickNext(jobs, tq.runningPerUser())]
+	if !tq.budget.admits(tq.resourceUsage(), next) {
+		return queuedJob{}, false
+	}
+	delete(tq.queued, next.ID)
+	if len(jobs) > 1 {
+		// Let another idle worker take the rest
+		tq.signal()
+	}
+	return next, true
+}
+
+// dueJobs filters out jobs whose retry backoff hasn't passed yet
+func dueJobs(jobs []queuedJob, now time.Time) []queuedJob {
+	due := jobs[:0:0]
+	for _, job := range jobs {
+		if !job.NotBefore.After(now) {
+			due = append(due, job)
+		}
+	}
+	return due
+}
+
+// waitingJobColumns are the columns the scheduler and the resource estimators read
+var waitingJobColumns = []string{
+	"id", "user_id", "priority", "status", "next_attempt_at",
+	"model_family", "model", "device", "compute_type", "diarize", "diarize_model",
+}
+
+// waitingJobs loads the scheduling fields of every queued job. Jobs that are no longer
+// pending (deleted, cancelled or picked up after a duplicate enqueue) are dropped.
+// IDs without a database row are kept with default priority. Callers hold queueMutex.
+func (tq *TaskQueue) waitingJobs() []queuedJob {
+	if len(tq.queued) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(tq.queued))
+	for id := range tq.queued {
+		ids = append(ids, id)
+	}
+
+	var rows []models.TranscriptionJob
+	if err := database.DB.Select(waitingJobColumns).Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		logger.Error("Failed to load queued jobs", "error", err)
+	}
+	stored := make(map[string]models.TranscriptionJob, len(rows))
+	for _, row := range rows {
+		stored[row.ID] = row
+	}
+
+	jobs := make([]queuedJob, 0, len(ids))
+	for _, id := range ids {
+		job := queuedJob{ID: id, QueuedAt: tq.queued[id]}
+		if row, ok := stored[id]; ok {
+			if row.Status != models.StatusPending {
+				delete(tq.queued, id)
+				continue
 			}
+			job.UserID = row.UserID
+			job.Priority = row.Priority
+			if row.NextAttemptAt != nil {
+				job.NotBefore = *row.NextAttemptAt
+			}
+			tq.estimateResources(&job, row.Parameters)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs
+}
+
+// runningPerUser counts the jobs each user currently has running
+func (tq *TaskQueue) runningPerUser() map[uint]int {
+	tq.jobsMutex.RLock()
+	defer tq.jobsMutex.RUnlock()
 
-			// Process the job with process registration
-			err := tq.processor.ProcessJobWithProcess(jobCtx, jobID, registerProcess)
-
-			// Remove job from running jobs
-			tq.jobsMutex.Lock()
-			delete(tq.runningJobs, jobID)
-			tq.jobsMutex.Unlock()
-
-			// Handle result
-			if err != nil {
-				if jobCtx.Err() == context.Canceled {
-					logger.Info("Job cancelled", "worker_id", id, "job_id", jobID)
-					tq.updateJobStatus(jobID, models.StatusFailed)
-					tq.updateJobError(jobID, "Job was cancelled by user")
-				} else {
-					logger.Error("Job processing failed", "worker_id", id, "job_id", jobID, "error", err)
-					tq.updateJobStatus(jobID, models.StatusFailed)
-					tq.updateJobError(jobID, err.Error())
-				}
-			} else {
-				logger.Debug("Job processed successfully", "worker_id", id, "job_id", jobID)
-				tq.updateJobStatus(jobID, models.StatusCompleted)
+	counts := make(map[uint]int)
+	for _, job := range tq.runningJobs {
+		counts[job.UserID]++
+	}
+	return counts
+}
+
+// pickNext returns the index of the job to run next. Higher priority always wins. Within a
+// priority, the user with the fewest jobs running goes first, so one user's backlog can't
+// starve everyone else, and jobs that have waited longer go before newer ones.
+func pickNext(jobs []queuedJob, running map[uint]int) int {
+	best := 0
+	for i := 1; i < len(jobs); i++ {
+		a, b := jobs[i], jobs[best]
+		switch {
+		case a.Priority != b.Priority:
+			if a.Priority > b.Priority {
+				best = i
+			}
+		case running[a.UserID] != running[b.UserID]:
+			if running[a.UserID] < running[b.UserID] {
+				best = i
 			}
+		case !a.QueuedAt.Equal(b.QueuedAt):
+			if a.QueuedAt.Before(b.QueuedAt) {
+				best = i
+			}
+		case a.ID < b.ID:
+			best = i
+		}
+	}
+	return best
+}
 
-		case <-tq.ctx.Done():
-			logger.Debug("Worker stopped", "worker_id", id, "reason", "context_cancelled")
-			return
+// scheduleOrder returns the jobs in the order workers are expected to pick them, assuming
+// each picked job keeps running while the rest are scheduled
+func scheduleOrder(jobs []queuedJob, running map[uint]int) []queuedJob {
+	remaining := append([]queuedJob(nil), jobs...)
+	order := make([]queuedJob, 0, len(jobs))
+	for len(remaining) > 0 {
+		i := pickNext(remaining, running)
+		order = append(order, remaining[i])
+		running[remaining[i].UserID]++
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return order
+}
+
+// QueuedJobs returns the waiting jobs in their estimated order. Jobs backing off before a
+// retry are listed after the rest, in the order their backoff ends.
+func (tq *TaskQueue) QueuedJobs() []QueuedJobInfo {
+	tq.queueMutex.Lock()
+	jobs := tq.waitingJobs()
+	tq.queueMutex.Unlock()
+
+	now := time.Now()
+	order := scheduleOrder(dueJobs(jobs, now), tq.runningPerUser())
+	var deferred []queuedJob
+	for _, job := range jobs {
+		if job.NotBefore.After(now) {
+			deferred = append(deferred, job)
+		}
+	}
+	sort.Slice(deferred, func(i, j int) bool { return deferred[i].NotBefore.Before(deferred[j].NotBefore) })
+	order = append(order, deferred...)
+
+	infos := make([]QueuedJobInfo, 0, len(order))
+	for i, job := range order {
+		info := QueuedJobInfo{
+			JobID:    job.ID,
+			UserID:   job.UserID,
+			Priority: job.Priority,
+			Position: i + 1,
+			QueuedAt: job.QueuedAt,
+		}
+		if job.NotBefore.After(now) {
+			retryAt := job.NotBefore
+			info.RetryAt = &retryAt
 		}
+		infos = append(infos, info)
 	}
+	return infos
+}
+
+// QueuePosition returns the estimated position of a waiting job, or 0 if it isn't queued
+func (tq *TaskQueue) QueuePosition(jobID string) int {
+	for _, info := range tq.QueuedJobs() {
+		if info.JobID == jobID {
+			return info.Position
+		}
+	}
+	return 0
 }
 
 // jobScanner scans for pending jobs and adds them to the queue
@@ -257,17 +613,20 @@ func (tq *TaskQueue) jobScanner() {
 func (tq *TaskQueue) scanPendingJobs() {
 	var jobs []models.TranscriptionJob
 
-	if err := database.DB.Where("status = ?", models.StatusPending).Find(&jobs).Error; err != nil {
+	// Oldest first, so jobs left over from a restart keep their relative order
+	if err := database.DB.Select("id").
+		Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", models.StatusPending, time.Now()).
+		Order("updated_at ASC").Find(&jobs).Error; err != nil {
 		logger.Error("Failed to scan pending jobs", "error", err)
 		return
 	}
 
 	for _, job := range jobs {
-		select {
-		case tq.jobChannel <- job.ID:
-			logger.Debug("Enqueued pending job", "job_id", job.ID)
-		default:
-			logger.Warn("Queue full, skipping job", "job_id", job.ID)
+		if tq.IsJobRunning(job.ID) {
+			continue
+		}
+		if err := tq.EnqueueJob(job.ID); err != nil {
+			logger.Warn("Failed to enqueue pending job", "job_id", job.ID, "error", err)
 			break
 		}
 	}
@@ -376,15 +735,16 @@ func (tq *TaskQueue) checkAndScale() {
 		return
 	}
 
-	queueSize := len(tq.jobChannel)
+	queueSize := tq.queueSize()
 	currentWorkers := int(atomic.LoadInt64(&tq.currentWorkers))
 	
 	tq.jobsMutex.RLock()
 	runningJobsCount := len(tq.runningJobs)
 	tq.jobsMutex.RUnlock()
 
-	// Scale up if queue is building up and we have capacity
-	if queueSize > 10 && currentWorkers < tq.maxWorkers {
+	// Scale up if queue is building up and we have capacity. Extra workers are pointless
+	// while the next job is held back by the resource budget.
+	if queueSize > 10 && currentWorkers < tq.maxWorkers && tq.canAdmitNext() {
 		newWorkerCount := currentWorkers + 1
 		log.Printf("Scaling up workers: %d -> %d (queue size: %d)", currentWorkers, newWorkerCount, queueSize)
 		
@@ -416,13 +776,34 @@ func (tq *TaskQueue) GetQueueStats() map[string]interface{} {
 	database.DB.Model(&models.TranscriptionJob{}).Where("status = ?", models.StatusCompleted).Count(&completedCount)
 	database.DB.Model(&models.TranscriptionJob{}).Where("status = ?", models.StatusFailed).Count(&failedCount)
 
+	// Backlog per priority, including pending jobs the scanner hasn't queued yet
+	var backlog []struct {
+		Priority int
+		Count    int64
+	}
+	database.DB.Model(&models.TranscriptionJob{}).
+		Select("priority, COUNT(*) AS count").
+		Where("status = ?", models.StatusPending).
+		Group("priority").
+		Scan(&backlog)
+	pendingByPriority := make(map[int]int64, len(backlog))
+	for _, row := range backlog {
+		pendingByPriority[row.Priority] = row.Count
+	}
+
+	// Failed jobs waiting out a retry backoff
+	var retryScheduledCount int64
+	database.DB.Model(&models.TranscriptionJob{}).
+		Where("status = ? AND next_attempt_at > ?", models.StatusPending, time.Now()).
+		Count(&retryScheduledCount)
+
 	tq.jobsMutex.RLock()
 	runningJobsCount := len(tq.runningJobs)
 	tq.jobsMutex.RUnlock()
 
 	return map[string]interface{}{
-		"queue_size":       len(tq.jobChannel),
-		"queue_capacity":   cap(tq.jobChannel),
+		"queue_size":       tq.queueSize(),
+		"queue_capacity":   queueCapacity,
 		"current_workers":  int(atomic.LoadInt64(&tq.currentWorkers)),
 		"min_workers":      tq.minWorkers,
 		"max_workers":      tq.maxWorkers,
@@ -432,5 +813,28 @@ func (tq *TaskQueue) GetQueueStats() map[string]interface{} {
 		"processing_jobs":  processingCount,
 		"completed_jobs":   completedCount,
 		"failed_jobs":      failedCount,
+		"retry_scheduled_jobs": retryScheduledCount,
+		"pending_by_priority": pendingByPriority,
+		"resources":           tq.resourceStats(),
+		"queued_jobs":         tq.QueuedJobs(),
 	}
 }
+
+// canAdmitNext reports whether the next due job fits in the resource budget
+func (tq *TaskQueue) canAdmitNext() bool {
+	tq.queueMutex.Lock()
+	defer tq.queueMutex.Unlock()
+
+	jobs := dueJobs(tq.waitingJobs(), time.Now())
+	if len(jobs) == 0 {
+		return false
+	}
+	return tq.budget.admits(tq.resourceUsage(), jobs[pickNext(jobs, tq.runningPerUser())])
+}
+
+// queueSize returns the number of jobs waiting for a worker
+func (tq *TaskQueue) queueSize() int {
+	tq.queueMutex.Lock()
+	defer tq.queueMutex.Unlock()
+	return len(tq.queued)
+}
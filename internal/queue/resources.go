@@ -0,0 +1,75 @@
+package queue
+
+import (
+	"synthezia/internal/models"
+)
+
+// ResourceEstimator is implemented by processors that can tell how much memory a job will need
+type ResourceEstimator interface {
+	EstimateResources(params models.WhisperXParams) (memoryMB int, gpu bool)
+}
+
+// ResourceBudget caps the memory used by running jobs. GPU jobs count against VRAM, others
+// against RAM. A zero limit means unlimited.
+type ResourceBudget struct {
+	RAMMB  int
+	VRAMMB int
+}
+
+// resourceUsage is the memory held by the running jobs
+type resourceUsage struct {
+	RAMMB    int
+	VRAMMB   int
+	RAMJobs  int
+	VRAMJobs int
+}
+
+// admits reports whether job fits next to the running jobs. A job always fits when nothing else
+// is using its pool, so a model larger than the whole budget still runs, just on its own.
+func (b ResourceBudget) admits(usage resourceUsage, job queuedJob) bool {
+	if job.GPU {
+		return b.VRAMMB <= 0 || usage.VRAMJobs == 0 || usage.VRAMMB+job.MemoryMB <= b.VRAMMB
+	}
+	return b.RAMMB <= 0 || usage.RAMJobs == 0 || usage.RAMMB+job.MemoryMB <= b.RAMMB
+}
+
+// SetResourceBudget sets the memory budget jobs are admitted against. Call it before Start.
+func (tq *TaskQueue) SetResourceBudget(budget ResourceBudget) {
+	tq.budget = budget
+}
+
+// estimateResources fills in a queued job's memory needs when the processor can estimate them
+func (tq *TaskQueue) estimateResources(job *queuedJob, params models.WhisperXParams) {
+	if estimator, ok := tq.processor.(ResourceEstimator); ok {
+		job.MemoryMB, job.GPU = estimator.EstimateResources(params)
+	}
+}
+
+// resourceUsage sums the memory held by the running jobs
+func (tq *TaskQueue) resourceUsage() resourceUsage {
+	tq.jobsMutex.RLock()
+	defer tq.jobsMutex.RUnlock()
+
+	var usage resourceUsage
+	for _, job := range tq.runningJobs {
+		if job.GPU {
+			usage.VRAMMB += job.MemoryMB
+			usage.VRAMJobs++
+		} else {
+			usage.RAMMB += job.MemoryMB
+			usage.RAMJobs++
+		}
+	}
+	return usage
+}
+
+// resourceStats reports the budget and how much of it is in use
+func (tq *TaskQueue) resourceStats() map[string]interface{} {
+	usage := tq.resourceUsage()
+	return map[string]interface{}{
+		"ram_budget_mb":  tq.budget.RAMMB,
+		"ram_in_use_mb":  usage.RAMMB,
+		"vram_budget_mb": tq.budget.VRAMMB,
+		"vram_in_use_mb": usage.VRAMMB,
+	}
+}
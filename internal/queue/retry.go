@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"strings"
+	"time"
+
+	"synthezia/internal/models"
+)
+
+// Failure classes recorded on TranscriptionJobExecution.FailureClass
+const (
+	FailureOutOfMemory       = "out_of_memory"
+	FailureProcessKilled     = "process_killed"
+	FailureModelDownload     = "model_download"
+	FailureUnsupportedAudio  = "unsupported_audio"
+	FailureInvalidParameters = "invalid_parameters"
+	FailureUnknown           = "unknown"
+)
+
+// failurePatterns maps failure classes to lower-case fragments of the error output that identify them.
+// Classes are checked in order, so transient causes win over incidental warnings in the same output.
+var failurePatterns = []struct {
+	class    string
+	patterns []string
+}{
+	{FailureOutOfMemory, []string{
+		"out of memory", "outofmemoryerror", "cannot allocate memory", "memoryerror",
+		"std::bad_alloc", "oom-kill", "oom killer",
+	}},
+	{FailureModelDownload, []string{
+		"failed to download", "connectionerror", "connection error", "couldn't connect", "could not connect",
+		"max retries exceeded", "read timed out", "temporary failure in name resolution",
+		"localentrynotfounderror", "incomplete download",
+	}},
+	{FailureProcessKilled, []string{
+		"signal: killed", "signal: terminated", "exit status 137", "exit status 143",
+	}},
+	{FailureUnsupportedAudio, []string{
+		"invalid data found when processing input", "unsupported audio", "unsupported format",
+		"does not contain any stream", "could not find codec", "failed to load audio",
+	}},
+	{FailureInvalidParameters, []string{
+		"invalid parameter", "invalid value", "invalid choice", "unrecognized arguments",
+		"unsupported model", "unknown model", "validation failed",
+	}},
+}
+
+// ClassifyFailure works out why a job failed from its error message
+func ClassifyFailure(err error) string {
+	if err == nil {
+		return FailureUnknown
+	}
+	message := strings.ToLower(err.Error())
+	for _, entry := range failurePatterns {
+		for _, pattern := range entry.patterns {
+			if strings.Contains(message, pattern) {
+				return entry.class
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// IsTransientFailure reports whether a failure class is worth retrying. Unsupported audio and bad
+// parameters fail the same way every time, and unknown failures aren't retried to be safe.
+func IsTransientFailure(class string) bool {
+	switch class {
+	case FailureOutOfMemory, FailureProcessKilled, FailureModelDownload:
+		return true
+	}
+	return false
+}
+
+// RetryPolicy controls automatic retries of failed jobs
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts, including the first; 1 disables retries
+	Backoff     time.Duration // delay before the first retry, doubled for each further one
+	MaxBackoff  time.Duration
+	OOMFallback bool // retry out-of-memory failures with a smaller batch size, then int8
+}
+
+// DefaultRetryPolicy returns the policy used unless SetRetryPolicy is called
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     30 * time.Second,
+		MaxBackoff:  10 * time.Minute,
+		OOMFallback: true,
+	}
+}
+
+// Delay returns how long to wait before the attempt after the given (1-based) failed attempt
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// ReduceMemory returns params adjusted to need less memory after an out-of-memory failure:
+// the batch size is halved, and once it can't shrink further the compute type drops to int8.
+// It returns false when there is nothing left to reduce.
+func ReduceMemory(params models.WhisperXParams) (models.WhisperXParams, bool) {
+	if params.BatchSize > 1 {
+		params.BatchSize /= 2
+		return params, true
+	}
+	if params.ComputeType != "int8" {
+		params.ComputeType = "int8"
+		return params, true
+	}
+	return params, false
+}
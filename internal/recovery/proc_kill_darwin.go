@@ -0,0 +1,35 @@
+//go:build darwin
+// +build darwin
+
+package recovery
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// killProcessGroup sends SIGKILL to a leftover process group on macOS. It reports false when
+// the group no longer exists, and refuses to kill a group whose leader can't be confirmed to be
+// a model process (the PID may have been reused since the crash).
+func killProcessGroup(pid int) (bool, error) {
+	if pid <= 1 {
+		return false, nil
+	}
+	if err := syscall.Kill(-pid, 0); err != nil {
+		return false, nil
+	}
+	command, err := exec.Command("ps", "-o", "command=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to check process %d: %w", pid, err)
+	}
+	if !isModelProcess([]byte(strings.Join(strings.Fields(string(command)), "\x00"))) {
+		return false, fmt.Errorf("process %d is not a model process", pid)
+	}
+	if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
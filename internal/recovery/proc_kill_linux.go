@@ -0,0 +1,29 @@
+//go:build linux
+// +build linux
+
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// killProcessGroup sends SIGKILL to a leftover process group on Linux. It reports false when
+// the group no longer exists, and refuses to kill a group whose leader is not a model process
+// (the PID may have been reused since the crash).
+func killProcessGroup(pid int) (bool, error) {
+	if pid <= 1 {
+		return false, nil
+	}
+	if err := syscall.Kill(-pid, 0); err != nil {
+		return false, nil
+	}
+	if cmdline, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid)); err == nil && !isModelProcess(cmdline) {
+		return false, fmt.Errorf("process %d is not a model process", pid)
+	}
+	if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,10 @@
+//go:build windows
+// +build windows
+
+package recovery
+
+// killProcessGroup is a no-op on Windows, where model processes are not started in
+// their own process group.
+func killProcessGroup(pid int) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,269 @@
+package recovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"synthezia/internal/config"
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
+	"synthezia/pkg/logger"
+)
+
+// InterruptedMessage is the error recorded on executions cut short by a restart
+const InterruptedMessage = "Interrupted by server restart"
+
+// Recovery actions recorded on TranscriptionJobExecution.RecoveryAction
+const (
+	ActionRequeued = "requeued"
+	ActionFailed   = "failed"
+)
+
+// trackJobPrefix marks the temporary per-track jobs created by multi-track transcription
+const trackJobPrefix = "track_"
+
+// Options controls startup reconciliation
+type Options struct {
+	Policy      string // config.RecoveryPolicyRequeue or config.RecoveryPolicyFail
+	MaxRequeues int    // interrupted runs after which a job is failed even when requeueing
+	UploadDir   string
+	TempDir     string // adapter working directories, laid out as <TempDir>/<model>/<job>
+}
+
+// Report summarizes what recovery did
+type Report struct {
+	Requeued        []string `json:"requeued"`
+	Failed          []string `json:"failed"`
+	KilledProcesses []int    `json:"killed_processes"`
+	RemovedPaths    []string `json:"removed_paths"`
+}
+
+// Run reconciles state left behind by a previous process. It must run before the task queue
+// starts: every job still marked processing is treated as orphaned.
+func Run(opts Options) (*Report, error) {
+	report := &Report{}
+
+	var jobs []models.TranscriptionJob
+	if err := database.DB.Where("status = ?", models.StatusProcessing).Find(&jobs).Error; err != nil {
+		return report, fmt.Errorf("failed to find processing jobs: %w", err)
+	}
+
+	// Temporary multi-track jobs are never resumed; their parent job is recovered instead
+	var parents, tracks []models.TranscriptionJob
+	for _, job := range jobs {
+		if strings.HasPrefix(job.ID, trackJobPrefix) {
+			tracks = append(tracks, job)
+		} else {
+			parents = append(parents, job)
+		}
+	}
+
+	for i := range parents {
+		if err := recoverJob(&parents[i], opts, report); err != nil {
+			logger.Error("Failed to recover job", "job_id", parents[i].ID, "error", err)
+		}
+	}
+	for _, track := range tracks {
+		// Normally already killed with the parent; covers tracks whose parent isn't processing
+		report.KilledProcesses = append(report.KilledProcesses, killExecutionProcesses(track.ID)...)
+		deleteTrackJob(track.ID)
+	}
+
+	report.RemovedPaths = append(report.RemovedPaths, cleanStaleFiles(opts)...)
+
+	if len(report.Requeued)+len(report.Failed)+len(report.KilledProcesses)+len(report.RemovedPaths) > 0 {
+		logger.Info("Recovered from previous run",
+			"requeued", len(report.Requeued),
+			"failed", len(report.Failed),
+			"killed_processes", len(report.KilledProcesses),
+			"removed_paths", len(report.RemovedPaths))
+	}
+	return report, nil
+}
+
+// recoverJob kills the job's leftover processes, removes its working files and requeues or fails it
+func recoverJob(job *models.TranscriptionJob, opts Options, report *Report) error {
+	var details []string
+
+	killed := killExecutionProcesses(job.ID)
+	for _, pid := range killed {
+		details = append(details, fmt.Sprintf("killed process group %d", pid))
+	}
+	report.KilledProcesses = append(report.KilledProcesses, killed...)
+
+	removed := removeJobFiles(job.ID, opts)
+	for _, path := range removed {
+		details = append(details, "removed "+path)
+	}
+	report.RemovedPaths = append(report.RemovedPaths, removed...)
+
+	action := ActionFailed
+	if opts.Policy != config.RecoveryPolicyFail {
+		var requeues int64
+		database.DB.Model(&models.TranscriptionJobExecution{}).
+			Where("transcription_job_id = ? AND recovery_action = ?", job.ID, ActionRequeued).
+			Count(&requeues)
+		if int(requeues) < opts.MaxRequeues {
+			action = ActionRequeued
+			details = append(details, fmt.Sprintf("requeued (%d of %d)", requeues+1, opts.MaxRequeues))
+		} else {
+			details = append(details, fmt.Sprintf("failed after %d interrupted runs", requeues+1))
+		}
+	} else {
+		details = append(details, "failed by recovery policy")
+	}
+
+	if err := recordRecovery(job, action, strings.Join(details, "; ")); err != nil {
+		return err
+	}
+
+	if action == ActionRequeued {
+		report.Requeued = append(report.Requeued, job.ID)
+		logger.Info("Requeued interrupted job", "job_id", job.ID)
+		return database.DB.Model(job).Updates(map[string]interface{}{
+			"status":        models.StatusPending,
+			"error_message": nil,
+		}).Error
+	}
+
+	report.Failed = append(report.Failed, job.ID)
+	logger.Warn("Failed interrupted job", "job_id", job.ID)
+	if err := database.DB.Model(job).Updates(map[string]interface{}{
+		"status":        models.StatusFailed,
+		"error_message": InterruptedMessage,
+	}).Error; err != nil {
+		return err
+	}
+	webhooks.EmitJobEvent(webhooks.EventJobFailed, job.ID)
+	return nil
+}
+
+// recordRecovery closes the job's interrupted executions, or adds one if the crash happened before it was created
+func recordRecovery(job *models.TranscriptionJob, action, details string) error {
+	now := time.Now()
+	message := InterruptedMessage
+
+	var executions []models.TranscriptionJobExecution
+	if err := database.DB.Where("transcription_job_id = ? AND status = ?", job.ID, models.StatusProcessing).
+		Find(&executions).Error; err != nil {
+		return fmt.Errorf("failed to find interrupted executions: %w", err)
+	}
+
+	if len(executions) == 0 {
+		executions = append(executions, models.TranscriptionJobExecution{
+			TranscriptionJobID: job.ID,
+			StartedAt:          job.UpdatedAt,
+			ActualParameters:   job.Parameters,
+		})
+	}
+
+	for i := range executions {
+		execution := &executions[i]
+		execution.Status = models.StatusFailed
+		execution.CompletedAt = &now
+		execution.ErrorMessage = &message
+		execution.RecoveryAction = &action
+		execution.RecoveryDetails = &details
+		execution.CalculateProcessingDuration()
+		if err := database.DB.Save(execution).Error; err != nil {
+			return fmt.Errorf("failed to record recovery: %w", err)
+		}
+	}
+	return nil
+}
+
+// killExecutionProcesses kills the process groups recorded on the interrupted executions of a
+// job and of its temporary track jobs
+func killExecutionProcesses(jobID string) []int {
+	var executions []models.TranscriptionJobExecution
+	database.DB.Where("(transcription_job_id = ? OR transcription_job_id LIKE ?) AND status = ? AND process_pid IS NOT NULL",
+		jobID, trackJobPrefix+jobID+"_%", models.StatusProcessing).
+		Find(&executions)
+
+	var killed []int
+	for _, execution := range executions {
+		pid := *execution.ProcessPID
+		ok, err := killProcessGroup(pid)
+		if err != nil {
+			logger.Warn("Failed to kill leftover process group", "job_id", jobID, "pid", pid, "error", err)
+			continue
+		}
+		if ok {
+			logger.Info("Killed leftover process group", "job_id", jobID, "pid", pid)
+			killed = append(killed, pid)
+		}
+	}
+	return killed
+}
+
+// deleteTrackJob removes a temporary multi-track job and its records
+func deleteTrackJob(jobID string) {
+	database.DB.Where("transcription_job_id = ?", jobID).Delete(&models.TranscriptionJobExecution{})
+	database.DB.Where("transcription_job_id = ?", jobID).Delete(&models.SpeakerMapping{})
+	if err := database.DB.Delete(&models.TranscriptionJob{}, "id = ?", jobID).Error; err != nil {
+		logger.Warn("Failed to delete temporary track job", "job_id", jobID, "error", err)
+	}
+}
+
+// removeJobFiles removes the working directories and half-written uploads of a job
+func removeJobFiles(jobID string, opts Options) []string {
+	var patterns []string
+	if opts.TempDir != "" {
+		patterns = append(patterns,
+			filepath.Join(opts.TempDir, "*", jobID),
+			filepath.Join(opts.TempDir, "*", trackJobPrefix+jobID+"_*"))
+	}
+	if opts.UploadDir != "" {
+		patterns = append(patterns, filepath.Join(opts.UploadDir, jobID+"_temp.*"))
+	}
+	return removeMatches(patterns)
+}
+
+// cleanStaleFiles removes working files no running job can own, since nothing is running yet
+func cleanStaleFiles(opts Options) []string {
+	var patterns []string
+	if opts.TempDir != "" {
+		patterns = append(patterns, filepath.Join(opts.TempDir, "*", "*"))
+	}
+	if opts.UploadDir != "" {
+		patterns = append(patterns,
+			filepath.Join(opts.UploadDir, "*_temp.*"),    // video uploads awaiting audio extraction
+			filepath.Join(opts.UploadDir, "*.part"),      // interrupted YouTube downloads
+			filepath.Join(opts.UploadDir, "openai", "*"), // OpenAI-compatible API uploads
+			filepath.Join(opts.UploadDir, "quick_transcriptions", "*"))
+	}
+	return removeMatches(patterns)
+}
+
+func removeMatches(patterns []string) []string {
+	var removed []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			continue
+		}
+		for _, path := range matches {
+			if err := os.RemoveAll(path); err != nil {
+				logger.Warn("Failed to remove stale file", "path", path, "error", err)
+				continue
+			}
+			removed = append(removed, path)
+		}
+	}
+	return removed
+}
+
+// isModelProcess reports whether a NUL-separated command line belongs to a uv or Python process
+func isModelProcess(cmdline []byte) bool {
+	for _, arg := range strings.Split(string(cmdline), "\x00") {
+		base := filepath.Base(arg)
+		if base == "uv" || strings.HasPrefix(base, "python") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,485 @@
+package retrieval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"synthezia/internal/database"
+	"synthezia/internal/llm"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/vectors"
+	"synthezia/pkg/logger"
+)
+
+// Retrieval modes of a chat session
+const (
+	ModeAuto       = "auto"       // Whole transcripts when they fit, BM25 otherwise
+	ModeFull       = "full"       // Always whole transcripts
+	ModeBM25       = "bm25"       // Passages ranked by keyword relevance
+	ModeEmbeddings = "embeddings" // Passages ranked by embedding similarity
+)
+
+// Size limits of the transcript context given to the model
+const (
+	// FullContextChars is the largest amount of transcript text the auto mode sends whole
+	FullContextChars = 40000
+	// ContextChars is the amount of transcript text retrieval picks passages up to
+	ContextChars = 16000
+	// MaxPassages caps the number of retrieved passages
+	MaxPassages = 12
+
+	maxChunkChars   = 1200
+	maxChunkSeconds = 90.0
+	bm25Candidates  = 200
+	embedBatchSize  = 64
+)
+
+// ValidMode reports whether mode is a known retrieval mode
+func ValidMode(mode string) bool {
+	switch mode {
+	case ModeAuto, ModeFull, ModeBM25, ModeEmbeddings:
+		return true
+	}
+	return false
+}
+
+// Source is a transcript a chat session covers
+type Source struct {
+	JobID        string
+	Title        string
+	CreatedAt    time.Time
+	Transcript   string            // Serialized transcript as stored on the job
+	SpeakerNames map[string]string // Custom names by speaker label
+}
+
+// Chunk is a run of consecutive segments of a transcript
+type Chunk struct {
+	JobID        string
+	Index        int
+	SegmentStart int
+	SegmentEnd   int
+	Start        float64
+	End          float64
+	Text         string
+}
+
+// Passage is a chunk handed to the model, labelled so that answers can cite it
+type Passage struct {
+	Chunk
+	Label string
+	Title string
+}
+
+// Options controls how passages are retrieved
+type Options struct {
+	Mode           string
+	EmbeddingModel string
+	Embedder       llm.Embedder // nil when the LLM provider can't compute embeddings
+}
+
+// ChunkTranscript splits a transcript into chunks of consecutive segments, each at most about
+// maxChunkChars long and maxChunkSeconds of audio. Segment indexes match those of the search index.
+func ChunkTranscript(source Source) ([]Chunk, error) {
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(source.Transcript), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse transcript for job %s: %w", source.JobID, err)
+	}
+
+	var chunks []Chunk
+	var current *Chunk
+	var text strings.Builder
+	flush := func() {
+		if current != nil {
+			current.Text = text.String()
+			chunks = append(chunks, *current)
+			current = nil
+			text.Reset()
+		}
+	}
+
+	for i, segment := range result.Segments {
+		line := strings.TrimSpace(segment.Text)
+		if line == "" {
+			continue
+		}
+		if segment.Speaker != nil && *segment.Speaker != "" {
+			speaker := *segment.Speaker
+			if name, ok := source.SpeakerNames[speaker]; ok {
+				speaker = name
+			}
+			line = speaker + ": " + line
+		}
+
+		if current != nil && (text.Len()+len(line) > maxChunkChars || segment.End-current.Start > maxChunkSeconds) {
+			flush()
+		}
+		if current == nil {
+			current = &Chunk{JobID: source.JobID, Index: len(chunks), SegmentStart: i, Start: segment.Start}
+		} else {
+			text.WriteString("\n")
+		}
+		text.WriteString(line)
+		current.SegmentEnd = i
+		current.End = segment.End
+	}
+	flush()
+
+	// Transcripts without segments are indexed as one segment holding the whole text
+	if len(chunks) == 0 && strings.TrimSpace(result.Text) != "" {
+		for _, piece := range splitText(strings.TrimSpace(result.Text), maxChunkChars) {
+			chunks = append(chunks, Chunk{JobID: source.JobID, Index: len(chunks), Text: piece})
+		}
+	}
+	return chunks, nil
+}
+
+// splitText cuts text into pieces of at most size bytes, preferring to cut at spaces
+func splitText(text string, size int) []string {
+	var pieces []string
+	for len(text) > size {
+		cut := strings.LastIndex(text[:size], " ")
+		if cut <= 0 {
+			cut = size
+		}
+		pieces = append(pieces, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		pieces = append(pieces, text)
+	}
+	return pieces
+}
+
+// scoredChunk is a chunk ranked by a retrieval mode, higher scores first
+type scoredChunk struct {
+	chunk Chunk
+	score float64
+}
+
+// Retrieve picks the passages of sources to answer query with. It returns them in transcript
+// order, labelled S1, S2 and so on, along with the mode that was actually used: the auto mode
+// resolves to full or bm25, and embeddings fall back to bm25 when they are unavailable.
+func Retrieve(ctx context.Context, sources []Source, query string, opts Options) ([]Passage, string, error) {
+	chunksByJob := make(map[string][]Chunk, len(sources))
+	totalChars := 0
+	for _, source := range sources {
+		chunks, err := ChunkTranscript(source)
+		if err != nil {
+			return nil, "", err
+		}
+		chunksByJob[source.JobID] = chunks
+		for _, chunk := range chunks {
+			totalChars += len(chunk.Text)
+		}
+	}
+
+	mode := opts.Mode
+	if mode == "" || mode == ModeAuto {
+		mode = ModeBM25
+		if totalChars <= FullContextChars {
+			mode = ModeFull
+		}
+	}
+
+	var ranked []scoredChunk
+	switch mode {
+	case ModeFull:
+		var all []Chunk
+		for _, source := range sources {
+			all = append(all, chunksByJob[source.JobID]...)
+		}
+		return labelPassages(sources, all), mode, nil
+	case ModeEmbeddings:
+		if opts.Embedder == nil || opts.EmbeddingModel == "" {
+			logger.Warn("Embeddings are not available, falling back to BM25 retrieval", "embedding_model", opts.EmbeddingModel)
+			mode = ModeBM25
+			break
+		}
+		var err error
+		ranked, err = rankByEmbeddings(ctx, sources, chunksByJob, query, opts)
+		if err != nil {
+			logger.Warn("Embedding retrieval failed, falling back to BM25", "error", err)
+			mode = ModeBM25
+		}
+	case ModeBM25:
+	default:
+		return nil, "", fmt.Errorf("unknown retrieval mode: %s", mode)
+	}
+
+	if mode == ModeBM25 {
+		var err error
+		ranked, err = rankByBM25(sources, chunksByJob, query)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Without any match, give the model the start of every transcript instead
+	if len(ranked) == 0 {
+		ranked = leadingChunks(sources, chunksByJob)
+	}
+
+	var picked []Chunk
+	chars := 0
+	for _, candidate := range ranked {
+		if len(picked) == MaxPassages || (len(picked) > 0 && chars+len(candidate.chunk.Text) > ContextChars) {
+			break
+		}
+		picked = append(picked, candidate.chunk)
+		chars += len(candidate.chunk.Text)
+	}
+	return labelPassages(sources, picked), mode, nil
+}
+
+// rankByBM25 ranks chunks by the keyword relevance of their segments in the search index
+func rankByBM25(sources []Source, chunksByJob map[string][]Chunk, query string) ([]scoredChunk, error) {
+	jobIDs := make([]string, 0, len(sources))
+	for _, source := range sources {
+		jobIDs = append(jobIDs, source.JobID)
+	}
+
+	rows, err := search.RankSegments(jobIDs, query, bm25Candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Segment scores are lower for better matches; a chunk scores the sum of its segments
+	scores := make(map[string]map[int]float64)
+	for _, row := range rows {
+		chunk, ok := chunkOfSegment(chunksByJob[row.JobID], row.SegmentIndex)
+		if !ok {
+			continue
+		}
+		if scores[row.JobID] == nil {
+			scores[row.JobID] = make(map[int]float64)
+		}
+		scores[row.JobID][chunk.Index] -= row.Score
+	}
+
+	var ranked []scoredChunk
+	for jobID, byChunk := range scores {
+		for index, score := range byChunk {
+			ranked = append(ranked, scoredChunk{chunk: chunksByJob[jobID][index], score: score})
+		}
+	}
+	sortRanked(ranked)
+	return ranked, nil
+}
+
+// chunkOfSegment finds the chunk holding a segment
+func chunkOfSegment(chunks []Chunk, segmentIndex int) (Chunk, bool) {
+	i := sort.Search(len(chunks), func(i int) bool { return chunks[i].SegmentEnd >= segmentIndex })
+	if i < len(chunks) && chunks[i].SegmentStart <= segmentIndex {
+		return chunks[i], true
+	}
+	return Chunk{}, false
+}
+
+// rankByEmbeddings ranks chunks by the cosine similarity of their embeddings to the query's
+func rankByEmbeddings(ctx context.Context, sources []Source, chunksByJob map[string][]Chunk, query string, opts Options) ([]scoredChunk, error) {
+	queryVectors, err := opts.Embedder.Embed(ctx, opts.EmbeddingModel, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed question: %w", err)
+	}
+
+	var ranked []scoredChunk
+	for _, source := range sources {
+		chunks := chunksByJob[source.JobID]
+		embeddings, err := chunkVectors(ctx, source.JobID, chunks, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i, chunk := range chunks {
+			ranked = append(ranked, scoredChunk{chunk: chunk, score: vectors.Cosine(queryVectors[0], embeddings[i])})
+		}
+	}
+	sortRanked(ranked)
+	return ranked, nil
+}
+
+// chunkVectors returns the embeddings of the chunks of a job, computing and storing them when
+// the stored ones are missing or belong to an older version of the transcript
+func chunkVectors(ctx context.Context, jobID string, chunks []Chunk, opts Options) ([][]float64, error) {
+	hash := sha256.New()
+	for _, chunk := range chunks {
+		hash.Write([]byte(chunk.Text))
+		hash.Write([]byte{0})
+	}
+	transcriptHash := hex.EncodeToString(hash.Sum(nil))
+
+	var stored []models.TranscriptChunkEmbedding
+	if err := database.DB.Where("transcription_job_id = ? AND model = ? AND transcript_hash = ?", jobID, opts.EmbeddingModel, transcriptHash).
+		Order("chunk_index ASC").Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to load chunk embeddings: %w", err)
+	}
+	if len(stored) == len(chunks) {
+		vectors := make([][]float64, len(stored))
+		for i, row := range stored {
+			vectors[i] = row.Vector
+		}
+		return vectors, nil
+	}
+
+	vectors := make([][]float64, 0, len(chunks))
+	for start := 0; start < len(chunks); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(chunks) {
+			end = len(chunks)
+		}
+		inputs := make([]string, 0, end-start)
+		for _, chunk := range chunks[start:end] {
+			inputs = append(inputs, chunk.Text)
+		}
+		batch, err := opts.Embedder.Embed(ctx, opts.EmbeddingModel, inputs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed transcript %s: %w", jobID, err)
+		}
+		if len(batch) != len(inputs) {
+			return nil, fmt.Errorf("embedding provider returned %d vectors for %d chunks", len(batch), len(inputs))
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	rows := make([]models.TranscriptChunkEmbedding, len(chunks))
+	for i := range chunks {
+		rows[i] = models.TranscriptChunkEmbedding{
+			TranscriptionJobID: jobID,
+			Model:              opts.EmbeddingModel,
+			TranscriptHash:     transcriptHash,
+			ChunkIndex:         i,
+			Vector:             vectors[i],
+		}
+	}
+	if err := database.DB.Where("transcription_job_id = ? AND model = ?", jobID, opts.EmbeddingModel).Delete(&models.TranscriptChunkEmbedding{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to clear chunk embeddings: %w", err)
+	}
+	if len(rows) > 0 {
+		if err := database.DB.Create(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to store chunk embeddings: %w", err)
+		}
+	}
+	return vectors, nil
+}
+
+// leadingChunks interleaves the chunks of all transcripts from their start
+func leadingChunks(sources []Source, chunksByJob map[string][]Chunk) []scoredChunk {
+	var ranked []scoredChunk
+	for index := 0; ; index++ {
+		added := false
+		for _, source := range sources {
+			if chunks := chunksByJob[source.JobID]; index < len(chunks) {
+				ranked = append(ranked, scoredChunk{chunk: chunks[index]})
+				added = true
+			}
+		}
+		if !added {
+			return ranked
+		}
+	}
+}
+
+// sortRanked orders chunks by descending score, keeping transcript order for ties
+func sortRanked(ranked []scoredChunk) {
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if ranked[i].chunk.JobID != ranked[j].chunk.JobID {
+			return ranked[i].chunk.JobID < ranked[j].chunk.JobID
+		}
+		return ranked[i].chunk.Index < ranked[j].chunk.Index
+	})
+}
+
+// labelPassages puts chunks in the order of their sources and transcripts and labels them
+func labelPassages(sources []Source, chunks []Chunk) []Passage {
+	order := make(map[string]int, len(sources))
+	titles := make(map[string]string, len(sources))
+	for i, source := range sources {
+		order[source.JobID] = i
+		titles[source.JobID] = source.Title
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		if order[chunks[i].JobID] != order[chunks[j].JobID] {
+			return order[chunks[i].JobID] < order[chunks[j].JobID]
+		}
+		return chunks[i].Index < chunks[j].Index
+	})
+
+	passages := make([]Passage, len(chunks))
+	for i, chunk := range chunks {
+		passages[i] = Passage{Chunk: chunk, Label: fmt.Sprintf("S%d", i+1), Title: titles[chunk.JobID]}
+	}
+	return passages
+}
+
+// formatTimestamp renders seconds as HH:MM:SS
+func formatTimestamp(seconds float64) string {
+	total := int(seconds)
+	return fmt.Sprintf("%02d:%02d:%02d", total/3600, total/60%60, total%60)
+}
+
+// SystemPrompt builds the system message holding the passages and the instructions to cite them
+func SystemPrompt(sources []Source, passages []Passage) string {
+	var sb strings.Builder
+	sb.WriteString("You are a helpful assistant answering questions about one or more transcripts. ")
+	sb.WriteString("Base your answers on the numbered transcript passages below. After each statement, cite the passages it comes from by their labels in square brackets, such as [S1] or [S2][S5]. ")
+	sb.WriteString("If the passages don't contain the answer, say so.\n\nTranscripts:\n")
+	for _, source := range sources {
+		fmt.Fprintf(&sb, "- %q, job %s, recorded %s\n", source.Title, source.JobID, source.CreatedAt.Format("2006-01-02"))
+	}
+
+	sb.WriteString("\nPassages:\n")
+	for _, passage := range passages {
+		fmt.Fprintf(&sb, "\n[%s] %q (job %s), %s-%s\n%s\n", passage.Label, passage.Title, passage.JobID,
+			formatTimestamp(passage.Start), formatTimestamp(passage.End), passage.Text)
+	}
+	return sb.String()
+}
+
+// Citation describes a passage as a citation
+func (p Passage) Citation() models.Citation {
+	return models.Citation{
+		Label:        p.Label,
+		JobID:        p.JobID,
+		Title:        p.Title,
+		SegmentStart: p.SegmentStart,
+		SegmentEnd:   p.SegmentEnd,
+		Start:        p.Start,
+		End:          p.End,
+	}
+}
+
+var citationPattern = regexp.MustCompile(`\[\s*(S\d+(?:\s*[,;]\s*S\d+)*)\s*\]`)
+var citationLabelPattern = regexp.MustCompile(`S\d+`)
+
+// Citations returns the passages an answer cites, in the order they are first cited
+func Citations(answer string, passages []Passage) []models.Citation {
+	byLabel := make(map[string]Passage, len(passages))
+	for _, passage := range passages {
+		byLabel[passage.Label] = passage
+	}
+
+	var citations []models.Citation
+	seen := make(map[string]bool)
+	for _, group := range citationPattern.FindAllStringSubmatch(answer, -1) {
+		for _, label := range citationLabelPattern.FindAllString(group[1], -1) {
+			passage, ok := byLabel[label]
+			if !ok || seen[label] {
+				continue
+			}
+			seen[label] = true
+			citations = append(citations, passage.Citation())
+		}
+	}
+	return citations
+}
@@ -0,0 +1,434 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+)
+
+// TableName is the full-text index table holding one row per transcript segment
+const TableName = "transcript_search"
+
+// Highlight markers wrapped around matched terms in snippets
+const (
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// Hit is a single matching segment inside a transcript
+type Hit struct {
+	SegmentIndex int     `json:"segment_index"`
+	Start        float64 `json:"start"`
+	End          float64 `json:"end"`
+	Speaker      *string `json:"speaker,omitempty"`
+	Snippet      string  `json:"snippet"`
+}
+
+// JobResult groups the hits found in one transcription job
+type JobResult struct {
+	JobID      string           `json:"job_id"`
+	Title      *string          `json:"title,omitempty"`
+	Status     models.JobStatus `json:"status"`
+	CreatedAt  time.Time        `json:"created_at"`
+	MatchCount int              `json:"match_count"`
+	Hits       []Hit            `json:"hits"`
+}
+
+// Options controls paging of search results
+type Options struct {
+	Limit      int   // jobs per page
+	Offset     int   // jobs to skip
+	HitsPerJob int   // maximum segments returned for each job
+	UserID     *uint // when set, only jobs owned by this user are searched
+}
+
+// IndexTranscript replaces the indexed segments of a job with those in result
+func IndexTranscript(jobID string, result *interfaces.TranscriptResult) error {
+	// Temporary per-track jobs of multi-track recordings are never listed, so don't index them
+	if strings.HasPrefix(jobID, "track_") || result == nil {
+		return nil
+	}
+
+	tx := database.DB.Begin()
+	if err := tx.Exec("DELETE FROM "+TableName+" WHERE job_id = ?", jobID).Error; err != nil {
+		tx.Rollback()
+		return fmt.Errorf("failed to clear search index for job %s: %w", jobID, err)
+	}
+
+	segments := result.Segments
+	if len(segments) == 0 && strings.TrimSpace(result.Text) != "" {
+		segments = []interfaces.TranscriptSegment{{Text: result.Text}}
+	}
+
+	for i, seg := range segments {
+		text := strings.TrimSpace(seg.Text)
+		if text == "" {
+			continue
+		}
+		speaker := ""
+		if seg.Speaker != nil {
+			speaker = *seg.Speaker
+		}
+		if err := tx.Exec("INSERT INTO "+TableName+" (job_id, segment_index, start_time, end_time, speaker, text) VALUES (?, ?, ?, ?, ?, ?)",
+			jobID, i, seg.Start, seg.End, speaker, text).Error; err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to index segment %d of job %s: %w", i, jobID, err)
+		}
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit search index for job %s: %w", jobID, err)
+	}
+	return nil
+}
+
+// IndexTranscriptJSON indexes a transcript stored in its serialized database form
+func IndexTranscriptJSON(jobID string, transcript string) error {
+	var result interfaces.TranscriptResult
+	if err := json.Unmarshal([]byte(transcript), &result); err != nil {
+		return fmt.Errorf("failed to parse transcript for job %s: %w", jobID, err)
+	}
+	return IndexTranscript(jobID, &result)
+}
+
+// RemoveJob drops every indexed segment of a job
+func RemoveJob(jobID string) error {
+	if err := database.DB.Exec("DELETE FROM "+TableName+" WHERE job_id = ?", jobID).Error; err != nil {
+		return fmt.Errorf("failed to remove job %s from search index: %w", jobID, err)
+	}
+	return nil
+}
+
+// BackfillIndex indexes completed transcripts that are not yet in the search index
+func BackfillIndex() (int, error) {
+	var jobs []models.TranscriptionJob
+	if err := database.DB.Select("id", "transcript").
+		Where("status = ? AND transcript IS NOT NULL AND id NOT LIKE 'track_%'", models.StatusCompleted).
+		Where("id NOT IN (SELECT DISTINCT job_id FROM " + TableName + ")").
+		Find(&jobs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find unindexed transcripts: %w", err)
+	}
+
+	indexed := 0
+	for _, job := range jobs {
+		if err := IndexTranscriptJSON(job.ID, *job.Transcript); err != nil {
+			logger.Warn("Failed to index transcript", "job_id", job.ID, "error", err)
+			continue
+		}
+		indexed++
+	}
+	return indexed, nil
+}
+
+// term is a word or quoted phrase of a search query
+type term struct {
+	text   string
+	prefix bool // also match words starting with text
+}
+
+// parseTerms splits free-form user input into terms. Double-quoted phrases are
+// kept together and a trailing * on a word makes it a prefix search.
+func parseTerms(input string) []term {
+	var terms []term
+	var current strings.Builder
+	inQuote := false
+
+	flush := func(quoted bool) {
+		text := strings.TrimSpace(current.String())
+		current.Reset()
+		if text == "" {
+			return
+		}
+		prefix := false
+		if !quoted && strings.HasSuffix(text, "*") {
+			text = strings.TrimRight(text, "*")
+			prefix = text != ""
+		}
+		if text == "" {
+			return
+		}
+		terms = append(terms, term{text: text, prefix: prefix})
+	}
+
+	for _, r := range input {
+		switch {
+		case r == '"':
+			flush(inQuote)
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			flush(false)
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush(inQuote)
+
+	return terms
+}
+
+// BuildMatchQuery turns free-form user input into a safe FTS5 MATCH expression.
+// Double-quoted phrases are kept together, a trailing * on a word is a prefix
+// search, and all remaining terms must match.
+func BuildMatchQuery(input string) string {
+	return ftsQuery(parseTerms(input), false)
+}
+
+// ftsQuery renders terms as an FTS5 MATCH expression. All terms must match unless any is set.
+func ftsQuery(terms []term, any bool) string {
+	exprs := make([]string, 0, len(terms))
+	for _, t := range terms {
+		expr := `"` + strings.ReplaceAll(t.text, `"`, `""`) + `"`
+		if t.prefix {
+			expr += "*"
+		}
+		exprs = append(exprs, expr)
+	}
+	if any {
+		return strings.Join(exprs, " OR ")
+	}
+	return strings.Join(exprs, " ")
+}
+
+// tsQuery renders terms as a PostgreSQL tsquery for the simple text search configuration.
+// The words of a phrase must follow each other, and all terms must match unless any is set.
+func tsQuery(terms []term, any bool) string {
+	exprs := make([]string, 0, len(terms))
+	for _, t := range terms {
+		words := strings.FieldsFunc(strings.ToLower(t.text), isNotWordRune)
+		if len(words) == 0 {
+			continue
+		}
+		lexemes := make([]string, len(words))
+		for i, word := range words {
+			lexemes[i] = "'" + word + "'"
+		}
+		if t.prefix {
+			lexemes[len(lexemes)-1] += ":*"
+		}
+		expr := strings.Join(lexemes, " <-> ")
+		if len(lexemes) > 1 {
+			expr = "(" + expr + ")"
+		}
+		exprs = append(exprs, expr)
+	}
+	if any {
+		return strings.Join(exprs, " | ")
+	}
+	return strings.Join(exprs, " & ")
+}
+
+// isNotWordRune reports whether r separates words
+func isNotWordRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+// stopWords are left out of keyword queries built from questions
+var stopWords = map[string]bool{
+	"a": true, "about": true, "after": true, "all": true, "an": true, "and": true, "any": true, "are": true,
+	"as": true, "at": true, "be": true, "been": true, "before": true, "but": true, "by": true, "can": true,
+	"could": true, "did": true, "do": true, "does": true, "for": true, "from": true, "had": true, "has": true,
+	"have": true, "how": true, "i": true, "if": true, "in": true, "into": true, "is": true, "it": true,
+	"its": true, "last": true, "me": true, "my": true, "of": true, "on": true, "or": true, "our": true,
+	"over": true, "said": true, "say": true, "so": true, "than": true, "that": true, "the": true,
+	"their": true, "them": true, "then": true, "there": true, "these": true, "they": true, "this": true,
+	"those": true, "to": true, "was": true, "we": true, "were": true, "what": true, "when": true,
+	"where": true, "which": true, "who": true, "why": true, "will": true, "with": true, "would": true,
+	"you": true, "your": true,
+}
+
+// BuildKeywordQuery turns a natural language question into an FTS5 MATCH expression that
+// matches any of its keywords, so that bm25 ranks segments by how many and how rare they are.
+// It returns an empty string when the question has no keywords.
+func BuildKeywordQuery(input string) string {
+	return ftsQuery(keywordTerms(input), true)
+}
+
+// keywordTerms returns the distinct words of a question that aren't stop words
+func keywordTerms(input string) []term {
+	words := strings.FieldsFunc(strings.ToLower(input), isNotWordRune)
+
+	var terms []term
+	seen := make(map[string]bool, len(words))
+	for _, word := range words {
+		if len([]rune(word)) < 2 || stopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, term{text: word})
+	}
+	return terms
+}
+
+// matcher holds the full-text SQL of the database dialect for one query: an FTS5 table ranked
+// by bm25 on SQLite, and a tsvector column ranked by ts_rank on PostgreSQL. The query is bound
+// to @query, and scores are lower for better matches with both.
+type matcher struct {
+	query     string
+	condition string // selects the matching segments
+	score     string // relevance of a matching segment
+	snippet   string // text of a matching segment with matches highlighted, around 32 words long
+}
+
+// newMatcher builds the matcher of terms. All terms must match unless any is set.
+func newMatcher(terms []term, any bool) matcher {
+	if database.IsPostgres() {
+		query := "to_tsquery('simple', @query)"
+		return matcher{
+			query:     tsQuery(terms, any),
+			condition: "document @@ " + query,
+			score:     "-ts_rank(document, " + query + ")",
+			snippet: "ts_headline('simple', text, " + query + ", 'StartSel=\"" + HighlightStart +
+				"\", StopSel=\"" + HighlightEnd + "\", MaxWords=32, MinWords=16')",
+		}
+	}
+	return matcher{
+		query:     ftsQuery(terms, any),
+		condition: TableName + " MATCH @query",
+		score:     "rank", // bm25 by default; auxiliary functions can't be used inside aggregates
+		snippet:   "snippet(" + TableName + ", 5, '" + HighlightStart + "', '" + HighlightEnd + "', '…', 32)",
+	}
+}
+
+// Search finds transcripts whose contents match query, best matches first.
+// It returns one page of job results and the total number of matching jobs.
+func Search(query string, opts Options) ([]JobResult, int64, error) {
+	m := newMatcher(parseTerms(query), false)
+	if m.query == "" {
+		return nil, 0, fmt.Errorf("search query is empty")
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = 10
+	}
+	if opts.HitsPerJob <= 0 {
+		opts.HitsPerJob = 5
+	}
+
+	matches := "(SELECT job_id, " + m.score + " AS score FROM " + TableName + " WHERE " + m.condition + ") m " +
+		"JOIN transcription_jobs j ON j.id = m.job_id"
+	args := map[string]interface{}{"query": m.query, "limit": opts.Limit, "offset": opts.Offset}
+	if opts.UserID != nil {
+		matches += " WHERE j.user_id = @user"
+		args["user"] = *opts.UserID
+	}
+
+	var total int64
+	if err := database.DB.Raw("SELECT COUNT(DISTINCT m.job_id) FROM "+matches, args).Scan(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count search results: %w", err)
+	}
+
+	type jobRow struct {
+		JobID      string
+		Title      *string
+		Status     models.JobStatus
+		CreatedAt  time.Time
+		Score      float64
+		MatchCount int
+	}
+	var jobRows []jobRow
+	if err := database.DB.Raw(
+		"SELECT m.job_id AS job_id, j.title AS title, j.status AS status, j.created_at AS created_at, "+
+			"MIN(m.score) AS score, COUNT(*) AS match_count FROM "+matches+
+			" GROUP BY m.job_id, j.title, j.status, j.created_at ORDER BY score ASC, j.created_at DESC LIMIT @limit OFFSET @offset",
+		args).Scan(&jobRows).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to search transcripts: %w", err)
+	}
+
+	results := make([]JobResult, 0, len(jobRows))
+	for _, row := range jobRows {
+		hits, err := jobHits(row.JobID, m, opts.HitsPerJob)
+		if err != nil {
+			return nil, 0, err
+		}
+		results = append(results, JobResult{
+			JobID:      row.JobID,
+			Title:      row.Title,
+			Status:     row.Status,
+			CreatedAt:  row.CreatedAt,
+			MatchCount: row.MatchCount,
+			Hits:       hits,
+		})
+	}
+
+	return results, total, nil
+}
+
+// jobHits returns the best matching segments of a job in playback order
+func jobHits(jobID string, m matcher, limit int) ([]Hit, error) {
+	type hitRow struct {
+		SegmentIndex int
+		StartTime    float64
+		EndTime      float64
+		Speaker      string
+		Snippet      string
+	}
+	var rows []hitRow
+	if err := database.DB.Raw(
+		"SELECT segment_index, start_time, end_time, speaker, "+m.snippet+" AS snippet "+
+			"FROM "+TableName+" WHERE "+m.condition+" AND job_id = @job "+
+			"ORDER BY "+m.score+" LIMIT @limit",
+		map[string]interface{}{"query": m.query, "job": jobID, "limit": limit}).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load search hits for job %s: %w", jobID, err)
+	}
+
+	speakerNames := make(map[string]string)
+	var mappings []models.SpeakerMapping
+	if err := database.DB.Where("transcription_job_id = ?", jobID).Find(&mappings).Error; err == nil {
+		for _, m := range mappings {
+			speakerNames[m.OriginalSpeaker] = m.CustomName
+		}
+	}
+
+	hits := make([]Hit, 0, len(rows))
+	for _, row := range rows {
+		hit := Hit{
+			SegmentIndex: row.SegmentIndex,
+			Start:        row.StartTime,
+			End:          row.EndTime,
+			Snippet:      row.Snippet,
+		}
+		if row.Speaker != "" {
+			speaker := row.Speaker
+			if custom, ok := speakerNames[speaker]; ok {
+				speaker = custom
+			}
+			hit.Speaker = &speaker
+		}
+		hits = append(hits, hit)
+	}
+
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Start < hits[j].Start })
+	return hits, nil
+}
+
+// SegmentScore is the relevance of an indexed segment to a question, lower is better
+type SegmentScore struct {
+	JobID        string
+	SegmentIndex int
+	Score        float64
+}
+
+// RankSegments returns the segments of the given jobs matching any keyword of question, best
+// first and at most limit of them. It returns nothing when the question has no keywords.
+func RankSegments(jobIDs []string, question string, limit int) ([]SegmentScore, error) {
+	m := newMatcher(keywordTerms(question), true)
+	if m.query == "" || len(jobIDs) == 0 {
+		return nil, nil
+	}
+
+	var scores []SegmentScore
+	if err := database.DB.Raw(
+		"SELECT job_id, segment_index, "+m.score+" AS score FROM "+TableName+
+			" WHERE "+m.condition+" AND job_id IN @jobs ORDER BY score LIMIT @limit",
+		map[string]interface{}{"query": m.query, "jobs": jobIDs, "limit": limit}).Scan(&scores).Error; err != nil {
+		return nil, fmt.Errorf("failed to rank transcript segments: %w", err)
+	}
+	return scores, nil
+}
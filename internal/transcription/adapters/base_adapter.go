@@ -1,6 +1,7 @@
 package adapters
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -375,6 +376,26 @@ func (b *BaseAdapter) CleanupTempDirectory(tempDir string) {
 	}
 }
 
+// RunCommand runs a model process in its own process group so it can be killed together with
+// its children, reports it through procCtx.RegisterProcess and returns its combined output
+func (b *BaseAdapter) RunCommand(cmd *exec.Cmd, procCtx interfaces.ProcessingContext) ([]byte, error) {
+	configureCmdSysProcAttr(cmd)
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	if procCtx.RegisterProcess != nil {
+		procCtx.RegisterProcess(cmd)
+	}
+
+	err := cmd.Wait()
+	return output.Bytes(), err
+}
+
 // ConvertAudioFormat converts audio to the required format for the model
 func (b *BaseAdapter) ConvertAudioFormat(ctx context.Context, input interfaces.AudioInput, targetFormat string, targetSampleRate int) (interfaces.AudioInput, error) {
 	// This is a placeholder for audio conversion functionality
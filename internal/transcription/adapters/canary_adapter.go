@@ -553,7 +553,7 @@ func (c *CanaryAdapter) Transcribe(ctx context.Context, input interfaces.AudioIn
 
 	logger.Info("Executing Canary command", "args", strings.Join(args, " "))
 	
-	output, err := cmd.CombinedOutput()
+	output, err := c.RunCommand(cmd, procCtx)
 	if ctx.Err() == context.Canceled {
 		return nil, fmt.Errorf("transcription was cancelled")
 	}
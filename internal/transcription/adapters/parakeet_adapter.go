@@ -491,7 +491,7 @@ func (p *ParakeetAdapter) Transcribe(ctx context.Context, input interfaces.Audio
 
 	logger.Info("Executing Parakeet command", "args", strings.Join(args, " "))
 	
-	output, err := cmd.CombinedOutput()
+	output, err := p.RunCommand(cmd, procCtx)
 	if ctx.Err() == context.Canceled {
 		return nil, fmt.Errorf("transcription was cancelled")
 	}
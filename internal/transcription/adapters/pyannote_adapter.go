@@ -114,6 +114,14 @@ func NewPyAnnoteAdapter() *PyAnnoteAdapter {
 			Description: "Use authentication token for model access",
 			Group:       "advanced",
 		},
+		{
+			Name:        "return_embeddings",
+			Type:        "bool",
+			Required:    false,
+			Default:     false,
+			Description: "Write a voice embedding per speaker to JSON output",
+			Group:       "advanced",
+		},
 		{
 			Name:        "device",
 			Type:        "string",
@@ -319,8 +327,8 @@ nemo-toolkit = { git = "https://github.com/NVIDIA/NeMo.git" }
 func (p *PyAnnoteAdapter) createDiarizationScript() error {
 	scriptPath := filepath.Join(p.envPath, "pyannote_diarize.py")
 	
-	// Check if script already exists
-	if _, err := os.Stat(scriptPath); err == nil {
+	// Check if script already exists; scripts written before embedding output was added are replaced
+	if existing, err := os.ReadFile(scriptPath); err == nil && strings.Contains(string(existing), "--embeddings") {
 		return nil
 	}
 
@@ -346,7 +354,8 @@ def diarize_audio(
     min_speakers: int = None,
     max_speakers: int = None,
     output_format: str = "rttm",
-    device: str = "cpu"
+    device: str = "cpu",
+    return_embeddings: bool = False
 ):
     """
     Perform speaker diarization on audio file using PyAnnote.
@@ -390,10 +399,14 @@ def diarize_audio(
             
         if diarization_params:
             print(f"Using speaker constraints: {diarization_params}")
-            diarization = pipeline(audio_path, **diarization_params)
         else:
             print("Using automatic speaker detection")
-            diarization = pipeline(audio_path)
+
+        embeddings = None
+        if return_embeddings:
+            diarization, embeddings = pipeline(audio_path, return_embeddings=True, **diarization_params)
+        else:
+            diarization = pipeline(audio_path, **diarization_params)
         
         print(f"Diarization completed. Saving results to: {output_file}")
         
@@ -403,7 +416,7 @@ def diarize_audio(
                 diarization.write_rttm(rttm)
         else:
             # Save as JSON format
-            save_json_format(diarization, output_file, audio_path)
+            save_json_format(diarization, output_file, audio_path, embeddings)
         
         # Print summary
         speakers = set()
@@ -424,7 +437,7 @@ def diarize_audio(
         sys.exit(1)
 
 
-def save_json_format(diarization, output_file: str, audio_path: str):
+def save_json_format(diarization, output_file: str, audio_path: str, embeddings=None):
     """Save diarization results in JSON format."""
     segments = []
     speakers = set()
@@ -455,6 +468,16 @@ def save_json_format(diarization, output_file: str, audio_path: str):
         }
     }
     
+    if embeddings is not None:
+        # Embeddings are ordered like diarization.labels(). Speakers the clustering
+        # found no centroid for come back as NaN or zero vectors and are left out.
+        results["embeddings"] = {}
+        for label, vector in zip(diarization.labels(), embeddings):
+            values = [float(v) for v in vector]
+            if any(v != v for v in values) or not any(values):
+                continue
+            results["embeddings"][label] = values
+
     with open(output_file, "w") as f:
         json.dump(results, f, indent=2)
 
@@ -504,6 +527,11 @@ def main():
         default="cpu",
         help="Device to use for computation"
     )
+    parser.add_argument(
+        "--embeddings",
+        action="store_true",
+        help="Include a voice embedding per speaker (JSON output only)"
+    )
 
     args = parser.parse_args()
 
@@ -539,7 +567,8 @@ def main():
             min_speakers=args.min_speakers,
             max_speakers=args.max_speakers,
             output_format=args.output_format,
-            device=args.device
+            device=args.device,
+            return_embeddings=args.embeddings
         )
     except Exception as e:
         print(f"Error during diarization: {e}")
@@ -600,7 +629,7 @@ func (p *PyAnnoteAdapter) Diarize(ctx context.Context, input interfaces.AudioInp
 
 	logger.Info("Executing PyAnnote command", "args", strings.Join(args, " "))
 	
-	output, err := cmd.CombinedOutput()
+	output, err := p.RunCommand(cmd, procCtx)
 	if ctx.Err() == context.Canceled {
 		return nil, fmt.Errorf("diarization was cancelled")
 	}
@@ -666,6 +695,10 @@ func (p *PyAnnoteAdapter) buildPyAnnoteArgs(input interfaces.AudioInput, params
 		args = append(args, "--device", device)
 	}
 
+	if p.GetBoolParameter(params, "return_embeddings") {
+		args = append(args, "--embeddings")
+	}
+
 	return args, nil
 }
 
@@ -702,6 +735,7 @@ func (p *PyAnnoteAdapter) parseJSONResult(tempDir string) (*interfaces.Diarizati
 		Speakers      []string `json:"speakers"`
 		SpeakerCount  int      `json:"speaker_count"`
 		TotalDuration float64  `json:"total_duration"`
+		Embeddings    map[string][]float64 `json:"embeddings"`
 	}
 
 	if err := json.Unmarshal(data, &pyannoteResult); err != nil {
@@ -713,6 +747,7 @@ func (p *PyAnnoteAdapter) parseJSONResult(tempDir string) (*interfaces.Diarizati
 		Segments:     make([]interfaces.DiarizationSegment, len(pyannoteResult.Segments)),
 		SpeakerCount: pyannoteResult.SpeakerCount,
 		Speakers:     pyannoteResult.Speakers,
+		SpeakerEmbeddings: pyannoteResult.Embeddings,
 	}
 
 	for i, seg := range pyannoteResult.Segments {
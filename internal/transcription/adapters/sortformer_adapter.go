@@ -671,7 +671,7 @@ func (s *SortformerAdapter) Diarize(ctx context.Context, input interfaces.AudioI
 
 	logger.Info("Executing Sortformer command", "args", strings.Join(args, " "))
 	
-	output, err := cmd.CombinedOutput()
+	output, err := s.RunCommand(cmd, procCtx)
 	if ctx.Err() == context.Canceled {
 		return nil, fmt.Errorf("diarization was cancelled")
 	}
@@ -0,0 +1,18 @@
+//go:build linux
+// +build linux
+
+package adapters
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureCmdSysProcAttr starts the command in its own process group on Linux and
+// kills the whole group when the command's context is cancelled.
+func configureCmdSysProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build windows
+// +build windows
+
+package adapters
+
+import "os/exec"
+
+// configureCmdSysProcAttr is a no-op on Windows to keep builds portable.
+// Cancellation falls back to killing the process itself.
+func configureCmdSysProcAttr(cmd *exec.Cmd) {
+	// No special attributes set on Windows here
+}
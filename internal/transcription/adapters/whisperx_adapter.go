@@ -423,7 +423,7 @@ func (w *WhisperXAdapter) Transcribe(ctx context.Context, input interfaces.Audio
 
 	logger.Info("Executing WhisperX command", "args", strings.Join(args, " "))
 	
-	output, err := cmd.CombinedOutput()
+	output, err := w.RunCommand(cmd, procCtx)
 	if ctx.Err() == context.Canceled {
 		return nil, fmt.Errorf("transcription was cancelled")
 	}
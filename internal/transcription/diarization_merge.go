@@ -0,0 +1,226 @@
+package transcription
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"synthezia/internal/editor"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+)
+
+const (
+	// overlapMinShare is the share of a word another speaker must cover to count as talking over it
+	overlapMinShare = 0.3
+	// speakerGapTolerance is how far, in seconds, a word may sit outside every diarized turn and
+	// still take the speaker of the nearest one
+	speakerGapTolerance = 0.5
+	// minWordDuration stands in for the length of words aligned to a single instant
+	minWordDuration = 0.01
+)
+
+// mergeDiarizationWithTranscription combines diarization results with transcription. Speakers are
+// assigned per word, segments are split where the speaker changes, and words and segments with
+// other speakers talking over them list those speakers in OverlapSpeakers.
+func (u *UnifiedTranscriptionService) mergeDiarizationWithTranscription(transcript *interfaces.TranscriptResult, diarization *interfaces.DiarizationResult) *interfaces.TranscriptResult {
+	logger.Info("Merging diarization with transcription",
+		"transcript_segments", len(transcript.Segments),
+		"transcript_words", len(transcript.WordSegments),
+		"diarization_segments", len(diarization.Segments))
+
+	// Create a copy of the transcript to avoid modifying the original
+	merged := *transcript
+	merged.WordSegments = make([]interfaces.TranscriptWord, len(transcript.WordSegments))
+	copy(merged.WordSegments, transcript.WordSegments)
+
+	for i := range merged.WordSegments {
+		word := &merged.WordSegments[i]
+		speaker, overlap := speakersForSpan(word.Start, word.End, diarization.Segments)
+		if speaker != "" {
+			word.Speaker = &speaker
+		}
+		word.OverlapSpeakers = overlap
+	}
+	smoothSpeakerChanges(merged.WordSegments)
+
+	merged.Segments = make([]interfaces.TranscriptSegment, 0, len(transcript.Segments))
+	for i, segment := range transcript.Segments {
+		lo, hi := editor.SegmentWords(transcript, i)
+		if lo == hi {
+			// Without words, fall back to the speaker who talks most during the segment
+			if bestSpeaker := u.findBestSpeakerForSegment(segment.Start, segment.End, diarization.Segments); bestSpeaker != "" {
+				segment.Speaker = &bestSpeaker
+			}
+			segment.OverlapSpeakers = nil
+			merged.Segments = append(merged.Segments, segment)
+			continue
+		}
+		merged.Segments = append(merged.Segments, splitSegmentBySpeaker(segment, merged.WordSegments[lo:hi])...)
+	}
+
+	return &merged
+}
+
+// speakersForSpan returns the speaker covering most of a span, and the other speakers covering
+// at least overlapMinShare of it. A span no turn covers takes the speaker of the nearest turn
+// within speakerGapTolerance.
+func speakersForSpan(start, end float64, turns []interfaces.DiarizationSegment) (string, []string) {
+	if end-start < minWordDuration {
+		end = start + minWordDuration
+	}
+
+	coverage := make(map[string]float64)
+	var order []string // speakers in the order they first appear, for stable tie-breaking
+	for _, turn := range turns {
+		overlap := min(end, turn.End) - max(start, turn.Start)
+		if overlap <= 0 {
+			continue
+		}
+		if _, seen := coverage[turn.Speaker]; !seen {
+			order = append(order, turn.Speaker)
+		}
+		coverage[turn.Speaker] += overlap
+	}
+
+	if len(order) == 0 {
+		nearest, bestGap := "", speakerGapTolerance
+		for _, turn := range turns {
+			gap := max(turn.Start-end, start-turn.End)
+			if gap <= bestGap {
+				nearest, bestGap = turn.Speaker, gap
+			}
+		}
+		return nearest, nil
+	}
+
+	primary := order[0]
+	for _, speaker := range order[1:] {
+		if coverage[speaker] > coverage[primary] {
+			primary = speaker
+		}
+	}
+	var overlapping []string
+	for _, speaker := range order {
+		if speaker != primary && coverage[speaker] >= overlapMinShare*(end-start) {
+			overlapping = append(overlapping, speaker)
+		}
+	}
+	sort.Strings(overlapping)
+	return primary, overlapping
+}
+
+// smoothSpeakerChanges gives a single overlapped word back to the speaker talking on both sides
+// of it, so brief interjections are marked as overlap instead of splitting the sentence around them
+func smoothSpeakerChanges(words []interfaces.TranscriptWord) {
+	for i := 1; i+1 < len(words); i++ {
+		prev, word, next := words[i-1].Speaker, words[i].Speaker, words[i+1].Speaker
+		if prev == nil || word == nil || next == nil || *prev != *next || *word == *prev {
+			continue
+		}
+		if !slices.Contains(words[i].OverlapSpeakers, *prev) {
+			continue
+		}
+		overlap := []string{*word}
+		for _, speaker := range words[i].OverlapSpeakers {
+			if speaker != *prev {
+				overlap = append(overlap, speaker)
+			}
+		}
+		sort.Strings(overlap)
+		words[i].Speaker = prev
+		words[i].OverlapSpeakers = overlap
+	}
+}
+
+// splitSegmentBySpeaker splits a segment into one segment per run of words with the same speaker.
+// Text is cut on word boundaries, so a segment whose text doesn't line up with its words is kept
+// whole and given the speaker of most of its words.
+func splitSegmentBySpeaker(segment interfaces.TranscriptSegment, words []interfaces.TranscriptWord) []interfaces.TranscriptSegment {
+	type run struct {
+		speaker *string
+		lo, hi  int
+	}
+	var runs []run
+	for i, word := range words {
+		if len(runs) > 0 {
+			last := &runs[len(runs)-1]
+			if word.Speaker == nil || last.speaker == nil || *word.Speaker == *last.speaker {
+				if last.speaker == nil {
+					last.speaker = word.Speaker
+				}
+				last.hi = i + 1
+				continue
+			}
+		}
+		runs = append(runs, run{speaker: word.Speaker, lo: i, hi: i + 1})
+	}
+
+	tokens := strings.Fields(segment.Text)
+	if len(runs) == 1 || len(tokens) != len(words) {
+		segment.Speaker = dominantSpeaker(words, segment.Speaker)
+		segment.OverlapSpeakers = overlappingSpeakers(words, segment.Speaker)
+		return []interfaces.TranscriptSegment{segment}
+	}
+
+	leading := ""
+	if strings.HasPrefix(segment.Text, " ") {
+		leading = " "
+	}
+	parts := make([]interfaces.TranscriptSegment, 0, len(runs))
+	for i, r := range runs {
+		part := segment
+		part.Speaker = r.speaker
+		part.Text = leading + strings.Join(tokens[r.lo:r.hi], " ")
+		if i > 0 {
+			part.Start = words[r.lo].Start
+		}
+		if i < len(runs)-1 {
+			part.End = words[r.hi-1].End
+		}
+		part.OverlapSpeakers = overlappingSpeakers(words[r.lo:r.hi], part.Speaker)
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// dominantSpeaker returns the speaker of most of the words' duration, or fallback if none has one
+func dominantSpeaker(words []interfaces.TranscriptWord, fallback *string) *string {
+	durations := make(map[string]float64)
+	var best *string
+	for _, word := range words {
+		if word.Speaker == nil {
+			continue
+		}
+		durations[*word.Speaker] += max(word.End-word.Start, minWordDuration)
+		if best == nil || durations[*word.Speaker] > durations[*best] {
+			best = word.Speaker
+		}
+	}
+	if best == nil {
+		return fallback
+	}
+	return best
+}
+
+// overlappingSpeakers lists every speaker other than speaker heard in the words
+func overlappingSpeakers(words []interfaces.TranscriptWord, speaker *string) []string {
+	seen := make(map[string]bool)
+	var speakers []string
+	add := func(s string) {
+		if (speaker == nil || s != *speaker) && !seen[s] {
+			seen[s] = true
+			speakers = append(speakers, s)
+		}
+	}
+	for _, word := range words {
+		if word.Speaker != nil {
+			add(*word.Speaker)
+		}
+		for _, s := range word.OverlapSpeakers {
+			add(s)
+		}
+	}
+	sort.Strings(speakers)
+	return speakers
+}
@@ -0,0 +1,188 @@
+package transcription
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"synthezia/internal/transcription/interfaces"
+)
+
+// timedWords builds one-second words starting at zero
+func timedWords(text string) []interfaces.TranscriptWord {
+	var words []interfaces.TranscriptWord
+	for i, w := range strings.Fields(text) {
+		words = append(words, interfaces.TranscriptWord{Start: float64(i), End: float64(i + 1), Word: w, Score: 1})
+	}
+	return words
+}
+
+func turn(speaker string, start, end float64) interfaces.DiarizationSegment {
+	return interfaces.DiarizationSegment{Start: start, End: end, Speaker: speaker}
+}
+
+// mergedSegment is the part of a merged segment the tests check
+type mergedSegment struct {
+	Start, End float64
+	Text       string
+	Speaker    string
+	Overlap    []string
+}
+
+func TestMergeDiarizationWithTranscription(t *testing.T) {
+	tests := []struct {
+		name         string
+		segments     []interfaces.TranscriptSegment
+		words        []interfaces.TranscriptWord
+		turns        []interfaces.DiarizationSegment
+		want         []mergedSegment
+		wantSpeakers []string // per word
+	}{
+		{
+			name:         "single speaker",
+			segments:     []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two three four"}},
+			words:        timedWords("one two three four"),
+			turns:        []interfaces.DiarizationSegment{turn("A", 0, 4)},
+			want:         []mergedSegment{{0, 4, " one two three four", "A", nil}},
+			wantSpeakers: []string{"A", "A", "A", "A"},
+		},
+		{
+			name:     "speaker change splits the segment",
+			segments: []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two three four"}},
+			words:    timedWords("one two three four"),
+			turns:    []interfaces.DiarizationSegment{turn("A", 0, 2), turn("B", 2, 4)},
+			want: []mergedSegment{
+				{0, 2, " one two", "A", nil},
+				{2, 4, " three four", "B", nil},
+			},
+			wantSpeakers: []string{"A", "A", "B", "B"},
+		},
+		{
+			name:         "background speech is marked as overlap",
+			segments:     []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two three four"}},
+			words:        timedWords("one two three four"),
+			turns:        []interfaces.DiarizationSegment{turn("A", 0, 4), turn("B", 1.5, 2.5)},
+			want:         []mergedSegment{{0, 4, " one two three four", "A", []string{"B"}}},
+			wantSpeakers: []string{"A", "A", "A", "A"},
+		},
+		{
+			name:         "interjection inside a sentence stays with the main speaker",
+			segments:     []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two three four"}},
+			words:        timedWords("one two three four"),
+			turns:        []interfaces.DiarizationSegment{turn("A", 0, 1.6), turn("B", 1, 2), turn("A", 1.9, 4)},
+			want:         []mergedSegment{{0, 4, " one two three four", "A", []string{"B"}}},
+			wantSpeakers: []string{"A", "A", "A", "A"},
+		},
+		{
+			name:     "turn taken over mid-sentence",
+			segments: []interfaces.TranscriptSegment{{Start: 0, End: 5, Text: "one two three four five"}},
+			words:    timedWords("one two three four five"),
+			turns:    []interfaces.DiarizationSegment{turn("A", 0, 2.6), turn("B", 2.2, 5)},
+			want: []mergedSegment{
+				{0, 2, "one two", "A", nil},
+				{2, 5, "three four five", "B", []string{"A"}},
+			},
+			wantSpeakers: []string{"A", "A", "B", "B", "B"},
+		},
+		{
+			name:     "word in a diarization gap takes the nearest speaker",
+			segments: []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two three four"}},
+			words:    timedWords("one two three four"),
+			turns:    []interfaces.DiarizationSegment{turn("A", 0, 1), turn("B", 2.3, 4)},
+			want: []mergedSegment{
+				{0, 2, " one two", "A", nil},
+				{2, 4, " three four", "B", nil},
+			},
+			wantSpeakers: []string{"A", "A", "B", "B"},
+		},
+		{
+			name:         "text that does not line up with words is not split",
+			segments:     []interfaces.TranscriptSegment{{Start: 0, End: 4, Text: " one two-three four"}},
+			words:        timedWords("one two three four"),
+			turns:        []interfaces.DiarizationSegment{turn("A", 0, 2.4), turn("B", 2.4, 4)},
+			want:         []mergedSegment{{0, 4, " one two-three four", "A", []string{"B"}}},
+			wantSpeakers: []string{"A", "A", "B", "B"},
+		},
+		{
+			name: "segment without words falls back to its own time span",
+			segments: []interfaces.TranscriptSegment{
+				{Start: 0, End: 2, Text: " one two"},
+				{Start: 5, End: 7, Text: " unaligned"},
+			},
+			words: timedWords("one two"),
+			turns: []interfaces.DiarizationSegment{turn("A", 0, 2), turn("B", 4.5, 7)},
+			want: []mergedSegment{
+				{0, 2, " one two", "A", nil},
+				{5, 7, " unaligned", "B", nil},
+			},
+			wantSpeakers: []string{"A", "A"},
+		},
+		{
+			name:         "no diarization leaves speakers unset",
+			segments:     []interfaces.TranscriptSegment{{Start: 0, End: 2, Text: " one two"}},
+			words:        timedWords("one two"),
+			want:         []mergedSegment{{0, 2, " one two", "", nil}},
+			wantSpeakers: []string{"", ""},
+		},
+	}
+
+	service := NewUnifiedTranscriptionService()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcript := &interfaces.TranscriptResult{Segments: tt.segments, WordSegments: tt.words}
+			merged := service.mergeDiarizationWithTranscription(transcript, &interfaces.DiarizationResult{Segments: tt.turns})
+
+			var got []mergedSegment
+			for _, seg := range merged.Segments {
+				m := mergedSegment{Start: seg.Start, End: seg.End, Text: seg.Text, Overlap: seg.OverlapSpeakers}
+				if seg.Speaker != nil {
+					m.Speaker = *seg.Speaker
+				}
+				got = append(got, m)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("segments = %+v, want %+v", got, tt.want)
+			}
+
+			var speakers []string
+			for _, word := range merged.WordSegments {
+				speaker := ""
+				if word.Speaker != nil {
+					speaker = *word.Speaker
+				}
+				speakers = append(speakers, speaker)
+			}
+			if !reflect.DeepEqual(speakers, tt.wantSpeakers) {
+				t.Errorf("word speakers = %v, want %v", speakers, tt.wantSpeakers)
+			}
+
+			// The input transcript is left untouched
+			if transcript.WordSegments[0].Speaker != nil || transcript.Segments[0].Speaker != nil {
+				t.Errorf("input transcript was modified")
+			}
+		})
+	}
+}
+
+func TestSpeakersForSpan(t *testing.T) {
+	turns := []interfaces.DiarizationSegment{turn("A", 0, 2), turn("B", 1, 3), turn("C", 2.9, 4)}
+	tests := []struct {
+		start, end  float64
+		wantSpeaker string
+		wantOverlap []string
+	}{
+		{0, 0.9, "A", nil},
+		{1.0, 2.0, "A", []string{"B"}}, // equal coverage goes to the first speaker
+		{1.5, 2.5, "B", []string{"A"}},
+		{2.5, 3.0, "B", nil}, // C covers only a fifth of the word
+		{2.5, 2.5, "B", nil}, // zero-length word
+		{4.4, 4.8, "C", nil}, // near the end of C's turn
+		{5.0, 6.0, "", nil},  // too far from any turn
+	}
+	for _, tt := range tests {
+		speaker, overlap := speakersForSpan(tt.start, tt.end, turns)
+		if speaker != tt.wantSpeaker || !reflect.DeepEqual(overlap, tt.wantOverlap) {
+			t.Errorf("speakersForSpan(%v, %v) = %q, %v; want %q, %v", tt.start, tt.end, speaker, overlap, tt.wantSpeaker, tt.wantOverlap)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"os/exec"
 	"time"
 
 	"synthezia/internal/models"
@@ -62,6 +63,8 @@ type TranscriptSegment struct {
 	Text     string  `json:"text"`
 	Speaker  *string `json:"speaker,omitempty"`
 	Language *string `json:"language,omitempty"`
+	// Other speakers talking over this segment, set when diarization finds overlapping speech
+	OverlapSpeakers []string `json:"overlap_speakers,omitempty"`
 }
 
 // TranscriptWord represents word-level timing information
@@ -71,6 +74,8 @@ type TranscriptWord struct {
 	Word    string  `json:"word"`
 	Score   float64 `json:"score"`
 	Speaker *string `json:"speaker,omitempty"`
+	// Other speakers talking over this word, set when diarization finds overlapping speech
+	OverlapSpeakers []string `json:"overlap_speakers,omitempty"`
 }
 
 // TranscriptResult represents the output of transcription
@@ -101,6 +106,8 @@ type DiarizationResult struct {
 	ProcessingTime time.Duration        `json:"processing_time"`
 	ModelUsed      string               `json:"model_used"`
 	Metadata       map[string]string    `json:"metadata"`
+	// SpeakerEmbeddings holds a voice embedding per speaker label, when the model was asked for them
+	SpeakerEmbeddings map[string][]float64 `json:"speaker_embeddings,omitempty"`
 }
 
 // ProcessingContext contains context information for processing
@@ -110,6 +117,9 @@ type ProcessingContext struct {
 	OutputDirectory string            `json:"output_directory"`
 	TempDirectory   string            `json:"temp_directory"`
 	Metadata        map[string]string `json:"metadata"`
+
+	// RegisterProcess, when set, is called with each model process once it has started
+	RegisterProcess func(cmd *exec.Cmd) `json:"-"`
 }
 
 // ModelAdapter is the base interface that all model adapters must implement
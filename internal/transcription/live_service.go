@@ -60,6 +60,7 @@ type StreamSegment struct {
 
 // CreateLiveSessionInput represents the inputs necessary to bootstrap a live session.
 type CreateLiveSessionInput struct {
+	UserID     uint
 	Title      *string
 	Parameters *models.WhisperXParams
 }
@@ -145,6 +146,7 @@ func (s *LiveTranscriptionService) CreateSession(ctx context.Context, input Crea
 	}
 
 	session := &models.LiveTranscriptionSession{
+		UserID:     input.UserID,
 		Title:      input.Title,
 		Parameters: params,
 		Status:     models.LiveStatusActive,
@@ -196,7 +198,7 @@ func (s *LiveTranscriptionService) AppendChunk(ctx context.Context, sessionID st
 		return nil, err
 	}
 
-	transcript, err := s.unified.TranscribeFile(ctx, normalizedPath, session.Parameters)
+	transcript, err := s.unified.TranscribeFile(ctx, normalizedPath, session.Parameters, "live-session")
 	if err != nil {
 		return nil, fmt.Errorf("chunk transcription failed: %w", err)
 	}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"sync"
@@ -14,7 +15,9 @@ import (
 
 	"synthezia/internal/database"
 	"synthezia/internal/models"
+	"synthezia/internal/search"
 	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/transcription/pipeline"
 	"synthezia/pkg/logger"
 
 	"gorm.io/gorm"
@@ -48,8 +51,9 @@ type TrackTranscript struct {
 	Result   *interfaces.TranscriptResult `json:"result"`
 }
 
-// ProcessMultiTrackTranscription processes a multi-track transcription job
-func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Context, jobID string) error {
+// ProcessMultiTrackTranscription processes a multi-track transcription job. The preprocessing
+// applied to the tracks is recorded on execution, if set.
+func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Context, jobID string, execution *models.TranscriptionJobExecution) error {
 	overallStartTime := time.Now()
 	
 	// Load the job and track files
@@ -91,6 +95,7 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 	trackTranscripts := make([]TrackTranscript, 0, len(job.MultiTrackFiles))
 	individualTranscripts := make(map[string]string)
 	trackTimings := make([]models.MultiTrackTiming, 0, len(job.MultiTrackFiles))
+	var filterChains []string
 
 	for i, trackFile := range job.MultiTrackFiles {
 		trackStartTime := time.Now()
@@ -102,10 +107,18 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 			"offset", trackFile.Offset)
 
 		// Create a temporary job for this individual track
-		trackResult, err := mt.transcribeIndividualTrack(ctx, &job, &trackFile)
+		trackResult, filters, err := mt.transcribeIndividualTrack(ctx, &job, &trackFile)
 		trackEndTime := time.Now()
 		trackDuration := trackEndTime.Sub(trackStartTime).Milliseconds()
-		
+
+		// Tracks share the job's parameters, so they usually share one filter chain
+		if filters != "" && !slices.Contains(filterChains, filters) {
+			filterChains = append(filterChains, filters)
+			recordPreprocessing(execution, map[string]string{
+				pipeline.FilterChainMetadataKey: strings.Join(filterChains, "; "),
+			})
+		}
+
 		if err != nil {
 			return fmt.Errorf("failed to transcribe track %s: %w", trackFile.FileName, err)
 		}
@@ -193,22 +206,25 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 	}
 
 	// Save results to database
-	updates := map[string]interface{}{
-		"transcript":             &mergedTranscriptStr,
-		"individual_transcripts": &individualTranscriptsStr,
-		"status":                 models.StatusCompleted,
-	}
+	updates := transcriptMetadata(mergedTranscript)
+	updates["transcript"] = &mergedTranscriptStr
+	updates["individual_transcripts"] = &individualTranscriptsStr
+	updates["status"] = models.StatusCompleted
 
 	if err := mt.db.Model(&models.TranscriptionJob{}).Where("id = ?", jobID).Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to save transcription results: %w", err)
 	}
 
+	if err := search.IndexTranscript(jobID, mergedTranscript); err != nil {
+		logger.Warn("Failed to index merged transcript for search", "job_id", jobID, "error", err)
+	}
+
 	// Create execution record with timing data for multi-track job
 	overallEndTime := time.Now()
 	overallDuration := overallEndTime.Sub(overallStartTime).Milliseconds()
 
 	if err := mt.createMultiTrackExecutionRecord(jobID, overallStartTime, overallEndTime, overallDuration, 
-		trackTimings, mergeStartTime, mergeEndTime, mergeDuration, job.Parameters); err != nil {
+		trackTimings, mergeStartTime, mergeEndTime, mergeDuration, job.Parameters, strings.Join(filterChains, "; ")); err != nil {
 		logger.Warn("Failed to create execution record", "job_id", jobID, "error", err)
 		// Don't fail the job for execution record issues, just log the warning
 	}
@@ -221,8 +237,9 @@ func (mt *MultiTrackTranscriber) ProcessMultiTrackTranscription(ctx context.Cont
 	return nil
 }
 
-// transcribeIndividualTrack transcribes a single track file using the direct transcription method
-func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context, job *models.TranscriptionJob, trackFile *models.MultiTrackFile) (*interfaces.TranscriptResult, error) {
+// transcribeIndividualTrack transcribes a single track file using the direct transcription method.
+// It also returns the preprocessing filters applied to the track's audio, if any.
+func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context, job *models.TranscriptionJob, trackFile *models.MultiTrackFile) (*interfaces.TranscriptResult, string, error) {
 	// Create a proper copy of parameters for this track (disable diarization, enable word timestamps)
 	trackParams := job.Parameters
 
@@ -280,6 +297,7 @@ func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context,
 	// Use StatusProcessing to prevent the main queue scanner from picking it up
 	tempJob := models.TranscriptionJob{
 		ID:         trackJobID,
+		UserID:     job.UserID,
 		AudioPath:  trackFile.FilePath,
 		Parameters: trackParams,
 		Status:     models.StatusProcessing, // Prevent queue scanner from picking this up
@@ -287,29 +305,30 @@ func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context,
 	
 	// Save temporary job to database for processing
 	if err := mt.db.Create(&tempJob).Error; err != nil {
-		return nil, fmt.Errorf("failed to create temp database entry for track: %w", err)
+		return nil, "", fmt.Errorf("failed to create temp database entry for track: %w", err)
 	}
 	
 	// Process with unified service - check for cancellation first
 	select {
 	case <-ctx.Done():
 		mt.cleanupTempJob(trackJobID)
-		return nil, fmt.Errorf("track transcription was cancelled")
+		return nil, "", fmt.Errorf("track transcription was cancelled")
 	default:
 	}
 	
 	err := mt.unifiedProcessor.ProcessJob(ctx, trackJobID)
+	filters := mt.trackPreprocessing(trackJobID)
 	if err != nil {
 		// Clean up temp job and associated records
 		mt.cleanupTempJob(trackJobID)
-		return nil, fmt.Errorf("failed to transcribe track file %s: %w", trackFile.FilePath, err)
+		return nil, "", fmt.Errorf("failed to transcribe track file %s: %w", trackFile.FilePath, err)
 	}
 	
 	// Load the processed result
 	var processedJob models.TranscriptionJob
 	if err := mt.db.Where("id = ?", trackJobID).First(&processedJob).Error; err != nil {
 		mt.cleanupTempJob(trackJobID)
-		return nil, fmt.Errorf("failed to load processed track result: %w", err)
+		return nil, "", fmt.Errorf("failed to load processed track result: %w", err)
 	}
 	
 	// Parse the transcript result
@@ -318,11 +337,11 @@ func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context,
 		result = &interfaces.TranscriptResult{}
 		if err := json.Unmarshal([]byte(*processedJob.Transcript), result); err != nil {
 			mt.cleanupTempJob(trackJobID)
-			return nil, fmt.Errorf("failed to parse track transcript: %w", err)
+			return nil, "", fmt.Errorf("failed to parse track transcript: %w", err)
 		}
 	} else {
 		mt.cleanupTempJob(trackJobID)
-		return nil, fmt.Errorf("no transcript found for track")
+		return nil, "", fmt.Errorf("no transcript found for track")
 	}
 	
 	// Clean up temporary database entry and associated records
@@ -334,7 +353,25 @@ func (mt *MultiTrackTranscriber) transcribeIndividualTrack(ctx context.Context,
 		"word_count", len(result.WordSegments),
 		"segment_count", len(result.Segments))
 
-	return result, nil
+	return result, filters, nil
+}
+
+// trackPreprocessing returns the preprocessing filters recorded for a track job, before the
+// job's execution records are cleaned up
+func (mt *MultiTrackTranscriber) trackPreprocessing(trackJobID string) string {
+	var execution models.TranscriptionJobExecution
+	err := mt.db.Select("preprocessing_filters").
+		Where("transcription_job_id = ? AND preprocessing_filters IS NOT NULL", trackJobID).
+		Order("created_at DESC").
+		Limit(1).Find(&execution).Error
+	if err != nil {
+		logger.Warn("Failed to load track preprocessing", "track_job_id", trackJobID, "error", err)
+		return ""
+	}
+	if execution.PreprocessingFilters == nil {
+		return ""
+	}
+	return *execution.PreprocessingFilters
 }
 
 // cleanupTempJob properly deletes a temporary job and all associated records
@@ -766,7 +803,8 @@ func (mt *MultiTrackTranscriber) createMultiTrackExecutionRecord(
 	trackTimings []models.MultiTrackTiming,
 	mergeStartTime, mergeEndTime time.Time,
 	mergeDuration int64,
-	parameters models.WhisperXParams) error {
+	parameters models.WhisperXParams,
+	preprocessingFilters string) error {
 
 	// Serialize track timings to JSON
 	trackTimingsJSON, err := json.Marshal(trackTimings)
@@ -791,6 +829,9 @@ func (mt *MultiTrackTranscriber) createMultiTrackExecutionRecord(
 		ActualParameters: parameters,
 		Status:          models.StatusCompleted,
 	}
+	if preprocessingFilters != "" {
+		execution.PreprocessingFilters = &preprocessingFilters
+	}
 
 	if err := mt.db.Create(execution).Error; err != nil {
 		return fmt.Errorf("failed to create execution record: %w", err)
@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+)
+
+// Denoise methods
+const (
+	DenoiseNone    = ""
+	DenoiseFFT     = "afftdn" // FFT-based spectral denoiser, no model needed
+	DenoiseRNNoise = "arnndn" // recurrent network denoiser, needs an RNNoise model file
+)
+
+// DefaultLoudnormTarget is the EBU R128 integrated loudness target in LUFS
+const DefaultLoudnormTarget = -23.0
+
+// RNNoiseModelDirectory holds the .rnnn model files arnndn can be pointed at
+var RNNoiseModelDirectory = "data/rnnoise"
+
+// FilterChainMetadataKey is the AudioInput metadata key listing the ffmpeg filters preprocessing applied
+const FilterChainMetadataKey = "filter_chain"
+
+var rnnoiseModelName = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// ValidateDenoise checks a denoise method and, for arnndn, the model file name
+func ValidateDenoise(method, model string) error {
+	switch method {
+	case DenoiseNone, DenoiseFFT:
+		return nil
+	case DenoiseRNNoise:
+		if model == "" {
+			return fmt.Errorf("arnndn needs a denoise_model")
+		}
+		if !rnnoiseModelName.MatchString(model) || strings.HasPrefix(model, ".") {
+			return fmt.Errorf("invalid denoise_model %q: must be a file name in %s", model, RNNoiseModelDirectory)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid denoise method %q: must be afftdn or arnndn", method)
+}
+
+// NoiseReductionPreprocessor cleans up audio in one ffmpeg pass: a high-pass filter against rumble,
+// a denoiser, then EBU R128 loudness normalization. Stages left at their zero value are skipped.
+type NoiseReductionPreprocessor struct {
+	HighpassHz     int    // cutoff frequency, 0 disables
+	Denoise        string // DenoiseNone, DenoiseFFT or DenoiseRNNoise
+	DenoiseModel   string // RNNoise model file in RNNoiseModelDirectory, for DenoiseRNNoise
+	Loudnorm       bool
+	LoudnormTarget float64 // integrated loudness in LUFS, DefaultLoudnormTarget when 0
+}
+
+// AppliesTo checks if this preprocessor should be used. It is added per job, so it applies to every model.
+func (n *NoiseReductionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
+	return true
+}
+
+// GetRequiredFormats returns the output formats this preprocessor can produce
+func (n *NoiseReductionPreprocessor) GetRequiredFormats() []string {
+	return []string{"wav"}
+}
+
+// FilterChain returns the ffmpeg audio filter chain for the enabled stages, or "" if none are
+func (n *NoiseReductionPreprocessor) FilterChain() (string, error) {
+	var filters []string
+
+	if n.HighpassHz > 0 {
+		filters = append(filters, fmt.Sprintf("highpass=f=%d", n.HighpassHz))
+	}
+
+	if err := ValidateDenoise(n.Denoise, n.DenoiseModel); err != nil {
+		return "", err
+	}
+	switch n.Denoise {
+	case DenoiseFFT:
+		filters = append(filters, "afftdn=nf=-25")
+	case DenoiseRNNoise:
+		model := filepath.ToSlash(filepath.Join(RNNoiseModelDirectory, n.DenoiseModel))
+		filters = append(filters, "arnndn=m="+model)
+	}
+
+	if n.Loudnorm {
+		target := n.LoudnormTarget
+		if target == 0 {
+			target = DefaultLoudnormTarget
+		}
+		filters = append(filters, fmt.Sprintf("loudnorm=I=%g:TP=-2:LRA=11", target))
+	}
+
+	return strings.Join(filters, ","), nil
+}
+
+// Process runs the filter chain and writes the cleaned audio as 16kHz mono wav
+func (n *NoiseReductionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	chain, err := n.FilterChain()
+	if err != nil {
+		return input, err
+	}
+	if chain == "" {
+		return input, nil
+	}
+	if n.Denoise == DenoiseRNNoise {
+		model := filepath.Join(RNNoiseModelDirectory, n.DenoiseModel)
+		if _, err := os.Stat(model); err != nil {
+			return input, fmt.Errorf("denoise model not found: %w", err)
+		}
+	}
+
+	logger.Info("Enhancing audio", "file", input.FilePath, "filters", chain)
+
+	outputPath := strings.TrimSuffix(input.FilePath, filepath.Ext(input.FilePath)) + "_enhanced.wav"
+	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-nostats",
+		"-i", input.FilePath,
+		"-af", chain,
+		"-ar", strconv.Itoa(16000),
+		"-ac", "1",
+		"-c:a", "pcm_s16le",
+		"-y", outputPath)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg audio enhancement failed", "output", string(output), "error", err)
+		os.Remove(outputPath)
+		return input, fmt.Errorf("audio enhancement failed: %w", err)
+	}
+
+	enhanced := interfaces.AudioInput{
+		FilePath:     outputPath,
+		Format:       "wav",
+		SampleRate:   16000,
+		Channels:     1,
+		Duration:     input.Duration,
+		Metadata:     withFilter(input.Metadata, chain),
+		TempFilePath: outputPath,
+	}
+	if stat, err := os.Stat(outputPath); err == nil {
+		enhanced.Size = stat.Size()
+	}
+	return enhanced, nil
+}
+
+// withFilter returns a copy of metadata with filter appended to its filter chain
+func withFilter(metadata map[string]string, filter string) map[string]string {
+	copied := make(map[string]string, len(metadata)+1)
+	for k, v := range metadata {
+		copied[k] = v
+	}
+	if existing := copied[FilterChainMetadataKey]; existing != "" {
+		filter = existing + ";" + filter
+	}
+	copied[FilterChainMetadataKey] = filter
+	return copied
+}
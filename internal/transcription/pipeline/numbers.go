@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type numberWordKind int
+
+const (
+	kindUnit    numberWordKind = iota // zero..nine
+	kindTeen                          // ten..nineteen
+	kindTens                          // twenty..ninety
+	kindHundred                       // hundred
+	kindScale                         // thousand, million, billion
+	kindAnd                           // "and" inside a number, as in "one hundred and five"
+)
+
+type numberWord struct {
+	kind  numberWordKind
+	value int64
+}
+
+var numberWords = map[string]numberWord{
+	"zero": {kindUnit, 0}, "one": {kindUnit, 1}, "two": {kindUnit, 2}, "three": {kindUnit, 3},
+	"four": {kindUnit, 4}, "five": {kindUnit, 5}, "six": {kindUnit, 6}, "seven": {kindUnit, 7},
+	"eight": {kindUnit, 8}, "nine": {kindUnit, 9},
+	"ten": {kindTeen, 10}, "eleven": {kindTeen, 11}, "twelve": {kindTeen, 12}, "thirteen": {kindTeen, 13},
+	"fourteen": {kindTeen, 14}, "fifteen": {kindTeen, 15}, "sixteen": {kindTeen, 16},
+	"seventeen": {kindTeen, 17}, "eighteen": {kindTeen, 18}, "nineteen": {kindTeen, 19},
+	"twenty": {kindTens, 20}, "thirty": {kindTens, 30}, "forty": {kindTens, 40}, "fifty": {kindTens, 50},
+	"sixty": {kindTens, 60}, "seventy": {kindTens, 70}, "eighty": {kindTens, 80}, "ninety": {kindTens, 90},
+	"hundred":  {kindHundred, 100},
+	"thousand": {kindScale, 1000}, "million": {kindScale, 1000000}, "billion": {kindScale, 1000000000},
+	"and": {kindAnd, 0},
+}
+
+// follows reports whether a number word of kind next may come after one of kind prev
+func follows(prev, next numberWord) bool {
+	switch prev.kind {
+	case kindUnit, kindTeen:
+		return next.kind == kindHundred || next.kind == kindScale
+	case kindTens:
+		return (next.kind == kindUnit && next.value > 0) || next.kind == kindScale
+	case kindHundred:
+		return next.kind != kindHundred
+	case kindScale:
+		return next.kind != kindHundred && next.kind != kindScale
+	case kindAnd:
+		return next.kind == kindUnit || next.kind == kindTeen || next.kind == kindTens
+	}
+	return false
+}
+
+// splitToken separates a token into leading punctuation, the word and trailing punctuation
+func splitToken(token string) (lead, word, trail string) {
+	start := strings.IndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+	if start < 0 {
+		return token, "", ""
+	}
+	end := strings.LastIndexFunc(token, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) })
+	return token[:start], token[start : end+1], token[end+1:]
+}
+
+// lookupNumberWords returns the number words of a token; hyphenated forms like "twenty-one" give two
+func lookupNumberWords(word string) ([]numberWord, bool) {
+	var words []numberWord
+	for _, part := range strings.Split(strings.ToLower(word), "-") {
+		w, ok := numberWords[part]
+		if !ok || (w.kind == kindAnd && len(words) > 0) {
+			return nil, false
+		}
+		words = append(words, w)
+	}
+	return words, len(words) > 0
+}
+
+// numberRun is a stretch of tokens spelling out one number
+type numberRun struct {
+	start, end int // tokens[start:end]
+	value      int64
+}
+
+// findNumberRuns finds spelled-out numbers in a token sequence. A lone word below ten
+// ("one of them") is left alone, since it reads better as a word.
+func findNumberRuns(tokens []string) []numberRun {
+	var runs []numberRun
+
+	for i := 0; i < len(tokens); {
+		end := numberRunEnd(tokens, i)
+		if end == i {
+			i++
+			continue
+		}
+		run := numberRun{start: i, end: end, value: numberValue(tokens[i:end])}
+		if end-i > 1 || run.value >= 10 {
+			runs = append(runs, run)
+		}
+		i = end
+	}
+	return runs
+}
+
+// numberRunEnd returns the end of the longest number starting at tokens[i], or i if none does
+func numberRunEnd(tokens []string, i int) int {
+	var prev *numberWord
+	end := i
+
+	for j := i; j < len(tokens); j++ {
+		lead, word, trail := splitToken(tokens[j])
+		if lead != "" && j > i {
+			break // punctuation before a word starts something new
+		}
+		words, ok := lookupNumberWords(word)
+		if !ok {
+			break
+		}
+		for k := range words {
+			w := words[k]
+			if prev == nil && w.kind != kindUnit && w.kind != kindTeen && w.kind != kindTens {
+				return end
+			}
+			if prev != nil && !follows(*prev, w) {
+				return end
+			}
+			prev = &words[k]
+		}
+		if prev.kind != kindAnd {
+			end = j + 1 // a number can't end on "and"
+		}
+		if trail != "" {
+			break // punctuation ends the number
+		}
+	}
+	return end
+}
+
+// numberValue computes the value of tokens that numberRunEnd accepted as one number
+func numberValue(tokens []string) int64 {
+	var total, current int64
+	for _, token := range tokens {
+		_, word, _ := splitToken(token)
+		words, _ := lookupNumberWords(word)
+		for _, w := range words {
+			switch w.kind {
+			case kindUnit, kindTeen, kindTens:
+				current += w.value
+			case kindHundred:
+				current *= 100
+			case kindScale:
+				total += current * w.value
+				current = 0
+			}
+		}
+	}
+	return total + current
+}
+
+// numberText renders a run as digits, keeping the punctuation around it
+func numberText(tokens []string, run numberRun) string {
+	lead, _, _ := splitToken(tokens[run.start])
+	_, _, trail := splitToken(tokens[run.end-1])
+	return lead + strconv.FormatInt(run.value, 10) + trail
+}
+
+// NormalizeNumbers replaces spelled-out English numbers in text with digits
+func NormalizeNumbers(text string) string {
+	tokens := strings.Fields(text)
+	runs := findNumberRuns(tokens)
+	if len(runs) == 0 {
+		return text
+	}
+
+	var out []string
+	next := 0
+	for _, run := range runs {
+		out = append(out, tokens[next:run.start]...)
+		out = append(out, numberText(tokens, run))
+		next = run.end
+	}
+	out = append(out, tokens[next:]...)
+
+	// Keep the leading space Whisper puts before segment text
+	leading := text[:len(text)-len(strings.TrimLeftFunc(text, unicode.IsSpace))]
+	return leading + strings.Join(out, " ")
+}
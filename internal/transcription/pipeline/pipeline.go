@@ -29,6 +29,14 @@ func NewProcessingPipeline() *ProcessingPipeline {
 	// Register default preprocessors
 	pipeline.RegisterPreprocessor(&AudioFormatPreprocessor{})
 
+	// Register default postprocessors. Each only runs when its parameters are passed; text
+	// rewriting goes numbers, then user replacements, then profanity masking so that
+	// replacements see digits and can't reintroduce masked words.
+	pipeline.RegisterPostprocessor(&TimestampRemapPostprocessor{})
+	pipeline.RegisterPostprocessor(&NumberPostprocessor{})
+	pipeline.RegisterPostprocessor(&ReplacementPostprocessor{})
+	pipeline.RegisterPostprocessor(&ProfanityPostprocessor{})
+
 	return pipeline
 }
 
@@ -42,11 +50,14 @@ func (p *ProcessingPipeline) RegisterPostprocessor(postprocessor interfaces.Post
 	p.postprocessors = append(p.postprocessors, postprocessor)
 }
 
-// ProcessAudio applies all applicable preprocessors to the audio input
-func (p *ProcessingPipeline) ProcessAudio(ctx context.Context, input interfaces.AudioInput, capabilities interfaces.ModelCapabilities) (interfaces.AudioInput, error) {
+// ProcessAudio applies all applicable preprocessors to the audio input, followed by any
+// job-specific ones. Intermediate files a later stage replaced are removed; the returned
+// input's TempFilePath is left for the caller to clean up.
+func (p *ProcessingPipeline) ProcessAudio(ctx context.Context, input interfaces.AudioInput, capabilities interfaces.ModelCapabilities, jobPreprocessors ...interfaces.Preprocessor) (interfaces.AudioInput, error) {
 	currentInput := input
 
-	for _, preprocessor := range p.preprocessors {
+	preprocessors := append(append([]interfaces.Preprocessor{}, p.preprocessors...), jobPreprocessors...)
+	for _, preprocessor := range preprocessors {
 		if preprocessor.AppliesTo(capabilities) {
 			logger.Info("Applying preprocessor", "type", fmt.Sprintf("%T", preprocessor))
 			processedInput, err := preprocessor.Process(ctx, currentInput)
@@ -54,6 +65,10 @@ func (p *ProcessingPipeline) ProcessAudio(ctx context.Context, input interfaces.
 				logger.Warn("Preprocessor failed, continuing with original input", "error", err)
 				continue
 			}
+			if currentInput.TempFilePath != "" && currentInput.TempFilePath != input.FilePath &&
+				currentInput.TempFilePath != processedInput.TempFilePath {
+				os.Remove(currentInput.TempFilePath)
+			}
 			currentInput = processedInput
 		}
 	}
@@ -61,6 +76,36 @@ func (p *ProcessingPipeline) ProcessAudio(ctx context.Context, input interfaces.
 	return currentInput, nil
 }
 
+// ProcessTranscript applies all applicable postprocessors to a transcription result
+func (p *ProcessingPipeline) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, capabilities interfaces.ModelCapabilities, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	for _, postprocessor := range p.postprocessors {
+		if !postprocessor.AppliesTo(capabilities, params) {
+			continue
+		}
+		processed, err := postprocessor.ProcessTranscript(ctx, result, params)
+		if err != nil {
+			return result, fmt.Errorf("%T failed: %w", postprocessor, err)
+		}
+		result = processed
+	}
+	return result, nil
+}
+
+// ProcessDiarization applies all applicable postprocessors to a diarization result
+func (p *ProcessingPipeline) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, capabilities interfaces.ModelCapabilities, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	for _, postprocessor := range p.postprocessors {
+		if !postprocessor.AppliesTo(capabilities, params) {
+			continue
+		}
+		processed, err := postprocessor.ProcessDiarization(ctx, result, params)
+		if err != nil {
+			return result, fmt.Errorf("%T failed: %w", postprocessor, err)
+		}
+		result = processed
+	}
+	return result, nil
+}
+
 // AudioFormatPreprocessor converts audio to required formats
 type AudioFormatPreprocessor struct{}
 
@@ -142,50 +187,6 @@ func (a *AudioFormatPreprocessor) Process(ctx context.Context, input interfaces.
 	return convertedInput, nil
 }
 
-// VoiceActivityDetectionPreprocessor applies VAD preprocessing
-type VoiceActivityDetectionPreprocessor struct{}
-
-// AppliesTo checks if this preprocessor should be used
-func (v *VoiceActivityDetectionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
-	// Apply to models that benefit from VAD preprocessing
-	return capabilities.Features["vad"] == true
-}
-
-// GetRequiredFormats returns the output formats this preprocessor can produce
-func (v *VoiceActivityDetectionPreprocessor) GetRequiredFormats() []string {
-	return []string{"wav", "mp3", "flac"}
-}
-
-// Process applies voice activity detection preprocessing
-func (v *VoiceActivityDetectionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
-	// For now, this is a placeholder
-	// In a real implementation, this would apply VAD to remove silence
-	logger.Info("VAD preprocessing (placeholder)", "file", input.FilePath)
-	return input, nil
-}
-
-// NoiseReductionPreprocessor applies noise reduction
-type NoiseReductionPreprocessor struct{}
-
-// AppliesTo checks if this preprocessor should be used
-func (n *NoiseReductionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
-	// Apply to models that would benefit from noise reduction
-	return capabilities.Features["high_quality"] == true
-}
-
-// GetRequiredFormats returns the output formats this preprocessor can produce
-func (n *NoiseReductionPreprocessor) GetRequiredFormats() []string {
-	return []string{"wav"}
-}
-
-// Process applies noise reduction preprocessing
-func (n *NoiseReductionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
-	// For now, this is a placeholder
-	// In a real implementation, this would apply noise reduction using FFmpeg or other tools
-	logger.Info("Noise reduction preprocessing (placeholder)", "file", input.FilePath)
-	return input, nil
-}
-
 // TextPostprocessor handles transcription result post-processing
 type TextPostprocessor struct{}
 
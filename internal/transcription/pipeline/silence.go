@@ -0,0 +1,333 @@
+package pipeline
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/pkg/logger"
+)
+
+// Silence removal defaults, used when the job parameters leave them unset
+const (
+	DefaultSilenceThresholdDB = -35.0 // quieter than this counts as silence
+	DefaultSilenceMinDuration = 2.0   // seconds; shorter pauses are kept
+	silencePadding            = 0.25  // seconds of each cut silence kept on both sides so words aren't clipped
+)
+
+// OffsetMapMetadataKey is the AudioInput metadata key carrying the JSON-encoded OffsetMap of compacted audio
+const OffsetMapMetadataKey = "silence_offset_map"
+
+// OffsetMapParam is the postprocessor parameter holding the OffsetMap to remap timestamps with
+const OffsetMapParam = "offset_map"
+
+// KeptSpan is a stretch of original audio kept in the compacted file
+type KeptSpan struct {
+	CompactStart  float64 `json:"compact_start"`  // seconds into the compacted audio
+	OriginalStart float64 `json:"original_start"` // seconds into the original audio
+	Duration      float64 `json:"duration"`
+}
+
+// OffsetMap maps compacted audio time back to the original timeline
+type OffsetMap struct {
+	Spans            []KeptSpan `json:"spans"`
+	OriginalDuration float64    `json:"original_duration,omitempty"` // 0 when unknown
+}
+
+// ToOriginal converts a time in the compacted audio to the original audio's timeline
+func (m OffsetMap) ToOriginal(t float64) float64 {
+	if len(m.Spans) == 0 {
+		return t
+	}
+	last := len(m.Spans) - 1
+	index := 0
+	for i, s := range m.Spans {
+		if s.CompactStart > t {
+			break
+		}
+		index = i
+	}
+	span := m.Spans[index]
+	offset := t - span.CompactStart
+	if offset > span.Duration && index != last {
+		offset = span.Duration
+	}
+	return span.OriginalStart + offset
+}
+
+// RemovedSeconds returns how much audio the compaction cut
+func (m OffsetMap) RemovedSeconds() float64 {
+	if len(m.Spans) == 0 {
+		return 0
+	}
+	last := m.Spans[len(m.Spans)-1]
+	if m.OriginalDuration > 0 && last.Duration != math.MaxFloat64 {
+		return m.OriginalDuration - (last.CompactStart + last.Duration)
+	}
+	return last.OriginalStart - last.CompactStart
+}
+
+// OffsetMapFromMetadata decodes the offset map a silence removal stage attached to its output
+func OffsetMapFromMetadata(metadata map[string]string) (OffsetMap, bool) {
+	encoded, ok := metadata[OffsetMapMetadataKey]
+	if !ok || encoded == "" {
+		return OffsetMap{}, false
+	}
+	var offsets OffsetMap
+	if err := json.Unmarshal([]byte(encoded), &offsets); err != nil || len(offsets.Spans) == 0 {
+		return OffsetMap{}, false
+	}
+	return offsets, true
+}
+
+// Silence is a silent interval detected in the audio, in seconds. End is +Inf when the silence runs to the end.
+type Silence struct {
+	Start float64
+	End   float64
+}
+
+var (
+	silenceStartPattern = regexp.MustCompile(`silence_start:\s*(-?[0-9.]+)`)
+	silenceEndPattern   = regexp.MustCompile(`silence_end:\s*([0-9.]+)`)
+)
+
+// ParseSilenceDetect extracts silent intervals from ffmpeg silencedetect output
+func ParseSilenceDetect(output []byte) []Silence {
+	var silences []Silence
+	open := false
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if m := silenceStartPattern.FindStringSubmatch(line); m != nil {
+			start, err := strconv.ParseFloat(m[1], 64)
+			if err != nil {
+				continue
+			}
+			silences = append(silences, Silence{Start: math.Max(start, 0), End: math.Inf(1)})
+			open = true
+		} else if m := silenceEndPattern.FindStringSubmatch(line); m != nil && open {
+			if end, err := strconv.ParseFloat(m[1], 64); err == nil {
+				silences[len(silences)-1].End = end
+			}
+			open = false
+		}
+	}
+	return silences
+}
+
+// BuildOffsetMap works out which audio to keep once the given silences are cut, keeping padding
+// seconds of each silence next to the speech around it. duration may be 0 when unknown.
+func BuildOffsetMap(silences []Silence, duration, padding float64) OffsetMap {
+	offsets := OffsetMap{OriginalDuration: math.Max(duration, 0)}
+	position, compact := 0.0, 0.0
+
+	keep := func(end float64) {
+		if end <= position {
+			return
+		}
+		offsets.Spans = append(offsets.Spans, KeptSpan{CompactStart: compact, OriginalStart: position, Duration: end - position})
+		compact += end - position
+	}
+
+	for _, silence := range silences {
+		cutStart := silence.Start + padding
+		if silence.Start <= 0 {
+			cutStart = 0 // leading silence needs no padding
+		}
+		cutEnd := silence.End - padding
+		if math.IsInf(silence.End, 1) || (duration > 0 && silence.End >= duration) {
+			cutEnd = math.Inf(1) // trailing silence is dropped entirely
+		}
+		if cutEnd <= cutStart {
+			continue
+		}
+		keep(cutStart)
+		position = cutEnd
+	}
+
+	if !math.IsInf(position, 1) {
+		if duration > position {
+			keep(duration)
+		} else if duration <= 0 {
+			// Unknown length: keep the rest, open-ended
+			offsets.Spans = append(offsets.Spans, KeptSpan{CompactStart: compact, OriginalStart: position, Duration: math.MaxFloat64})
+		}
+	}
+	return offsets
+}
+
+// selectExpression builds the aselect filter expression keeping the spans of an offset map
+func selectExpression(offsets OffsetMap) string {
+	parts := make([]string, 0, len(offsets.Spans))
+	for _, span := range offsets.Spans {
+		if span.Duration == math.MaxFloat64 {
+			parts = append(parts, fmt.Sprintf("gte(t,%.3f)", span.OriginalStart))
+		} else {
+			parts = append(parts, fmt.Sprintf("between(t,%.3f,%.3f)", span.OriginalStart, span.OriginalStart+span.Duration))
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// VoiceActivityDetectionPreprocessor cuts long silences out of the audio. The compacted file
+// carries an OffsetMap in its metadata so TimestampRemapPostprocessor can restore the original timeline.
+type VoiceActivityDetectionPreprocessor struct {
+	ThresholdDB float64 // silence threshold in dBFS, DefaultSilenceThresholdDB when 0
+	MinSilence  float64 // minimum silence length to cut in seconds, DefaultSilenceMinDuration when 0
+}
+
+// AppliesTo checks if this preprocessor should be used. It is added per job, so it applies to every model.
+func (v *VoiceActivityDetectionPreprocessor) AppliesTo(capabilities interfaces.ModelCapabilities) bool {
+	return true
+}
+
+// GetRequiredFormats returns the output formats this preprocessor can produce
+func (v *VoiceActivityDetectionPreprocessor) GetRequiredFormats() []string {
+	return []string{"wav"}
+}
+
+// Process detects silences with ffmpeg silencedetect and writes the audio without them
+func (v *VoiceActivityDetectionPreprocessor) Process(ctx context.Context, input interfaces.AudioInput) (interfaces.AudioInput, error) {
+	threshold := v.ThresholdDB
+	if threshold == 0 {
+		threshold = DefaultSilenceThresholdDB
+	}
+	minSilence := v.MinSilence
+	if minSilence <= 0 {
+		minSilence = DefaultSilenceMinDuration
+	}
+
+	detect := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-nostats",
+		"-i", input.FilePath,
+		"-af", fmt.Sprintf("silencedetect=noise=%gdB:d=%g", threshold, minSilence),
+		"-f", "null", "-")
+	output, err := detect.CombinedOutput()
+	if err != nil {
+		return input, fmt.Errorf("silence detection failed: %w", err)
+	}
+
+	duration := input.Duration.Seconds()
+	offsets := BuildOffsetMap(ParseSilenceDetect(output), duration, silencePadding)
+	if len(offsets.Spans) == 0 || offsets.RemovedSeconds() <= 0 {
+		logger.Info("No long silences found", "file", input.FilePath)
+		return input, nil
+	}
+
+	outputPath := strings.TrimSuffix(input.FilePath, filepath.Ext(input.FilePath)) + "_vad.wav"
+	filter := fmt.Sprintf("aselect='%s',asetpts=N/SR/TB", selectExpression(offsets))
+	cut := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-nostats",
+		"-i", input.FilePath,
+		"-af", filter,
+		"-ar", strconv.Itoa(16000),
+		"-ac", "1",
+		"-c:a", "pcm_s16le",
+		"-y", outputPath)
+	if output, err := cut.CombinedOutput(); err != nil {
+		logger.Error("FFmpeg silence removal failed", "output", string(output), "error", err)
+		os.Remove(outputPath)
+		return input, fmt.Errorf("silence removal failed: %w", err)
+	}
+
+	encoded, err := json.Marshal(offsets)
+	if err != nil {
+		os.Remove(outputPath)
+		return input, fmt.Errorf("failed to encode offset map: %w", err)
+	}
+
+	metadata := withFilter(input.Metadata, filter)
+	metadata[OffsetMapMetadataKey] = string(encoded)
+
+	removed := offsets.RemovedSeconds()
+	compacted := interfaces.AudioInput{
+		FilePath:     outputPath,
+		Format:       "wav",
+		SampleRate:   16000,
+		Channels:     1,
+		Metadata:     metadata,
+		TempFilePath: outputPath,
+	}
+	if duration > 0 {
+		compacted.Duration = time.Duration((duration - removed) * float64(time.Second))
+	}
+	if stat, err := os.Stat(outputPath); err == nil {
+		compacted.Size = stat.Size()
+	}
+
+	logger.Info("Removed silences",
+		"file", input.FilePath,
+		"removed_seconds", removed,
+		"kept_spans", len(offsets.Spans))
+
+	return compacted, nil
+}
+
+// TimestampRemapPostprocessor moves timestamps from compacted audio back onto the original timeline
+type TimestampRemapPostprocessor struct{}
+
+// AppliesTo reports whether the audio was compacted, i.e. an offset map was passed
+func (t *TimestampRemapPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	offsets, ok := params[OffsetMapParam].(OffsetMap)
+	return ok && len(offsets.Spans) > 0
+}
+
+// ProcessTranscript remaps segment and word timestamps
+func (t *TimestampRemapPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	offsets, _ := params[OffsetMapParam].(OffsetMap)
+	if result == nil || len(offsets.Spans) == 0 {
+		return result, nil
+	}
+
+	for i := range result.Segments {
+		result.Segments[i].Start, result.Segments[i].End = remapSpan(offsets, result.Segments[i].Start, result.Segments[i].End)
+	}
+	for i := range result.WordSegments {
+		result.WordSegments[i].Start, result.WordSegments[i].End = remapSpan(offsets, result.WordSegments[i].Start, result.WordSegments[i].End)
+	}
+
+	if result.Metadata == nil {
+		result.Metadata = map[string]string{}
+	}
+	result.Metadata["silence_removed_seconds"] = strconv.FormatFloat(offsets.RemovedSeconds(), 'f', 3, 64)
+	return result, nil
+}
+
+// ProcessDiarization remaps speaker turn timestamps
+func (t *TimestampRemapPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	offsets, _ := params[OffsetMapParam].(OffsetMap)
+	if result == nil || len(offsets.Spans) == 0 {
+		return result, nil
+	}
+	for i := range result.Segments {
+		result.Segments[i].Start, result.Segments[i].End = remapSpan(offsets, result.Segments[i].Start, result.Segments[i].End)
+	}
+	return result, nil
+}
+
+// remapSpan maps a start/end pair, treating the end as inclusive so a span ending exactly
+// at a cut stays inside the stretch of audio it came from
+func remapSpan(offsets OffsetMap, start, end float64) (float64, float64) {
+	originalStart := offsets.ToOriginal(start)
+	originalEnd := offsets.ToOriginal(end)
+	for i := 1; i < len(offsets.Spans); i++ {
+		if end == offsets.Spans[i].CompactStart && start < end {
+			prev := offsets.Spans[i-1]
+			originalEnd = prev.OriginalStart + prev.Duration
+			break
+		}
+	}
+	if originalEnd < originalStart {
+		originalEnd = originalStart
+	}
+	return originalStart, originalEnd
+}
@@ -0,0 +1,327 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+)
+
+// Postprocessor parameters selecting the text postprocessors
+const (
+	ReplacementsParam     = "replacements"      // []models.ReplacementRule
+	NormalizeNumbersParam = "normalize_numbers" // bool
+	MaskProfanityParam    = "mask_profanity"    // bool
+)
+
+// compiledRule is a ReplacementRule ready to apply
+type compiledRule struct {
+	pattern     *regexp.Regexp
+	replacement string
+	expand      bool // replacement may reference capture groups
+}
+
+// compileRule turns a replacement rule into a regular expression
+func compileRule(rule models.ReplacementRule) (compiledRule, error) {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return compiledRule{}, fmt.Errorf("pattern is required")
+	}
+	expr := rule.Pattern
+	if !rule.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if rule.WholeWord {
+		expr = `\b(?:` + expr + `)\b`
+	}
+	if !rule.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("invalid pattern %q: %w", rule.Pattern, err)
+	}
+	return compiledRule{pattern: pattern, replacement: rule.Replacement, expand: rule.Regex}, nil
+}
+
+func (r compiledRule) apply(text string) string {
+	if r.expand {
+		return r.pattern.ReplaceAllString(text, r.replacement)
+	}
+	return r.pattern.ReplaceAllLiteralString(text, r.replacement)
+}
+
+// expandMatch appends the replacement of one match in text to dst
+func (r compiledRule) expandMatch(dst []byte, text string, match []int) []byte {
+	if r.expand {
+		return r.pattern.ExpandString(dst, r.replacement, text, match)
+	}
+	return append(dst, r.replacement...)
+}
+
+// applyWords applies the rule to a run of words read as one text. The words a match spans are
+// merged into one word timed from the first word's start to the last word's end, and words
+// left without text are dropped.
+func (r compiledRule) applyWords(words []interfaces.TranscriptWord) []interfaces.TranscriptWord {
+	var joined strings.Builder
+	bounds := make([][2]int, len(words)) // Offsets of each word in the joined text
+	for i, word := range words {
+		if i > 0 {
+			joined.WriteByte(' ')
+		}
+		bounds[i][0] = joined.Len()
+		joined.WriteString(strings.TrimSpace(word.Word))
+		bounds[i][1] = joined.Len()
+	}
+	text := joined.String()
+	matches := r.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return words
+	}
+
+	out := make([]interfaces.TranscriptWord, 0, len(words))
+	next := 0
+	for m := 0; m < len(matches); m++ {
+		start, end := matches[m][0], matches[m][1]
+		first := next
+		for first < len(words) && bounds[first][1] <= start {
+			first++
+		}
+		last := first - 1
+		for last+1 < len(words) && bounds[last+1][0] < end {
+			last++
+		}
+		if start == end || last < first {
+			continue // Nothing to attach the replacement to, such as a match between words
+		}
+
+		// Later matches within the merged words rewrite them too
+		groupStart := m
+		for m+1 < len(matches) && matches[m+1][0] < bounds[last][1] {
+			m++
+			for last+1 < len(words) && bounds[last+1][0] < matches[m][1] {
+				last++
+			}
+		}
+		group := matches[groupStart : m+1]
+
+		from := min(bounds[first][0], start)
+		to := max(bounds[last][1], group[len(group)-1][1])
+		var rewritten []byte
+		for _, match := range group {
+			rewritten = append(rewritten, text[from:match[0]]...)
+			rewritten = r.expandMatch(rewritten, text, match)
+			from = match[1]
+		}
+		rewritten = append(rewritten, text[from:to]...)
+
+		out = append(out, words[next:first]...)
+		merged := words[first]
+		merged.Word = strings.TrimSpace(string(rewritten))
+		merged.End = words[last].End
+		for _, w := range words[first+1 : last+1] {
+			merged.Score = min(merged.Score, w.Score)
+		}
+		if merged.Word != "" {
+			out = append(out, merged)
+		}
+		next = last + 1
+	}
+	return append(out, words[next:]...)
+}
+
+// ValidateReplacementRules checks that every rule has a pattern that compiles
+func ValidateReplacementRules(rules []models.ReplacementRule) error {
+	for i, rule := range rules {
+		if _, err := compileRule(rule); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// rewriteTranscript applies rewrite to the transcript text and every segment
+func rewriteTranscript(result *interfaces.TranscriptResult, rewrite func(string) string) {
+	result.Text = rewrite(result.Text)
+	for i := range result.Segments {
+		result.Segments[i].Text = rewrite(result.Segments[i].Text)
+	}
+}
+
+// ReplacementPostprocessor applies user replacement dictionaries to transcripts. Rules match the
+// words of a segment the way they match its text, so a rule spanning several words merges them.
+type ReplacementPostprocessor struct{}
+
+// AppliesTo reports whether any replacement rules were passed
+func (r *ReplacementPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	rules, _ := params[ReplacementsParam].([]models.ReplacementRule)
+	return len(rules) > 0
+}
+
+// ProcessTranscript rewrites segments and words with each rule in turn
+func (r *ReplacementPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	rules, _ := params[ReplacementsParam].([]models.ReplacementRule)
+	if result == nil || len(rules) == 0 {
+		return result, nil
+	}
+
+	compiled := make([]compiledRule, 0, len(rules))
+	for i, rule := range rules {
+		c, err := compileRule(rule)
+		if err != nil {
+			return result, fmt.Errorf("replacement rule %d: %w", i+1, err)
+		}
+		compiled = append(compiled, c)
+	}
+	rewrite := func(text string) string {
+		for _, rule := range compiled {
+			text = rule.apply(text)
+		}
+		return text
+	}
+
+	rewriteTranscript(result, rewrite)
+	if len(result.WordSegments) == 0 {
+		return result, nil
+	}
+	words := make([]interfaces.TranscriptWord, 0, len(result.WordSegments))
+	for _, span := range segmentWordRanges(result) {
+		run := result.WordSegments[span[0]:span[1]]
+		for _, rule := range compiled {
+			run = rule.applyWords(run)
+		}
+		words = append(words, run...)
+	}
+	result.WordSegments = words
+	return result, nil
+}
+
+// segmentWordRanges splits the words into the runs falling within each segment, so that a rule
+// only matches words that are also next to each other in a segment's text. Words after the last
+// timed segment form one run.
+func segmentWordRanges(result *interfaces.TranscriptResult) [][2]int {
+	words := result.WordSegments
+	var ranges [][2]int
+	start := 0
+	for _, segment := range result.Segments {
+		end := start
+		for end < len(words) && words[end].Start < segment.End {
+			end++
+		}
+		if end > start {
+			ranges = append(ranges, [2]int{start, end})
+			start = end
+		}
+	}
+	if start < len(words) {
+		ranges = append(ranges, [2]int{start, len(words)})
+	}
+	return ranges
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (r *ReplacementPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// NumberPostprocessor writes spelled-out numbers as digits
+type NumberPostprocessor struct{}
+
+// AppliesTo reports whether number normalization was requested
+func (n *NumberPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	enabled, _ := params[NormalizeNumbersParam].(bool)
+	return enabled
+}
+
+// ProcessTranscript normalizes segment text, and merges the words of each number into one timed word.
+// Words are merged within a segment only, as a number never spans two segments.
+func (n *NumberPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	rewriteTranscript(result, NormalizeNumbers)
+
+	if len(result.WordSegments) == 0 {
+		return result, nil
+	}
+	words := make([]interfaces.TranscriptWord, 0, len(result.WordSegments))
+	for _, span := range segmentWordRanges(result) {
+		words = append(words, mergeNumberWords(result.WordSegments[span[0]:span[1]])...)
+	}
+	result.WordSegments = words
+	return result, nil
+}
+
+// mergeNumberWords replaces the words of each spelled-out number with one word holding its digits
+func mergeNumberWords(run []interfaces.TranscriptWord) []interfaces.TranscriptWord {
+	tokens := make([]string, len(run))
+	for i, word := range run {
+		tokens[i] = strings.TrimSpace(word.Word)
+	}
+	numbers := findNumberRuns(tokens)
+	if len(numbers) == 0 {
+		return run
+	}
+
+	words := make([]interfaces.TranscriptWord, 0, len(run))
+	next := 0
+	for _, number := range numbers {
+		words = append(words, run[next:number.start]...)
+		merged := run[number.start]
+		merged.Word = numberText(tokens, number)
+		merged.End = run[number.end-1].End
+		for _, w := range run[number.start+1 : number.end] {
+			merged.Score = min(merged.Score, w.Score)
+		}
+		words = append(words, merged)
+		next = number.end
+	}
+	return append(words, run[next:]...)
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (n *NumberPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
+
+// profanityPattern matches common English profanity, including inflected forms
+var profanityPattern = regexp.MustCompile(`(?i)\b(?:` + strings.Join([]string{
+	`f+u+c+k\w*`, `motherf\w*`, `shit\w*`, `bullshit\w*`, `bitch\w*`, `asshole\w*`, `bastard\w*`,
+	`cunt\w*`, `dick(?:head)?s?`, `piss(?:ed)?`, `wank\w*`, `twat\w*`, `cock(?:sucker)?s?`,
+}, "|") + `)\b`)
+
+// MaskProfanity replaces every letter of profane words after the first with asterisks
+func MaskProfanity(text string) string {
+	return profanityPattern.ReplaceAllStringFunc(text, func(word string) string {
+		runes := []rune(word)
+		return string(runes[0]) + strings.Repeat("*", len(runes)-1)
+	})
+}
+
+// ProfanityPostprocessor masks profanity in transcripts
+type ProfanityPostprocessor struct{}
+
+// AppliesTo reports whether profanity masking was requested
+func (p *ProfanityPostprocessor) AppliesTo(capabilities interfaces.ModelCapabilities, params map[string]interface{}) bool {
+	enabled, _ := params[MaskProfanityParam].(bool)
+	return enabled
+}
+
+// ProcessTranscript masks profanity in segments and words
+func (p *ProfanityPostprocessor) ProcessTranscript(ctx context.Context, result *interfaces.TranscriptResult, params map[string]interface{}) (*interfaces.TranscriptResult, error) {
+	if result == nil {
+		return result, nil
+	}
+	rewriteTranscript(result, MaskProfanity)
+	for i := range result.WordSegments {
+		result.WordSegments[i].Word = MaskProfanity(result.WordSegments[i].Word)
+	}
+	return result, nil
+}
+
+// ProcessDiarization leaves diarization results unchanged
+func (p *ProfanityPostprocessor) ProcessDiarization(ctx context.Context, result *interfaces.DiarizationResult, params map[string]interface{}) (*interfaces.DiarizationResult, error) {
+	return result, nil
+}
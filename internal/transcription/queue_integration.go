@@ -32,16 +32,10 @@ func (u *UnifiedJobProcessor) ProcessJob(ctx context.Context, jobID string) erro
 
 // ProcessJobWithProcess implements the enhanced JobProcessor interface with process registration
 func (u *UnifiedJobProcessor) ProcessJobWithProcess(ctx context.Context, jobID string, registerProcess func(*exec.Cmd)) error {
-	// Note: The new adapter architecture doesn't expose the underlying process in the same way
-	// For backward compatibility, we'll call the registerProcess function with nil
-	// In the future, we could modify adapters to support process registration if needed
-	
 	logger.Info("Processing job with unified processor (with process registration)", "job_id", jobID)
-	
-	// Register a nil process for backward compatibility
-	registerProcess(nil)
-	
-	return u.unifiedService.ProcessJob(ctx, jobID)
+
+	// Adapters report each model process as it starts so the queue can kill it
+	return u.unifiedService.ProcessJobWithProcess(ctx, jobID, registerProcess)
 }
 
 // GetUnifiedService returns the underlying unified service for direct access to new features
@@ -0,0 +1,77 @@
+package transcription
+
+import (
+	"strings"
+
+	"synthezia/internal/models"
+)
+
+// whisperModelMemoryMB approximates the memory each Whisper model size needs at float16.
+// Sizes are matched by prefix, so large-v3 and tiny.en resolve to large and tiny.
+var whisperModelMemoryMB = []struct {
+	prefix   string
+	memoryMB int
+}{
+	{"large-v3-turbo", 6144},
+	{"turbo", 6144},
+	{"large", 10240},
+	{"medium", 5120},
+	{"small", 2048},
+	{"base", 1024},
+	{"tiny", 1024},
+}
+
+// EstimateResources estimates the memory a job with these parameters holds while it runs and
+// whether that memory is on the GPU. Estimates start from the adapters' declared requirements;
+// Whisper models are sized per model, and int8 roughly halves them.
+func (u *UnifiedTranscriptionService) EstimateResources(params models.WhisperXParams) (memoryMB int, gpu bool) {
+	transcriptionModelID, diarizationModelID := modelsForParams(params)
+
+	if adapter, err := u.registry.GetTranscriptionAdapter(transcriptionModelID); err == nil {
+		capabilities := adapter.GetCapabilities()
+		memoryMB = capabilities.MemoryRequirement
+		gpu = capabilities.RequiresGPU
+	}
+	if transcriptionModelID == "whisperx" {
+		if size := whisperMemoryMB(params.Model); size > 0 {
+			memoryMB = size
+		}
+	}
+	if strings.EqualFold(params.ComputeType, "int8") {
+		memoryMB /= 2
+	}
+
+	if diarizationModelID != "" {
+		if adapter, err := u.registry.GetDiarizationAdapter(diarizationModelID); err == nil {
+			diarizationMB := adapter.GetCapabilities().MemoryRequirement
+			if transcriptionModelID == "whisperx" && diarizationModelID == "pyannote" {
+				// WhisperX diarizes in the same process, with the transcription model still loaded
+				memoryMB += diarizationMB
+			} else if diarizationMB > memoryMB {
+				// Separate diarization runs after transcription has exited
+				memoryMB = diarizationMB
+			}
+		}
+	}
+
+	if !gpu {
+		gpu = u.resolveDevicePreference(params.Device) == "cuda"
+	}
+	return memoryMB, gpu
+}
+
+// whisperMemoryMB returns the approximate memory of a Whisper model size, or 0 if it is unknown
+func whisperMemoryMB(model string) int {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(model), "distil-"))
+	for _, size := range whisperModelMemoryMB {
+		if strings.HasPrefix(name, size.prefix) {
+			return size.memoryMB
+		}
+	}
+	return 0
+}
+
+// EstimateResources implements queue.ResourceEstimator
+func (u *UnifiedJobProcessor) EstimateResources(params models.WhisperXParams) (memoryMB int, gpu bool) {
+	return u.unifiedService.EstimateResources(params)
+}
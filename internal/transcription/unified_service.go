@@ -13,10 +13,14 @@ import (
 	"time"
 
 	"synthezia/internal/database"
+	"synthezia/internal/editor"
 	"synthezia/internal/models"
+	"synthezia/internal/queue"
+	"synthezia/internal/search"
 	"synthezia/internal/transcription/interfaces"
 	"synthezia/internal/transcription/pipeline"
 	"synthezia/internal/transcription/registry"
+	"synthezia/internal/voices"
 	"synthezia/pkg/logger"
 
 	"github.com/google/uuid"
@@ -73,6 +77,12 @@ func (u *UnifiedTranscriptionService) Initialize(ctx context.Context) error {
 
 // ProcessJob processes a transcription job using the new adapter architecture
 func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID string) error {
+	return u.ProcessJobWithProcess(ctx, jobID, nil)
+}
+
+// ProcessJobWithProcess processes a job, reporting each model process to registerProcess (if set).
+// Process IDs are also stored on the execution record so they can be cleaned up after a crash.
+func (u *UnifiedTranscriptionService) ProcessJobWithProcess(ctx context.Context, jobID string, registerProcess func(*exec.Cmd)) error {
 	startTime := time.Now()
 	logger.Info("Processing job with unified service", "job_id", jobID)
 
@@ -88,6 +98,7 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 		StartedAt:          startTime,
 		ActualParameters:   job.Parameters,
 		Status:             models.StatusProcessing,
+		Attempt:            job.Attempts,
 	}
 
 	if err := database.DB.Create(execution).Error; err != nil {
@@ -108,17 +119,35 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 		database.DB.Save(execution)
 	}
 
-	// Check for multi-track processing
-	if job.IsMultiTrack && job.Parameters.IsMultiTrackEnabled {
+	trackProcess := func(cmd *exec.Cmd) {
+		if cmd != nil && cmd.Process != nil {
+			pid := cmd.Process.Pid
+			execution.ProcessPID = &pid
+			database.DB.Model(execution).Update("process_pid", pid)
+		}
+		if registerProcess != nil {
+			registerProcess(cmd)
+		}
+	}
+
+	// Check for diarization-only and multi-track processing
+	if job.JobType == models.JobTypeRediarize {
+		logger.Info("Re-running diarization", "job_id", jobID, "diarize_model", job.Parameters.DiarizeModel)
+		if err := u.rediarizeJob(ctx, &job, trackProcess); err != nil {
+			updateExecutionStatus(models.StatusFailed, fmt.Sprintf("rediarization failed: %v", err))
+			// The queue puts the job back to its status before the run, since its transcript is still valid
+			return fmt.Errorf("%w: %w", queue.ErrRediarizeFailed, err)
+		}
+	} else if job.IsMultiTrack && job.Parameters.IsMultiTrackEnabled {
 		logger.Info("Processing multi-track job", "job_id", jobID)
-		if err := u.processMultiTrackJob(ctx, &job); err != nil {
+		if err := u.processMultiTrackJob(ctx, &job, execution); err != nil {
 			errMsg := fmt.Sprintf("multi-track processing failed: %v", err)
 			updateExecutionStatus(models.StatusFailed, errMsg)
 			return fmt.Errorf("%s", errMsg)
 		}
 	} else {
 		// Process single track
-		if err := u.processSingleTrackJob(ctx, &job); err != nil {
+		if err := u.processSingleTrackJob(ctx, &job, execution, trackProcess); err != nil {
 			errMsg := fmt.Sprintf("single-track processing failed: %v", err)
 			updateExecutionStatus(models.StatusFailed, errMsg)
 			return fmt.Errorf("%s", errMsg)
@@ -132,15 +161,18 @@ func (u *UnifiedTranscriptionService) ProcessJob(ctx context.Context, jobID stri
 }
 
 // processSingleTrackJob handles single audio file transcription
-func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context, job *models.TranscriptionJob) error {
+func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context, job *models.TranscriptionJob, execution *models.TranscriptionJobExecution, registerProcess func(*exec.Cmd)) error {
 	logger.Info("Processing single-track job", "job_id", job.ID, "model_family", job.Parameters.ModelFamily)
 
 	// Create processing context
+	userID := strconv.FormatUint(uint64(job.UserID), 10)
 	procCtx := interfaces.ProcessingContext{
 		JobID:           job.ID,
+		UserID:          &userID,
 		OutputDirectory: filepath.Join(u.outputDirectory, job.ID),
 		TempDirectory:   u.tempDirectory,
 		Metadata:        map[string]string{},
+		RegisterProcess: registerProcess,
 	}
 
 	// Create output directory
@@ -154,7 +186,8 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 		return fmt.Errorf("failed to create audio input: %w", err)
 	}
 
-	transcriptResult, err := u.transcribeAudioInput(ctx, audioInput, job.Parameters, procCtx)
+	transcriptResult, diarizationResult, err := u.transcribeAudioInput(ctx, audioInput, voiceIdentificationParams(job, job.Parameters), procCtx)
+	recordPreprocessing(execution, procCtx.Metadata)
 	if err != nil {
 		return err
 	}
@@ -164,32 +197,167 @@ func (u *UnifiedTranscriptionService) processSingleTrackJob(ctx context.Context,
 			return fmt.Errorf("failed to save transcription results: %w", err)
 		}
 	}
+	if diarizationResult != nil {
+		u.identifySpeakers(job, diarizationResult)
+	}
+
+	return nil
+}
+
+// rediarizeJob runs only the diarization model on a transcribed job and assigns the new speakers
+// to the stored words and segments. The previous transcript is kept as a revision.
+func (u *UnifiedTranscriptionService) rediarizeJob(ctx context.Context, job *models.TranscriptionJob, registerProcess func(*exec.Cmd)) error {
+	if job.Transcript == nil || *job.Transcript == "" {
+		return fmt.Errorf("job has no transcript to diarize")
+	}
+
+	params := job.Parameters
+	params.Diarize = true
+	params = voiceIdentificationParams(job, params)
+	_, diarizationModelID := modelsForParams(params)
+	diarizationAdapter, err := u.registry.GetDiarizationAdapter(diarizationModelID)
+	if err != nil {
+		return fmt.Errorf("failed to get diarization adapter: %w", err)
+	}
+
+	userID := strconv.FormatUint(uint64(job.UserID), 10)
+	procCtx := interfaces.ProcessingContext{
+		JobID:           job.ID,
+		UserID:          &userID,
+		OutputDirectory: filepath.Join(u.outputDirectory, job.ID),
+		TempDirectory:   u.tempDirectory,
+		Metadata:        map[string]string{},
+		RegisterProcess: registerProcess,
+	}
+	if err := os.MkdirAll(procCtx.OutputDirectory, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	audioInput, err := u.createAudioInput(job.AudioPath)
+	if err != nil {
+		return fmt.Errorf("failed to create audio input: %w", err)
+	}
+
+	// The stored transcript is timed against the original audio, so skip the job's
+	// preprocessors: silence removal would shift the diarization timeline
+	capabilities := diarizationAdapter.GetCapabilities()
+	diarizationInput, err := u.pipeline.ProcessAudio(ctx, audioInput, capabilities)
+	if err != nil {
+		logger.Warn("Audio preprocessing failed, using original", "error", err)
+		diarizationInput = audioInput
+	} else if diarizationInput.TempFilePath != "" && diarizationInput.TempFilePath != audioInput.FilePath {
+		defer func() {
+			if err := os.Remove(diarizationInput.TempFilePath); err != nil {
+				logger.Warn("Failed to clean up temporary file", "file", diarizationInput.TempFilePath, "error", err)
+			}
+		}()
+	}
 
+	diarizationResult, err := diarizationAdapter.Diarize(ctx, diarizationInput, u.convertParametersForModel(params, diarizationModelID), procCtx)
+	if err != nil {
+		return fmt.Errorf("diarization failed: %w", err)
+	}
+	diarizationResult, err = u.pipeline.ProcessDiarization(ctx, diarizationResult, capabilities, map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("diarization post-processing failed: %w", err)
+	}
+
+	change := editor.Change{
+		UserID:      job.UserID,
+		Operation:   editor.OpRediarize,
+		Description: fmt.Sprintf("Re-ran diarization with %s (%d speakers)", diarizationModelID, diarizationResult.SpeakerCount),
+	}
+	_, _, err = editor.Apply(job.ID, change, func(current *interfaces.TranscriptResult) (*interfaces.TranscriptResult, error) {
+		// Drop the previous speakers so none survive where the new diarization finds nobody
+		cleared := editor.Clone(current)
+		for i := range cleared.Segments {
+			cleared.Segments[i].Speaker = nil
+		}
+		for i := range cleared.WordSegments {
+			cleared.WordSegments[i].Speaker = nil
+		}
+		return u.mergeDiarizationWithTranscription(cleared, diarizationResult), nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save diarized transcript: %w", err)
+	}
+	u.identifySpeakers(job, diarizationResult)
 	return nil
 }
 
-// TranscribeFile runs the unified pipeline against an arbitrary audio file and returns the transcript
-func (u *UnifiedTranscriptionService) TranscribeFile(ctx context.Context, audioPath string, params models.WhisperXParams) (*interfaces.TranscriptResult, error) {
-	jobID := fmt.Sprintf("live-%s", uuid.New().String())
+// voiceIdentificationParams asks the diarization model for speaker embeddings when the job's owner
+// has a voice profile, so that known speakers are identified without every job opting in.
+// Sortformer produces no embeddings, so its jobs are diarized without voice identification.
+func voiceIdentificationParams(job *models.TranscriptionJob, params models.WhisperXParams) models.WhisperXParams {
+	if !params.Diarize || params.SpeakerEmbeddings {
+		return params
+	}
+	hasProfiles, err := voices.HasProfiles(job.UserID)
+	if err != nil {
+		logger.Warn("Failed to check for voice profiles", "job_id", job.ID, "error", err)
+		return params
+	}
+	if !hasProfiles {
+		return params
+	}
+	if _, diarizationModelID := modelsForParams(params); diarizationModelID != "pyannote" {
+		logger.Warn("Voice identification needs pyannote diarization, skipping it",
+			"job_id", job.ID, "diarize_model", params.DiarizeModel)
+		return params
+	}
+	params.SpeakerEmbeddings = true
+	return params
+}
+
+// identifySpeakers stores the speaker embeddings of a diarization run and names the speakers that
+// match an enrolled voice. Speaker identification is best effort and never fails the job.
+func (u *UnifiedTranscriptionService) identifySpeakers(job *models.TranscriptionJob, diarization *interfaces.DiarizationResult) {
+	if len(diarization.SpeakerEmbeddings) == 0 {
+		return
+	}
+	if err := voices.SaveJobEmbeddings(job.ID, diarization.ModelUsed, diarization.SpeakerEmbeddings); err != nil {
+		logger.Warn("Failed to save speaker embeddings", "job_id", job.ID, "error", err)
+		return
+	}
+	applied, err := voices.AutoApply(job.UserID, job.ID)
+	if err != nil {
+		logger.Warn("Failed to match speakers against voice profiles", "job_id", job.ID, "error", err)
+		return
+	}
+	for _, match := range applied {
+		logger.Info("Identified speaker from voice profile",
+			"job_id", job.ID,
+			"speaker", match.Speaker,
+			"name", match.Name,
+			"similarity", match.Similarity)
+	}
+}
+
+// TranscribeFile runs the unified pipeline against an arbitrary audio file and returns the transcript.
+// source tells what the file is for in the processing metadata, e.g. "live-session". Nothing the run
+// writes is kept: its output directory is removed once the transcript is returned.
+func (u *UnifiedTranscriptionService) TranscribeFile(ctx context.Context, audioPath string, params models.WhisperXParams, source string) (*interfaces.TranscriptResult, error) {
+	jobID := fmt.Sprintf("%s-%s", source, uuid.New().String())
 	procCtx := interfaces.ProcessingContext{
 		JobID:           jobID,
-		OutputDirectory: filepath.Join(u.outputDirectory, "live", jobID),
+		OutputDirectory: filepath.Join(u.tempDirectory, jobID),
 		TempDirectory:   u.tempDirectory,
 		Metadata: map[string]string{
-			"source": "live-session",
+			"source": source,
 		},
 	}
 
 	if err := os.MkdirAll(procCtx.OutputDirectory, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create live output directory: %w", err)
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
+	defer os.RemoveAll(procCtx.OutputDirectory)
 
 	audioInput, err := u.createAudioInput(audioPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create audio input: %w", err)
 	}
 
-	result, err := u.transcribeAudioInput(ctx, audioInput, params, procCtx)
+	result, _, err := u.transcribeAudioInput(ctx, audioInput, params, procCtx)
 	if err != nil {
 		return nil, err
 	}
@@ -198,14 +366,15 @@ func (u *UnifiedTranscriptionService) TranscribeFile(ctx context.Context, audioP
 }
 
 // transcribeAudioInput centralizes preprocessing, adapter invocation, and optional diarization merging.
-func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context, audioInput interfaces.AudioInput, params models.WhisperXParams, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, error) {
+// The diarization result is returned when a separate diarization model ran.
+func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context, audioInput interfaces.AudioInput, params models.WhisperXParams, procCtx interfaces.ProcessingContext) (*interfaces.TranscriptResult, *interfaces.DiarizationResult, error) {
 	transcriptionModelID, diarizationModelID, err := u.selectModels(params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select models: %w", err)
+		return nil, nil, fmt.Errorf("failed to select models: %w", err)
 	}
 
 	if transcriptionModelID == "" && (!params.Diarize || diarizationModelID == "") {
-		return nil, fmt.Errorf("no transcription model selected")
+		return nil, nil, fmt.Errorf("no transcription model selected")
 	}
 
 	var tempFilesToCleanup []string
@@ -222,10 +391,24 @@ func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context,
 		}
 	}
 
-	preprocessedInput, err := u.pipeline.ProcessAudio(ctx, audioInput, capabilities)
+	preprocessedInput, err := u.pipeline.ProcessAudio(ctx, audioInput, capabilities, jobPreprocessors(params)...)
 	if err != nil {
 		logger.Warn("Audio preprocessing failed, using original", "error", err)
 		preprocessedInput = audioInput
+	} else if filters := preprocessedInput.Metadata[pipeline.FilterChainMetadataKey]; filters != "" {
+		// Keep filtered audio next to the transcript so the run can be reproduced and checked
+		preprocessedInput = keepPreprocessedAudio(preprocessedInput, procCtx.OutputDirectory)
+		if procCtx.Metadata != nil {
+			procCtx.Metadata[pipeline.FilterChainMetadataKey] = filters
+			procCtx.Metadata[preprocessedAudioKey] = preprocessedInput.FilePath
+		}
+		if preprocessedInput.TempFilePath != "" {
+			tempFilesToCleanup = append(tempFilesToCleanup, preprocessedInput.TempFilePath)
+		}
+		logger.Info("Audio preprocessing completed",
+			"original", audioInput.FilePath,
+			"preprocessed", preprocessedInput.FilePath,
+			"filters", filters)
 	} else if preprocessedInput.TempFilePath != "" && preprocessedInput.TempFilePath != audioInput.FilePath {
 		tempFilesToCleanup = append(tempFilesToCleanup, preprocessedInput.TempFilePath)
 		logger.Info("Audio preprocessing completed",
@@ -250,17 +433,38 @@ func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context,
 	var transcriptResult *interfaces.TranscriptResult
 	var diarizationResult *interfaces.DiarizationResult
 
+	// Postprocessors map results of the preprocessed audio back onto the original, then rewrite the text
+	postprocessParams := map[string]interface{}{
+		pipeline.NormalizeNumbersParam: params.NormalizeNumbers,
+		pipeline.MaskProfanityParam:    params.MaskProfanity,
+	}
+	if offsets, ok := pipeline.OffsetMapFromMetadata(preprocessedInput.Metadata); ok {
+		postprocessParams[pipeline.OffsetMapParam] = offsets
+	}
+	if len(params.ReplacementDictionaries) > 0 {
+		rules, err := loadReplacementRules(params.ReplacementDictionaries, procCtx.UserID)
+		if err != nil {
+			return nil, nil, err
+		}
+		postprocessParams[pipeline.ReplacementsParam] = rules
+	}
+
 	if transcriptionModelID != "" {
 		logger.Info("Running transcription", "model_id", transcriptionModelID, "job_id", procCtx.JobID)
 		transcriptionAdapter, err := u.registry.GetTranscriptionAdapter(transcriptionModelID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get transcription adapter: %w", err)
+			return nil, nil, fmt.Errorf("failed to get transcription adapter: %w", err)
 		}
 
 		paramsForModel := u.convertParametersForModel(params, transcriptionModelID)
 		transcriptResult, err = transcriptionAdapter.Transcribe(ctx, preprocessedInput, paramsForModel, procCtx)
 		if err != nil {
-			return nil, fmt.Errorf("transcription failed: %w", err)
+			return nil, nil, fmt.Errorf("transcription failed: %w", err)
+		}
+
+		transcriptResult, err = u.pipeline.ProcessTranscript(ctx, transcriptResult, capabilities, postprocessParams)
+		if err != nil {
+			return nil, nil, fmt.Errorf("transcript post-processing failed: %w", err)
 		}
 	}
 
@@ -270,12 +474,17 @@ func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context,
 			logger.Info("Running separate diarization", "model_id", diarizationModelID, "job_id", procCtx.JobID)
 			diarizationAdapter, err := u.registry.GetDiarizationAdapter(diarizationModelID)
 			if err != nil {
-				return nil, fmt.Errorf("failed to get diarization adapter: %w", err)
+				return nil, nil, fmt.Errorf("failed to get diarization adapter: %w", err)
 			}
 
 			diarizationResult, err = diarizationAdapter.Diarize(ctx, preprocessedInput, diarizationParams, procCtx)
 			if err != nil {
-				return nil, fmt.Errorf("diarization failed: %w", err)
+				return nil, nil, fmt.Errorf("diarization failed: %w", err)
+			}
+
+			diarizationResult, err = u.pipeline.ProcessDiarization(ctx, diarizationResult, capabilities, postprocessParams)
+			if err != nil {
+				return nil, nil, fmt.Errorf("diarization post-processing failed: %w", err)
 			}
 
 			if transcriptResult != nil && diarizationResult != nil {
@@ -284,11 +493,99 @@ func (u *UnifiedTranscriptionService) transcribeAudioInput(ctx context.Context,
 		}
 	}
 
-	return transcriptResult, nil
+	return transcriptResult, diarizationResult, nil
+}
+
+// preprocessedAudioKey is the ProcessingContext metadata key for the audio preprocessing produced
+const preprocessedAudioKey = "preprocessed_audio"
+
+// jobPreprocessors returns the preprocessing stages a job's parameters ask for on top of the pipeline defaults.
+// Enhancement runs before silence removal so silences are detected on the cleaned audio.
+func jobPreprocessors(params models.WhisperXParams) []interfaces.Preprocessor {
+	var preprocessors []interfaces.Preprocessor
+	if params.HighpassFrequency > 0 || params.Denoise != "" || params.Loudnorm {
+		preprocessors = append(preprocessors, &pipeline.NoiseReductionPreprocessor{
+			HighpassHz:     params.HighpassFrequency,
+			Denoise:        params.Denoise,
+			DenoiseModel:   params.DenoiseModel,
+			Loudnorm:       params.Loudnorm,
+			LoudnormTarget: params.LoudnormTarget,
+		})
+	}
+	if params.RemoveSilence {
+		preprocessors = append(preprocessors, &pipeline.VoiceActivityDetectionPreprocessor{
+			ThresholdDB: params.SilenceThresholdDB,
+			MinSilence:  params.SilenceMinDuration,
+		})
+	}
+	return preprocessors
+}
+
+// keepPreprocessedAudio moves filtered audio into the job's output directory. If it can't be
+// moved it stays a temporary file and is removed after the run.
+func keepPreprocessedAudio(input interfaces.AudioInput, outputDirectory string) interfaces.AudioInput {
+	if input.TempFilePath == "" || outputDirectory == "" {
+		return input
+	}
+	kept := filepath.Join(outputDirectory, "preprocessed"+filepath.Ext(input.FilePath))
+	if err := os.Rename(input.TempFilePath, kept); err != nil {
+		logger.Warn("Failed to keep preprocessed audio", "file", input.TempFilePath, "error", err)
+		return input
+	}
+	input.FilePath = kept
+	input.TempFilePath = ""
+	return input
+}
+
+// loadReplacementRules collects the rules of the given dictionaries, in the order listed.
+// When userID is set, dictionaries belonging to other users are ignored.
+func loadReplacementRules(dictionaryIDs []string, userID *string) ([]models.ReplacementRule, error) {
+	query := database.DB.Where("id IN ?", dictionaryIDs)
+	if userID != nil {
+		owner, err := strconv.ParseUint(*userID, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user id %q: %w", *userID, err)
+		}
+		query = query.Where("user_id = ?", uint(owner))
+	}
+	var dictionaries []models.ReplacementDictionary
+	if err := query.Find(&dictionaries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load replacement dictionaries: %w", err)
+	}
+
+	byID := make(map[string]models.ReplacementDictionary, len(dictionaries))
+	for _, dictionary := range dictionaries {
+		byID[dictionary.ID] = dictionary
+	}
+	var rules []models.ReplacementRule
+	for _, id := range dictionaryIDs {
+		dictionary, ok := byID[id]
+		if !ok {
+			logger.Warn("Replacement dictionary not found, skipping", "dictionary_id", id)
+			continue
+		}
+		rules = append(rules, dictionary.Rules...)
+	}
+	return rules, nil
+}
+
+// recordPreprocessing stores the filters applied to a job's audio on its execution record
+func recordPreprocessing(execution *models.TranscriptionJobExecution, metadata map[string]string) {
+	filters := metadata[pipeline.FilterChainMetadataKey]
+	if execution == nil || filters == "" {
+		return
+	}
+	execution.PreprocessingFilters = &filters
+	updates := map[string]interface{}{"preprocessing_filters": filters}
+	if audio := metadata[preprocessedAudioKey]; audio != "" {
+		execution.PreprocessedAudio = &audio
+		updates["preprocessed_audio"] = audio
+	}
+	database.DB.Model(execution).Updates(updates)
 }
 
 // processMultiTrackJob handles multi-track audio processing
-func (u *UnifiedTranscriptionService) processMultiTrackJob(ctx context.Context, job *models.TranscriptionJob) error {
+func (u *UnifiedTranscriptionService) processMultiTrackJob(ctx context.Context, job *models.TranscriptionJob, execution *models.TranscriptionJobExecution) error {
 	logger.Info("Processing multi-track job", "job_id", job.ID, "track_count", len(job.MultiTrackFiles))
 
 	// Create unified processor for this service
@@ -301,7 +598,12 @@ func (u *UnifiedTranscriptionService) processMultiTrackJob(ctx context.Context,
 	u.multiTrackTranscriber = transcriber
 
 	// Process the multi-track transcription
-	return transcriber.ProcessMultiTrackTranscription(ctx, job.ID)
+	return transcriber.ProcessMultiTrackTranscription(ctx, job.ID, execution)
+}
+
+// TempDirectory returns the directory adapters create per-job working directories in
+func (u *UnifiedTranscriptionService) TempDirectory() string {
+	return u.tempDirectory
 }
 
 // TerminateMultiTrackJob terminates a multi-track job and all its individual track jobs
@@ -323,6 +625,19 @@ func (u *UnifiedTranscriptionService) IsMultiTrackJob(jobID string) bool {
 
 // selectModels determines which models to use based on job parameters
 func (u *UnifiedTranscriptionService) selectModels(params models.WhisperXParams) (transcriptionModelID, diarizationModelID string, err error) {
+	transcriptionModelID, diarizationModelID = modelsForParams(params)
+
+	logger.Info("Selected models",
+		"transcription", transcriptionModelID,
+		"diarization", diarizationModelID,
+		"original_family", params.ModelFamily,
+		"original_diarize_model", params.DiarizeModel)
+
+	return transcriptionModelID, diarizationModelID, nil
+}
+
+// modelsForParams maps job parameters to the registered transcription and diarization model IDs
+func modelsForParams(params models.WhisperXParams) (transcriptionModelID, diarizationModelID string) {
 	// Determine transcription model
 	switch params.ModelFamily {
 	case "nvidia_parakeet":
@@ -347,13 +662,7 @@ func (u *UnifiedTranscriptionService) selectModels(params models.WhisperXParams)
 		}
 	}
 
-	logger.Info("Selected models",
-		"transcription", transcriptionModelID,
-		"diarization", diarizationModelID,
-		"original_family", params.ModelFamily,
-		"original_diarize_model", params.DiarizeModel)
-
-	return transcriptionModelID, diarizationModelID, nil
+	return transcriptionModelID, diarizationModelID
 }
 
 // transcriptionIncludesDiarization checks if the transcription model already includes diarization
@@ -662,6 +971,9 @@ func (u *UnifiedTranscriptionService) convertToPyannoteParams(params models.Whis
 	if params.HfToken != nil {
 		paramMap["hf_token"] = *params.HfToken
 	}
+	if params.SpeakerEmbeddings {
+		paramMap["return_embeddings"] = true
+	}
 
 	return paramMap
 }
@@ -751,43 +1063,6 @@ func (u *UnifiedTranscriptionService) parametersToMap(params models.WhisperXPara
 	return paramMap
 }
 
-// mergeDiarizationWithTranscription combines diarization results with transcription
-func (u *UnifiedTranscriptionService) mergeDiarizationWithTranscription(transcript *interfaces.TranscriptResult, diarization *interfaces.DiarizationResult) *interfaces.TranscriptResult {
-	logger.Info("Merging diarization with transcription",
-		"transcript_segments", len(transcript.Segments),
-		"diarization_segments", len(diarization.Segments))
-
-	// Create a copy of the transcript to avoid modifying the original
-	mergedTranscript := *transcript
-	mergedTranscript.Segments = make([]interfaces.TranscriptSegment, len(transcript.Segments))
-	copy(mergedTranscript.Segments, transcript.Segments)
-
-	// Assign speakers to transcript segments based on timing overlap
-	for i := range mergedTranscript.Segments {
-		segment := &mergedTranscript.Segments[i]
-		bestSpeaker := u.findBestSpeakerForSegment(segment.Start, segment.End, diarization.Segments)
-		if bestSpeaker != "" {
-			segment.Speaker = &bestSpeaker
-		}
-	}
-
-	// Also assign speakers to words if available
-	if len(transcript.WordSegments) > 0 {
-		mergedTranscript.WordSegments = make([]interfaces.TranscriptWord, len(transcript.WordSegments))
-		copy(mergedTranscript.WordSegments, transcript.WordSegments)
-
-		for i := range mergedTranscript.WordSegments {
-			word := &mergedTranscript.WordSegments[i]
-			bestSpeaker := u.findBestSpeakerForSegment(word.Start, word.End, diarization.Segments)
-			if bestSpeaker != "" {
-				word.Speaker = &bestSpeaker
-			}
-		}
-	}
-
-	return &mergedTranscript
-}
-
 // findBestSpeakerForSegment finds the speaker with maximum overlap for a given time segment
 func (u *UnifiedTranscriptionService) findBestSpeakerForSegment(start, end float64, diarizationSegments []interfaces.DiarizationSegment) string {
 	maxOverlap := 0.0
@@ -817,16 +1092,38 @@ func (u *UnifiedTranscriptionService) saveTranscriptionResults(jobID string, res
 	}
 
 	// Update the job in the database
+	updates := transcriptMetadata(result)
+	updates["transcript"] = resultJSON
 	if err := database.DB.Model(&models.TranscriptionJob{}).
 		Where("id = ?", jobID).
-		Update("transcript", resultJSON).Error; err != nil {
+		Updates(updates).Error; err != nil {
 		return fmt.Errorf("failed to update job transcript: %w", err)
 	}
 
+	// Keep the full-text index in sync; search is best effort and must not fail the job
+	if err := search.IndexTranscript(jobID, result); err != nil {
+		logger.Warn("Failed to index transcript for search", "job_id", jobID, "error", err)
+	}
+
 	logger.Info("Saved transcription results", "job_id", jobID, "text_length", len(result.Text))
 	return nil
 }
 
+// transcriptMetadata returns the job columns that describe a transcript: the audio duration,
+// taken from the end of the last segment, and the detected language
+func transcriptMetadata(result *interfaces.TranscriptResult) map[string]interface{} {
+	duration := 0.0
+	for _, segment := range result.Segments {
+		duration = max(duration, segment.End)
+	}
+
+	updates := map[string]interface{}{"audio_duration": duration}
+	if result.Language != "" {
+		updates["detected_language"] = result.Language
+	}
+	return updates
+}
+
 // convertTranscriptResultToJSON converts the interface result to JSON format
 func (u *UnifiedTranscriptionService) convertTranscriptResultToJSON(result *interfaces.TranscriptResult) (string, error) {
 	// Now that the struct fields match the JSON field names, we can directly marshal
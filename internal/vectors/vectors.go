@@ -0,0 +1,21 @@
+// Package vectors holds the arithmetic shared by the packages comparing embeddings
+package vectors
+
+import "math"
+
+// Cosine returns the cosine similarity of two vectors, or 0 if they can't be compared
+func Cosine(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
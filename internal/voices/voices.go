@@ -0,0 +1,289 @@
+package voices
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/vectors"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrNoEmbedding is returned when enrolling a speaker the diarization model kept no embedding for
+	ErrNoEmbedding = errors.New("no voice embedding stored for this speaker")
+	// ErrProfileNotFound is returned when a voice profile does not exist or belongs to someone else
+	ErrProfileNotFound = errors.New("voice profile not found")
+)
+
+// Thresholds are the cosine similarities at which a diarized speaker is matched to a voice profile
+type Thresholds struct {
+	Suggest   float64 // the profile is offered as a name for the speaker
+	AutoApply float64 // the profile's name is applied to the speaker without asking
+}
+
+var (
+	thresholdsMu sync.RWMutex
+	thresholds   = Thresholds{Suggest: 0.6, AutoApply: 0.75}
+)
+
+// SetThresholds changes the similarities used for matching speakers to voice profiles
+func SetThresholds(t Thresholds) {
+	thresholdsMu.Lock()
+	defer thresholdsMu.Unlock()
+	thresholds = t
+}
+
+// CurrentThresholds returns the similarities used for matching speakers to voice profiles
+func CurrentThresholds() Thresholds {
+	thresholdsMu.RLock()
+	defer thresholdsMu.RUnlock()
+	return thresholds
+}
+
+// Similarity returns the cosine similarity of two embeddings, or 0 if they can't be compared
+func Similarity(a, b []float64) float64 {
+	return vectors.Cosine(a, b)
+}
+
+// HasProfiles reports whether a user has at least one voice profile
+func HasProfiles(userID uint) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.VoiceProfile{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to count voice profiles: %w", err)
+	}
+	return count > 0, nil
+}
+
+// SaveJobEmbeddings replaces the speaker embeddings stored for a job
+func SaveJobEmbeddings(jobID, model string, embeddings map[string][]float64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ?", jobID).Delete(&models.SpeakerEmbedding{}).Error; err != nil {
+			return fmt.Errorf("failed to clear speaker embeddings of job %s: %w", jobID, err)
+		}
+		for speaker, vector := range embeddings {
+			embedding := models.SpeakerEmbedding{
+				TranscriptionJobID: jobID,
+				Speaker:            speaker,
+				Model:              model,
+				Vector:             vector,
+			}
+			if err := tx.Create(&embedding).Error; err != nil {
+				return fmt.Errorf("failed to save embedding of %s in job %s: %w", speaker, jobID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// Enrollment adds the voice of a diarized speaker to a voice profile
+type Enrollment struct {
+	UserID    uint
+	JobID     string
+	Speaker   string
+	ProfileID string // profile to add the voice to; when empty, Name picks or creates one
+	Name      string
+}
+
+// Enroll stores the embedding of a job speaker as a sample of a voice profile and names the
+// speaker after the profile. Enrolling a speaker twice replaces the earlier sample.
+func Enroll(e Enrollment) (*models.VoiceProfile, error) {
+	var profile models.VoiceProfile
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var embedding models.SpeakerEmbedding
+		if err := tx.Where("transcription_job_id = ? AND speaker = ?", e.JobID, e.Speaker).First(&embedding).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNoEmbedding
+			}
+			return fmt.Errorf("failed to load embedding of %s in job %s: %w", e.Speaker, e.JobID, err)
+		}
+
+		if e.ProfileID != "" {
+			if err := tx.Where("id = ? AND user_id = ?", e.ProfileID, e.UserID).First(&profile).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrProfileNotFound
+				}
+				return fmt.Errorf("failed to load voice profile %s: %w", e.ProfileID, err)
+			}
+		} else {
+			name := strings.TrimSpace(e.Name)
+			if err := tx.Where("user_id = ? AND LOWER(name) = LOWER(?)", e.UserID, name).Limit(1).Find(&profile).Error; err != nil {
+				return fmt.Errorf("failed to look up voice profile %q: %w", name, err)
+			}
+			if profile.ID == "" {
+				profile = models.VoiceProfile{UserID: e.UserID, Name: name}
+				if err := tx.Create(&profile).Error; err != nil {
+					return fmt.Errorf("failed to create voice profile %q: %w", name, err)
+				}
+			}
+		}
+
+		var sample models.VoiceSample
+		if err := tx.Where("voice_profile_id = ? AND transcription_job_id = ? AND speaker = ?", profile.ID, e.JobID, e.Speaker).
+			Limit(1).Find(&sample).Error; err != nil {
+			return fmt.Errorf("failed to look up voice sample: %w", err)
+		}
+		sample.VoiceProfileID = profile.ID
+		sample.TranscriptionJobID = e.JobID
+		sample.Speaker = e.Speaker
+		sample.Model = embedding.Model
+		sample.Vector = embedding.Vector
+		if err := tx.Save(&sample).Error; err != nil {
+			return fmt.Errorf("failed to save voice sample: %w", err)
+		}
+
+		return nameSpeaker(tx, e.JobID, e.Speaker, &profile)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Preload("Samples").Where("id = ?", profile.ID).First(&profile).Error; err != nil {
+		return nil, fmt.Errorf("failed to load voice profile %s: %w", profile.ID, err)
+	}
+	return &profile, nil
+}
+
+// nameSpeaker sets the speaker mapping of a job speaker to the name of a voice profile
+func nameSpeaker(tx *gorm.DB, jobID, speaker string, profile *models.VoiceProfile) error {
+	var mapping models.SpeakerMapping
+	if err := tx.Where("transcription_job_id = ? AND original_speaker = ?", jobID, speaker).Limit(1).Find(&mapping).Error; err != nil {
+		return fmt.Errorf("failed to look up speaker mapping: %w", err)
+	}
+	mapping.TranscriptionJobID = jobID
+	mapping.OriginalSpeaker = speaker
+	mapping.CustomName = profile.Name
+	mapping.VoiceProfileID = &profile.ID
+	if err := tx.Save(&mapping).Error; err != nil {
+		return fmt.Errorf("failed to name speaker %s: %w", speaker, err)
+	}
+	return nil
+}
+
+// Match is a voice profile a diarized speaker of a job sounds like
+type Match struct {
+	Speaker        string  `json:"speaker"`
+	VoiceProfileID string  `json:"voice_profile_id"`
+	Name           string  `json:"name"`
+	Similarity     float64 `json:"similarity"`
+	Applied        bool    `json:"applied"` // the speaker is already named after this profile
+}
+
+// Suggest matches the speakers of a job against the voice profiles of a user. Matches below the
+// suggestion threshold are dropped, and each speaker and each profile is used at most once, best
+// matches first.
+func Suggest(userID uint, jobID string) ([]Match, error) {
+	var embeddings []models.SpeakerEmbedding
+	if err := database.DB.Where("transcription_job_id = ?", jobID).Find(&embeddings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load speaker embeddings of job %s: %w", jobID, err)
+	}
+	if len(embeddings) == 0 {
+		return []Match{}, nil
+	}
+	var profiles []models.VoiceProfile
+	if err := database.DB.Preload("Samples").Where("user_id = ?", userID).Find(&profiles).Error; err != nil {
+		return nil, fmt.Errorf("failed to load voice profiles: %w", err)
+	}
+
+	matches := matchSpeakers(embeddings, profiles, CurrentThresholds().Suggest)
+	if len(matches) == 0 {
+		return matches, nil
+	}
+
+	var mappings []models.SpeakerMapping
+	if err := database.DB.Where("transcription_job_id = ? AND voice_profile_id IS NOT NULL", jobID).Find(&mappings).Error; err != nil {
+		return nil, fmt.Errorf("failed to load speaker mappings of job %s: %w", jobID, err)
+	}
+	for i := range matches {
+		for _, mapping := range mappings {
+			if mapping.OriginalSpeaker == matches[i].Speaker && *mapping.VoiceProfileID == matches[i].VoiceProfileID {
+				matches[i].Applied = true
+			}
+		}
+	}
+	return matches, nil
+}
+
+// matchSpeakers pairs speakers with the profile whose closest sample is most similar to them
+func matchSpeakers(embeddings []models.SpeakerEmbedding, profiles []models.VoiceProfile, threshold float64) []Match {
+	var candidates []Match
+	for _, embedding := range embeddings {
+		for _, profile := range profiles {
+			best := 0.0
+			for _, sample := range profile.Samples {
+				if sample.Model != embedding.Model {
+					continue
+				}
+				best = math.Max(best, Similarity(sample.Vector, embedding.Vector))
+			}
+			if best >= threshold {
+				candidates = append(candidates, Match{
+					Speaker:        embedding.Speaker,
+					VoiceProfileID: profile.ID,
+					Name:           profile.Name,
+					Similarity:     best,
+				})
+			}
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Similarity > candidates[j].Similarity })
+
+	matches := []Match{}
+	usedSpeakers := make(map[string]bool)
+	usedProfiles := make(map[string]bool)
+	for _, candidate := range candidates {
+		if usedSpeakers[candidate.Speaker] || usedProfiles[candidate.VoiceProfileID] {
+			continue
+		}
+		usedSpeakers[candidate.Speaker] = true
+		usedProfiles[candidate.VoiceProfileID] = true
+		matches = append(matches, candidate)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Speaker < matches[j].Speaker })
+	return matches
+}
+
+// AutoApply names the speakers of a job after the voice profiles they match above the auto-apply
+// threshold. Names applied by an earlier run are replaced; names set by hand are kept.
+func AutoApply(userID uint, jobID string) ([]Match, error) {
+	matches, err := Suggest(userID, jobID)
+	if err != nil {
+		return nil, err
+	}
+	threshold := CurrentThresholds().AutoApply
+
+	applied := []Match{}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transcription_job_id = ? AND voice_profile_id IS NOT NULL", jobID).Delete(&models.SpeakerMapping{}).Error; err != nil {
+			return fmt.Errorf("failed to clear matched speaker names of job %s: %w", jobID, err)
+		}
+		var named []string
+		if err := tx.Model(&models.SpeakerMapping{}).Where("transcription_job_id = ?", jobID).Pluck("original_speaker", &named).Error; err != nil {
+			return fmt.Errorf("failed to load speaker mappings of job %s: %w", jobID, err)
+		}
+
+		for _, match := range matches {
+			if match.Similarity < threshold || slices.Contains(named, match.Speaker) {
+				continue
+			}
+			profile := models.VoiceProfile{ID: match.VoiceProfileID, Name: match.Name}
+			if err := nameSpeaker(tx, jobID, match.Speaker, &profile); err != nil {
+				return err
+			}
+			match.Applied = true
+			applied = append(applied, match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
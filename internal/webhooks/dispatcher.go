@@ -0,0 +1,206 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/pkg/logger"
+)
+
+const (
+	defaultMaxAttempts = 6
+	defaultBaseDelay   = 30 * time.Second
+	defaultMaxDelay    = time.Hour
+	maxResponseBody    = 2048
+	dispatchBatchSize  = 50
+)
+
+// Dispatcher sends queued webhook deliveries, retrying failures with exponential backoff
+type Dispatcher struct {
+	Client      *http.Client
+	MaxAttempts int           // attempts before a delivery is marked failed
+	BaseDelay   time.Duration // delay before the first retry; doubles on each further attempt
+	MaxDelay    time.Duration // upper bound for the retry delay
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a dispatcher. WEBHOOK_MAX_ATTEMPTS overrides the attempt limit and
+// WEBHOOK_ALLOW_PRIVATE_TARGETS=true lets deliveries reach addresses of the internal network.
+func NewDispatcher() *Dispatcher {
+	maxAttempts := defaultMaxAttempts
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			maxAttempts = n
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		Client:      newHTTPClient(),
+		MaxAttempts: maxAttempts,
+		BaseDelay:   defaultBaseDelay,
+		MaxDelay:    defaultMaxDelay,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start begins delivering queued webhooks in the background
+func (d *Dispatcher) Start() {
+	d.wg.Add(1)
+	go d.run()
+}
+
+// Stop stops the dispatcher and waits for in-flight deliveries
+func (d *Dispatcher) Stop() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// run delivers due webhooks whenever new ones are queued and on a timer for retries
+func (d *Dispatcher) run() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	logger.Debug("Webhook dispatcher started")
+
+	for {
+		d.ProcessDue()
+		select {
+		case <-ticker.C:
+		case <-wake:
+		case <-d.ctx.Done():
+			logger.Debug("Webhook dispatcher stopped")
+			return
+		}
+	}
+}
+
+// ProcessDue sends every pending delivery whose next attempt is due and returns how many were attempted
+func (d *Dispatcher) ProcessDue() int {
+	attempted := 0
+	for {
+		var due []models.WebhookDelivery
+		if err := database.DB.Preload("Webhook").
+			Where("status = ? AND next_attempt_at <= ?", models.DeliveryPending, time.Now()).
+			Order("next_attempt_at ASC").Limit(dispatchBatchSize).
+			Find(&due).Error; err != nil {
+			logger.Error("Failed to load due webhook deliveries", "error", err)
+			return attempted
+		}
+		if len(due) == 0 {
+			return attempted
+		}
+
+		for i := range due {
+			if d.ctx.Err() != nil {
+				return attempted
+			}
+			if err := d.Deliver(&due[i]); err != nil {
+				logger.Debug("Webhook delivery attempt failed", "delivery_id", due[i].ID, "attempt", due[i].Attempts, "error", err)
+			}
+			attempted++
+		}
+	}
+}
+
+// Deliver makes one attempt to send a delivery and records the outcome.
+// Failed attempts are rescheduled until MaxAttempts is reached.
+func (d *Dispatcher) Deliver(delivery *models.WebhookDelivery) error {
+	delivery.Attempts++
+
+	var sendErr error
+	if !delivery.Webhook.IsActive {
+		sendErr = fmt.Errorf("webhook is disabled")
+		delivery.Attempts = d.MaxAttempts // no point retrying
+	} else {
+		sendErr = d.send(delivery)
+	}
+
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = models.DeliverySucceeded
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.Error = nil
+	} else {
+		msg := sendErr.Error()
+		delivery.Error = &msg
+		if delivery.Attempts >= d.MaxAttempts {
+			delivery.Status = models.DeliveryFailed
+			delivery.NextAttemptAt = nil
+			logger.Warn("Webhook delivery failed permanently", "delivery_id", delivery.ID, "webhook_id", delivery.WebhookID, "attempts", delivery.Attempts, "error", sendErr)
+		} else {
+			next := time.Now().Add(d.Backoff(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := database.DB.Model(delivery).Select("attempts", "status", "next_attempt_at", "response_status", "response_body", "error", "delivered_at").
+		Updates(delivery).Error; err != nil {
+		return fmt.Errorf("failed to record delivery attempt: %w", err)
+	}
+	return sendErr
+}
+
+// Backoff returns the delay before retrying after the given number of attempts
+func (d *Dispatcher) Backoff(attempts int) time.Duration {
+	delay := d.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.MaxDelay {
+			return d.MaxDelay
+		}
+	}
+	return delay
+}
+
+// send posts the signed payload and stores the endpoint's response on the delivery
+func (d *Dispatcher) send(delivery *models.WebhookDelivery) error {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Synthezia-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Webhook.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		delivery.ResponseStatus = nil
+		delivery.ResponseBody = nil
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	status := resp.StatusCode
+	text := string(respBody)
+	delivery.ResponseStatus = &status
+	delivery.ResponseBody = &text
+
+	if status < 200 || status >= 300 {
+		return fmt.Errorf("endpoint responded with status %d", status)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// envAllowPrivateTargets lets webhooks reach loopback, private and link-local addresses, for
+// instances whose receivers run on the same host or network
+const envAllowPrivateTargets = "WEBHOOK_ALLOW_PRIVATE_TARGETS"
+
+// ErrPrivateTarget is returned for webhook URLs that point into the internal network
+var ErrPrivateTarget = errors.New("webhook URL must not point to a loopback, private or link-local address")
+
+// AllowPrivateTargets reports whether webhooks may be sent into the internal network
+func AllowPrivateTargets() bool {
+	return os.Getenv(envAllowPrivateTargets) == "true"
+}
+
+// ValidateTargetURL checks that a webhook URL is an absolute http(s) URL whose host isn't a
+// loopback, private or link-local address. Host names are checked again when a delivery
+// connects, against the addresses they resolve to then.
+func ValidateTargetURL(rawURL string) error {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	if AllowPrivateTargets() {
+		return nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateTarget
+	}
+	if ip := net.ParseIP(host); ip != nil && isPrivateIP(ip) {
+		return ErrPrivateTarget
+	}
+	return nil
+}
+
+// isPrivateIP reports whether an address is loopback, private, link-local or unspecified
+func isPrivateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified()
+}
+
+// refusePrivateAddress is a dialer control refusing connections into the internal network,
+// which also covers host names resolving to such addresses and redirects to them
+func refusePrivateAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || isPrivateIP(ip) {
+		return fmt.Errorf("%w: %s", ErrPrivateTarget, host)
+	}
+	return nil
+}
+
+// newHTTPClient returns the client deliveries are sent with
+func newHTTPClient() *http.Client {
+	if AllowPrivateTargets() {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // A proxy would connect on the dispatcher's behalf
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   refusePrivateAddress,
+	}).DialContext
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
@@ -0,0 +1,225 @@
+package webhooks
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// Lifecycle events a webhook can subscribe to
+const (
+	EventJobProcessing            = "job.processing"
+	EventJobCompleted             = "job.completed"
+	EventJobFailed                = "job.failed"
+	EventJobRetrying              = "job.retrying"
+	EventSummaryCompleted         = "summary.completed"
+	EventMultiTrackMergeCompleted = "multitrack.merge_completed"
+	EventMultiTrackMergeFailed    = "multitrack.merge_failed"
+)
+
+// SupportedEvents lists every event that can be delivered
+var SupportedEvents = []string{
+	EventJobProcessing,
+	EventJobCompleted,
+	EventJobFailed,
+	EventJobRetrying,
+	EventSummaryCompleted,
+	EventMultiTrackMergeCompleted,
+	EventMultiTrackMergeFailed,
+}
+
+// Headers sent with every delivery
+const (
+	HeaderEvent     = "X-Synthezia-Event"
+	HeaderDelivery  = "X-Synthezia-Delivery"
+	HeaderTimestamp = "X-Synthezia-Timestamp"
+	HeaderSignature = "X-Synthezia-Signature"
+)
+
+// Payload is the JSON body posted to webhook endpoints
+type Payload struct {
+	ID        string      `json:"id"` // Event ID, shared by every delivery (and replay) of the same event
+	Event     string      `json:"event"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// JobData describes a transcription job in job and merge event payloads
+type JobData struct {
+	JobID        string           `json:"job_id"`
+	Title        *string          `json:"title,omitempty"`
+	Status       models.JobStatus `json:"status"`
+	ErrorMessage *string          `json:"error_message,omitempty"`
+	IsMultiTrack bool             `json:"is_multi_track"`
+	MergeStatus  string           `json:"merge_status,omitempty"`
+	MergeError   *string          `json:"merge_error,omitempty"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// SummaryData describes a generated summary in summary event payloads
+type SummaryData struct {
+	SummaryID       string  `json:"summary_id"`
+	TranscriptionID string  `json:"transcription_id"`
+	TemplateID      *string `json:"template_id,omitempty"`
+	Model           string  `json:"model"`
+}
+
+// wake nudges a running dispatcher when new deliveries are queued
+var wake = make(chan struct{}, 1)
+
+// IsSupportedEvent reports whether event can be subscribed to
+func IsSupportedEvent(event string) bool {
+	if event == "*" {
+		return true
+	}
+	for _, e := range SupportedEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateSecret returns a random signing secret for a new webhook
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign computes the signature header value for a payload.
+// The HMAC-SHA256 covers "<timestamp>.<body>" so receivers can reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header value produced by Sign
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Emit records a delivery of event for every active webhook of userID subscribed to it.
+// Deliveries are sent asynchronously by the Dispatcher.
+func Emit(event string, userID uint, data interface{}) {
+	if database.DB == nil {
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := database.DB.Where("user_id = ? AND is_active = ?", userID, true).Find(&hooks).Error; err != nil {
+		logger.Warn("Failed to load webhooks", "event", event, "error", err)
+		return
+	}
+
+	body, err := json.Marshal(Payload{
+		ID:        uuid.New().String(),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	})
+	if err != nil {
+		logger.Warn("Failed to encode webhook payload", "event", event, "error", err)
+		return
+	}
+
+	queued := 0
+	now := time.Now()
+	for _, hook := range hooks {
+		if !hook.Subscribes(event) {
+			continue
+		}
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			Event:         event,
+			Payload:       string(body),
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := database.DB.Create(&delivery).Error; err != nil {
+			logger.Warn("Failed to queue webhook delivery", "webhook_id", hook.ID, "event", event, "error", err)
+			continue
+		}
+		queued++
+	}
+
+	if queued > 0 {
+		notify()
+	}
+}
+
+// EmitJobEvent emits event with the current state of a transcription job
+func EmitJobEvent(event, jobID string) {
+	if database.DB == nil {
+		return
+	}
+
+	var job models.TranscriptionJob
+	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		logger.Warn("Failed to load job for webhook event", "job_id", jobID, "event", event, "error", err)
+		return
+	}
+
+	Emit(event, job.UserID, JobData{
+		JobID:        job.ID,
+		Title:        job.Title,
+		Status:       job.Status,
+		ErrorMessage: job.ErrorMessage,
+		IsMultiTrack: job.IsMultiTrack,
+		MergeStatus:  job.MergeStatus,
+		MergeError:   job.MergeError,
+		UpdatedAt:    job.UpdatedAt,
+	})
+}
+
+// EmitSummaryEvent emits a summary.completed event for a stored summary
+func EmitSummaryEvent(summary *models.Summary) {
+	Emit(EventSummaryCompleted, summary.UserID, SummaryData{
+		SummaryID:       summary.ID,
+		TranscriptionID: summary.TranscriptionID,
+		TemplateID:      summary.TemplateID,
+		Model:           summary.Model,
+	})
+}
+
+// Replay queues a new delivery that re-sends the payload of an earlier one
+func Replay(original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     original.WebhookID,
+		Event:         original.Event,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOf:      &original.ID,
+	}
+	if err := database.DB.Create(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue replay: %w", err)
+	}
+	notify()
+	return &delivery, nil
+}
+
+// notify wakes the dispatcher without blocking if a wake-up is already pending
+func notify() {
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,294 @@
+package whisperapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"synthezia/internal/export"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+)
+
+// Response formats accepted by the OpenAI audio endpoints
+const (
+	FormatJSON        = "json"
+	FormatVerboseJSON = "verbose_json"
+	FormatText        = "text"
+	FormatSRT         = "srt"
+	FormatVTT         = "vtt"
+)
+
+// SupportedFormats lists every accepted response_format
+var SupportedFormats = []string{FormatJSON, FormatVerboseJSON, FormatText, FormatSRT, FormatVTT}
+
+// Timestamp granularities for verbose_json responses
+const (
+	GranularitySegment = "segment"
+	GranularityWord    = "word"
+)
+
+// DefaultModel is the model name OpenAI clients send by default
+const DefaultModel = "whisper-1"
+
+// whisperModels are the WhisperX model sizes that can be requested by name
+var whisperModels = []string{
+	"tiny", "tiny.en", "base", "base.en", "small", "small.en", "medium", "medium.en",
+	"large", "large-v1", "large-v2", "large-v3",
+}
+
+// Request holds the form fields of a transcription or translation request
+type Request struct {
+	Model                  string
+	Language               string
+	Prompt                 string
+	ResponseFormat         string
+	Temperature            *float64
+	TimestampGranularities []string
+	Translate              bool // translate to English instead of transcribing
+}
+
+// IsSupportedFormat reports whether format is a valid response_format
+func IsSupportedFormat(format string) bool {
+	for _, f := range SupportedFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultParams returns the parameters used for whisper-1 when the user has no default profile
+func DefaultParams() models.WhisperXParams {
+	return models.WhisperXParams{
+		ModelFamily:                    "whisper",
+		Model:                          "large-v2", // the model behind OpenAI's whisper-1
+		Device:                         "auto",
+		BatchSize:                      8,
+		ComputeType:                    "float32",
+		OutputFormat:                   "all",
+		Task:                           "transcribe",
+		InterpolateMethod:              "nearest",
+		VadMethod:                      "pyannote",
+		VadOnset:                       0.5,
+		VadOffset:                      0.363,
+		ChunkSize:                      30,
+		DiarizeModel:                   "pyannote",
+		BestOf:                         5,
+		BeamSize:                       5,
+		Patience:                       1.0,
+		LengthPenalty:                  1.0,
+		Fp16:                           true,
+		TemperatureIncrementOnFallback: 0.2,
+		CompressionRatioThreshold:      2.4,
+		LogprobThreshold:               -1.0,
+		NoSpeechThreshold:              0.6,
+		SegmentResolution:              "sentence",
+		AttentionContextLeft:           256,
+		AttentionContextRight:          256,
+	}
+}
+
+// ModelParams resolves a built-in model name to transcription parameters.
+// Accepted names are whisper-1, WhisperX sizes (optionally prefixed with "whisper-"),
+// parakeet and canary. It returns false for unknown names.
+func ModelParams(model string) (models.WhisperXParams, bool) {
+	params := DefaultParams()
+	name := strings.ToLower(strings.TrimSpace(model))
+
+	switch name {
+	case "", DefaultModel:
+		return params, true
+	case "parakeet", "nvidia_parakeet":
+		params.ModelFamily = "nvidia_parakeet"
+		params.Model = "parakeet"
+		return params, true
+	case "canary", "nvidia_canary":
+		params.ModelFamily = "nvidia_canary"
+		params.Model = "canary"
+		return params, true
+	}
+
+	name = strings.TrimPrefix(name, "whisper-")
+	for _, m := range whisperModels {
+		if m == name {
+			params.Model = m
+			return params, true
+		}
+	}
+	return params, false
+}
+
+// SupportsTranslation reports whether params select a model that can translate to English
+func SupportsTranslation(params models.WhisperXParams) bool {
+	return params.ModelFamily == "" || params.ModelFamily == "whisper"
+}
+
+// ApplyRequest overlays the request's language, prompt, temperature and task onto params
+func ApplyRequest(params models.WhisperXParams, req Request) models.WhisperXParams {
+	params.Task = "transcribe"
+	if req.Translate {
+		params.Task = "translate"
+	}
+	if req.Language != "" {
+		language := req.Language
+		params.Language = &language
+	}
+	if req.Prompt != "" {
+		prompt := req.Prompt
+		params.InitialPrompt = &prompt
+	}
+	if req.Temperature != nil {
+		params.Temperature = *req.Temperature
+	}
+	if wantsGranularity(req, GranularityWord) {
+		// Word timings come from alignment
+		params.NoAlign = false
+	}
+	// API responses are a single transcript; diarization is never requested
+	params.Diarize = false
+	params.IsMultiTrackEnabled = false
+	return params
+}
+
+// Output is a rendered response body
+type Output struct {
+	Data        []byte
+	ContentType string
+}
+
+// verboseSegment mirrors a segment of OpenAI's verbose_json response
+type verboseSegment struct {
+	ID          int     `json:"id"`
+	Seek        int     `json:"seek"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Text        string  `json:"text"`
+	Tokens      []int   `json:"tokens"`
+	Temperature float64 `json:"temperature"`
+}
+
+// verboseWord mirrors a word of OpenAI's verbose_json response
+type verboseWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// verboseResponse mirrors OpenAI's verbose_json response
+type verboseResponse struct {
+	Task     string           `json:"task"`
+	Language string           `json:"language"`
+	Duration float64          `json:"duration"`
+	Text     string           `json:"text"`
+	Segments []verboseSegment `json:"segments,omitempty"`
+	Words    []verboseWord    `json:"words,omitempty"`
+}
+
+// Render formats a transcript as the requested response_format
+func Render(result *interfaces.TranscriptResult, req Request) (*Output, error) {
+	if result == nil {
+		return nil, fmt.Errorf("transcript is empty")
+	}
+
+	switch req.ResponseFormat {
+	case "", FormatJSON:
+		return jsonOutput(map[string]string{"text": Text(result)})
+	case FormatText:
+		return &Output{Data: []byte(Text(result) + "\n"), ContentType: "text/plain; charset=utf-8"}, nil
+	case FormatSRT, FormatVTT:
+		out, err := export.Render(req.ResponseFormat, result, export.Options{})
+		if err != nil {
+			return nil, err
+		}
+		return &Output{Data: out.Data, ContentType: out.ContentType}, nil
+	case FormatVerboseJSON:
+		return jsonOutput(verbose(result, req))
+	default:
+		return nil, fmt.Errorf("unsupported response format: %s", req.ResponseFormat)
+	}
+}
+
+// Text returns the plain transcript text, built from segments when the model didn't provide it
+func Text(result *interfaces.TranscriptResult) string {
+	if text := strings.TrimSpace(result.Text); text != "" {
+		return text
+	}
+	parts := make([]string, 0, len(result.Segments))
+	for _, seg := range result.Segments {
+		if t := strings.TrimSpace(seg.Text); t != "" {
+			parts = append(parts, t)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// verbose builds the verbose_json body, including segments and words per the requested granularities
+func verbose(result *interfaces.TranscriptResult, req Request) verboseResponse {
+	task := "transcribe"
+	if req.Translate {
+		task = "translate"
+	}
+	temperature := 0.0
+	if req.Temperature != nil {
+		temperature = *req.Temperature
+	}
+
+	resp := verboseResponse{
+		Task:     task,
+		Language: result.Language,
+		Text:     Text(result),
+	}
+
+	for _, seg := range result.Segments {
+		if seg.End > resp.Duration {
+			resp.Duration = seg.End
+		}
+	}
+	for _, w := range result.WordSegments {
+		if w.End > resp.Duration {
+			resp.Duration = w.End
+		}
+	}
+
+	if len(req.TimestampGranularities) == 0 || wantsGranularity(req, GranularitySegment) {
+		resp.Segments = make([]verboseSegment, 0, len(result.Segments))
+		for i, seg := range result.Segments {
+			resp.Segments = append(resp.Segments, verboseSegment{
+				ID:          i,
+				Start:       seg.Start,
+				End:         seg.End,
+				Text:        seg.Text,
+				Tokens:      []int{},
+				Temperature: temperature,
+			})
+		}
+	}
+
+	if wantsGranularity(req, GranularityWord) {
+		resp.Words = make([]verboseWord, 0, len(result.WordSegments))
+		for _, w := range result.WordSegments {
+			resp.Words = append(resp.Words, verboseWord{Word: strings.TrimSpace(w.Word), Start: w.Start, End: w.End})
+		}
+	}
+
+	return resp
+}
+
+// wantsGranularity reports whether the request asked for the given timestamp granularity
+func wantsGranularity(req Request, granularity string) bool {
+	for _, g := range req.TimestampGranularities {
+		if g == granularity {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonOutput(v interface{}) (*Output, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+	return &Output{Data: data, ContentType: "application/json; charset=utf-8"}, nil
+}
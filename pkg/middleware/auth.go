@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 	"time"
@@ -10,6 +11,7 @@ import (
 	"synthezia/internal/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // AuthMiddleware handles both API key and JWT authentication
@@ -18,9 +20,10 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 		// Check for API key first
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey != "" {
-			if validateAPIKey(apiKey) {
-				c.Set("auth_type", "api_key")
-				c.Set("api_key", apiKey)
+			if key, ok := validateAPIKey(apiKey); ok {
+				if !setAPIKeyAuth(c, apiKey, key) {
+					return
+				}
 				c.Next()
 				return
 			}
@@ -53,26 +56,135 @@ func AuthMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 		c.Set("auth_type", "jwt")
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		if !setUserRole(c, claims.UserID) {
+			return
+		}
 		c.Next()
 	}
 }
 
-// validateAPIKey validates an API key against the database and updates last used timestamp
-func validateAPIKey(key string) bool {
+// validateAPIKey validates an API key against the database and records its use
+func validateAPIKey(key string) (*models.APIKey, bool) {
 	var apiKey models.APIKey
-	result := database.DB.Where("key = ? AND is_active = ?", key, true).First(&apiKey)
-	if result.Error != nil {
-		return false
+	if err := database.DB.Where("key = ? AND hashed = ? AND is_active = ?", auth.HashAPIKey(key), true, true).
+		Limit(1).Find(&apiKey).Error; err != nil {
+		return nil, false
+	}
+	if apiKey.ID == 0 || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		return nil, false
 	}
 
-	// Update last used timestamp
-	now := time.Now()
-	apiKey.LastUsed = &now
-	database.DB.Save(&apiKey)
+	// Update last used timestamp and usage count
+	database.DB.Model(&models.APIKey{}).Where("id = ?", apiKey.ID).Updates(map[string]interface{}{
+		"last_used":   time.Now(),
+		"usage_count": gorm.Expr("usage_count + 1"),
+	})
 
+	return &apiKey, true
+}
+
+// setAPIKeyAuth stores the authenticated API key and its user in the context. It aborts the
+// request and returns false when the user's account is disabled.
+func setAPIKeyAuth(c *gin.Context, rawKey string, key *models.APIKey) bool {
+	c.Set("auth_type", "api_key")
+	c.Set("api_key", rawKey)
+	c.Set("api_key_id", key.ID)
+	c.Set("api_key_scopes", key.Scopes)
+	c.Set("user_id", key.UserID)
+	return setUserRole(c, key.UserID)
+}
+
+// setUserRole stores the role of the authenticated user in the context. It aborts the request
+// and returns false when the user no longer exists, can't be loaded, is disabled or has no role.
+func setUserRole(c *gin.Context, userID uint) bool {
+	var user models.User
+	if err := database.DB.Select("id", "role", "disabled").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load user"})
+		}
+		c.Abort()
+		return false
+	}
+	if user.Disabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		c.Abort()
+		return false
+	}
+	if user.Role == "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account has no role"})
+		c.Abort()
+		return false
+	}
+	c.Set("role", user.Role)
 	return true
 }
 
+// RoleMiddleware only allows users with one of the given roles. It must run after one of the
+// authentication middlewares.
+func RoleMiddleware(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, allowed := range roles {
+			if role == allowed {
+				c.Next()
+				return
+			}
+		}
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// WriteAccessMiddleware lets viewers make read requests only, while admins and editors
+// can make any request. It must run after one of the authentication middlewares.
+func WriteAccessMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		role := c.GetString("role")
+		if role != models.RoleAdmin && role != models.RoleEditor {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Your role only allows read access"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ScopeMiddleware requires API keys to carry scope. Requests authenticated with a JWT are not restricted.
+func ScopeMiddleware(scope string) gin.HandlerFunc {
+	return ReadWriteScopeMiddleware(scope, scope)
+}
+
+// ReadWriteScopeMiddleware requires API keys to carry readScope for read requests and writeScope
+// for all others. Requests authenticated with a JWT are not restricted.
+func ReadWriteScopeMiddleware(readScope, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != "api_key" {
+			c.Next()
+			return
+		}
+		scope := writeScope
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			scope = readScope
+		}
+		value, _ := c.Get("api_key_scopes")
+		scopes, _ := value.([]string)
+		if !models.ScopesAllow(scopes, scope) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API key lacks the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
 // APIKeyOnlyMiddleware only allows API key authentication
 func APIKeyOnlyMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -83,14 +195,16 @@ func APIKeyOnlyMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if !validateAPIKey(apiKey) {
+		key, ok := validateAPIKey(apiKey)
+		if !ok {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
 			c.Abort()
 			return
 		}
 
-		c.Set("auth_type", "api_key")
-		c.Set("api_key", apiKey)
+		if !setAPIKeyAuth(c, apiKey, key) {
+			return
+		}
 		c.Next()
 	}
 }
@@ -123,6 +237,40 @@ func JWTOnlyMiddleware(authService *auth.AuthService) gin.HandlerFunc {
 		c.Set("auth_type", "jwt")
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
+		if !setUserRole(c, claims.UserID) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// BearerAPIKeyMiddleware authenticates API keys sent as "Authorization: Bearer <key>",
+// the scheme used by OpenAI-compatible clients. X-API-Key is accepted as well.
+func BearerAPIKeyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		apiKey := c.GetHeader("X-API-Key")
+		if apiKey == "" {
+			parts := strings.SplitN(c.GetHeader("Authorization"), " ", 2)
+			if len(parts) == 2 && parts[0] == "Bearer" {
+				apiKey = strings.TrimSpace(parts[1])
+			}
+		}
+		if apiKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required"})
+			c.Abort()
+			return
+		}
+
+		key, ok := validateAPIKey(apiKey)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid API key"})
+			c.Abort()
+			return
+		}
+
+		if !setAPIKeyAuth(c, apiKey, key) {
+			return
+		}
 		c.Next()
 	}
 }
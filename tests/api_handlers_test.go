@@ -2,6 +2,7 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -9,6 +10,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -292,6 +294,27 @@ func (suite *APIHandlerTestSuite) TestUpdateTranscriptionTitle() {
 	assert.Equal(suite.T(), "Updated Title", *response.Title)
 }
 
+// Test changing the priority of a waiting job
+func (suite *APIHandlerTestSuite) TestUpdateJobPriority() {
+	testJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Priority Job")
+	path := fmt.Sprintf("/api/v1/transcription/%s/priority", testJob.ID)
+
+	w := suite.makeAuthenticatedRequest("PUT", path, map[string]int{"priority": models.PriorityHigh}, false)
+	assert.Equal(suite.T(), 200, w.Code)
+
+	var stored models.TranscriptionJob
+	suite.helper.DB.Where("id = ?", testJob.ID).First(&stored)
+	assert.Equal(suite.T(), models.PriorityHigh, stored.Priority)
+
+	w = suite.makeAuthenticatedRequest("PUT", path, map[string]int{"priority": models.MaxPriority + 1}, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	// Jobs that already started keep their priority
+	suite.helper.DB.Model(&stored).Update("status", models.StatusProcessing)
+	w = suite.makeAuthenticatedRequest("PUT", path, map[string]int{"priority": models.PriorityLow}, false)
+	assert.Equal(suite.T(), 409, w.Code)
+}
+
 // Test deleting transcription job
 func (suite *APIHandlerTestSuite) TestDeleteTranscriptionJob() {
 	testJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job to Delete")
@@ -366,6 +389,110 @@ func (suite *APIHandlerTestSuite) TestProfileManagement() {
 	assert.Equal(suite.T(), 200, w.Code)
 }
 
+// Test that profiles reject invalid audio enhancement settings
+func (suite *APIHandlerTestSuite) TestProfilePreprocessingValidation() {
+	profileData := map[string]interface{}{
+		"name": "Noisy Room",
+		"parameters": map[string]interface{}{
+			"model":              "base",
+			"highpass_frequency": 80,
+			"denoise":            "afftdn",
+			"loudnorm":           true,
+			"loudnorm_target":    -16,
+		},
+	}
+	w := suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", profileData, false)
+	assert.Equal(suite.T(), 200, w.Code)
+
+	var profile models.TranscriptionProfile
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(suite.T(), "afftdn", profile.Parameters.Denoise)
+	assert.Equal(suite.T(), 80, profile.Parameters.HighpassFrequency)
+
+	invalid := []map[string]interface{}{
+		{"denoise": "sox"},
+		{"denoise": "arnndn"},
+		{"denoise": "arnndn", "denoise_model": "../../etc/passwd"},
+		{"highpass_frequency": 5000},
+		{"loudnorm": true, "loudnorm_target": 3},
+	}
+	for i, params := range invalid {
+		data := map[string]interface{}{"name": fmt.Sprintf("Invalid %d", i), "parameters": params}
+		w = suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", data, false)
+		assert.Equal(suite.T(), 400, w.Code, "params %v", params)
+	}
+
+	update := map[string]interface{}{"name": "Noisy Room", "parameters": map[string]interface{}{"denoise": "nope"}}
+	w = suite.makeAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/profiles/%s", profile.ID), update, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/profiles/%s", profile.ID), nil, false)
+}
+
+// Test replacement dictionaries and selecting them from a profile
+func (suite *APIHandlerTestSuite) TestReplacementDictionaries() {
+	dictionaryData := map[string]interface{}{
+		"name": "Product names",
+		"rules": []map[string]interface{}{
+			{"pattern": "synthesia", "replacement": "Synthezia", "whole_word": true},
+		},
+	}
+	w := suite.makeAuthenticatedRequest("POST", "/api/v1/dictionaries/", dictionaryData, false)
+	assert.Equal(suite.T(), 201, w.Code)
+
+	var dictionary models.ReplacementDictionary
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &dictionary))
+	assert.Len(suite.T(), dictionary.Rules, 1)
+
+	// Invalid regular expressions are rejected
+	invalid := map[string]interface{}{
+		"name":  "Broken",
+		"rules": []map[string]interface{}{{"pattern": "(", "regex": true}},
+	}
+	w = suite.makeAuthenticatedRequest("POST", "/api/v1/dictionaries/", invalid, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	// Profiles may only reference the user's own dictionaries
+	profileData := map[string]interface{}{
+		"name": "With dictionary",
+		"parameters": map[string]interface{}{
+			"replacement_dictionaries": []string{dictionary.ID},
+			"normalize_numbers":        true,
+			"mask_profanity":           true,
+		},
+	}
+	w = suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", profileData, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	var profile models.TranscriptionProfile
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &profile))
+	assert.Equal(suite.T(), []string{dictionary.ID}, profile.Parameters.ReplacementDictionaries)
+	assert.True(suite.T(), profile.Parameters.NormalizeNumbers)
+
+	profileData["name"] = "Unknown dictionary"
+	profileData["parameters"] = map[string]interface{}{"replacement_dictionaries": []string{"missing"}}
+	w = suite.makeAuthenticatedRequest("POST", "/api/v1/profiles/", profileData, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	update := map[string]interface{}{
+		"name":  "Product names",
+		"rules": []map[string]interface{}{{"pattern": `v(\d+)`, "replacement": "version $1", "regex": true}},
+	}
+	w = suite.makeAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/dictionaries/%s", dictionary.ID), update, false)
+	assert.Equal(suite.T(), 200, w.Code)
+
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/dictionaries/%s", dictionary.ID), nil, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &dictionary))
+	assert.True(suite.T(), dictionary.Rules[0].Regex)
+
+	w = suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/dictionaries/%s", dictionary.ID), nil, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/dictionaries/%s", dictionary.ID), nil, false)
+	assert.Equal(suite.T(), 404, w.Code)
+
+	suite.makeAuthenticatedRequest("DELETE", fmt.Sprintf("/api/v1/profiles/%s", profile.ID), nil, false)
+}
+
 // Test notes management
 func (suite *APIHandlerTestSuite) TestNotesManagement() {
 	// Create a transcription job first
@@ -420,6 +547,160 @@ func (suite *APIHandlerTestSuite) TestNotesManagement() {
 	assert.Equal(suite.T(), 200, w.Code)
 }
 
+// Test transcript editing and revision history
+func (suite *APIHandlerTestSuite) TestTranscriptEditing() {
+	testJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job for Editing")
+	transcript, _ := json.Marshal(editableTranscript())
+	transcriptJSON := string(transcript)
+	testJob.Status = models.StatusCompleted
+	testJob.Transcript = &transcriptJSON
+	assert.NoError(suite.T(), suite.helper.DB.Save(testJob).Error)
+
+	// A note on "general Kenobi."
+	noteData := map[string]interface{}{
+		"start_word_index": 2,
+		"end_word_index":   3,
+		"start_time":       1.0,
+		"end_time":         2.0,
+		"quote":            "general Kenobi.",
+		"content":          "Famous line",
+	}
+	w := suite.makeAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/transcription/%s/notes", testJob.ID), noteData, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	var note models.Note
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &note))
+
+	// Inserting a word before the note shifts its word indices
+	edit := map[string]string{"text": "Well hello there general Kenobi."}
+	w = suite.makeAuthenticatedRequest("PATCH", fmt.Sprintf("/api/v1/transcription/%s/segments/0", testJob.ID), edit, true)
+	assert.Equal(suite.T(), 200, w.Code)
+	var editResponse api.TranscriptEditResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &editResponse))
+	assert.Equal(suite.T(), 2, editResponse.Revision.Revision)
+	assert.Equal(suite.T(), "edit_text", editResponse.Revision.Operation)
+	assert.Equal(suite.T(), suite.helper.TestUser.Username, editResponse.Revision.Author)
+	assert.Equal(suite.T(), " Well hello there general Kenobi.", editResponse.Transcript.Segments[0].Text)
+
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/notes/%s", note.ID), nil, false)
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &note))
+	assert.Equal(suite.T(), []int{3, 4}, []int{note.StartWordIndex, note.EndWordIndex})
+
+	// Speaker reassignment by word range
+	speakerData := map[string]interface{}{"speaker": "SPEAKER_02", "start_word_index": 5, "end_word_index": 6}
+	w = suite.makeAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/transcription/%s/speaker", testJob.ID), speakerData, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	w = suite.makeAuthenticatedRequest("PUT", fmt.Sprintf("/api/v1/transcription/%s/speaker", testJob.ID), map[string]interface{}{"speaker": "SPEAKER_02"}, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	// Invalid edits are rejected without recording a revision
+	w = suite.makeAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/transcription/%s/segments/9/merge", testJob.ID), nil, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/transcription/%s/revisions", testJob.ID), nil, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	var revisions []models.TranscriptRevision
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &revisions))
+	if assert.Len(suite.T(), revisions, 3) {
+		assert.Equal(suite.T(), "transcribed", revisions[0].Operation)
+		assert.Empty(suite.T(), revisions[0].Transcript)
+		assert.NotEmpty(suite.T(), revisions[1].Diff)
+	}
+
+	// The model output can be viewed and restored
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/transcription/%s/revisions/1", testJob.ID), nil, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	var original models.TranscriptRevision
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &original))
+	assert.JSONEq(suite.T(), transcriptJSON, original.Transcript)
+
+	w = suite.makeAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/transcription/%s/revisions/1/restore", testJob.ID), nil, false)
+	assert.Equal(suite.T(), 200, w.Code)
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &editResponse))
+	assert.Equal(suite.T(), 4, editResponse.Revision.Revision)
+	assert.Equal(suite.T(), editableTranscript().Segments, editResponse.Transcript.Segments)
+
+	w = suite.makeAuthenticatedRequest("GET", fmt.Sprintf("/api/v1/notes/%s", note.ID), nil, false)
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &note))
+	assert.Equal(suite.T(), []int{2, 3}, []int{note.StartWordIndex, note.EndWordIndex})
+
+	w = suite.makeAuthenticatedRequest("POST", fmt.Sprintf("/api/v1/transcription/%s/revisions/42/restore", testJob.ID), nil, false)
+	assert.Equal(suite.T(), 404, w.Code)
+}
+
+// Test queuing a diarization-only run
+func (suite *APIHandlerTestSuite) TestRediarizeJob() {
+	testJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job for Rediarization")
+	path := fmt.Sprintf("/api/v1/transcription/%s/rediarize", testJob.ID)
+
+	// Only finished jobs with a transcript can be diarized again
+	w := suite.makeAuthenticatedRequest("POST", path, nil, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	transcript, _ := json.Marshal(editableTranscript())
+	transcriptJSON := string(transcript)
+	testJob.Status = models.StatusCompleted
+	testJob.Transcript = &transcriptJSON
+	assert.NoError(suite.T(), suite.helper.DB.Save(testJob).Error)
+
+	w = suite.makeAuthenticatedRequest("POST", path, map[string]interface{}{"diarize_model": "pyannote"}, false)
+	assert.Equal(suite.T(), 400, w.Code)
+	w = suite.makeAuthenticatedRequest("POST", path, map[string]interface{}{"diarize_model": "nvidia_sortformer", "min_speakers": 3, "max_speakers": 2}, false)
+	assert.Equal(suite.T(), 400, w.Code)
+	w = suite.makeAuthenticatedRequest("POST", path, map[string]interface{}{"diarize_model": "unknown"}, false)
+	assert.Equal(suite.T(), 400, w.Code)
+
+	w = suite.makeAuthenticatedRequest("POST", path, map[string]interface{}{"diarize_model": "nvidia_sortformer", "max_speakers": 3}, false)
+	assert.Equal(suite.T(), 200, w.Code)
+
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", testJob.ID).First(&job).Error)
+	assert.Equal(suite.T(), models.JobTypeRediarize, job.JobType)
+	assert.Equal(suite.T(), models.StatusPending, job.Status)
+	if assert.NotNil(suite.T(), job.PreviousStatus) {
+		assert.Equal(suite.T(), models.StatusCompleted, *job.PreviousStatus)
+	}
+	assert.True(suite.T(), job.Parameters.Diarize)
+	assert.Equal(suite.T(), "nvidia_sortformer", job.Parameters.DiarizeModel)
+	assert.Equal(suite.T(), 3, *job.Parameters.MaxSpeakers)
+	if assert.NotNil(suite.T(), job.Transcript) {
+		assert.JSONEq(suite.T(), transcriptJSON, *job.Transcript)
+	}
+
+	// A queued job can't be queued again
+	w = suite.makeAuthenticatedRequest("POST", path, nil, false)
+	assert.Equal(suite.T(), 400, w.Code)
+}
+
+// Test a failed diarization re-run keeps the transcript and reports the failure to the queue
+func (suite *APIHandlerTestSuite) TestFailedRediarizeKeepsTranscript() {
+	testJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job with failing Rediarization")
+	transcript, _ := json.Marshal(editableTranscript())
+	transcriptJSON := string(transcript)
+	testJob.Status = models.StatusCompleted
+	testJob.Transcript = &transcriptJSON
+	testJob.JobType = models.JobTypeRediarize
+	testJob.AudioPath = filepath.Join(suite.helper.Config.UploadDir, "missing-audio.wav")
+	testJob.Parameters.Diarize = true
+	testJob.Parameters.DiarizeModel = "nvidia_sortformer"
+	assert.NoError(suite.T(), suite.helper.DB.Save(testJob).Error)
+
+	err := suite.unifiedProcessor.ProcessJobWithProcess(context.Background(), testJob.ID, nil)
+	assert.ErrorIs(suite.T(), err, queue.ErrRediarizeFailed)
+
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", testJob.ID).First(&job).Error)
+	if assert.NotNil(suite.T(), job.Transcript) {
+		assert.JSONEq(suite.T(), transcriptJSON, *job.Transcript)
+	}
+
+	var execution models.TranscriptionJobExecution
+	assert.NoError(suite.T(), suite.helper.DB.Where("transcription_job_id = ?", testJob.ID).First(&execution).Error)
+	assert.Equal(suite.T(), models.StatusFailed, execution.Status)
+	if assert.NotNil(suite.T(), execution.ErrorMessage) {
+		assert.Contains(suite.T(), *execution.ErrorMessage, "rediarization failed")
+	}
+}
+
 // Test queue stats
 func (suite *APIHandlerTestSuite) TestGetQueueStats() {
 	w := suite.makeAuthenticatedRequest("GET", "/api/v1/admin/queue/stats", nil, false)
@@ -464,6 +745,7 @@ func (suite *APIHandlerTestSuite) TestTranscriptionSubmit() {
 	writer.WriteField("title", "API Handler Test Audio")
 	writer.WriteField("model", "base")
 	writer.WriteField("diarization", "false")
+	writer.WriteField("priority", "urgent")
 
 	writer.Close()
 
@@ -482,6 +764,7 @@ func (suite *APIHandlerTestSuite) TestTranscriptionSubmit() {
 	assert.NotEmpty(suite.T(), response.ID)
 	assert.Equal(suite.T(), "API Handler Test Audio", *response.Title)
 	assert.Equal(suite.T(), models.StatusPending, response.Status)
+	assert.Equal(suite.T(), models.PriorityUrgent, response.Priority)
 }
 
 // Test error responses for non-existent resources
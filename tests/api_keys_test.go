@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"synthezia/internal/api"
+	"synthezia/internal/auth"
+	"synthezia/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type APIKeysTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *APIKeysTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "api_keys_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *APIKeysTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *APIKeysTestSuite) SetupTest() {
+	// Keep the helper's own key
+	suite.helper.DB.Where("name NOT LIKE ?", "Test API Key for %").Delete(&models.APIKey{})
+}
+
+// withJWT performs a request authenticated with a JWT
+func (suite *APIKeysTestSuite) withJWT(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// withKey performs a request authenticated with an API key
+func (suite *APIKeysTestSuite) withKey(key, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *APIKeysTestSuite) createKey(body map[string]interface{}) api.CreateAPIKeyResponse {
+	w := suite.withJWT(suite.helper.TestToken, "POST", "/api/v1/api-keys/", body)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response api.CreateAPIKeyResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func (suite *APIKeysTestSuite) listKeys() []api.APIKeyListResponse {
+	w := suite.withJWT(suite.helper.TestToken, "GET", "/api/v1/api-keys/", nil)
+	var response api.APIKeysWrapper
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response.APIKeys
+}
+
+func (suite *APIKeysTestSuite) TestKeysAreStoredHashed() {
+	created := suite.createKey(map[string]interface{}{"name": "Hashed"})
+	assert.Len(suite.T(), created.Key, 32)
+	assert.Equal(suite.T(), []string{models.ScopeTranscriptsRead, models.ScopeTranscribeWrite, models.ScopeChat}, created.Scopes)
+
+	var stored models.APIKey
+	assert.NoError(suite.T(), suite.helper.DB.First(&stored, created.ID).Error)
+	assert.True(suite.T(), stored.Hashed)
+	assert.Equal(suite.T(), auth.HashAPIKey(created.Key), stored.Key)
+	assert.Equal(suite.T(), created.Key[:8], stored.KeyPrefix)
+
+	for _, key := range suite.listKeys() {
+		if key.ID == created.ID {
+			assert.Equal(suite.T(), created.Key[:8]+"...", key.KeyPreview)
+		}
+	}
+}
+
+func (suite *APIKeysTestSuite) TestPlaintextKeysAreRejected() {
+	legacy := models.APIKey{UserID: suite.helper.TestUser.ID, Key: "legacy-plaintext-key", Name: "Legacy", IsActive: true}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&legacy).Error)
+
+	w := suite.withKey("legacy-plaintext-key", "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+func (suite *APIKeysTestSuite) TestKeysWithoutScopesGetDefaultScopes() {
+	unscoped := models.APIKey{
+		UserID: suite.helper.TestUser.ID, Key: auth.HashAPIKey("unscoped-key"), Hashed: true, Name: "Unscoped", IsActive: true,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&unscoped).Error)
+
+	w := suite.withKey("unscoped-key", "POST", "/api/v1/tags/", map[string]string{"name": "Unscoped"})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	w = suite.withKey("unscoped-key", "GET", "/api/v1/llm/config", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+}
+
+func (suite *APIKeysTestSuite) TestScopesAreEnforced() {
+	reader := suite.createKey(map[string]interface{}{"name": "Reader", "scopes": []string{"transcripts:read"}})
+
+	w := suite.withKey(reader.Key, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.withKey(reader.Key, "POST", "/api/v1/tags/", map[string]string{"name": "Nope"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "transcribe:write")
+	w = suite.withKey(reader.Key, "GET", "/api/v1/chat/models", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.withKey(reader.Key, "GET", "/api/v1/llm/config", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// Keys get every scope but admin by default
+	standard := suite.createKey(map[string]interface{}{"name": "Standard"})
+	w = suite.withKey(standard.Key, "POST", "/api/v1/tags/", map[string]string{"name": "Allowed"})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	w = suite.withKey(standard.Key, "GET", "/api/v1/webhooks/", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// JWT sessions aren't limited by scopes
+	w = suite.withJWT(suite.helper.TestToken, "GET", "/api/v1/webhooks/", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *APIKeysTestSuite) TestCreateValidation() {
+	w := suite.withJWT(suite.helper.TestToken, "POST", "/api/v1/api-keys/", map[string]interface{}{"name": "Bad", "scopes": []string{"everything"}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.withJWT(suite.helper.TestToken, "POST", "/api/v1/api-keys/", map[string]interface{}{
+		"name": "Past", "expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339),
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	hashedPassword, _ := auth.HashPassword("password123")
+	editor := &models.User{Username: "key-editor", Password: hashedPassword, Role: models.RoleEditor}
+	assert.NoError(suite.T(), suite.helper.DB.Create(editor).Error)
+	editorToken, _ := suite.helper.AuthService.GenerateToken(editor)
+	w = suite.withJWT(editorToken, "POST", "/api/v1/api-keys/", map[string]interface{}{"name": "Admin", "scopes": []string{"admin"}})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	suite.helper.DB.Delete(editor)
+
+	created := suite.createKey(map[string]interface{}{"name": "Admin", "scopes": []string{"admin", "admin"}})
+	assert.Equal(suite.T(), []string{models.ScopeAdmin}, created.Scopes)
+}
+
+func (suite *APIKeysTestSuite) TestExpiryAndUsage() {
+	created := suite.createKey(map[string]interface{}{
+		"name": "Short-lived", "expires_at": time.Now().Add(time.Hour).Format(time.RFC3339),
+	})
+
+	for i := 0; i < 3; i++ {
+		w := suite.withKey(created.Key, "GET", "/api/v1/transcription/list", nil)
+		assert.Equal(suite.T(), http.StatusOK, w.Code)
+	}
+
+	var listed *api.APIKeyListResponse
+	for _, key := range suite.listKeys() {
+		if key.ID == created.ID {
+			key := key
+			listed = &key
+		}
+	}
+	if assert.NotNil(suite.T(), listed) {
+		assert.Equal(suite.T(), int64(3), listed.UsageCount)
+		assert.NotEmpty(suite.T(), listed.LastUsed)
+		assert.False(suite.T(), listed.Expired)
+	}
+
+	suite.helper.DB.Model(&models.APIKey{}).Where("id = ?", created.ID).Update("expires_at", time.Now().Add(-time.Minute))
+	w := suite.withKey(created.Key, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+func TestAPIKeysTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeysTestSuite))
+}
@@ -0,0 +1,389 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/backup"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+const backupAudio = "not really audio, but bytes to checksum"
+
+type BackupTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+	dir    string // Holds archives, outside of the instance
+}
+
+func (suite *BackupTestSuite) SetupTest() {
+	suite.dir = suite.T().TempDir()
+	suite.openInstance("backup_test.db")
+}
+
+func (suite *BackupTestSuite) TearDownTest() {
+	suite.helper.Cleanup()
+}
+
+func (suite *BackupTestSuite) openInstance(dbName string) {
+	suite.helper = NewTestHelper(suite.T(), dbName)
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+// workspace is the data seedWorkspace creates
+type workspace struct {
+	job     *models.TranscriptionJob
+	folder  *models.Folder
+	tag     *models.Tag
+	session *models.ChatSession
+}
+
+// seedWorkspace creates a transcript with its audio, organization, notes and a chat
+func (suite *BackupTestSuite) seedWorkspace() workspace {
+	t := suite.T()
+	db := suite.helper.DB
+	userID := suite.helper.TestUser.ID
+
+	folder := &models.Folder{UserID: userID, Name: "Interviews"}
+	assert.NoError(t, db.Create(folder).Error)
+	tag := &models.Tag{UserID: userID, Name: "important"}
+	assert.NoError(t, db.Create(tag).Error)
+
+	audioPath := filepath.Join(suite.helper.Config.UploadDir, "interview.mp3")
+	assert.NoError(t, os.WriteFile(audioPath, []byte(backupAudio), 0644))
+	transcript := `{"segments":[{"start":0,"end":2,"text":"Welcome to the lighthouse interview."}]}`
+	title := "Lighthouse"
+	job := &models.TranscriptionJob{
+		UserID:     userID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  audioPath,
+		Transcript: &transcript,
+		FolderID:   &folder.ID,
+		Tags:       []models.Tag{*tag},
+	}
+	assert.NoError(t, db.Create(job).Error)
+	assert.NoError(t, search.IndexTranscriptJSON(job.ID, transcript))
+
+	assert.NoError(t, db.Create(&models.Note{
+		UserID: userID, TranscriptionID: job.ID, EndWordIndex: 3, EndTime: 2, Quote: "Welcome", Content: "Opening",
+	}).Error)
+	assert.NoError(t, db.Create(&models.SpeakerMapping{
+		TranscriptionJobID: job.ID, OriginalSpeaker: "SPEAKER_00", CustomName: "Keeper",
+	}).Error)
+
+	session := &models.ChatSession{
+		UserID: userID, JobID: job.ID, TranscriptionID: job.ID, Model: "test-model", IsActive: true,
+	}
+	assert.NoError(t, db.Create(session).Error)
+	// A zero value for a column with a default must survive a restore
+	assert.NoError(t, db.Model(session).Update("is_active", false).Error)
+	assert.NoError(t, db.Create(&models.ChatMessage{
+		ChatSessionID: session.ID, Role: "assistant", Content: "It is about a lighthouse [S1].",
+		Citations: []models.Citation{{Label: "S1", JobID: job.ID}},
+	}).Error)
+
+	return workspace{job: job, folder: folder, tag: tag, session: session}
+}
+
+// writeBackup takes a backup of the instance and returns the archive path
+func (suite *BackupTestSuite) writeBackup() (string, *backup.Manifest) {
+	snapshot, err := backup.Prepare(backup.Options{UploadDir: suite.helper.Config.UploadDir})
+	if err != nil {
+		suite.T().Fatal("Failed to prepare backup:", err)
+	}
+	defer snapshot.Close()
+
+	path := filepath.Join(suite.dir, "backup.zip")
+	manifest, err := snapshot.WriteFile(path)
+	if err != nil {
+		suite.T().Fatal("Failed to write backup:", err)
+	}
+	return path, manifest
+}
+
+func (suite *BackupTestSuite) restore(path string) *backup.RestoreResult {
+	result, err := backup.Restore(path, backup.RestoreOptions{UploadDir: suite.helper.Config.UploadDir})
+	if err != nil {
+		suite.T().Fatal("Failed to restore backup:", err)
+	}
+	return result
+}
+
+func (suite *BackupTestSuite) TestArchiveHasManifestWithChecksums() {
+	suite.seedWorkspace()
+	path, manifest := suite.writeBackup()
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		suite.T().Fatal("Failed to open archive:", err)
+	}
+	defer archive.Close()
+
+	names := make(map[string]bool)
+	for _, file := range archive.File {
+		names[file.Name] = true
+	}
+	assert.True(suite.T(), names[backup.ManifestName])
+	assert.True(suite.T(), names[backup.DatabaseName])
+	assert.True(suite.T(), names["uploads/interview.mp3"])
+
+	sum := sha256.Sum256([]byte(backupAudio))
+	assert.Contains(suite.T(), manifest.Files, backup.FileEntry{
+		Path: "uploads/interview.mp3", Size: int64(len(backupAudio)), SHA256: hex.EncodeToString(sum[:]),
+	})
+	assert.Equal(suite.T(), backup.Format, manifest.Format)
+	assert.NotZero(suite.T(), manifest.SchemaVersion)
+	assert.NotEmpty(suite.T(), manifest.SourceDialect)
+
+	verified, err := backup.Verify(path)
+	assert.NoError(suite.T(), err)
+	if assert.NotNil(suite.T(), verified) {
+		assert.Equal(suite.T(), manifest.ID, verified.ID)
+		assert.Len(suite.T(), verified.Files, len(manifest.Files))
+	}
+}
+
+func (suite *BackupTestSuite) TestArchiveHoldsReferencedFilesOnly() {
+	ws := suite.seedWorkspace()
+	uploadDir := suite.helper.Config.UploadDir
+	assert.NoError(suite.T(), os.WriteFile(filepath.Join(uploadDir, "stray.mp3"), []byte("no job"), 0644))
+	gone := filepath.Join(uploadDir, "gone.mp3")
+	assert.NoError(suite.T(), suite.helper.DB.Model(ws.job).Update("merged_audio_path", gone).Error)
+
+	path, manifest := suite.writeBackup()
+
+	var names []string
+	for _, entry := range manifest.Files {
+		names = append(names, entry.Path)
+	}
+	assert.ElementsMatch(suite.T(), []string{backup.DatabaseName, "uploads/interview.mp3"}, names)
+	assert.Equal(suite.T(), []string{gone}, manifest.Missing)
+
+	verified, err := backup.Verify(path)
+	assert.NoError(suite.T(), err)
+	if assert.NotNil(suite.T(), verified) {
+		assert.Equal(suite.T(), []string{gone}, verified.Missing)
+	}
+}
+
+func (suite *BackupTestSuite) TestRestoreIntoNewInstanceKeepsIDs() {
+	seeded := suite.seedWorkspace()
+	second := models.User{Username: "second", Password: "x", Role: models.RoleViewer, FastFinalizeEnabled: false}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&second).Error)
+	assert.NoError(suite.T(), suite.helper.DB.Model(&second).Update("fast_finalize_enabled", false).Error)
+	path, _ := suite.writeBackup()
+
+	// Move to an instance of its own
+	suite.helper.Cleanup()
+	suite.openInstance("backup_restore_test.db")
+	result := suite.restore(path)
+
+	assert.Equal(suite.T(), []string{"testuser"}, result.MergedUsers)
+	assert.Equal(suite.T(), 1, result.Records["users"])
+	assert.Equal(suite.T(), 1, result.Records["transcription_jobs"])
+	assert.Equal(suite.T(), 0, result.RenamedIDs)
+	assert.Equal(suite.T(), suite.helper.Config.UploadDir, result.UploadRoot)
+	assert.Equal(suite.T(), 1, result.Files)
+
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Preload("Tags").First(&job, "id = ?", seeded.job.ID).Error)
+	assert.Equal(suite.T(), suite.helper.TestUser.ID, job.UserID)
+	assert.Equal(suite.T(), filepath.Join(suite.helper.Config.UploadDir, "interview.mp3"), job.AudioPath)
+	data, err := os.ReadFile(job.AudioPath)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), backupAudio, string(data))
+	if assert.NotNil(suite.T(), job.FolderID) {
+		assert.Equal(suite.T(), seeded.folder.ID, *job.FolderID)
+	}
+	if assert.Len(suite.T(), job.Tags, 1) {
+		assert.Equal(suite.T(), seeded.tag.ID, job.Tags[0].ID)
+	}
+
+	// Zero values are kept where columns have defaults
+	var session models.ChatSession
+	assert.NoError(suite.T(), suite.helper.DB.First(&session, "id = ?", seeded.session.ID).Error)
+	assert.False(suite.T(), session.IsActive)
+	var user models.User
+	assert.NoError(suite.T(), suite.helper.DB.First(&user, "username = ?", "second").Error)
+	assert.Equal(suite.T(), models.RoleViewer, user.Role)
+	assert.False(suite.T(), user.FastFinalizeEnabled)
+
+	// The search index is rebuilt
+	_, total, err := search.Search("lighthouse", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+}
+
+func (suite *BackupTestSuite) TestRestoreMergesWithoutCollisions() {
+	seeded := suite.seedWorkspace()
+	path, manifest := suite.writeBackup()
+	result := suite.restore(path)
+
+	assert.Equal(suite.T(), []string{"testuser"}, result.MergedUsers)
+	assert.Equal(suite.T(), 0, result.Records["users"])
+	assert.Equal(suite.T(), 0, result.Records["tags"])
+	assert.Equal(suite.T(), 1, result.Records["transcription_jobs"])
+	assert.Equal(suite.T(), 1, result.Records["notes"])
+	assert.Equal(suite.T(), 1, result.Records["chat_messages"])
+	assert.Positive(suite.T(), result.RenamedIDs)
+
+	var jobs []models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Preload("Tags").Find(&jobs).Error)
+	if !assert.Len(suite.T(), jobs, 2) {
+		return
+	}
+	restored := jobs[0]
+	if restored.ID == seeded.job.ID {
+		restored = jobs[1]
+	}
+	assert.NotEqual(suite.T(), seeded.job.ID, restored.ID)
+
+	// Files go below a folder of their own instead of replacing the existing ones
+	root := filepath.Join(suite.helper.Config.UploadDir, "restored", manifest.ID)
+	assert.Equal(suite.T(), root, result.UploadRoot)
+	assert.Equal(suite.T(), filepath.Join(root, "interview.mp3"), restored.AudioPath)
+	_, err := os.Stat(restored.AudioPath)
+	assert.NoError(suite.T(), err)
+
+	// References follow the new IDs; the tag merges into the existing one
+	if assert.NotNil(suite.T(), restored.FolderID) {
+		assert.NotEqual(suite.T(), seeded.folder.ID, *restored.FolderID)
+	}
+	if assert.Len(suite.T(), restored.Tags, 1) {
+		assert.Equal(suite.T(), seeded.tag.ID, restored.Tags[0].ID)
+	}
+	var note models.Note
+	assert.NoError(suite.T(), suite.helper.DB.First(&note, "transcription_id = ?", restored.ID).Error)
+	var mappings int64
+	suite.helper.DB.Model(&models.SpeakerMapping{}).Where("transcription_job_id = ?", restored.ID).Count(&mappings)
+	assert.Equal(suite.T(), int64(1), mappings)
+
+	var session models.ChatSession
+	assert.NoError(suite.T(), suite.helper.DB.First(&session, "transcription_id = ?", restored.ID).Error)
+	assert.NotEqual(suite.T(), seeded.session.ID, session.ID)
+	var message models.ChatMessage
+	assert.NoError(suite.T(), suite.helper.DB.First(&message, "chat_session_id = ?", session.ID).Error)
+	assert.Equal(suite.T(), session.ID, message.SessionID)
+	if assert.Len(suite.T(), message.Citations, 1) {
+		assert.Equal(suite.T(), restored.ID, message.Citations[0].JobID)
+	}
+
+	// Restoring again uses yet another folder
+	again := suite.restore(path)
+	assert.NotEqual(suite.T(), result.UploadRoot, again.UploadRoot)
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Count(&count)
+	assert.Equal(suite.T(), int64(3), count)
+}
+
+func (suite *BackupTestSuite) TestRestoreRejectsTamperedArchive() {
+	suite.seedWorkspace()
+	path, _ := suite.writeBackup()
+
+	// Copy the archive, replacing the audio but keeping the manifest
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		suite.T().Fatal("Failed to open archive:", err)
+	}
+	tampered := filepath.Join(suite.dir, "tampered.zip")
+	out, err := os.Create(tampered)
+	if err != nil {
+		suite.T().Fatal("Failed to create archive:", err)
+	}
+	writer := zip.NewWriter(out)
+	for _, file := range archive.File {
+		w, err := writer.Create(file.Name)
+		assert.NoError(suite.T(), err)
+		if file.Name == "uploads/interview.mp3" {
+			w.Write([]byte("something else entirely"))
+			continue
+		}
+		r, err := file.Open()
+		assert.NoError(suite.T(), err)
+		io.Copy(w, r)
+		r.Close()
+	}
+	assert.NoError(suite.T(), writer.Close())
+	out.Close()
+	archive.Close()
+
+	_, err = backup.Restore(tampered, backup.RestoreOptions{UploadDir: suite.helper.Config.UploadDir})
+	if assert.ErrorIs(suite.T(), err, backup.ErrInvalidArchive) {
+		assert.Contains(suite.T(), err.Error(), "checksum mismatch")
+	}
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+	_, err = os.Stat(filepath.Join(suite.helper.Config.UploadDir, "restored"))
+	assert.True(suite.T(), os.IsNotExist(err))
+}
+
+func (suite *BackupTestSuite) TestAdminEndpoints() {
+	suite.seedWorkspace()
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/backup", nil)
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	if !assert.Equal(suite.T(), http.StatusOK, w.Code) {
+		return
+	}
+	assert.Equal(suite.T(), "application/zip", w.Header().Get("Content-Type"))
+	assert.Contains(suite.T(), w.Header().Get("Content-Disposition"), "synthezia-backup-")
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	part, _ := form.CreateFormFile("archive", "backup.zip")
+	part.Write(w.Body.Bytes())
+	form.Close()
+
+	req, _ = http.NewRequest("POST", "/api/v1/admin/restore", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var result backup.RestoreResult
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(suite.T(), 1, result.Records["transcription_jobs"])
+	assert.Equal(suite.T(), 1, result.Files)
+
+	// Anything but a backup is refused
+	body = &bytes.Buffer{}
+	form = multipart.NewWriter(body)
+	part, _ = form.CreateFormFile("archive", "backup.zip")
+	part.Write([]byte("not a zip"))
+	form.Close()
+
+	req, _ = http.NewRequest("POST", "/api/v1/admin/restore", body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w = httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestBackupTestSuite(t *testing.T) {
+	suite.Run(t, new(BackupTestSuite))
+}
@@ -0,0 +1,258 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+	"synthezia/internal/queue"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type BulkOperationsTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *BulkOperationsTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "bulk_test.db")
+	// The queue is never started, so queued jobs just stay pending
+	taskQueue := queue.NewTaskQueue(1, nil)
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, taskQueue, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *BulkOperationsTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *BulkOperationsTestSuite) SetupTest() {
+	suite.helper.DB.Exec("DELETE FROM transcription_job_tags")
+	suite.helper.DB.Where("1 = 1").Delete(&models.Tag{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionProfile{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+func (suite *BulkOperationsTestSuite) createJob(title string, status models.JobStatus) *models.TranscriptionJob {
+	data, _ := json.Marshal(editableTranscript())
+	transcript := string(data)
+	job := &models.TranscriptionJob{
+		UserID:    suite.helper.TestUser.ID,
+		Title:     &title,
+		Status:    status,
+		AudioPath: "test/path/missing.mp3",
+	}
+	if status == models.StatusCompleted {
+		job.Transcript = &transcript
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	return job
+}
+
+func (suite *BulkOperationsTestSuite) request(path string, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req, _ := http.NewRequest("POST", path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *BulkOperationsTestSuite) decode(w *httptest.ResponseRecorder) api.BulkResponse {
+	var response api.BulkResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func (suite *BulkOperationsTestSuite) TestSelectionValidation() {
+	job := suite.createJob("Meeting", models.StatusCompleted)
+
+	w := suite.request("/api/v1/transcription/bulk/delete", map[string]interface{}{})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request("/api/v1/transcription/bulk/delete", map[string]interface{}{
+		"ids":    []string{job.ID},
+		"filter": map[string]string{"status": "completed"},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *BulkOperationsTestSuite) TestBulkDelete() {
+	done := suite.createJob("Done", models.StatusCompleted)
+	running := suite.createJob("Running", models.StatusProcessing)
+
+	w := suite.request("/api/v1/transcription/bulk/delete", map[string]interface{}{
+		"ids": []string{"missing-id", done.ID, running.ID, done.ID},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	response := suite.decode(w)
+	assert.Len(suite.T(), response.Results, 3)
+	assert.Equal(suite.T(), 1, response.Succeeded)
+	assert.Equal(suite.T(), 1, response.Skipped)
+	assert.Equal(suite.T(), 1, response.Failed)
+	assert.Equal(suite.T(), api.BulkItemResult{ID: "missing-id", Status: api.BulkStatusError, Error: "Job not found"}, response.Results[0])
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", done.ID).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", running.ID).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+}
+
+func (suite *BulkOperationsTestSuite) TestBulkDeleteByFilter() {
+	suite.createJob("Weekly sync", models.StatusCompleted)
+	suite.createJob("Weekly review", models.StatusFailed)
+	keep := suite.createJob("Interview", models.StatusCompleted)
+
+	w := suite.request("/api/v1/transcription/bulk/delete", map[string]interface{}{
+		"filter": map[string]string{"q": "weekly"},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), 2, suite.decode(w).Succeeded)
+
+	var jobs []models.TranscriptionJob
+	suite.helper.DB.Find(&jobs)
+	assert.Len(suite.T(), jobs, 1)
+	assert.Equal(suite.T(), keep.ID, jobs[0].ID)
+}
+
+func (suite *BulkOperationsTestSuite) TestBulkRetranscribeWithProfile() {
+	done := suite.createJob("Done", models.StatusCompleted)
+	pending := suite.createJob("Pending", models.StatusPending)
+
+	profile := &models.TranscriptionProfile{
+		UserID:     suite.helper.TestUser.ID,
+		Name:       "Large",
+		Parameters: models.WhisperXParams{Model: "large-v3", ModelFamily: "whisper"},
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(profile).Error)
+
+	w := suite.request("/api/v1/transcription/bulk/retranscribe", map[string]interface{}{
+		"profile_id": "missing",
+		"ids":        []string{done.ID},
+	})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.request("/api/v1/transcription/bulk/retranscribe", map[string]interface{}{
+		"profile_id": profile.ID,
+		"priority":   "high",
+		"ids":        []string{done.ID, pending.ID},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	response := suite.decode(w)
+	assert.Equal(suite.T(), 1, response.Succeeded)
+	assert.Equal(suite.T(), 1, response.Skipped)
+
+	var updated models.TranscriptionJob
+	suite.helper.DB.First(&updated, "id = ?", done.ID)
+	assert.Equal(suite.T(), models.StatusPending, updated.Status)
+	assert.Equal(suite.T(), "large-v3", updated.Parameters.Model)
+	assert.Nil(suite.T(), updated.Transcript)
+	assert.Greater(suite.T(), updated.Priority, 0)
+}
+
+func (suite *BulkOperationsTestSuite) TestBulkTags() {
+	first := suite.createJob("First", models.StatusCompleted)
+	second := suite.createJob("Second", models.StatusCompleted)
+
+	w := suite.request("/api/v1/transcription/bulk/tags", map[string]interface{}{
+		"ids": []string{first.ID, second.ID},
+		"add": []string{"Clients", " clients ", "Q3"},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), 2, suite.decode(w).Succeeded)
+
+	var tagCount int64
+	suite.helper.DB.Model(&models.Tag{}).Count(&tagCount)
+	assert.Equal(suite.T(), int64(2), tagCount)
+
+	w = suite.request("/api/v1/transcription/bulk/tags", map[string]interface{}{
+		"ids":    []string{second.ID},
+		"add":    []string{"clients"},
+		"remove": []string{"q3", "unknown"},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var job models.TranscriptionJob
+	suite.helper.DB.Preload("Tags").First(&job, "id = ?", second.ID)
+	assert.Len(suite.T(), job.Tags, 1)
+	assert.Equal(suite.T(), "Clients", job.Tags[0].Name)
+
+	var other models.TranscriptionJob
+	suite.helper.DB.Preload("Tags").First(&other, "id = ?", first.ID)
+	assert.Len(suite.T(), other.Tags, 2)
+
+	w = suite.request("/api/v1/transcription/bulk/tags", map[string]interface{}{"ids": []string{first.ID}})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *BulkOperationsTestSuite) TestBulkExport() {
+	audioPath := filepath.Join(suite.T().TempDir(), "meeting.mp3")
+	assert.NoError(suite.T(), os.WriteFile(audioPath, []byte("audio"), 0644))
+
+	first := suite.createJob("Meeting", models.StatusCompleted)
+	first.AudioPath = audioPath
+	suite.helper.DB.Save(first)
+	second := suite.createJob("Meeting", models.StatusCompleted)
+	failed := suite.createJob("Broken", models.StatusFailed)
+
+	w := suite.request("/api/v1/transcription/bulk/export", map[string]interface{}{
+		"ids":     []string{first.ID},
+		"formats": []string{"pdf"},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request("/api/v1/transcription/bulk/export", map[string]interface{}{
+		"ids":           []string{first.ID, second.ID, failed.ID},
+		"formats":       []string{"srt", "txt"},
+		"include_audio": true,
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "application/zip", w.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(suite.T(), err)
+	files := make(map[string]*zip.File)
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	assert.Contains(suite.T(), files, "Meeting/meeting.mp3")
+	assert.Contains(suite.T(), files, "Meeting/Meeting.srt")
+	assert.Contains(suite.T(), files, "Meeting/Meeting.txt")
+	assert.Contains(suite.T(), files, "Meeting-"+second.ID+"/Meeting.srt")
+	assert.Contains(suite.T(), files, "results.json")
+
+	reader, err := files["results.json"].Open()
+	assert.NoError(suite.T(), err)
+	data, _ := io.ReadAll(reader)
+	reader.Close()
+
+	var response api.BulkResponse
+	assert.NoError(suite.T(), json.Unmarshal(data, &response))
+	assert.Len(suite.T(), response.Results, 3)
+	assert.Equal(suite.T(), api.BulkStatusOK, response.Results[0].Status)
+	// The second job's audio file doesn't exist and the failed job has no transcript
+	assert.Equal(suite.T(), api.BulkStatusError, response.Results[1].Status)
+	assert.Equal(suite.T(), api.BulkStatusError, response.Results[2].Status)
+	assert.Equal(suite.T(), 1, response.Succeeded)
+	assert.Equal(suite.T(), 2, response.Failed)
+}
+
+func TestBulkOperationsTestSuite(t *testing.T) {
+	suite.Run(t, new(BulkOperationsTestSuite))
+}
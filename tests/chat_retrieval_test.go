@@ -0,0 +1,286 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+	"synthezia/internal/retrieval"
+	"synthezia/internal/search"
+	"synthezia/internal/transcription/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// keywordEmbedder embeds texts as counts of a few keywords
+type keywordEmbedder struct {
+	keywords []string
+	calls    int
+}
+
+func (e *keywordEmbedder) Embed(ctx context.Context, model string, inputs []string) ([][]float64, error) {
+	e.calls++
+	vectors := make([][]float64, len(inputs))
+	for i, input := range inputs {
+		vector := make([]float64, len(e.keywords))
+		for k, keyword := range e.keywords {
+			vector[k] = float64(strings.Count(strings.ToLower(input), keyword))
+		}
+		vectors[i] = vector
+	}
+	return vectors, nil
+}
+
+type ChatRetrievalTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *ChatRetrievalTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "chat_retrieval_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *ChatRetrievalTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *ChatRetrievalTestSuite) SetupTest() {
+	suite.helper.DB.Exec("DELETE FROM " + search.TableName)
+	suite.helper.DB.Exec("DELETE FROM chat_session_transcriptions")
+	suite.helper.DB.Where("1 = 1").Delete(&models.ChatMessage{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.ChatSession{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptChunkEmbedding{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.LLMConfig{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+// createIndexedJob stores a completed job and indexes its segments
+func (suite *ChatRetrievalTestSuite) createIndexedJob(title string, segments ...interfaces.TranscriptSegment) *models.TranscriptionJob {
+	result := &interfaces.TranscriptResult{Segments: segments, Language: "en"}
+	data, _ := json.Marshal(result)
+	transcript := string(data)
+
+	job := &models.TranscriptionJob{
+		UserID:     suite.helper.TestUser.ID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  "test/path/audio.mp3",
+		Transcript: &transcript,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	assert.NoError(suite.T(), search.IndexTranscript(job.ID, result))
+	return job
+}
+
+func (suite *ChatRetrievalTestSuite) source(job *models.TranscriptionJob) retrieval.Source {
+	return retrieval.Source{JobID: job.ID, Title: *job.Title, CreatedAt: job.CreatedAt, Transcript: *job.Transcript}
+}
+
+func (suite *ChatRetrievalTestSuite) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *ChatRetrievalTestSuite) TestChunkTranscript() {
+	speaker := stringPtr("SPEAKER_00")
+	long := strings.Repeat("word ", 200)
+	job := suite.createIndexedJob("Long",
+		interfaces.TranscriptSegment{Start: 0, End: 5, Text: " Welcome everyone.", Speaker: speaker},
+		interfaces.TranscriptSegment{Start: 5, End: 10, Text: "   "},
+		interfaces.TranscriptSegment{Start: 10, End: 20, Text: long},
+		interfaces.TranscriptSegment{Start: 20, End: 30, Text: long},
+		interfaces.TranscriptSegment{Start: 200, End: 210, Text: " Much later."},
+	)
+
+	source := suite.source(job)
+	source.SpeakerNames = map[string]string{"SPEAKER_00": "Alice"}
+	chunks, err := retrieval.ChunkTranscript(source)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), chunks, 3)
+
+	assert.Equal(suite.T(), 0, chunks[0].SegmentStart)
+	assert.Equal(suite.T(), 2, chunks[0].SegmentEnd)
+	assert.True(suite.T(), strings.HasPrefix(chunks[0].Text, "Alice: Welcome everyone.\nword"))
+	// The second long segment doesn't fit, and the last one is too far into the audio
+	assert.Equal(suite.T(), 3, chunks[1].SegmentStart)
+	assert.Equal(suite.T(), 4, chunks[2].SegmentStart)
+	assert.Equal(suite.T(), 200.0, chunks[2].Start)
+	assert.Equal(suite.T(), 2, chunks[2].Index)
+
+	// Transcripts without segments are chunked from their text
+	chunks, err = retrieval.ChunkTranscript(retrieval.Source{JobID: "plain", Transcript: `{"text": "Just text."}`})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), chunks, 1)
+	assert.Equal(suite.T(), "Just text.", chunks[0].Text)
+}
+
+func (suite *ChatRetrievalTestSuite) TestRetrieveModes() {
+	budget := suite.createIndexedJob("Budget review",
+		interfaces.TranscriptSegment{Start: 0, End: 60, Text: " Let's start with the agenda."},
+		interfaces.TranscriptSegment{Start: 100, End: 160, Text: " The marketing budget grows next quarter."},
+	)
+	weather := suite.createIndexedJob("Weather chat",
+		interfaces.TranscriptSegment{Start: 0, End: 10, Text: " It rained all weekend."},
+	)
+	sources := []retrieval.Source{suite.source(budget), suite.source(weather)}
+
+	// Short transcripts are sent whole in auto mode
+	passages, mode, err := retrieval.Retrieve(context.Background(), sources, "What about the budget?", retrieval.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), retrieval.ModeFull, mode)
+	assert.Len(suite.T(), passages, 3)
+	assert.Equal(suite.T(), "S1", passages[0].Label)
+	assert.Equal(suite.T(), weather.ID, passages[2].JobID)
+
+	passages, mode, err = retrieval.Retrieve(context.Background(), sources, "What about the budget?", retrieval.Options{Mode: retrieval.ModeBM25})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), retrieval.ModeBM25, mode)
+	assert.Len(suite.T(), passages, 1)
+	assert.Equal(suite.T(), budget.ID, passages[0].JobID)
+	assert.Equal(suite.T(), 1, passages[0].SegmentStart)
+	assert.Equal(suite.T(), "Budget review", passages[0].Title)
+
+	// Without any match the transcripts are used from their start
+	passages, _, err = retrieval.Retrieve(context.Background(), sources, "penguins", retrieval.Options{Mode: retrieval.ModeBM25})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), passages, 3)
+	assert.Equal(suite.T(), weather.ID, passages[2].JobID)
+
+	// Embeddings fall back to BM25 without an embedder
+	_, mode, err = retrieval.Retrieve(context.Background(), sources, "budget", retrieval.Options{Mode: retrieval.ModeEmbeddings, EmbeddingModel: "embed"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), retrieval.ModeBM25, mode)
+
+	_, _, err = retrieval.Retrieve(context.Background(), sources, "budget", retrieval.Options{Mode: "magic"})
+	assert.Error(suite.T(), err)
+}
+
+func (suite *ChatRetrievalTestSuite) TestRetrieveEmbeddings() {
+	budget := suite.createIndexedJob("Budget review",
+		interfaces.TranscriptSegment{Start: 0, End: 60, Text: " Let's start with the agenda."},
+		interfaces.TranscriptSegment{Start: 100, End: 160, Text: " The budget grows, the budget is fine."},
+	)
+	weather := suite.createIndexedJob("Weather chat",
+		interfaces.TranscriptSegment{Start: 0, End: 10, Text: " It rained all weekend, bad weather."},
+	)
+	sources := []retrieval.Source{suite.source(budget), suite.source(weather)}
+	embedder := &keywordEmbedder{keywords: []string{"weather", "budget"}}
+	opts := retrieval.Options{Mode: retrieval.ModeEmbeddings, EmbeddingModel: "embed", Embedder: embedder}
+
+	passages, mode, err := retrieval.Retrieve(context.Background(), sources, "How was the weather?", opts)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), retrieval.ModeEmbeddings, mode)
+	assert.NotEmpty(suite.T(), passages)
+	assert.Equal(suite.T(), 3, embedder.calls)
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptChunkEmbedding{}).Count(&count)
+	assert.Equal(suite.T(), int64(3), count)
+
+	// Stored chunk embeddings are reused, only the question is embedded again
+	_, _, err = retrieval.Retrieve(context.Background(), sources, "And the budget?", opts)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 4, embedder.calls)
+}
+
+func (suite *ChatRetrievalTestSuite) TestCitations() {
+	passages := []retrieval.Passage{
+		{Chunk: retrieval.Chunk{JobID: "job-1", Start: 61, End: 3725}, Label: "S1", Title: "First"},
+		{Chunk: retrieval.Chunk{JobID: "job-2", SegmentStart: 4, SegmentEnd: 6}, Label: "S2", Title: "Second"},
+	}
+
+	citations := retrieval.Citations("Sales grew [S2]. Costs fell [S1, S2][S9]. Again [S2].", passages)
+	assert.Len(suite.T(), citations, 2)
+	assert.Equal(suite.T(), "S2", citations[0].Label)
+	assert.Equal(suite.T(), "job-2", citations[0].JobID)
+	assert.Equal(suite.T(), 4, citations[0].SegmentStart)
+	assert.Equal(suite.T(), "S1", citations[1].Label)
+	assert.Empty(suite.T(), retrieval.Citations("No sources here.", passages))
+
+	prompt := retrieval.SystemPrompt(nil, passages)
+	assert.Contains(suite.T(), prompt, `[S1] "First" (job job-1), 00:01:01-01:02:05`)
+}
+
+func (suite *ChatRetrievalTestSuite) TestBuildKeywordQuery() {
+	assert.Equal(suite.T(), `"budget" OR "q3"`, search.BuildKeywordQuery("What is the budget for Q3?"))
+	assert.Equal(suite.T(), "", search.BuildKeywordQuery("what is it?"))
+}
+
+func (suite *ChatRetrievalTestSuite) TestMultiTranscriptSession() {
+	apiKey := "test-key"
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{Provider: "openai", APIKey: &apiKey, IsActive: true}).Error)
+
+	first := suite.createIndexedJob("Monday", interfaces.TranscriptSegment{Start: 0, End: 5, Text: " Hello."})
+	second := suite.createIndexedJob("Tuesday", interfaces.TranscriptSegment{Start: 0, End: 5, Text: " Bye."})
+
+	w := suite.request("POST", "/api/v1/chat/sessions", map[string]interface{}{
+		"transcription_ids": []string{first.ID, "missing"},
+		"model":             "gpt-4o",
+	})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.request("POST", "/api/v1/chat/sessions", map[string]interface{}{
+		"transcription_ids": []string{first.ID, second.ID},
+		"model":             "gpt-4o",
+		"retrieval":         "embeddings",
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request("POST", "/api/v1/chat/sessions", map[string]interface{}{
+		"transcription_ids": []string{first.ID, second.ID, first.ID},
+		"model":             "gpt-4o",
+		"retrieval":         "bm25",
+	})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var session api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(suite.T(), first.ID, session.TranscriptionID)
+	assert.ElementsMatch(suite.T(), []string{first.ID, second.ID}, session.TranscriptionIDs)
+	assert.Equal(suite.T(), retrieval.ModeBM25, session.RetrievalMode)
+
+	// The session is listed under every transcript it covers
+	w = suite.request("GET", "/api/v1/chat/transcriptions/"+second.ID+"/sessions", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var sessions []api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Len(suite.T(), sessions, 1)
+	assert.Equal(suite.T(), session.ID, sessions[0].ID)
+
+	// Deleting the anchor moves the session to the remaining transcript
+	w = suite.request("DELETE", "/api/v1/transcription/"+first.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var stored models.ChatSession
+	assert.NoError(suite.T(), suite.helper.DB.Preload("Transcriptions").First(&stored, "id = ?", session.ID).Error)
+	assert.Equal(suite.T(), second.ID, stored.TranscriptionID)
+	assert.Len(suite.T(), stored.Transcriptions, 1)
+
+	// Deleting the last transcript deletes the session
+	w = suite.request("DELETE", "/api/v1/transcription/"+second.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var count int64
+	suite.helper.DB.Model(&models.ChatSession{}).Where("id = ?", session.ID).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func TestChatRetrievalTestSuite(t *testing.T) {
+	suite.Run(t, new(ChatRetrievalTestSuite))
+}
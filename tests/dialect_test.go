@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+	"synthezia/internal/transcription/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+// DialectTestSuite covers the queries written differently for SQLite and PostgreSQL. It runs
+// against PostgreSQL when TEST_DATABASE_URL is set.
+type DialectTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *DialectTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "dialect_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *DialectTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *DialectTestSuite) SetupTest() {
+	suite.helper.DB.Exec("DELETE FROM " + search.TableName)
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+// createIndexedJob stores a completed job and indexes its segments
+func (suite *DialectTestSuite) createIndexedJob(title string, texts ...string) *models.TranscriptionJob {
+	result := &interfaces.TranscriptResult{}
+	for i, text := range texts {
+		result.Segments = append(result.Segments, interfaces.TranscriptSegment{Start: float64(i), End: float64(i + 1), Text: text})
+	}
+	data, _ := json.Marshal(result)
+	transcript := string(data)
+
+	job := &models.TranscriptionJob{
+		UserID:     suite.helper.TestUser.ID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  "test/path/" + title + ".mp3",
+		Transcript: &transcript,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	assert.NoError(suite.T(), search.IndexTranscript(job.ID, result))
+	return job
+}
+
+func (suite *DialectTestSuite) TestDialectFollowsConfiguration() {
+	if os.Getenv("TEST_DATABASE_URL") != "" {
+		assert.Equal(suite.T(), database.DialectPostgres, database.Dialect())
+		assert.Equal(suite.T(), "title ILIKE ?", database.ILike("title"))
+	} else {
+		assert.Equal(suite.T(), database.DialectSQLite, database.Dialect())
+		assert.Equal(suite.T(), "title LIKE ? COLLATE NOCASE", database.ILike("title"))
+	}
+}
+
+func (suite *DialectTestSuite) TestListJobsQueryIgnoresCase() {
+	suite.createIndexedJob("Quarterly Review", "Nothing to see here.")
+	suite.createIndexedJob("Standup", "Nothing to see here either.")
+
+	req, _ := http.NewRequest("GET", "/api/v1/transcription/list?q=QUARTERLY", nil)
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var response struct {
+		Jobs []models.TranscriptionJob `json:"jobs"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	if assert.Len(suite.T(), response.Jobs, 1) {
+		assert.Equal(suite.T(), "Quarterly Review", *response.Jobs[0].Title)
+	}
+}
+
+func (suite *DialectTestSuite) TestSearchPhrasesAndPrefixes() {
+	job := suite.createIndexedJob("Kenobi", "Hello there, general Kenobi.", "You are a bold one.")
+	suite.createIndexedJob("Other", "General admission tickets are sold out.")
+
+	results, total, err := search.Search(`"general kenobi"`, search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), job.ID, results[0].JobID)
+		if assert.Len(suite.T(), results[0].Hits, 1) {
+			// SQLite highlights the phrase, PostgreSQL each of its words
+			assert.Contains(suite.T(), results[0].Hits[0].Snippet, search.HighlightStart+"general")
+			assert.Contains(suite.T(), results[0].Hits[0].Snippet, "Kenobi"+search.HighlightEnd)
+		}
+	}
+
+	// Words of a phrase must follow each other
+	_, total, err = search.Search(`"kenobi general"`, search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), total)
+
+	// Prefixes match whole words
+	_, total, err = search.Search("gen*", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(2), total)
+
+	// Every term must match
+	_, total, err = search.Search("general bold", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(0), total)
+}
+
+func (suite *DialectTestSuite) TestRankSegments() {
+	job := suite.createIndexedJob("Budget",
+		"We talked about lunch.",
+		"The budget for the budget review is set.",
+		"The budget is fine.")
+
+	scores, err := search.RankSegments([]string{job.ID}, "What about the budget?", 10)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), scores, 2) {
+		assert.Equal(suite.T(), 1, scores[0].SegmentIndex)
+		assert.Equal(suite.T(), 2, scores[1].SegmentIndex)
+		assert.Less(suite.T(), scores[0].Score, scores[1].Score)
+	}
+
+	scores, err = search.RankSegments([]string{job.ID}, "what is it?", 10)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), scores)
+}
+
+func TestDialectTestSuite(t *testing.T) {
+	suite.Run(t, new(DialectTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"synthezia/internal/config"
 	"synthezia/internal/dropzone"
 	"synthezia/internal/models"
 
@@ -36,6 +37,8 @@ type DropzoneTestSuite struct {
 func (suite *DropzoneTestSuite) SetupSuite() {
 	suite.helper = NewTestHelper(suite.T(), "dropzone_test.db")
 	suite.dropzonePath = filepath.Join("test_dropzone_data", "dropzone")
+	suite.helper.Config.DropzonePath = suite.dropzonePath
+	suite.helper.Config.DropzoneRecursive = true
 	suite.mockQueue = new(MockDropzoneTaskQueue)
 }
 
@@ -47,6 +50,8 @@ func (suite *DropzoneTestSuite) TearDownSuite() {
 func (suite *DropzoneTestSuite) SetupTest() {
 	// Clean dropzone before each test
 	os.RemoveAll(suite.dropzonePath)
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.DropzoneFile{})
 	suite.mockQueue.enqueuedJobs = []string{}
 }
 
@@ -71,7 +76,7 @@ func (suite *DropzoneTestSuite) TestServiceStart() {
 	assert.NoError(suite.T(), err)
 	
 	// Verify dropzone directory was created
-	_, err = os.Stat(filepath.Join("data", "dropzone"))
+	_, err = os.Stat(suite.dropzonePath)
 	assert.NoError(suite.T(), err)
 	
 	// Stop service
@@ -423,6 +428,152 @@ func (suite *DropzoneTestSuite) TestMultiTrackNotAutoTranscribed() {
 	assert.Equal(suite.T(), models.StatusUploaded, job.Status)
 }
 
+// startWith starts a dropzone service with config overrides, restoring the config afterwards
+func (suite *DropzoneTestSuite) startWith(configure func(cfg *config.Config)) *dropzone.Service {
+	original := *suite.helper.Config
+	suite.T().Cleanup(func() { *suite.helper.Config = original })
+
+	suite.helper.Config.UploadDir = filepath.Join("test_dropzone_data", "uploads")
+	configure(suite.helper.Config)
+
+	suite.mockQueue.On("EnqueueJob", mock.Anything).Return(nil)
+	service := dropzone.NewService(suite.helper.Config, suite.mockQueue)
+	assert.NoError(suite.T(), service.Start())
+	suite.T().Cleanup(func() { service.Stop() })
+	return service
+}
+
+// Test the default dropzone path when none is configured
+func (suite *DropzoneTestSuite) TestDefaultPath() {
+	service := dropzone.NewService(&config.Config{}, suite.mockQueue)
+	assert.Equal(suite.T(), filepath.Join("data", "dropzone"), service.Path())
+}
+
+// Test the move action keeps processed files below processed/ without re-ingesting them
+func (suite *DropzoneTestSuite) TestMoveToProcessed() {
+	os.MkdirAll(filepath.Join(suite.dropzonePath, "calls"), 0755)
+	audioFile := filepath.Join(suite.dropzonePath, "calls", "moved.mp3")
+	os.WriteFile(audioFile, []byte("dummy audio"), 0644)
+
+	suite.startWith(func(cfg *config.Config) {
+		cfg.DropzoneAfterProcess = config.DropzoneActionMove
+	})
+
+	time.Sleep(1 * time.Second)
+
+	_, err := os.Stat(audioFile)
+	assert.True(suite.T(), os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(suite.dropzonePath, "processed", "calls", "moved.mp3"))
+	assert.NoError(suite.T(), err)
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("title = ?", "moved.mp3").Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+}
+
+// Test the leave action keeps files in place
+func (suite *DropzoneTestSuite) TestLeaveInPlace() {
+	suite.startWith(func(cfg *config.Config) {
+		cfg.DropzoneAfterProcess = config.DropzoneActionLeave
+	})
+
+	audioFile := filepath.Join(suite.dropzonePath, "left.mp3")
+	os.WriteFile(audioFile, []byte("dummy audio"), 0644)
+
+	time.Sleep(1500 * time.Millisecond)
+
+	_, err := os.Stat(audioFile)
+	assert.NoError(suite.T(), err)
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("title = ?", "left.mp3").Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+}
+
+// Test files left in place are ingested once, and files dropped while stopped are picked up on start
+func (suite *DropzoneTestSuite) TestLeaveIngestsUnseenFilesOnStart() {
+	leave := func(cfg *config.Config) {
+		cfg.DropzoneAfterProcess = config.DropzoneActionLeave
+	}
+
+	os.MkdirAll(suite.dropzonePath, 0755)
+	firstFile := filepath.Join(suite.dropzonePath, "first.mp3")
+	os.WriteFile(firstFile, []byte("dummy audio"), 0644)
+
+	service := suite.startWith(leave)
+	time.Sleep(1 * time.Second)
+	service.Stop()
+
+	var record models.DropzoneFile
+	assert.NoError(suite.T(), suite.helper.DB.Where("path = ?", "first.mp3").First(&record).Error)
+	assert.NotEmpty(suite.T(), record.JobID)
+
+	// Dropped while the server was down
+	secondFile := filepath.Join(suite.dropzonePath, "second.mp3")
+	os.WriteFile(secondFile, []byte("dummy audio"), 0644)
+
+	suite.startWith(leave)
+	time.Sleep(1500 * time.Millisecond)
+
+	for _, title := range []string{"first.mp3", "second.mp3"} {
+		var count int64
+		suite.helper.DB.Model(&models.TranscriptionJob{}).Where("title = ?", title).Count(&count)
+		assert.Equal(suite.T(), int64(1), count, title)
+	}
+	_, err := os.Stat(firstFile)
+	assert.NoError(suite.T(), err)
+	_, err = os.Stat(secondFile)
+	assert.NoError(suite.T(), err)
+}
+
+// Test subfolders are ignored when recursive watching is disabled
+func (suite *DropzoneTestSuite) TestNonRecursive() {
+	os.MkdirAll(filepath.Join(suite.dropzonePath, "nested"), 0755)
+	nestedFile := filepath.Join(suite.dropzonePath, "nested", "nested.mp3")
+	rootFile := filepath.Join(suite.dropzonePath, "root.mp3")
+	os.WriteFile(nestedFile, []byte("dummy audio"), 0644)
+	os.WriteFile(rootFile, []byte("dummy audio"), 0644)
+
+	suite.startWith(func(cfg *config.Config) {
+		cfg.DropzoneRecursive = false
+	})
+
+	time.Sleep(1500 * time.Millisecond)
+
+	_, err := os.Stat(nestedFile)
+	assert.NoError(suite.T(), err, "nested file should be ignored")
+	_, err = os.Stat(rootFile)
+	assert.True(suite.T(), os.IsNotExist(err), "root file should be processed")
+}
+
+// Test subfolders mapped to profiles apply the profile's parameters
+func (suite *DropzoneTestSuite) TestSubfolderProfileMapping() {
+	interview := suite.helper.CreateTestProfile(suite.T(), "Interview", false)
+	interview.Parameters.Diarize = true
+	interview.Parameters.Model = "large-v3"
+	suite.helper.DB.Save(interview)
+
+	os.MkdirAll(filepath.Join(suite.dropzonePath, "interviews", "remote"), 0755)
+	os.WriteFile(filepath.Join(suite.dropzonePath, "interviews", "remote", "guest.mp3"), []byte("dummy audio"), 0644)
+	os.WriteFile(filepath.Join(suite.dropzonePath, "unmapped.mp3"), []byte("dummy audio"), 0644)
+
+	suite.startWith(func(cfg *config.Config) {
+		cfg.DropzoneProfiles = map[string]string{"interviews": "Interview"}
+	})
+
+	time.Sleep(1500 * time.Millisecond)
+
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("title = ?", "guest.mp3").First(&job).Error)
+	assert.True(suite.T(), job.Diarization)
+	assert.Equal(suite.T(), "large-v3", job.Parameters.Model)
+	assert.Equal(suite.T(), suite.helper.TestUser.ID, job.UserID)
+
+	var unmapped models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("title = ?", "unmapped.mp3").First(&unmapped).Error)
+	assert.False(suite.T(), unmapped.Diarization)
+}
+
 func TestDropzoneTestSuite(t *testing.T) {
 	suite.Run(t, new(DropzoneTestSuite))
 }
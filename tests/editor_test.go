@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"synthezia/internal/editor"
+	"synthezia/internal/transcription/interfaces"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TranscriptEditorTestSuite struct {
+	suite.Suite
+}
+
+func wordTexts(t *interfaces.TranscriptResult) []string {
+	words := make([]string, len(t.WordSegments))
+	for i, w := range t.WordSegments {
+		words[i] = w.Word
+	}
+	return words
+}
+
+func (suite *TranscriptEditorTestSuite) TestEditSegmentText() {
+	original := editableTranscript()
+	edited, err := editor.EditSegmentText(original, 0, "Hello there, General Grievous the Kenobi.")
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), " Hello there, General Grievous the Kenobi.", edited.Segments[0].Text)
+	assert.Equal(suite.T(), "Hello there, General Grievous the Kenobi. You are a bold one.", edited.Text)
+	assert.Equal(suite.T(), []string{"Hello", "there,", "General", "Grievous", "the", "Kenobi.", "You", "are", "a", "bold", "one."}, wordTexts(edited))
+
+	// Matched words keep their timing, inserted ones share the gap before the next match
+	assert.Equal(suite.T(), 1.0, edited.WordSegments[2].Start)
+	assert.Equal(suite.T(), 1.4, edited.WordSegments[3].Start)
+	assert.Equal(suite.T(), 1.45, edited.WordSegments[4].Start)
+	assert.Equal(suite.T(), 1.5, edited.WordSegments[4].End)
+	assert.Equal(suite.T(), "SPEAKER_00", *edited.WordSegments[3].Speaker)
+	assert.Equal(suite.T(), 1.5, edited.WordSegments[5].Start)
+
+	// The original is left untouched
+	assert.Equal(suite.T(), " Hello there general Kenobi.", original.Segments[0].Text)
+	assert.Len(suite.T(), original.WordSegments, 9)
+
+	_, err = editor.EditSegmentText(original, 5, "text")
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+	_, err = editor.EditSegmentText(original, 0, "   ")
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+}
+
+func (suite *TranscriptEditorTestSuite) TestSplitAndMerge() {
+	split, err := editor.SplitSegment(editableTranscript(), 1, 2)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), split.Segments, 3) {
+		assert.Equal(suite.T(), " You are", split.Segments[1].Text)
+		assert.Equal(suite.T(), 3.1, split.Segments[1].End)
+		assert.Equal(suite.T(), " a bold one.", split.Segments[2].Text)
+		assert.Equal(suite.T(), 3.2, split.Segments[2].Start)
+		assert.Equal(suite.T(), "SPEAKER_01", *split.Segments[2].Speaker)
+	}
+	assert.Len(suite.T(), split.WordSegments, 9)
+
+	merged, err := editor.MergeSegments(split, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), editableTranscript().Segments, merged.Segments)
+
+	_, err = editor.SplitSegment(editableTranscript(), 0, 4)
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+	_, err = editor.MergeSegments(editableTranscript(), 1)
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+}
+
+func (suite *TranscriptEditorTestSuite) TestReassignSpeaker() {
+	edited, err := editor.ReassignSegmentSpeaker(editableTranscript(), 1, "SPEAKER_00")
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "SPEAKER_00", *edited.Segments[1].Speaker)
+	assert.Equal(suite.T(), "SPEAKER_00", *edited.WordSegments[8].Speaker)
+
+	// A word range inside a segment gets a segment of its own
+	edited, err = editor.ReassignWordSpeaker(editableTranscript(), 2, 3, "SPEAKER_02")
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), edited.Segments, 3) {
+		assert.Equal(suite.T(), " Hello there", edited.Segments[0].Text)
+		assert.Equal(suite.T(), "SPEAKER_00", *edited.Segments[0].Speaker)
+		assert.Equal(suite.T(), " general Kenobi.", edited.Segments[1].Text)
+		assert.Equal(suite.T(), "SPEAKER_02", *edited.Segments[1].Speaker)
+		assert.Equal(suite.T(), "SPEAKER_01", *edited.Segments[2].Speaker)
+	}
+	assert.Equal(suite.T(), "SPEAKER_00", *edited.WordSegments[1].Speaker)
+	assert.Equal(suite.T(), "SPEAKER_02", *edited.WordSegments[2].Speaker)
+
+	// A speaker given a segment is no longer listed as talking over it
+	overlapped := editableTranscript()
+	overlapped.Segments[1].OverlapSpeakers = []string{"SPEAKER_00"}
+	overlapped.WordSegments[5].OverlapSpeakers = []string{"SPEAKER_00"}
+	edited, err = editor.ReassignSegmentSpeaker(overlapped, 1, "SPEAKER_00")
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), edited.Segments[1].OverlapSpeakers)
+	assert.Empty(suite.T(), edited.WordSegments[5].OverlapSpeakers)
+	assert.Equal(suite.T(), []string{"SPEAKER_00"}, overlapped.Segments[1].OverlapSpeakers)
+
+	_, err = editor.ReassignWordSpeaker(editableTranscript(), 3, 9, "SPEAKER_02")
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+	_, err = editor.ReassignSegmentSpeaker(editableTranscript(), 0, " ")
+	assert.True(suite.T(), errors.Is(err, editor.ErrInvalidEdit))
+}
+
+func (suite *TranscriptEditorTestSuite) TestWordMapFollowsEdits() {
+	original := editableTranscript()
+	edited, err := editor.EditSegmentText(original, 0, "Well hello general.")
+	assert.NoError(suite.T(), err)
+
+	wordMap := editor.AlignWords(original.WordSegments, edited.WordSegments)
+	assert.Equal(suite.T(), editor.WordMap{1, -1, 2, -1, 3, 4, 5, 6, 7}, wordMap)
+
+	// Removed words at the edges of a range are skipped
+	start, end, ok := wordMap.RemapRange(1, 4, len(edited.WordSegments))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), []int{2, 3}, []int{start, end})
+
+	// A range whose words all went away collapses onto the next surviving word
+	start, end, ok = wordMap.RemapRange(3, 3, len(edited.WordSegments))
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), []int{3, 3}, []int{start, end})
+}
+
+func (suite *TranscriptEditorTestSuite) TestDiffTranscripts() {
+	original := editableTranscript()
+	assert.True(suite.T(), editor.DiffTranscripts(original, editableTranscript()).Empty())
+
+	edited, err := editor.ReassignSegmentSpeaker(original, 1, "SPEAKER_00")
+	assert.NoError(suite.T(), err)
+	diff := editor.DiffTranscripts(original, edited)
+	if assert.NotNil(suite.T(), diff.Segments) && assert.NotNil(suite.T(), diff.Words) {
+		assert.Equal(suite.T(), 1, diff.Segments.Index)
+		assert.Len(suite.T(), diff.Segments.After, 1)
+		assert.Equal(suite.T(), 4, diff.Words.Index)
+		assert.Len(suite.T(), diff.Words.Before, 5)
+	}
+}
+
+func TestTranscriptEditorTestSuite(t *testing.T) {
+	suite.Run(t, new(TranscriptEditorTestSuite))
+}
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/transcription/pipeline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type AudioEnhancementTestSuite struct {
+	suite.Suite
+}
+
+func (suite *AudioEnhancementTestSuite) TestFilterChain() {
+	cases := []struct {
+		name     string
+		stage    pipeline.NoiseReductionPreprocessor
+		expected string
+	}{
+		{"nothing enabled", pipeline.NoiseReductionPreprocessor{}, ""},
+		{"highpass only", pipeline.NoiseReductionPreprocessor{HighpassHz: 80}, "highpass=f=80"},
+		{"loudnorm defaults to EBU R128", pipeline.NoiseReductionPreprocessor{Loudnorm: true}, "loudnorm=I=-23:TP=-2:LRA=11"},
+		{
+			"all stages in order",
+			pipeline.NoiseReductionPreprocessor{HighpassHz: 100, Denoise: pipeline.DenoiseFFT, Loudnorm: true, LoudnormTarget: -16},
+			"highpass=f=100,afftdn=nf=-25,loudnorm=I=-16:TP=-2:LRA=11",
+		},
+		{
+			"rnnoise model",
+			pipeline.NoiseReductionPreprocessor{Denoise: pipeline.DenoiseRNNoise, DenoiseModel: "sh.rnnn"},
+			"arnndn=m=" + pipeline.RNNoiseModelDirectory + "/sh.rnnn",
+		},
+	}
+	for _, tc := range cases {
+		chain, err := tc.stage.FilterChain()
+		assert.NoError(suite.T(), err, tc.name)
+		assert.Equal(suite.T(), tc.expected, chain, tc.name)
+	}
+}
+
+func (suite *AudioEnhancementTestSuite) TestValidateDenoise() {
+	assert.NoError(suite.T(), pipeline.ValidateDenoise("", ""))
+	assert.NoError(suite.T(), pipeline.ValidateDenoise(pipeline.DenoiseFFT, ""))
+	assert.NoError(suite.T(), pipeline.ValidateDenoise(pipeline.DenoiseRNNoise, "bd.rnnn"))
+
+	assert.Error(suite.T(), pipeline.ValidateDenoise("sox", ""))
+	assert.Error(suite.T(), pipeline.ValidateDenoise(pipeline.DenoiseRNNoise, ""))
+	assert.Error(suite.T(), pipeline.ValidateDenoise(pipeline.DenoiseRNNoise, "../model.rnnn"))
+	assert.Error(suite.T(), pipeline.ValidateDenoise(pipeline.DenoiseRNNoise, "m.rnnn,volume=10"))
+}
+
+func (suite *AudioEnhancementTestSuite) TestRecordsFilterChain() {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		suite.T().Skip("ffmpeg not installed")
+	}
+
+	path := filepath.Join(suite.T().TempDir(), "hum.wav")
+	cmd := exec.Command("ffmpeg", "-y", "-f", "lavfi", "-i", "sine=frequency=50:duration=2", "-ar", "16000", "-ac", "1", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		suite.T().Skipf("could not generate test audio: %s", output)
+	}
+
+	stage := &pipeline.NoiseReductionPreprocessor{HighpassHz: 120, Denoise: pipeline.DenoiseFFT, Loudnorm: true}
+	input := interfaces.AudioInput{FilePath: path, Format: "wav", SampleRate: 16000, Channels: 1, Duration: 2 * time.Second}
+	enhanced, err := stage.Process(context.Background(), input)
+	assert.NoError(suite.T(), err)
+	defer os.Remove(enhanced.TempFilePath)
+
+	chain, _ := stage.FilterChain()
+	assert.Equal(suite.T(), chain, enhanced.Metadata[pipeline.FilterChainMetadataKey])
+	assert.NotEqual(suite.T(), path, enhanced.FilePath)
+	assert.FileExists(suite.T(), enhanced.FilePath)
+}
+
+func TestAudioEnhancementTestSuite(t *testing.T) {
+	suite.Run(t, new(AudioEnhancementTestSuite))
+}
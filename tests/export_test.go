@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/export"
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type ExportTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *ExportTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "export_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *ExportTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+// sampleTranscript returns a two-speaker transcript with word-level timings
+func sampleTranscript() *interfaces.TranscriptResult {
+	s0 := "SPEAKER_00"
+	s1 := "SPEAKER_01"
+	return &interfaces.TranscriptResult{
+		Text:     "Hello there my friend. How are you?",
+		Language: "en",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0.0, End: 2.0, Text: " Hello there my friend.", Speaker: &s0},
+			{Start: 2.5, End: 4.0, Text: " How are you?", Speaker: &s1},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.0, End: 0.4, Word: "Hello", Speaker: &s0},
+			{Start: 0.5, End: 0.9, Word: "there", Speaker: &s0},
+			{Start: 1.0, End: 1.3, Word: "my", Speaker: &s0},
+			{Start: 1.4, End: 2.0, Word: "friend.", Speaker: &s0},
+			{Start: 2.5, End: 2.8, Word: "How", Speaker: &s1},
+			{Start: 2.9, End: 3.2, Word: "are", Speaker: &s1},
+			{Start: 3.3, End: 4.0, Word: "you?", Speaker: &s1},
+		},
+	}
+}
+
+// createCompletedJob stores a completed job with the sample transcript
+func (suite *ExportTestSuite) createCompletedJob(title string, params models.WhisperXParams) *models.TranscriptionJob {
+	data, err := json.Marshal(sampleTranscript())
+	assert.NoError(suite.T(), err)
+	transcript := string(data)
+
+	params.Diarize = true
+	job := &models.TranscriptionJob{
+		UserID:     suite.helper.TestUser.ID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  "test/path/audio.mp3",
+		Transcript: &transcript,
+		Parameters: params,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	return job
+}
+
+func (suite *ExportTestSuite) get(path string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *ExportTestSuite) TestRenderSRTAppliesSpeakerNames() {
+	out, err := export.Render(export.FormatSRT, sampleTranscript(), export.Options{
+		SpeakerNames: map[string]string{"SPEAKER_00": "Alice"},
+	})
+	assert.NoError(suite.T(), err)
+
+	srt := string(out.Data)
+	assert.Contains(suite.T(), srt, "1\n00:00:00,000 --> 00:00:02,000\nAlice: Hello there my friend.\n")
+	assert.Contains(suite.T(), srt, "2\n00:00:02,500 --> 00:00:04,000\nSPEAKER_01: How are you?\n")
+}
+
+func (suite *ExportTestSuite) TestRenderVTTWrapsLines() {
+	out, err := export.Render(export.FormatVTT, sampleTranscript(), export.Options{
+		MaxLineWidth: 11,
+		MaxLineCount: 1,
+	})
+	assert.NoError(suite.T(), err)
+
+	vtt := string(out.Data)
+	assert.True(suite.T(), strings.HasPrefix(vtt, "WEBVTT\n\n"))
+	// "Hello there my friend." is split into two single-line cues
+	assert.Contains(suite.T(), vtt, "00:00:00.000 --> 00:00:00.900\n<v SPEAKER_00>Hello there\n")
+	assert.Contains(suite.T(), vtt, "00:00:01.000 --> 00:00:02.000\n<v SPEAKER_00>my friend.\n")
+}
+
+func (suite *ExportTestSuite) TestRenderSRTHighlightWords() {
+	out, err := export.Render(export.FormatSRT, sampleTranscript(), export.Options{HighlightWords: true})
+	assert.NoError(suite.T(), err)
+
+	srt := string(out.Data)
+	assert.Contains(suite.T(), srt, "00:00:00,000 --> 00:00:00,400\nSPEAKER_00: <u>Hello</u> there my friend.\n")
+	assert.Contains(suite.T(), srt, "00:00:00,500 --> 00:00:00,900\nSPEAKER_00: Hello <u>there</u> my friend.\n")
+	// Gaps between words show the cue without highlighting
+	assert.Contains(suite.T(), srt, "00:00:00,400 --> 00:00:00,500\nSPEAKER_00: Hello there my friend.\n")
+}
+
+func (suite *ExportTestSuite) TestRenderTXTAndTSV() {
+	out, err := export.Render(export.FormatTXT, sampleTranscript(), export.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "SPEAKER_00: Hello there my friend.\nSPEAKER_01: How are you?\n", string(out.Data))
+
+	out, err = export.Render(export.FormatTSV, sampleTranscript(), export.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "start\tend\tspeaker\ttext\n0\t2000\tSPEAKER_00\tHello there my friend.\n2500\t4000\tSPEAKER_01\tHow are you?\n", string(out.Data))
+}
+
+func (suite *ExportTestSuite) TestRenderDOCX() {
+	out, err := export.Render(export.FormatDOCX, sampleTranscript(), export.Options{
+		Title:        "Meeting & Notes",
+		SpeakerNames: map[string]string{"SPEAKER_01": "Bob"},
+	})
+	assert.NoError(suite.T(), err)
+
+	zr, err := zip.NewReader(bytes.NewReader(out.Data), int64(len(out.Data)))
+	assert.NoError(suite.T(), err)
+
+	var document string
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			rc, err := f.Open()
+			assert.NoError(suite.T(), err)
+			data, _ := io.ReadAll(rc)
+			rc.Close()
+			document = string(data)
+		}
+	}
+	assert.Contains(suite.T(), document, "Meeting &amp; Notes")
+	assert.Contains(suite.T(), document, "Bob: ")
+	assert.Contains(suite.T(), document, "How are you?")
+}
+
+func (suite *ExportTestSuite) TestExportEndpointUsesJobSettings() {
+	width := 11
+	count := 1
+	job := suite.createCompletedJob("Weekly Sync", models.WhisperXParams{
+		Model:        "base",
+		MaxLineWidth: &width,
+		MaxLineCount: &count,
+	})
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.SpeakerMapping{
+		TranscriptionJobID: job.ID,
+		OriginalSpeaker:    "SPEAKER_00",
+		CustomName:         "Alice",
+	}).Error)
+
+	w := suite.get("/api/v1/transcription/" + job.ID + "/export?format=srt")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), `attachment; filename="Weekly_Sync.srt"`, w.Header().Get("Content-Disposition"))
+	assert.Contains(suite.T(), w.Body.String(), "Alice: Hello there\n")
+	assert.Contains(suite.T(), w.Body.String(), "Alice: my friend.\n")
+
+	// Query parameters override the stored formatting options
+	w = suite.get("/api/v1/transcription/" + job.ID + "/export?format=srt&max_line_width=0")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "Alice: Hello there my friend.\n")
+}
+
+func (suite *ExportTestSuite) TestExportEndpointJSON() {
+	job := suite.createCompletedJob("JSON Export", models.WhisperXParams{Model: "base"})
+
+	w := suite.get("/api/v1/transcription/" + job.ID + "/export?format=json")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var result interfaces.TranscriptResult
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Len(suite.T(), result.Segments, 2)
+	assert.Len(suite.T(), result.WordSegments, 7)
+}
+
+func (suite *ExportTestSuite) TestExportEndpointErrors() {
+	job := suite.createCompletedJob("Errors", models.WhisperXParams{Model: "base"})
+
+	w := suite.get("/api/v1/transcription/" + job.ID + "/export?format=pdf")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.get("/api/v1/transcription/does-not-exist/export?format=srt")
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	pending := suite.helper.CreateTestTranscriptionJob(suite.T(), "Pending")
+	w = suite.get("/api/v1/transcription/" + pending.ID + "/export?format=srt")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestExportTestSuite(t *testing.T) {
+	suite.Run(t, new(ExportTestSuite))
+}
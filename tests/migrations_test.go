@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"synthezia/internal/auth"
+	"synthezia/internal/config"
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MigrationsTestSuite struct {
+	suite.Suite
+	cfg *config.Config
+}
+
+func (suite *MigrationsTestSuite) SetupTest() {
+	suite.cfg = &config.Config{DatabasePath: "migrations_test.db"}
+	suite.removeDatabase()
+}
+
+func (suite *MigrationsTestSuite) TearDownTest() {
+	database.Close()
+	suite.removeDatabase()
+}
+
+func (suite *MigrationsTestSuite) removeDatabase() {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		os.Remove(suite.cfg.DatabasePath + suffix)
+	}
+}
+
+// openBaselineSnapshot creates a database with the schema of the first release and some data
+func (suite *MigrationsTestSuite) openBaselineSnapshot() {
+	if err := database.Open(suite.cfg); err != nil {
+		suite.T().Fatal("Failed to open database:", err)
+	}
+
+	schema, err := os.ReadFile("testdata/baseline_schema.sql")
+	if err != nil {
+		suite.T().Fatal("Failed to read baseline schema:", err)
+	}
+	for _, statement := range strings.Split(string(schema), ";\n") {
+		var lines []string
+		for _, line := range strings.Split(statement, "\n") {
+			if !strings.HasPrefix(line, "--") {
+				lines = append(lines, line)
+			}
+		}
+		if statement = strings.TrimSpace(strings.Join(lines, "\n")); statement != "" {
+			if err := database.DB.Exec(statement).Error; err != nil {
+				suite.T().Fatal("Failed to create baseline schema:", err)
+			}
+		}
+	}
+
+	for _, statement := range []string{
+		"INSERT INTO users (username, password, created_at, updated_at) VALUES ('first', 'x', datetime('now'), datetime('now'))",
+		"INSERT INTO users (username, password, created_at, updated_at) VALUES ('second', 'x', datetime('now'), datetime('now'))",
+		"INSERT INTO api_keys (key, name, is_active, created_at, updated_at) VALUES ('old-plaintext-key', 'Old', true, datetime('now'), datetime('now'))",
+		"INSERT INTO transcription_jobs (id, title, status, audio_path, created_at, updated_at) VALUES ('old-job', 'Old job', 'completed', 'uploads/old.mp3', datetime('now'), datetime('now'))",
+		"INSERT INTO live_transcription_sessions (id, status, created_at, updated_at) VALUES ('old-session', 'completed', datetime('now'), datetime('now'))",
+	} {
+		if err := database.DB.Exec(statement).Error; err != nil {
+			suite.T().Fatal("Failed to insert baseline data:", err)
+		}
+	}
+}
+
+func (suite *MigrationsTestSuite) TestDryRunLeavesBaselineUntouched() {
+	suite.openBaselineSnapshot()
+
+	pending, err := database.Migrate(database.MigrateOptions{DryRun: true})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), pending, database.LatestSchemaVersion())
+	assert.Equal(suite.T(), 1, pending[0].Version)
+
+	version, err := database.SchemaVersion()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, version)
+	assert.False(suite.T(), database.DB.Migrator().HasTable(&database.SchemaMigration{}))
+	assert.False(suite.T(), database.DB.Migrator().HasColumn(&models.User{}, "role"))
+}
+
+func (suite *MigrationsTestSuite) TestUpgradeFromBaselineSnapshot() {
+	suite.openBaselineSnapshot()
+
+	applied, err := database.Migrate(database.MigrateOptions{})
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+	assert.Len(suite.T(), applied, database.LatestSchemaVersion())
+
+	version, err := database.SchemaVersion()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), database.LatestSchemaVersion(), version)
+
+	var recorded []database.SchemaMigration
+	assert.NoError(suite.T(), database.DB.Order("version ASC").Find(&recorded).Error)
+	if assert.Len(suite.T(), recorded, len(applied)) {
+		assert.Equal(suite.T(), "baseline_schema", recorded[0].Name)
+		assert.False(suite.T(), recorded[0].AppliedAt.IsZero())
+	}
+
+	// New columns and tables exist, and existing rows are kept
+	assert.True(suite.T(), database.DB.Migrator().HasColumn(&models.TranscriptionJob{}, "user_id"))
+	assert.True(suite.T(), database.DB.Migrator().HasTable(&models.Invitation{}))
+	var job models.TranscriptionJob
+	assert.NoError(suite.T(), database.DB.First(&job, "id = ?", "old-job").Error)
+	assert.Equal(suite.T(), "uploads/old.mp3", job.AudioPath)
+
+	// Data migrations ran
+	var key models.APIKey
+	assert.NoError(suite.T(), database.DB.First(&key, "name = ?", "Old").Error)
+	assert.True(suite.T(), key.Hashed)
+	assert.Equal(suite.T(), auth.HashAPIKey("old-plaintext-key"), key.Key)
+	assert.Equal(suite.T(), "old-plai", key.KeyPrefix)
+	assert.Equal(suite.T(), models.DefaultAPIKeyScopes(), key.Scopes)
+
+	var users []models.User
+	assert.NoError(suite.T(), database.DB.Order("id ASC").Find(&users).Error)
+	if assert.Len(suite.T(), users, 2) {
+		assert.Equal(suite.T(), models.RoleAdmin, users[0].Role)
+		assert.Equal(suite.T(), models.RoleEditor, users[1].Role)
+		assert.Equal(suite.T(), users[0].ID, job.UserID)
+		assert.Equal(suite.T(), users[0].ID, key.UserID)
+
+		var session models.LiveTranscriptionSession
+		assert.NoError(suite.T(), database.DB.First(&session, "id = ?", "old-session").Error)
+		assert.Equal(suite.T(), users[0].ID, session.UserID)
+	}
+
+	// Nothing is left to do
+	applied, err = database.Migrate(database.MigrateOptions{})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), applied)
+}
+
+func (suite *MigrationsTestSuite) TestUpgradedSchemaMatchesModels() {
+	suite.openBaselineSnapshot()
+	_, err := database.Migrate(database.MigrateOptions{})
+	if !assert.NoError(suite.T(), err) {
+		return
+	}
+
+	for _, model := range []interface{}{
+		&models.TranscriptionJob{}, &models.TranscriptionJobExecution{}, &models.SpeakerMapping{},
+		&models.MultiTrackFile{}, &models.User{}, &models.APIKey{}, &models.TranscriptionProfile{},
+		&models.LLMConfig{}, &models.ChatSession{}, &models.ChatMessage{}, &models.SummaryTemplate{},
+		&models.SummarySetting{}, &models.Summary{}, &models.Note{}, &models.RefreshToken{},
+		&models.Invitation{}, &models.LiveTranscriptionSession{}, &models.LiveTranscriptionChunk{},
+		&models.Webhook{}, &models.WebhookDelivery{}, &models.ReplacementDictionary{},
+		&models.TranscriptRevision{}, &models.VoiceProfile{}, &models.VoiceSample{},
+		&models.SpeakerEmbedding{}, &models.Tag{}, &models.Folder{}, &models.TranscriptChunkEmbedding{},
+		&models.DropzoneFile{},
+	} {
+		stmt := &gorm.Statement{DB: database.DB}
+		if !assert.NoError(suite.T(), stmt.Parse(model)) {
+			continue
+		}
+		if !assert.True(suite.T(), database.DB.Migrator().HasTable(model), "table %s", stmt.Schema.Table) {
+			continue
+		}
+		// A model change without a migration of its own shows up as a missing column
+		for _, column := range stmt.Schema.DBNames {
+			assert.True(suite.T(), database.DB.Migrator().HasColumn(model, column), "column %s.%s", stmt.Schema.Table, column)
+		}
+	}
+	assert.True(suite.T(), database.DB.Migrator().HasTable("transcription_job_tags"))
+	assert.True(suite.T(), database.DB.Migrator().HasTable("chat_session_transcriptions"))
+}
+
+func (suite *MigrationsTestSuite) TestFreshDatabaseIsAtLatestVersion() {
+	if err := database.Initialize(suite.cfg); err != nil {
+		suite.T().Fatal("Failed to initialize database:", err)
+	}
+
+	version, err := database.SchemaVersion()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), database.LatestSchemaVersion(), version)
+
+	pending, err := database.PendingMigrations()
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), pending)
+}
+
+func (suite *MigrationsTestSuite) TestRefusesNewerSchema() {
+	if err := database.Initialize(suite.cfg); err != nil {
+		suite.T().Fatal("Failed to initialize database:", err)
+	}
+	assert.NoError(suite.T(), database.DB.Create(&database.SchemaMigration{
+		Version: database.LatestSchemaVersion() + 1, Name: "from_the_future",
+	}).Error)
+
+	_, err := database.Migrate(database.MigrateOptions{})
+	if assert.Error(suite.T(), err) {
+		assert.Contains(suite.T(), err.Error(), "newer than this build supports")
+	}
+}
+
+func TestMigrationsTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationsTestSuite))
+}
@@ -0,0 +1,271 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrganizationTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *OrganizationTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "organization_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *OrganizationTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *OrganizationTestSuite) SetupTest() {
+	suite.helper.DB.Exec("DELETE FROM transcription_job_tags")
+	suite.helper.DB.Where("1 = 1").Delete(&models.ChatMessage{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.ChatSession{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.LLMConfig{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.Tag{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.Folder{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+func (suite *OrganizationTestSuite) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *OrganizationTestSuite) createJob(title string, mutate func(job *models.TranscriptionJob)) *models.TranscriptionJob {
+	data, _ := json.Marshal(editableTranscript())
+	transcript := string(data)
+	job := &models.TranscriptionJob{
+		UserID:     suite.helper.TestUser.ID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  "test/path/audio.mp3",
+		Transcript: &transcript,
+	}
+	if mutate != nil {
+		mutate(job)
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	return job
+}
+
+func (suite *OrganizationTestSuite) createFolder(name string, parentID string) models.Folder {
+	w := suite.request("POST", "/api/v1/folders/", map[string]string{"name": name, "parent_id": parentID})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var folder models.Folder
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &folder))
+	return folder
+}
+
+// listTitles returns the titles of the jobs ListJobs returns for a query string
+func (suite *OrganizationTestSuite) listTitles(query string) []string {
+	w := suite.request("GET", "/api/v1/transcription/list?limit=100&"+query, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response struct {
+		Jobs []models.TranscriptionJob `json:"jobs"`
+	}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	titles := []string{}
+	for _, job := range response.Jobs {
+		titles = append(titles, *job.Title)
+	}
+	return titles
+}
+
+func (suite *OrganizationTestSuite) TestTagCRUD() {
+	w := suite.request("POST", "/api/v1/tags/", map[string]string{"name": " Clients "})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var tag models.Tag
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &tag))
+	assert.Equal(suite.T(), "Clients", tag.Name)
+
+	w = suite.request("POST", "/api/v1/tags/", map[string]string{"name": "clients"})
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	job := suite.createJob("Call", nil)
+	w = suite.request("PUT", "/api/v1/transcription/"+job.ID+"/tags", map[string][]string{"tags": {"clients", "Urgent"}})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request("GET", "/api/v1/tags/", nil)
+	var tags []api.TagResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &tags))
+	assert.Len(suite.T(), tags, 2)
+	assert.Equal(suite.T(), "Clients", tags[0].Name)
+	assert.Equal(suite.T(), int64(1), tags[0].JobCount)
+
+	w = suite.request("PUT", "/api/v1/tags/"+tag.ID, map[string]string{"name": "urgent"})
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+	w = suite.request("PUT", "/api/v1/tags/"+tag.ID, map[string]string{"name": "Customers"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request("DELETE", "/api/v1/tags/"+tag.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var updated models.TranscriptionJob
+	suite.helper.DB.Preload("Tags").First(&updated, "id = ?", job.ID)
+	assert.Len(suite.T(), updated.Tags, 1)
+	assert.Equal(suite.T(), "Urgent", updated.Tags[0].Name)
+}
+
+func (suite *OrganizationTestSuite) TestFolderHierarchy() {
+	work := suite.createFolder("Work", "")
+	meetings := suite.createFolder("Meetings", work.ID)
+	standups := suite.createFolder("Standups", meetings.ID)
+
+	w := suite.request("GET", "/api/v1/folders/"+standups.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var detail api.FolderDetailResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &detail))
+	assert.Len(suite.T(), detail.Path, 2)
+	assert.Equal(suite.T(), "Work", detail.Path[0].Name)
+	assert.Equal(suite.T(), "Meetings", detail.Path[1].Name)
+
+	// A folder can't move below itself
+	w = suite.request("PUT", "/api/v1/folders/"+work.ID, map[string]string{"parent_id": standups.ID})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request("PUT", "/api/v1/folders/"+standups.ID, map[string]string{"parent_id": "", "name": "Daily"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var moved models.Folder
+	suite.helper.DB.First(&moved, "id = ?", standups.ID)
+	assert.Nil(suite.T(), moved.ParentID)
+	assert.Equal(suite.T(), "Daily", moved.Name)
+
+	// Deleting a folder moves its contents up
+	job := suite.createJob("Retro", nil)
+	w = suite.request("PUT", "/api/v1/transcription/"+job.ID+"/folder", map[string]string{"folder_id": meetings.ID})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	suite.request("PUT", "/api/v1/folders/"+standups.ID, map[string]string{"parent_id": meetings.ID})
+
+	w = suite.request("DELETE", "/api/v1/folders/"+meetings.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var updated models.TranscriptionJob
+	suite.helper.DB.First(&updated, "id = ?", job.ID)
+	assert.Equal(suite.T(), work.ID, *updated.FolderID)
+	suite.helper.DB.First(&moved, "id = ?", standups.ID)
+	assert.Equal(suite.T(), work.ID, *moved.ParentID)
+}
+
+func (suite *OrganizationTestSuite) TestListJobsFilters() {
+	work := suite.createFolder("Work", "")
+	meetings := suite.createFolder("Meetings", work.ID)
+
+	english, german := "en", "de"
+	long, short := 3600.0, 60.0
+	old := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	suite.createJob("Planning", func(job *models.TranscriptionJob) {
+		job.FolderID = &work.ID
+		job.AudioDuration = &long
+		job.DetectedLanguage = &english
+		job.Parameters.Model = "large-v3"
+		job.CreatedAt = old
+	})
+	suite.createJob("Standup", func(job *models.TranscriptionJob) {
+		job.FolderID = &meetings.ID
+		job.AudioDuration = &short
+		job.DetectedLanguage = &german
+		job.Parameters.Model = "small"
+	})
+	suite.createJob("Voice memo", func(job *models.TranscriptionJob) {
+		job.Parameters.Model = "small"
+		job.Parameters.Language = &german
+	})
+
+	suite.request("POST", "/api/v1/transcription/bulk/tags", map[string]interface{}{
+		"filter": map[string]string{"model": "small"},
+		"add":    []string{"Team"},
+	})
+
+	assert.ElementsMatch(suite.T(), []string{"Standup", "Voice memo"}, suite.listTitles("tag=team"))
+	assert.ElementsMatch(suite.T(), []string{"Planning"}, suite.listTitles("folder_id="+work.ID))
+	assert.ElementsMatch(suite.T(), []string{"Planning", "Standup"}, suite.listTitles("folder_id="+work.ID+"&include_subfolders=true"))
+	assert.ElementsMatch(suite.T(), []string{"Voice memo"}, suite.listTitles("folder_id=root"))
+	assert.ElementsMatch(suite.T(), []string{"Planning"}, suite.listTitles("created_before=2024-03-01"))
+	assert.ElementsMatch(suite.T(), []string{"Standup", "Voice memo"}, suite.listTitles("created_after=2025-01-01"))
+	assert.ElementsMatch(suite.T(), []string{"Planning"}, suite.listTitles("min_duration=600"))
+	assert.ElementsMatch(suite.T(), []string{"Standup"}, suite.listTitles("max_duration=600"))
+	assert.ElementsMatch(suite.T(), []string{"Planning"}, suite.listTitles("model=large-v3"))
+	assert.ElementsMatch(suite.T(), []string{"Standup", "Voice memo"}, suite.listTitles("language=DE"))
+	assert.ElementsMatch(suite.T(), []string{"Standup"}, suite.listTitles("language=de&tag=Team&folder_id="+work.ID+"&include_subfolders=true"))
+
+	w := suite.request("GET", "/api/v1/transcription/list?created_after=yesterday", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	w = suite.request("GET", "/api/v1/transcription/list?min_duration=-1", nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *OrganizationTestSuite) TestFolderChatSession() {
+	apiKey := "test-key"
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.LLMConfig{Provider: "openai", APIKey: &apiKey, IsActive: true}).Error)
+
+	folder := suite.createFolder("Standups", "")
+	sub := suite.createFolder("Week 1", folder.ID)
+
+	w := suite.request("POST", "/api/v1/folders/"+folder.ID+"/chat/sessions", map[string]string{"model": "gpt-4o"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	first := suite.createJob("Monday", func(job *models.TranscriptionJob) {
+		job.FolderID = &sub.ID
+		job.CreatedAt = time.Now().Add(-time.Hour)
+	})
+	second := suite.createJob("Tuesday", func(job *models.TranscriptionJob) { job.FolderID = &folder.ID })
+
+	w = suite.request("POST", "/api/v1/folders/"+folder.ID+"/chat/sessions", map[string]string{"model": "gpt-4o"})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var session api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(suite.T(), folder.ID, *session.FolderID)
+	assert.Equal(suite.T(), "Standups", session.Title)
+	assert.Equal(suite.T(), second.ID, session.TranscriptionID)
+
+	w = suite.request("GET", "/api/v1/folders/"+folder.ID+"/chat/sessions", nil)
+	var sessions []api.ChatSessionResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Len(suite.T(), sessions, 1)
+
+	// Folder sessions don't show up among the sessions of the transcript they are anchored to
+	w = suite.request("GET", "/api/v1/chat/transcriptions/"+second.ID+"/sessions", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "null", w.Body.String())
+
+	// Deleting the anchor moves the session to another transcript of the folder
+	w = suite.request("DELETE", "/api/v1/transcription/"+second.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var stored models.ChatSession
+	assert.NoError(suite.T(), suite.helper.DB.First(&stored, "id = ?", session.ID).Error)
+	assert.Equal(suite.T(), first.ID, stored.TranscriptionID)
+
+	w = suite.request("DELETE", "/api/v1/folders/"+folder.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var count int64
+	suite.helper.DB.Model(&models.ChatSession{}).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+}
+
+func TestOrganizationTestSuite(t *testing.T) {
+	suite.Run(t, new(OrganizationTestSuite))
+}
@@ -0,0 +1,219 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/auth"
+	"synthezia/internal/database"
+	"synthezia/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type OwnershipTestSuite struct {
+	suite.Suite
+	helper     *TestHelper
+	router     *gin.Engine
+	otherUser  *models.User
+	otherToken string
+}
+
+func (suite *OwnershipTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "ownership_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+
+	hashedPassword, err := auth.HashPassword("otherpassword123")
+	assert.NoError(suite.T(), err)
+	suite.otherUser = &models.User{Username: "otheruser", Password: hashedPassword}
+	assert.NoError(suite.T(), suite.helper.DB.Create(suite.otherUser).Error)
+
+	suite.otherToken, err = suite.helper.AuthService.GenerateToken(suite.otherUser)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *OwnershipTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+// requestAs performs a request authenticated with the given JWT
+func (suite *OwnershipTestSuite) requestAs(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *OwnershipTestSuite) TestJobsAreHiddenFromOtherUsers() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Private job")
+
+	w := suite.requestAs(suite.helper.TestToken, "GET", "/api/v1/transcription/"+job.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", "/api/v1/transcription/"+job.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "PUT", "/api/v1/transcription/"+job.ID+"/title", map[string]string{"title": "Hijacked"})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "DELETE", "/api/v1/transcription/"+job.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var response map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &response))
+	for _, item := range response["jobs"].([]interface{}) {
+		assert.NotEqual(suite.T(), job.ID, item.(map[string]interface{})["id"])
+	}
+
+	var stored models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", job.ID).First(&stored).Error)
+	assert.Equal(suite.T(), "Private job", *stored.Title)
+}
+
+func (suite *OwnershipTestSuite) TestNotesAreHiddenFromOtherUsers() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job with notes")
+	note := suite.helper.CreateTestNote(suite.T(), job.ID)
+
+	w := suite.requestAs(suite.otherToken, "GET", "/api/v1/transcription/"+job.ID+"/notes", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", "/api/v1/notes/"+note.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "PUT", "/api/v1/notes/"+note.ID, map[string]string{"content": "changed"})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	suite.requestAs(suite.otherToken, "DELETE", "/api/v1/notes/"+note.ID, nil)
+	var count int64
+	suite.helper.DB.Model(&models.Note{}).Where("id = ?", note.ID).Count(&count)
+	assert.Equal(suite.T(), int64(1), count)
+
+	w = suite.requestAs(suite.helper.TestToken, "GET", "/api/v1/notes/"+note.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *OwnershipTestSuite) TestProfilesAreScopedPerUser() {
+	profile := suite.helper.CreateTestProfile(suite.T(), "Owner profile", false)
+
+	w := suite.requestAs(suite.otherToken, "GET", "/api/v1/profiles/"+profile.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "DELETE", "/api/v1/profiles/"+profile.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	// Profile names only need to be unique per user
+	w = suite.requestAs(suite.otherToken, "POST", "/api/v1/profiles/", map[string]interface{}{
+		"name":       "Owner profile",
+		"parameters": map[string]interface{}{"model": "base"},
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var created models.TranscriptionProfile
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(suite.T(), suite.otherUser.ID, created.UserID)
+
+	w = suite.requestAs(suite.otherToken, "GET", "/api/v1/profiles/", nil)
+	var profiles []models.TranscriptionProfile
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &profiles))
+	if assert.Len(suite.T(), profiles, 1) {
+		assert.Equal(suite.T(), created.ID, profiles[0].ID)
+	}
+}
+
+func (suite *OwnershipTestSuite) TestChatSessionsAreHiddenFromOtherUsers() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Job with chat")
+	session := suite.helper.CreateTestChatSession(suite.T(), job.ID)
+
+	w := suite.requestAs(suite.otherToken, "GET", "/api/v1/chat/sessions/"+session.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "DELETE", "/api/v1/chat/sessions/"+session.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", "/api/v1/chat/transcriptions/"+job.ID+"/sessions", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var sessions []interface{}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &sessions))
+	assert.Len(suite.T(), sessions, 0)
+}
+
+func (suite *OwnershipTestSuite) TestLiveSessionsAreHiddenFromOtherUsers() {
+	session := &models.LiveTranscriptionSession{UserID: suite.helper.TestUser.ID, Status: models.LiveStatusActive}
+	assert.NoError(suite.T(), suite.helper.DB.Create(session).Error)
+	path := "/api/v1/transcription/live/sessions/" + session.ID
+
+	w := suite.requestAs(suite.helper.TestToken, "GET", path, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", path, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "POST", path+"/chunks", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "GET", path+"/stream", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "POST", path+"/finalize", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestAs(suite.otherToken, "POST", path+"/cancel", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	var stored models.LiveTranscriptionSession
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", session.ID).First(&stored).Error)
+	assert.Equal(suite.T(), models.LiveStatusActive, stored.Status)
+}
+
+func (suite *OwnershipTestSuite) TestAssignUnownedRecords() {
+	title := "Legacy job"
+	job := &models.TranscriptionJob{Title: &title, Status: models.StatusCompleted, AudioPath: "legacy.mp3"}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	assert.Equal(suite.T(), uint(0), job.UserID)
+	folder := &models.Folder{Name: "Legacy folder"}
+	tag := &models.Tag{Name: "legacy"}
+	voice := &models.VoiceProfile{Name: "Legacy voice"}
+	dictionary := &models.ReplacementDictionary{Name: "Legacy dictionary"}
+	session := &models.LiveTranscriptionSession{Status: models.LiveStatusCompleted}
+	for _, record := range []interface{}{folder, tag, voice, dictionary, session} {
+		assert.NoError(suite.T(), suite.helper.DB.Create(record).Error)
+	}
+
+	assert.NoError(suite.T(), database.AssignUnownedRecords(suite.helper.TestUser.ID))
+
+	var stored models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", job.ID).First(&stored).Error)
+	assert.Equal(suite.T(), suite.helper.TestUser.ID, stored.UserID)
+	for _, record := range []interface{}{folder, tag, voice, dictionary, session} {
+		assert.NoError(suite.T(), suite.helper.DB.First(record).Error)
+	}
+	for _, owner := range []uint{folder.UserID, tag.UserID, voice.UserID, dictionary.UserID, session.UserID} {
+		assert.Equal(suite.T(), suite.helper.TestUser.ID, owner)
+	}
+
+	// Rows that already have an owner keep it
+	other := &models.TranscriptionJob{UserID: suite.otherUser.ID, Title: &title, Status: models.StatusCompleted, AudioPath: "other.mp3"}
+	assert.NoError(suite.T(), suite.helper.DB.Create(other).Error)
+	assert.NoError(suite.T(), database.AssignUnownedRecords(suite.helper.TestUser.ID))
+	var storedOther models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", other.ID).First(&storedOther).Error)
+	assert.Equal(suite.T(), suite.otherUser.ID, storedOther.UserID)
+}
+
+func TestOwnershipTestSuite(t *testing.T) {
+	suite.Run(t, new(OwnershipTestSuite))
+}
@@ -2,14 +2,18 @@ package tests
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"sync"
 	"testing"
 	"time"
 
+	"synthezia/internal/database"
 	"synthezia/internal/models"
 	"synthezia/internal/queue"
+	"synthezia/internal/transcription"
+	_ "synthezia/internal/transcription/adapters" // Register adapters
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -407,6 +411,399 @@ func (suite *QueueTestSuite) TestConcurrentAccess() {
 	assert.NotNil(suite.T(), stats)
 }
 
+// queuedPositions maps job IDs to their estimated queue positions
+func queuedPositions(tq *queue.TaskQueue) map[string]int {
+	positions := make(map[string]int)
+	for _, info := range tq.QueuedJobs() {
+		positions[info.JobID] = info.Position
+	}
+	return positions
+}
+
+// Test that higher priority jobs are picked before older ones
+func (suite *QueueTestSuite) TestPriorityOrder() {
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{})
+
+	normal := suite.helper.CreateTestTranscriptionJob(suite.T(), "Long Podcast")
+	low := suite.helper.CreateTestTranscriptionJob(suite.T(), "Background Job")
+	suite.helper.DB.Model(low).Update("priority", models.PriorityLow)
+	urgent := suite.helper.CreateTestTranscriptionJob(suite.T(), "Voicemail")
+	suite.helper.DB.Model(urgent).Update("priority", models.PriorityUrgent)
+
+	for _, job := range []*models.TranscriptionJob{normal, low, urgent} {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		time.Sleep(time.Millisecond)
+	}
+
+	positions := queuedPositions(tq)
+	assert.Equal(suite.T(), 1, positions[urgent.ID])
+	assert.Equal(suite.T(), 2, positions[normal.ID])
+	assert.Equal(suite.T(), 3, positions[low.ID])
+
+	// Raising a waiting job's priority moves it up
+	suite.helper.DB.Model(low).Update("priority", models.MaxPriority)
+	assert.Equal(suite.T(), 1, tq.QueuePosition(low.ID))
+
+	stats := tq.GetQueueStats()
+	assert.Contains(suite.T(), stats, "pending_by_priority")
+	assert.Len(suite.T(), stats["queued_jobs"], 3)
+}
+
+// Test that users with the same priority take turns
+func (suite *QueueTestSuite) TestPerUserFairness() {
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{})
+
+	other := &models.User{Username: "queue-fairness-user", Password: "x"}
+	assert.NoError(suite.T(), suite.helper.DB.Create(other).Error)
+
+	first := suite.helper.CreateTestTranscriptionJob(suite.T(), "Batch 1")
+	second := suite.helper.CreateTestTranscriptionJob(suite.T(), "Batch 2")
+	otherJob := suite.helper.CreateTestTranscriptionJob(suite.T(), "Other User")
+	suite.helper.DB.Model(otherJob).Update("user_id", other.ID)
+
+	for _, job := range []*models.TranscriptionJob{first, second, otherJob} {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+		time.Sleep(time.Millisecond)
+	}
+
+	positions := queuedPositions(tq)
+	assert.Equal(suite.T(), 1, positions[first.ID])
+	assert.Equal(suite.T(), 2, positions[otherJob.ID])
+	assert.Equal(suite.T(), 3, positions[second.ID])
+}
+
+// Test that duplicate and stale entries are not run
+func (suite *QueueTestSuite) TestDuplicateAndStaleJobs() {
+	tq := queue.NewTaskQueue(1, &MockJobProcessor{})
+
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Queued Twice")
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	assert.Equal(suite.T(), 1, tq.GetQueueStats()["queue_size"])
+
+	// A job that is no longer pending drops out of the queue
+	suite.helper.DB.Model(job).Update("status", models.StatusCompleted)
+	assert.Equal(suite.T(), 0, tq.QueuePosition(job.ID))
+	assert.Empty(suite.T(), tq.QueuedJobs())
+}
+
+// scriptedProcessor fails attempts with the given errors in turn, then succeeds. Like the real
+// processor, it records an execution per attempt.
+type scriptedProcessor struct {
+	mu     sync.Mutex
+	errs   []error
+	params []models.WhisperXParams // parameters seen by each attempt
+}
+
+func (p *scriptedProcessor) ProcessJob(ctx context.Context, jobID string) error {
+	return p.ProcessJobWithProcess(ctx, jobID, nil)
+}
+
+func (p *scriptedProcessor) ProcessJobWithProcess(ctx context.Context, jobID string, registerProcess func(*exec.Cmd)) error {
+	var job models.TranscriptionJob
+	if err := database.DB.Where("id = ?", jobID).First(&job).Error; err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.params = append(p.params, job.Parameters)
+	var err error
+	if len(p.errs) > 0 {
+		err, p.errs = p.errs[0], p.errs[1:]
+	}
+	p.mu.Unlock()
+
+	status := models.StatusCompleted
+	if err != nil {
+		status = models.StatusFailed
+	}
+	database.DB.Create(&models.TranscriptionJobExecution{
+		TranscriptionJobID: jobID,
+		StartedAt:          time.Now(),
+		Status:             status,
+		Attempt:            job.Attempts,
+	})
+	return err
+}
+
+func (p *scriptedProcessor) attempts() []models.WhisperXParams {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]models.WhisperXParams(nil), p.params...)
+}
+
+func (suite *QueueTestSuite) TestClassifyFailure() {
+	cases := []struct {
+		message string
+		class   string
+	}{
+		{"single-track processing failed: RuntimeError: CUDA error: out of memory", queue.FailureOutOfMemory},
+		{"torch.OutOfMemoryError: tried to allocate 2.00 GiB", queue.FailureOutOfMemory},
+		{"WhisperX execution failed: signal: killed", queue.FailureProcessKilled},
+		{"huggingface_hub.errors.LocalEntryNotFoundError: cannot find the requested files", queue.FailureModelDownload},
+		{"Max retries exceeded with url: /pyannote/speaker-diarization-3.1", queue.FailureModelDownload},
+		{"input.xyz: Invalid data found when processing input", queue.FailureUnsupportedAudio},
+		{"whisperx: error: argument --compute_type: invalid choice: 'int3'", queue.FailureInvalidParameters},
+		{"something unexpected", queue.FailureUnknown},
+	}
+	for _, tc := range cases {
+		class := queue.ClassifyFailure(errors.New(tc.message))
+		assert.Equal(suite.T(), tc.class, class, tc.message)
+	}
+
+	assert.True(suite.T(), queue.IsTransientFailure(queue.FailureOutOfMemory))
+	assert.True(suite.T(), queue.IsTransientFailure(queue.FailureModelDownload))
+	assert.False(suite.T(), queue.IsTransientFailure(queue.FailureUnsupportedAudio))
+	assert.False(suite.T(), queue.IsTransientFailure(queue.FailureUnknown))
+}
+
+func (suite *QueueTestSuite) TestRetryBackoffAndMemoryReduction() {
+	policy := queue.RetryPolicy{MaxAttempts: 5, Backoff: 10 * time.Second, MaxBackoff: 30 * time.Second}
+	assert.Equal(suite.T(), 10*time.Second, policy.Delay(1))
+	assert.Equal(suite.T(), 20*time.Second, policy.Delay(2))
+	assert.Equal(suite.T(), 30*time.Second, policy.Delay(3))
+
+	params, ok := queue.ReduceMemory(models.WhisperXParams{BatchSize: 8, ComputeType: "float16"})
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), 4, params.BatchSize)
+
+	params, ok = queue.ReduceMemory(models.WhisperXParams{BatchSize: 1, ComputeType: "float16"})
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "int8", params.ComputeType)
+
+	_, ok = queue.ReduceMemory(models.WhisperXParams{BatchSize: 1, ComputeType: "int8"})
+	assert.False(suite.T(), ok)
+}
+
+// Test that transient failures are retried with lighter settings after an OOM
+func (suite *QueueTestSuite) TestTransientFailureRetried() {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Retried Job")
+	suite.helper.DB.Model(job).Update("batch_size", 8)
+
+	processor := &scriptedProcessor{errs: []error{errors.New("RuntimeError: CUDA error: out of memory")}}
+	tq := queue.NewTaskQueue(1, processor)
+	tq.SetRetryPolicy(queue.RetryPolicy{MaxAttempts: 3, Backoff: 20 * time.Millisecond, OOMFallback: true})
+	tq.Start()
+	defer tq.Stop()
+
+	assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	time.Sleep(300 * time.Millisecond)
+
+	stored, err := tq.GetJobStatus(job.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusCompleted, stored.Status)
+	assert.Equal(suite.T(), 2, stored.Attempts)
+
+	params := processor.attempts()
+	if assert.Len(suite.T(), params, 2) {
+		assert.Equal(suite.T(), 8, params[0].BatchSize)
+		assert.Equal(suite.T(), 4, params[1].BatchSize)
+	}
+
+	var executions []models.TranscriptionJobExecution
+	suite.helper.DB.Where("transcription_job_id = ?", job.ID).Order("attempt ASC").Find(&executions)
+	if assert.Len(suite.T(), executions, 2) {
+		assert.Equal(suite.T(), 1, executions[0].Attempt)
+		assert.Equal(suite.T(), queue.FailureOutOfMemory, *executions[0].FailureClass)
+		assert.Equal(suite.T(), 2, executions[1].Attempt)
+		assert.Nil(suite.T(), executions[1].FailureClass)
+	}
+}
+
+// Test that permanent failures and exhausted attempts fail the job
+func (suite *QueueTestSuite) TestFailureNotRetried() {
+	permanent := suite.helper.CreateTestTranscriptionJob(suite.T(), "Bad Audio")
+	exhausted := suite.helper.CreateTestTranscriptionJob(suite.T(), "Keeps Dying")
+
+	processor := &scriptedProcessor{}
+	tq := queue.NewTaskQueue(1, processor)
+	tq.SetRetryPolicy(queue.RetryPolicy{MaxAttempts: 2, Backoff: 10 * time.Millisecond})
+	tq.Start()
+	defer tq.Stop()
+
+	processor.mu.Lock()
+	processor.errs = []error{errors.New("Invalid data found when processing input")}
+	processor.mu.Unlock()
+	assert.NoError(suite.T(), tq.EnqueueJob(permanent.ID))
+	time.Sleep(100 * time.Millisecond)
+
+	stored, _ := tq.GetJobStatus(permanent.ID)
+	assert.Equal(suite.T(), models.StatusFailed, stored.Status)
+	assert.Equal(suite.T(), 1, stored.Attempts)
+
+	processor.mu.Lock()
+	processor.errs = []error{errors.New("signal: killed"), errors.New("signal: killed")}
+	processor.mu.Unlock()
+	assert.NoError(suite.T(), tq.EnqueueJob(exhausted.ID))
+	time.Sleep(200 * time.Millisecond)
+
+	stored, _ = tq.GetJobStatus(exhausted.ID)
+	assert.Equal(suite.T(), models.StatusFailed, stored.Status)
+	assert.Equal(suite.T(), 2, stored.Attempts)
+	assert.Contains(suite.T(), *stored.ErrorMessage, "signal: killed")
+}
+
+// Test that a failed re-diarization returns the job to its earlier status instead of completing it
+func (suite *QueueTestSuite) TestFailedRediarizeRestoresStatus() {
+	failed := suite.helper.CreateTestTranscriptionJob(suite.T(), "Failed Before Rediarization")
+	completed := suite.helper.CreateTestTranscriptionJob(suite.T(), "Completed Before Rediarization")
+	for _, job := range []*models.TranscriptionJob{failed, completed} {
+		previous := models.StatusFailed
+		if job == completed {
+			previous = models.StatusCompleted
+		}
+		suite.helper.DB.Model(job).Updates(map[string]interface{}{
+			"job_type":        models.JobTypeRediarize,
+			"status":          models.StatusPending,
+			"previous_status": previous,
+		})
+	}
+
+	processor := &scriptedProcessor{}
+	tq := queue.NewTaskQueue(1, processor)
+	tq.SetRetryPolicy(queue.RetryPolicy{MaxAttempts: 1})
+	tq.Start()
+	defer tq.Stop()
+
+	processor.mu.Lock()
+	processor.errs = []error{
+		fmt.Errorf("%w: %w", queue.ErrRediarizeFailed, errors.New("diarization produced no segments")),
+		fmt.Errorf("%w: %w", queue.ErrRediarizeFailed, errors.New("diarization produced no segments")),
+	}
+	processor.mu.Unlock()
+	assert.NoError(suite.T(), tq.EnqueueJob(failed.ID))
+	assert.NoError(suite.T(), tq.EnqueueJob(completed.ID))
+	time.Sleep(200 * time.Millisecond)
+
+	stored, err := tq.GetJobStatus(failed.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusFailed, stored.Status)
+	if assert.NotNil(suite.T(), stored.ErrorMessage) {
+		assert.Contains(suite.T(), *stored.ErrorMessage, "rediarization failed")
+	}
+
+	stored, err = tq.GetJobStatus(completed.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.StatusCompleted, stored.Status)
+	if assert.NotNil(suite.T(), stored.ErrorMessage) {
+		assert.Contains(suite.T(), *stored.ErrorMessage, "diarization produced no segments")
+	}
+}
+
+// sizedProcessor holds every job until released and sizes jobs by model name
+type sizedProcessor struct {
+	release chan struct{}
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+func (p *sizedProcessor) EstimateResources(params models.WhisperXParams) (int, bool) {
+	if params.Model == "large-v3" {
+		return 10240, false
+	}
+	return 1024, false
+}
+
+func (p *sizedProcessor) ProcessJob(ctx context.Context, jobID string) error {
+	return p.ProcessJobWithProcess(ctx, jobID, nil)
+}
+
+func (p *sizedProcessor) ProcessJobWithProcess(ctx context.Context, jobID string, registerProcess func(*exec.Cmd)) error {
+	p.mu.Lock()
+	p.running++
+	if p.running > p.peak {
+		p.peak = p.running
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.release:
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	p.running--
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *sizedProcessor) counts() (running, peak int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.running, p.peak
+}
+
+// sizedJobs creates pending jobs using the given Whisper model
+func (suite *QueueTestSuite) sizedJobs(model string, count int) []*models.TranscriptionJob {
+	jobs := make([]*models.TranscriptionJob, count)
+	for i := range jobs {
+		jobs[i] = suite.helper.CreateTestTranscriptionJob(suite.T(), fmt.Sprintf("%s job %d", model, i))
+		suite.helper.DB.Model(jobs[i]).Update("model", model)
+	}
+	return jobs
+}
+
+// Test that large models are admitted one at a time while small ones share the budget
+func (suite *QueueTestSuite) TestResourceAdmission() {
+	processor := &sizedProcessor{release: make(chan struct{})}
+	tq := queue.NewTaskQueue(3, processor)
+	tq.SetResourceBudget(queue.ResourceBudget{RAMMB: 12288})
+	tq.Start()
+	defer tq.Stop()
+
+	for _, job := range suite.sizedJobs("large-v3", 2) {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	running, _ := processor.counts()
+	assert.Equal(suite.T(), 1, running)
+	resources := tq.GetQueueStats()["resources"].(map[string]interface{})
+	assert.Equal(suite.T(), 10240, resources["ram_in_use_mb"])
+
+	// Finishing the first large job lets the second one in
+	processor.release <- struct{}{}
+	time.Sleep(100 * time.Millisecond)
+	running, peak := processor.counts()
+	assert.Equal(suite.T(), 1, running)
+	assert.Equal(suite.T(), 1, peak)
+	processor.release <- struct{}{}
+
+	for _, job := range suite.sizedJobs("tiny", 3) {
+		assert.NoError(suite.T(), tq.EnqueueJob(job.ID))
+	}
+	time.Sleep(100 * time.Millisecond)
+	running, _ = processor.counts()
+	assert.Equal(suite.T(), 3, running)
+	close(processor.release)
+}
+
+func (suite *QueueTestSuite) TestResourceEstimates() {
+	processor := transcription.NewUnifiedJobProcessor()
+	params := models.WhisperXParams{ModelFamily: "whisper", Model: "large-v3", Device: "cpu", ComputeType: "float16"}
+
+	memory, gpu := processor.EstimateResources(params)
+	assert.Equal(suite.T(), 10240, memory)
+	assert.False(suite.T(), gpu)
+
+	params.ComputeType = "int8"
+	memory, _ = processor.EstimateResources(params)
+	assert.Equal(suite.T(), 5120, memory)
+
+	params.Model = "tiny.en"
+	memory, _ = processor.EstimateResources(params)
+	assert.Equal(suite.T(), 512, memory)
+
+	// WhisperX diarizes with the transcription model still loaded
+	params = models.WhisperXParams{ModelFamily: "whisper", Model: "small", Device: "cpu", Diarize: true, DiarizeModel: "pyannote"}
+	memory, _ = processor.EstimateResources(params)
+	assert.Equal(suite.T(), 2048+2048, memory)
+
+	memory, _ = processor.EstimateResources(models.WhisperXParams{ModelFamily: "nvidia_canary", Device: "cpu"})
+	assert.Equal(suite.T(), 8192, memory)
+}
+
 func TestQueueTestSuite(t *testing.T) {
 	suite.Run(t, new(QueueTestSuite))
 }
@@ -0,0 +1,59 @@
+//go:build linux
+// +build linux
+
+package tests
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"synthezia/internal/recovery"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startProcessGroup starts a command as the leader of a new process group
+func startProcessGroup(name string, args ...string) (*exec.Cmd, error) {
+	cmd := exec.Command(name, args...)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	return cmd, cmd.Start()
+}
+
+func (suite *RecoveryTestSuite) TestKillsLeftoverProcessGroup() {
+	// A stand-in for a model process: its command line names python
+	script := filepath.Join(suite.workDir, "python")
+	os.WriteFile(script, []byte("#!/bin/sh\nsleep 60\n"), 0755)
+	modelCmd, err := startProcessGroup(script)
+	assert.NoError(suite.T(), err)
+
+	// An unrelated process that reused a recorded PID must be left alone
+	otherCmd, err := startProcessGroup("sleep", "60")
+	assert.NoError(suite.T(), err)
+	defer syscall.Kill(-otherCmd.Process.Pid, syscall.SIGKILL)
+
+	modelPID := modelCmd.Process.Pid
+	otherPID := otherCmd.Process.Pid
+	job := suite.processingJob(&modelPID)
+	other := suite.processingJob(&otherPID)
+
+	report, err := recovery.Run(suite.opts)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), report.KilledProcesses, modelPID)
+	assert.NotContains(suite.T(), report.KilledProcesses, otherPID)
+
+	exited := make(chan error, 1)
+	go func() { exited <- modelCmd.Wait() }()
+	select {
+	case <-exited:
+	case <-time.After(5 * time.Second):
+		syscall.Kill(-modelPID, syscall.SIGKILL)
+		suite.T().Fatal("leftover model process was not killed")
+	}
+
+	assert.Contains(suite.T(), *suite.executions(job.ID)[0].RecoveryDetails, "killed process group")
+	assert.NotContains(suite.T(), *suite.executions(other.ID)[0].RecoveryDetails, "killed process group")
+	assert.NoError(suite.T(), syscall.Kill(otherPID, 0), "unrelated process should still be running")
+}
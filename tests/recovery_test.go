@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"synthezia/internal/config"
+	"synthezia/internal/models"
+	"synthezia/internal/recovery"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecoveryTestSuite struct {
+	suite.Suite
+	helper  *TestHelper
+	workDir string
+	opts    recovery.Options
+}
+
+func (suite *RecoveryTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "recovery_test.db")
+}
+
+func (suite *RecoveryTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *RecoveryTestSuite) SetupTest() {
+	suite.workDir = suite.T().TempDir()
+	suite.opts = recovery.Options{
+		Policy:      config.RecoveryPolicyRequeue,
+		MaxRequeues: 2,
+		UploadDir:   filepath.Join(suite.workDir, "uploads"),
+		TempDir:     filepath.Join(suite.workDir, "temp"),
+	}
+	os.MkdirAll(suite.opts.UploadDir, 0755)
+	os.MkdirAll(suite.opts.TempDir, 0755)
+}
+
+// processingJob creates a job left in processing, with an open execution when pid is non-nil
+func (suite *RecoveryTestSuite) processingJob(pid *int) *models.TranscriptionJob {
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Interrupted")
+	suite.helper.DB.Model(job).Update("status", models.StatusProcessing)
+
+	execution := &models.TranscriptionJobExecution{
+		TranscriptionJobID: job.ID,
+		StartedAt:          time.Now().Add(-time.Minute),
+		Status:             models.StatusProcessing,
+		ProcessPID:         pid,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(execution).Error)
+	return job
+}
+
+func (suite *RecoveryTestSuite) reload(job *models.TranscriptionJob) models.TranscriptionJob {
+	var stored models.TranscriptionJob
+	assert.NoError(suite.T(), suite.helper.DB.Where("id = ?", job.ID).First(&stored).Error)
+	return stored
+}
+
+func (suite *RecoveryTestSuite) executions(jobID string) []models.TranscriptionJobExecution {
+	var executions []models.TranscriptionJobExecution
+	suite.helper.DB.Where("transcription_job_id = ?", jobID).Order("started_at ASC").Find(&executions)
+	return executions
+}
+
+func (suite *RecoveryTestSuite) TestRequeuesInterruptedJob() {
+	job := suite.processingJob(nil)
+	jobTempDir := filepath.Join(suite.opts.TempDir, "whisperx", job.ID)
+	os.MkdirAll(jobTempDir, 0755)
+	os.WriteFile(filepath.Join(jobTempDir, "partial.json"), []byte("{"), 0644)
+
+	report, err := recovery.Run(suite.opts)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), report.Requeued, job.ID)
+
+	stored := suite.reload(job)
+	assert.Equal(suite.T(), models.StatusPending, stored.Status)
+
+	executions := suite.executions(job.ID)
+	if assert.Len(suite.T(), executions, 1) {
+		assert.Equal(suite.T(), models.StatusFailed, executions[0].Status)
+		assert.Equal(suite.T(), recovery.ActionRequeued, *executions[0].RecoveryAction)
+		assert.Equal(suite.T(), recovery.InterruptedMessage, *executions[0].ErrorMessage)
+		assert.Contains(suite.T(), *executions[0].RecoveryDetails, "removed "+jobTempDir)
+		assert.NotNil(suite.T(), executions[0].CompletedAt)
+	}
+
+	_, err = os.Stat(jobTempDir)
+	assert.True(suite.T(), os.IsNotExist(err))
+}
+
+func (suite *RecoveryTestSuite) TestFailsAfterMaxRequeues() {
+	job := suite.processingJob(nil)
+	action := recovery.ActionRequeued
+	for i := 0; i < 2; i++ {
+		suite.helper.DB.Create(&models.TranscriptionJobExecution{
+			TranscriptionJobID: job.ID,
+			StartedAt:          time.Now().Add(-time.Hour),
+			Status:             models.StatusFailed,
+			RecoveryAction:     &action,
+		})
+	}
+
+	report, err := recovery.Run(suite.opts)
+	assert.NoError(suite.T(), err)
+	assert.Contains(suite.T(), report.Failed, job.ID)
+
+	stored := suite.reload(job)
+	assert.Equal(suite.T(), models.StatusFailed, stored.Status)
+	assert.Equal(suite.T(), recovery.InterruptedMessage, *stored.ErrorMessage)
+}
+
+func (suite *RecoveryTestSuite) TestFailPolicy() {
+	suite.opts.Policy = config.RecoveryPolicyFail
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "No execution yet")
+	suite.helper.DB.Model(job).Update("status", models.StatusProcessing)
+
+	_, err := recovery.Run(suite.opts)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), models.StatusFailed, suite.reload(job).Status)
+
+	// The crash happened before an execution existed, so one is recorded
+	executions := suite.executions(job.ID)
+	if assert.Len(suite.T(), executions, 1) {
+		assert.Equal(suite.T(), recovery.ActionFailed, *executions[0].RecoveryAction)
+		assert.Contains(suite.T(), *executions[0].RecoveryDetails, "failed by recovery policy")
+	}
+}
+
+func (suite *RecoveryTestSuite) TestRemovesTrackJobsAndStaleFiles() {
+	parent := suite.processingJob(nil)
+	track := &models.TranscriptionJob{
+		ID:        "track_" + parent.ID + "_speaker1.wav_abcd1234",
+		UserID:    suite.helper.TestUser.ID,
+		AudioPath: "test/track.wav",
+		Status:    models.StatusProcessing,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(track).Error)
+
+	staleFiles := []string{
+		filepath.Join(suite.opts.UploadDir, "abc_temp.mp4"),
+		filepath.Join(suite.opts.UploadDir, "def.mp3.part"),
+		filepath.Join(suite.opts.UploadDir, "openai", "upload.wav"),
+	}
+	kept := filepath.Join(suite.opts.UploadDir, "finished.mp3")
+	for _, path := range append(staleFiles, kept) {
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("data"), 0644)
+	}
+
+	_, err := recovery.Run(suite.opts)
+	assert.NoError(suite.T(), err)
+
+	var count int64
+	suite.helper.DB.Model(&models.TranscriptionJob{}).Where("id = ?", track.ID).Count(&count)
+	assert.Equal(suite.T(), int64(0), count)
+
+	for _, path := range staleFiles {
+		_, err := os.Stat(path)
+		assert.True(suite.T(), os.IsNotExist(err), path)
+	}
+	_, err = os.Stat(kept)
+	assert.NoError(suite.T(), err)
+}
+
+func TestRecoveryTestSuite(t *testing.T) {
+	suite.Run(t, new(RecoveryTestSuite))
+}
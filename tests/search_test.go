@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+	"synthezia/internal/search"
+	"synthezia/internal/transcription/interfaces"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SearchTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *SearchTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "search_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *SearchTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *SearchTestSuite) SetupTest() {
+	suite.helper.DB.Exec("DELETE FROM " + search.TableName)
+	suite.helper.DB.Where("1 = 1").Delete(&models.SpeakerMapping{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+// createIndexedJob stores a completed job and indexes its segments
+func (suite *SearchTestSuite) createIndexedJob(title string, segments ...interfaces.TranscriptSegment) *models.TranscriptionJob {
+	result := &interfaces.TranscriptResult{Segments: segments, Language: "en"}
+	data, _ := json.Marshal(result)
+	transcript := string(data)
+
+	job := &models.TranscriptionJob{
+		UserID:     suite.helper.TestUser.ID,
+		Title:      &title,
+		Status:     models.StatusCompleted,
+		AudioPath:  "test/path/audio.mp3",
+		Transcript: &transcript,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	assert.NoError(suite.T(), search.IndexTranscript(job.ID, result))
+	return job
+}
+
+func (suite *SearchTestSuite) searchRequest(query string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	req, _ := http.NewRequest("GET", "/api/v1/transcription/search?q="+url.QueryEscape(query), nil)
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func (suite *SearchTestSuite) TestBuildMatchQuery() {
+	assert.Equal(suite.T(), `"quarterly" "churn"`, search.BuildMatchQuery("quarterly churn"))
+	assert.Equal(suite.T(), `"quarterly churn"`, search.BuildMatchQuery(`"quarterly churn"`))
+	assert.Equal(suite.T(), `"churn"*`, search.BuildMatchQuery("churn*"))
+	assert.Equal(suite.T(), `"OR" "NEAR(a"`, search.BuildMatchQuery("OR NEAR(a"))
+	assert.Equal(suite.T(), "", search.BuildMatchQuery(`  "" * `))
+}
+
+func (suite *SearchTestSuite) TestSearchReturnsSnippetsAndTimes() {
+	speaker := "SPEAKER_00"
+	job := suite.createIndexedJob("Board meeting",
+		interfaces.TranscriptSegment{Start: 0, End: 4.5, Text: "Welcome everyone to the board meeting."},
+		interfaces.TranscriptSegment{Start: 62.25, End: 70, Text: "Our quarterly churn went down to three percent.", Speaker: &speaker},
+	)
+	suite.createIndexedJob("Standup",
+		interfaces.TranscriptSegment{Start: 0, End: 3, Text: "Nothing about retention here."},
+	)
+	assert.NoError(suite.T(), suite.helper.DB.Create(&models.SpeakerMapping{
+		TranscriptionJobID: job.ID,
+		OriginalSpeaker:    speaker,
+		CustomName:         "Dana",
+	}).Error)
+
+	results, total, err := search.Search("quarterly churn", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), int64(1), total)
+	if assert.Len(suite.T(), results, 1) {
+		assert.Equal(suite.T(), job.ID, results[0].JobID)
+		assert.Equal(suite.T(), "Board meeting", *results[0].Title)
+		if assert.Len(suite.T(), results[0].Hits, 1) {
+			hit := results[0].Hits[0]
+			assert.Equal(suite.T(), 1, hit.SegmentIndex)
+			assert.Equal(suite.T(), 62.25, hit.Start)
+			assert.Equal(suite.T(), "Dana", *hit.Speaker)
+			assert.Contains(suite.T(), hit.Snippet, "<mark>quarterly</mark> <mark>churn</mark>")
+		}
+	}
+}
+
+func (suite *SearchTestSuite) TestSearchPhraseAndPrefix() {
+	suite.createIndexedJob("Phrase",
+		interfaces.TranscriptSegment{Start: 1, End: 2, Text: "churn was quarterly"},
+	)
+
+	results, _, err := search.Search(`"quarterly churn"`, search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 0)
+
+	results, _, err = search.Search("quart*", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+}
+
+func (suite *SearchTestSuite) TestReindexReplacesSegments() {
+	job := suite.createIndexedJob("Reindex",
+		interfaces.TranscriptSegment{Start: 0, End: 1, Text: "original wording"},
+	)
+	assert.NoError(suite.T(), search.IndexTranscript(job.ID, &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Start: 0, End: 1, Text: "corrected wording"}},
+	}))
+
+	results, _, err := search.Search("original", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 0)
+
+	results, _, err = search.Search("corrected", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+
+	assert.NoError(suite.T(), search.RemoveJob(job.ID))
+	results, _, err = search.Search("corrected", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 0)
+}
+
+func (suite *SearchTestSuite) TestBackfillIndex() {
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Start: 5, End: 6, Text: "legacy transcript content"}},
+	}
+	data, _ := json.Marshal(result)
+	transcript := string(data)
+	title := "Legacy"
+	job := &models.TranscriptionJob{Title: &title, Status: models.StatusCompleted, AudioPath: "a.mp3", Transcript: &transcript}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+
+	indexed, err := search.BackfillIndex()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 1, indexed)
+
+	// Already indexed jobs are skipped on the next run
+	indexed, err = search.BackfillIndex()
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 0, indexed)
+
+	results, _, err := search.Search("legacy", search.Options{})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), results, 1)
+}
+
+func (suite *SearchTestSuite) TestSearchEndpoint() {
+	suite.createIndexedJob("Endpoint",
+		interfaces.TranscriptSegment{Start: 12.5, End: 15, Text: "Let's talk about quarterly churn."},
+	)
+
+	w, body := suite.searchRequest("churn")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	results := body["results"].([]interface{})
+	assert.Len(suite.T(), results, 1)
+	hits := results[0].(map[string]interface{})["hits"].([]interface{})
+	assert.Equal(suite.T(), 12.5, hits[0].(map[string]interface{})["start"])
+
+	w, _ = suite.searchRequest("   ")
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestSearchTestSuite(t *testing.T) {
+	suite.Run(t, new(SearchTestSuite))
+}
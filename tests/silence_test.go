@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"context"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/transcription/pipeline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type SilenceRemovalTestSuite struct {
+	suite.Suite
+}
+
+const silenceDetectOutput = `Input #0, wav, from 'talk.wav':
+  Duration: 00:01:00.00, bitrate: 256 kb/s
+[silencedetect @ 0x5581] silence_start: 0
+[silencedetect @ 0x5581] silence_end: 3.5 | silence_duration: 3.5
+[silencedetect @ 0x5581] silence_start: 10.25
+[silencedetect @ 0x5581] silence_end: 20.25 | silence_duration: 10
+[silencedetect @ 0x5581] silence_start: 55
+size=N/A time=00:01:00.00 bitrate=N/A speed= 800x
+`
+
+func (suite *SilenceRemovalTestSuite) TestParseSilenceDetect() {
+	silences := pipeline.ParseSilenceDetect([]byte(silenceDetectOutput))
+	if assert.Len(suite.T(), silences, 3) {
+		assert.Equal(suite.T(), pipeline.Silence{Start: 0, End: 3.5}, silences[0])
+		assert.Equal(suite.T(), pipeline.Silence{Start: 10.25, End: 20.25}, silences[1])
+		assert.Equal(suite.T(), 55.0, silences[2].Start)
+		assert.True(suite.T(), math.IsInf(silences[2].End, 1), "silence running to the end is open")
+	}
+}
+
+func (suite *SilenceRemovalTestSuite) TestBuildOffsetMap() {
+	silences := pipeline.ParseSilenceDetect([]byte(silenceDetectOutput))
+	offsets := pipeline.BuildOffsetMap(silences, 60, 0.25)
+
+	// Leading and trailing silence go entirely, the middle one keeps 0.25s on each side
+	assert.Equal(suite.T(), []pipeline.KeptSpan{
+		{CompactStart: 0, OriginalStart: 3.25, Duration: 7.25},
+		{CompactStart: 7.25, OriginalStart: 20, Duration: 35.25},
+	}, offsets.Spans)
+	assert.InDelta(suite.T(), 60-42.5, offsets.RemovedSeconds(), 0.001)
+
+	// Silences shorter than the padding on both sides leave nothing to cut
+	assert.Zero(suite.T(), pipeline.BuildOffsetMap([]pipeline.Silence{{Start: 5, End: 5.4}}, 0, 0.25).RemovedSeconds())
+}
+
+func (suite *SilenceRemovalTestSuite) TestToOriginal() {
+	offsets := pipeline.OffsetMap{
+		Spans: []pipeline.KeptSpan{
+			{CompactStart: 0, OriginalStart: 3.25, Duration: 7.25},
+			{CompactStart: 7.25, OriginalStart: 20, Duration: 35.25},
+		},
+		OriginalDuration: 60,
+	}
+	assert.InDelta(suite.T(), 3.25, offsets.ToOriginal(0), 0.001)
+	assert.InDelta(suite.T(), 8.25, offsets.ToOriginal(5), 0.001)
+	assert.InDelta(suite.T(), 20, offsets.ToOriginal(7.25), 0.001)
+	assert.InDelta(suite.T(), 30, offsets.ToOriginal(17.25), 0.001)
+	assert.InDelta(suite.T(), 3, pipeline.OffsetMap{}.ToOriginal(3), 0.001)
+}
+
+func (suite *SilenceRemovalTestSuite) TestRemapPostprocessor() {
+	offsets := pipeline.OffsetMap{
+		Spans: []pipeline.KeptSpan{
+			{CompactStart: 0, OriginalStart: 3.25, Duration: 7.25},
+			{CompactStart: 7.25, OriginalStart: 20, Duration: 35.25},
+		},
+		OriginalDuration: 60,
+	}
+	params := map[string]interface{}{pipeline.OffsetMapParam: offsets}
+	remap := &pipeline.TimestampRemapPostprocessor{}
+	assert.True(suite.T(), remap.AppliesTo(interfaces.ModelCapabilities{}, params))
+	assert.False(suite.T(), remap.AppliesTo(interfaces.ModelCapabilities{}, map[string]interface{}{}))
+
+	transcript := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 1, End: 7.25, Text: "before the pause"},
+			{Start: 7.25, End: 9, Text: "after the pause"},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 6, End: 7.25, Word: "pause"},
+			{Start: 8, End: 9, Word: "after"},
+		},
+	}
+	result, err := remap.ProcessTranscript(context.Background(), transcript, params)
+	assert.NoError(suite.T(), err)
+
+	// A segment ending exactly at a cut ends where its audio did, not after the silence
+	assert.InDelta(suite.T(), 4.25, result.Segments[0].Start, 0.001)
+	assert.InDelta(suite.T(), 10.5, result.Segments[0].End, 0.001)
+	assert.InDelta(suite.T(), 20, result.Segments[1].Start, 0.001)
+	assert.InDelta(suite.T(), 21.75, result.Segments[1].End, 0.001)
+	assert.InDelta(suite.T(), 9.25, result.WordSegments[0].Start, 0.001)
+	assert.InDelta(suite.T(), 10.5, result.WordSegments[0].End, 0.001)
+	assert.InDelta(suite.T(), 20.75, result.WordSegments[1].Start, 0.001)
+	assert.Equal(suite.T(), "17.500", result.Metadata["silence_removed_seconds"])
+
+	diarization := &interfaces.DiarizationResult{
+		Segments: []interfaces.DiarizationSegment{{Start: 8, End: 12, Speaker: "SPEAKER_00"}},
+	}
+	diarized, err := remap.ProcessDiarization(context.Background(), diarization, params)
+	assert.NoError(suite.T(), err)
+	assert.InDelta(suite.T(), 20.75, diarized.Segments[0].Start, 0.001)
+	assert.InDelta(suite.T(), 24.75, diarized.Segments[0].End, 0.001)
+}
+
+func (suite *SilenceRemovalTestSuite) TestCompactsAudio() {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		suite.T().Skip("ffmpeg not installed")
+	}
+
+	// 2s tone, 5s silence, 2s tone
+	path := filepath.Join(suite.T().TempDir(), "speech.wav")
+	cmd := exec.Command("ffmpeg", "-y", "-f", "lavfi", "-i",
+		"sine=frequency=440:duration=2,apad=pad_dur=5[a];sine=frequency=440:duration=2[b];[a][b]concat=v=0:a=1",
+		"-ar", "16000", "-ac", "1", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		suite.T().Skipf("could not generate test audio: %s", output)
+	}
+
+	vad := &pipeline.VoiceActivityDetectionPreprocessor{}
+	input := interfaces.AudioInput{FilePath: path, Format: "wav", SampleRate: 16000, Channels: 1, Duration: 9 * time.Second}
+	compacted, err := vad.Process(context.Background(), input)
+	assert.NoError(suite.T(), err)
+	defer os.Remove(compacted.TempFilePath)
+
+	offsets, ok := pipeline.OffsetMapFromMetadata(compacted.Metadata)
+	if assert.True(suite.T(), ok) && assert.Len(suite.T(), offsets.Spans, 2) {
+		assert.InDelta(suite.T(), 4.5, offsets.RemovedSeconds(), 0.1)
+		assert.InDelta(suite.T(), 6.75, offsets.Spans[1].OriginalStart, 0.1)
+	}
+	assert.NotEqual(suite.T(), path, compacted.FilePath)
+}
+
+func TestSilenceRemovalTestSuite(t *testing.T) {
+	suite.Run(t, new(SilenceRemovalTestSuite))
+}
@@ -9,6 +9,7 @@ import (
 	"synthezia/internal/config"
 	"synthezia/internal/database"
 	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -41,6 +42,12 @@ func NewTestHelper(t *testing.T, dbName string) *TestHelper {
 		WhisperXEnv:  "test_whisperx_env",
 	}
 
+	// Run against PostgreSQL when a test database is configured
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		cfg.DatabaseURL = url
+		resetPostgresSchema(t, cfg)
+	}
+
 	// Initialize test database
 	if err := database.Initialize(cfg); err != nil {
 		t.Fatal("Failed to initialize test database:", err)
@@ -67,6 +74,22 @@ func NewTestHelper(t *testing.T, dbName string) *TestHelper {
 	return helper
 }
 
+// resetPostgresSchema drops every table of the PostgreSQL test database, so that each helper
+// starts from an empty database like it does with a new SQLite file
+func resetPostgresSchema(t *testing.T, cfg *config.Config) {
+	if err := database.Open(cfg); err != nil {
+		t.Fatal("Failed to connect to test database:", err)
+	}
+	defer database.Close()
+
+	if err := database.DB.Exec("DROP SCHEMA public CASCADE").Error; err != nil {
+		t.Fatal("Failed to reset test database:", err)
+	}
+	if err := database.DB.Exec("CREATE SCHEMA public").Error; err != nil {
+		t.Fatal("Failed to reset test database:", err)
+	}
+}
+
 // GetDB returns the current database instance
 func (h *TestHelper) GetDB() *gorm.DB {
 	return h.DB
@@ -88,33 +111,43 @@ func (h *TestHelper) createTestCredentials(t *testing.T) {
 	user := models.User{
 		Username: "testuser",
 		Password: hashedPassword,
+		Role:     models.RoleAdmin, // Like the first registered user
 	}
 
 	result := h.DB.Create(&user)
 	assert.NoError(t, result.Error)
 	h.TestUser = &user
 
+	// Registration hands records created before the first user over to them
+	assert.NoError(t, database.AssignUnownedRecords(user.ID))
+
 	// Generate JWT token
 	token, err := h.AuthService.GenerateToken(&user)
 	assert.NoError(t, err)
 	h.TestToken = token
 
 	// Create test API key
+	rawKey := "test-api-key-" + strings.ReplaceAll(t.Name(), "/", "_")
 	apiKey := models.APIKey{
-		Key:      "test-api-key-" + strings.ReplaceAll(t.Name(), "/", "_"),
-		Name:     "Test API Key for " + strings.ReplaceAll(t.Name(), "/", "_"),
-		IsActive: true,
+		UserID:    user.ID,
+		Key:       auth.HashAPIKey(rawKey),
+		KeyPrefix: auth.APIKeyPrefix(rawKey),
+		Hashed:    true,
+		Scopes:    []string{models.ScopeTranscriptsRead, models.ScopeTranscribeWrite, models.ScopeChat, models.ScopeAdmin},
+		Name:      "Test API Key for " + strings.ReplaceAll(t.Name(), "/", "_"),
+		IsActive:  true,
 	}
 
 	result = h.DB.Create(&apiKey)
 	assert.NoError(t, result.Error)
-	h.TestAPIKey = apiKey.Key
+	h.TestAPIKey = rawKey
 }
 
 // CreateTestTranscriptionJob creates a test transcription job
 func (h *TestHelper) CreateTestTranscriptionJob(t *testing.T, title string) *models.TranscriptionJob {
 	// Let GORM assign a unique UUID via model hook to avoid ID collisions
 	job := &models.TranscriptionJob{
+		UserID:    h.TestUser.ID,
 		Title:     &title,
 		Status:    models.StatusPending,
 		AudioPath: "test/path/audio.mp3",
@@ -135,6 +168,7 @@ func (h *TestHelper) CreateTestTranscriptionJob(t *testing.T, title string) *mod
 func (h *TestHelper) CreateTestProfile(t *testing.T, name string, isDefault bool) *models.TranscriptionProfile {
 	profile := &models.TranscriptionProfile{
 		ID:          "test-profile-" + strings.ReplaceAll(t.Name(), "/", "_"),
+		UserID:      h.TestUser.ID,
 		Name:        name,
 		Description: stringPtr("Test profile description"),
 		IsDefault:   isDefault,
@@ -156,6 +190,7 @@ func (h *TestHelper) CreateTestNote(t *testing.T, transcriptionID string) *model
 	note := &models.Note{
 		ID:              "test-note-" + strings.ReplaceAll(t.Name(), "/", "_"),
 		TranscriptionID: transcriptionID,
+		UserID:          h.TestUser.ID,
 		StartWordIndex:  0,
 		EndWordIndex:    5,
 		StartTime:       0.0,
@@ -190,6 +225,7 @@ func (h *TestHelper) CreateTestChatSession(t *testing.T, transcriptionID string)
 		ID:              "test-chat-session-" + strings.ReplaceAll(t.Name(), "/", "_"),
 		JobID:           transcriptionID,
 		TranscriptionID: transcriptionID,
+		UserID:          h.TestUser.ID,
 		Title:           "Test Chat Session",
 		Model:           "gpt-4",
 		Provider:        "openai",
@@ -235,3 +271,26 @@ func floatPtr(f float64) *float64 {
 func boolPtr(b bool) *bool {
 	return &b
 }
+
+// editableTranscript has two segments whose words line up with their text
+func editableTranscript() *interfaces.TranscriptResult {
+	a, b := stringPtr("SPEAKER_00"), stringPtr("SPEAKER_01")
+	return &interfaces.TranscriptResult{
+		Text: "Hello there general Kenobi. You are a bold one.",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0.0, End: 2.0, Text: " Hello there general Kenobi.", Speaker: a},
+			{Start: 2.5, End: 4.5, Text: " You are a bold one.", Speaker: b},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.0, End: 0.4, Word: "Hello", Score: 0.9, Speaker: a},
+			{Start: 0.5, End: 0.9, Word: "there", Score: 0.9, Speaker: a},
+			{Start: 1.0, End: 1.4, Word: "general", Score: 0.9, Speaker: a},
+			{Start: 1.5, End: 2.0, Word: "Kenobi.", Score: 0.9, Speaker: a},
+			{Start: 2.5, End: 2.8, Word: "You", Score: 0.9, Speaker: b},
+			{Start: 2.9, End: 3.1, Word: "are", Score: 0.9, Speaker: b},
+			{Start: 3.2, End: 3.3, Word: "a", Score: 0.9, Speaker: b},
+			{Start: 3.4, End: 3.9, Word: "bold", Score: 0.9, Speaker: b},
+			{Start: 4.0, End: 4.5, Word: "one.", Score: 0.9, Speaker: b},
+		},
+	}
+}
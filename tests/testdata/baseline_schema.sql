@@ -0,0 +1,34 @@
+-- Schema of a database created by the first release, before versioned migrations
+CREATE TABLE `api_keys` (`id` integer PRIMARY KEY AUTOINCREMENT,`key` varchar(255) NOT NULL,`name` varchar(100) NOT NULL,`description` text,`is_active` boolean NOT NULL,`last_used` datetime,`created_at` datetime,`updated_at` datetime);
+CREATE TABLE `chat_messages` (`id` integer PRIMARY KEY AUTOINCREMENT,`session_id` varchar(36) NOT NULL,`chat_session_id` varchar(36) NOT NULL,`role` varchar(20) NOT NULL,`content` text NOT NULL,`tokens_used` integer,`created_at` datetime,CONSTRAINT `fk_chat_sessions_messages` FOREIGN KEY (`chat_session_id`) REFERENCES `chat_sessions`(`id`));
+CREATE TABLE `chat_sessions` (`id` varchar(36),`job_id` varchar(36) NOT NULL,`transcription_id` varchar(36) NOT NULL,`title` varchar(255) NOT NULL,`model` varchar(100) NOT NULL,`provider` varchar(50) NOT NULL DEFAULT "openai",`system_context` text,`message_count` integer DEFAULT 0,`last_activity_at` datetime,`is_active` boolean DEFAULT true,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`),CONSTRAINT `fk_chat_sessions_transcription` FOREIGN KEY (`transcription_id`) REFERENCES `transcription_jobs`(`id`),CONSTRAINT `fk_chat_sessions_job` FOREIGN KEY (`job_id`) REFERENCES `transcription_jobs`(`id`));
+CREATE TABLE `live_transcription_chunks` (`id` integer PRIMARY KEY AUTOINCREMENT,`session_id` varchar(36) NOT NULL,`sequence` integer NOT NULL,`start_offset` real,`end_offset` real,`audio_path` text NOT NULL,`transcript_json` text,`created_at` datetime,CONSTRAINT `fk_live_transcription_sessions_chunks` FOREIGN KEY (`session_id`) REFERENCES `live_transcription_sessions`(`id`));
+CREATE TABLE `live_transcription_sessions` (`id` varchar(36),`title` text,`status` varchar(20) NOT NULL DEFAULT "active",`model_family` varchar(20) DEFAULT "whisper",`model` varchar(50) DEFAULT "small",`model_cache_only` boolean DEFAULT false,`model_dir` text,`device` varchar(20) DEFAULT "cpu",`device_index` integer DEFAULT 0,`batch_size` integer DEFAULT 8,`compute_type` varchar(20) DEFAULT "float32",`threads` integer DEFAULT 0,`output_format` varchar(20) DEFAULT "all",`verbose` boolean DEFAULT true,`task` varchar(20) DEFAULT "transcribe",`language` varchar(10),`align_model` varchar(100),`interpolate_method` varchar(20) DEFAULT "nearest",`no_align` boolean DEFAULT false,`return_char_alignments` boolean DEFAULT false,`vad_method` varchar(20) DEFAULT "pyannote",`vad_onset` real DEFAULT 0.5,`vad_offset` real DEFAULT 0.363,`chunk_size` integer DEFAULT 30,`diarize` boolean DEFAULT false,`min_speakers` integer,`max_speakers` integer,`diarize_model` varchar(50) DEFAULT "pyannote",`speaker_embeddings` boolean DEFAULT false,`temperature` real DEFAULT 0,`best_of` integer DEFAULT 5,`beam_size` integer DEFAULT 5,`patience` real DEFAULT 1,`length_penalty` real DEFAULT 1,`suppress_tokens` text,`suppress_numerals` boolean DEFAULT false,`initial_prompt` text,`condition_on_previous_text` boolean DEFAULT false,`fp16` boolean DEFAULT true,`temperature_increment_on_fallback` real DEFAULT 0.2,`compression_ratio_threshold` real DEFAULT 2.4,`logprob_threshold` real DEFAULT -1,`no_speech_threshold` real DEFAULT 0.6,`max_line_width` integer,`max_line_count` integer,`highlight_words` boolean DEFAULT false,`segment_resolution` varchar(20) DEFAULT "sentence",`hf_token` text,`print_progress` boolean DEFAULT false,`attention_context_left` integer DEFAULT 256,`attention_context_right` integer DEFAULT 256,`is_multi_track_enabled` boolean DEFAULT false,`chunk_count` integer NOT NULL DEFAULT 0,`last_sequence` integer NOT NULL DEFAULT 0,`accumulated_transcript` text,`output_audio_path` text,`final_job_id` varchar(36),`created_at` datetime,`updated_at` datetime,`completed_at` datetime,PRIMARY KEY (`id`));
+CREATE TABLE `llm_configs` (`id` integer PRIMARY KEY AUTOINCREMENT,`provider` varchar(50) NOT NULL,`base_url` text,`api_key` text,`is_active` boolean DEFAULT false,`created_at` datetime,`updated_at` datetime);
+CREATE TABLE `multi_track_files` (`id` integer PRIMARY KEY AUTOINCREMENT,`transcription_job_id` varchar(36) NOT NULL,`file_name` varchar(255) NOT NULL,`file_path` text NOT NULL,`track_index` integer NOT NULL,`offset` real DEFAULT 0,`gain` real DEFAULT 1,`pan` real DEFAULT 0,`mute` boolean DEFAULT false,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_transcription_jobs_multi_track_files` FOREIGN KEY (`transcription_job_id`) REFERENCES `transcription_jobs`(`id`));
+CREATE TABLE `notes` (`id` varchar(36),`transcription_id` varchar(36) NOT NULL,`start_word_index` integer NOT NULL,`end_word_index` integer NOT NULL,`start_time` real NOT NULL,`end_time` real NOT NULL,`quote` text NOT NULL,`content` text NOT NULL,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`));
+CREATE TABLE `refresh_tokens` (`id` integer PRIMARY KEY AUTOINCREMENT,`user_id` integer NOT NULL,`hashed` varchar(128) NOT NULL,`expires_at` datetime NOT NULL,`revoked` numeric NOT NULL DEFAULT false,`created_at` datetime,`updated_at` datetime);
+CREATE TABLE `speaker_mappings` (`id` integer PRIMARY KEY AUTOINCREMENT,`transcription_job_id` varchar(36) NOT NULL,`original_speaker` varchar(50) NOT NULL,`custom_name` varchar(100) NOT NULL,`created_at` datetime,`updated_at` datetime,CONSTRAINT `fk_speaker_mappings_transcription_job` FOREIGN KEY (`transcription_job_id`) REFERENCES `transcription_jobs`(`id`));
+CREATE TABLE `summaries` (`id` varchar(36),`transcription_id` varchar(36) NOT NULL,`template_id` varchar(36),`model` varchar(255) NOT NULL,`content` text NOT NULL,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`));
+CREATE TABLE `summary_settings` (`id` integer PRIMARY KEY AUTOINCREMENT,`default_model` varchar(255) NOT NULL DEFAULT "",`updated_at` datetime);
+CREATE TABLE `summary_templates` (`id` varchar(36),`name` varchar(255) NOT NULL,`description` text,`model` varchar(255) NOT NULL DEFAULT "",`prompt` text NOT NULL,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`));
+CREATE TABLE `transcription_job_executions` (`id` varchar(36),`transcription_job_id` varchar(36) NOT NULL,`started_at` datetime NOT NULL,`completed_at` datetime,`processing_duration` integer,`multi_track_timings` text,`merge_start_time` datetime,`merge_end_time` datetime,`merge_duration` integer,`actual_model_family` varchar(20) DEFAULT "whisper",`actual_model` varchar(50) DEFAULT "small",`actual_model_cache_only` boolean DEFAULT false,`actual_model_dir` text,`actual_device` varchar(20) DEFAULT "cpu",`actual_device_index` integer DEFAULT 0,`actual_batch_size` integer DEFAULT 8,`actual_compute_type` varchar(20) DEFAULT "float32",`actual_threads` integer DEFAULT 0,`actual_output_format` varchar(20) DEFAULT "all",`actual_verbose` boolean DEFAULT true,`actual_task` varchar(20) DEFAULT "transcribe",`actual_language` varchar(10),`actual_align_model` varchar(100),`actual_interpolate_method` varchar(20) DEFAULT "nearest",`actual_no_align` boolean DEFAULT false,`actual_return_char_alignments` boolean DEFAULT false,`actual_vad_method` varchar(20) DEFAULT "pyannote",`actual_vad_onset` real DEFAULT 0.5,`actual_vad_offset` real DEFAULT 0.363,`actual_chunk_size` integer DEFAULT 30,`actual_diarize` boolean DEFAULT false,`actual_min_speakers` integer,`actual_max_speakers` integer,`actual_diarize_model` varchar(50) DEFAULT "pyannote",`actual_speaker_embeddings` boolean DEFAULT false,`actual_temperature` real DEFAULT 0,`actual_best_of` integer DEFAULT 5,`actual_beam_size` integer DEFAULT 5,`actual_patience` real DEFAULT 1,`actual_length_penalty` real DEFAULT 1,`actual_suppress_tokens` text,`actual_suppress_numerals` boolean DEFAULT false,`actual_initial_prompt` text,`actual_condition_on_previous_text` boolean DEFAULT false,`actual_fp16` boolean DEFAULT true,`actual_temperature_increment_on_fallback` real DEFAULT 0.2,`actual_compression_ratio_threshold` real DEFAULT 2.4,`actual_logprob_threshold` real DEFAULT -1,`actual_no_speech_threshold` real DEFAULT 0.6,`actual_max_line_width` integer,`actual_max_line_count` integer,`actual_highlight_words` boolean DEFAULT false,`actual_segment_resolution` varchar(20) DEFAULT "sentence",`actual_hf_token` text,`actual_print_progress` boolean DEFAULT false,`actual_attention_context_left` integer DEFAULT 256,`actual_attention_context_right` integer DEFAULT 256,`actual_is_multi_track_enabled` boolean DEFAULT false,`status` varchar(20) NOT NULL,`error_message` text,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`),CONSTRAINT `fk_transcription_job_executions_transcription_job` FOREIGN KEY (`transcription_job_id`) REFERENCES `transcription_jobs`(`id`));
+CREATE TABLE `transcription_jobs` (`id` varchar(36),`title` text,`status` varchar(20) NOT NULL DEFAULT "pending",`audio_path` text NOT NULL,`transcript` text,`diarization` boolean DEFAULT false,`summary` text,`error_message` text,`is_multi_track` boolean DEFAULT false,`aup_file_path` text,`multi_track_folder` text,`merged_audio_path` text,`merge_status` varchar(20) DEFAULT "none",`merge_error` text,`individual_transcripts` text,`created_at` datetime,`updated_at` datetime,`model_family` varchar(20) DEFAULT "whisper",`model` varchar(50) DEFAULT "small",`model_cache_only` boolean DEFAULT false,`model_dir` text,`device` varchar(20) DEFAULT "cpu",`device_index` integer DEFAULT 0,`batch_size` integer DEFAULT 8,`compute_type` varchar(20) DEFAULT "float32",`threads` integer DEFAULT 0,`output_format` varchar(20) DEFAULT "all",`verbose` boolean DEFAULT true,`task` varchar(20) DEFAULT "transcribe",`language` varchar(10),`align_model` varchar(100),`interpolate_method` varchar(20) DEFAULT "nearest",`no_align` boolean DEFAULT false,`return_char_alignments` boolean DEFAULT false,`vad_method` varchar(20) DEFAULT "pyannote",`vad_onset` real DEFAULT 0.5,`vad_offset` real DEFAULT 0.363,`chunk_size` integer DEFAULT 30,`diarize` boolean DEFAULT false,`min_speakers` integer,`max_speakers` integer,`diarize_model` varchar(50) DEFAULT "pyannote",`speaker_embeddings` boolean DEFAULT false,`temperature` real DEFAULT 0,`best_of` integer DEFAULT 5,`beam_size` integer DEFAULT 5,`patience` real DEFAULT 1,`length_penalty` real DEFAULT 1,`suppress_tokens` text,`suppress_numerals` boolean DEFAULT false,`initial_prompt` text,`condition_on_previous_text` boolean DEFAULT false,`fp16` boolean DEFAULT true,`temperature_increment_on_fallback` real DEFAULT 0.2,`compression_ratio_threshold` real DEFAULT 2.4,`logprob_threshold` real DEFAULT -1,`no_speech_threshold` real DEFAULT 0.6,`max_line_width` integer,`max_line_count` integer,`highlight_words` boolean DEFAULT false,`segment_resolution` varchar(20) DEFAULT "sentence",`hf_token` text,`print_progress` boolean DEFAULT false,`attention_context_left` integer DEFAULT 256,`attention_context_right` integer DEFAULT 256,`is_multi_track_enabled` boolean DEFAULT false,PRIMARY KEY (`id`));
+CREATE TABLE `transcription_profiles` (`id` varchar(36),`name` varchar(255) NOT NULL,`description` text,`is_default` boolean DEFAULT false,`model_family` varchar(20) DEFAULT "whisper",`model` varchar(50) DEFAULT "small",`model_cache_only` boolean DEFAULT false,`model_dir` text,`device` varchar(20) DEFAULT "cpu",`device_index` integer DEFAULT 0,`batch_size` integer DEFAULT 8,`compute_type` varchar(20) DEFAULT "float32",`threads` integer DEFAULT 0,`output_format` varchar(20) DEFAULT "all",`verbose` boolean DEFAULT true,`task` varchar(20) DEFAULT "transcribe",`language` varchar(10),`align_model` varchar(100),`interpolate_method` varchar(20) DEFAULT "nearest",`no_align` boolean DEFAULT false,`return_char_alignments` boolean DEFAULT false,`vad_method` varchar(20) DEFAULT "pyannote",`vad_onset` real DEFAULT 0.5,`vad_offset` real DEFAULT 0.363,`chunk_size` integer DEFAULT 30,`diarize` boolean DEFAULT false,`min_speakers` integer,`max_speakers` integer,`diarize_model` varchar(50) DEFAULT "pyannote",`speaker_embeddings` boolean DEFAULT false,`temperature` real DEFAULT 0,`best_of` integer DEFAULT 5,`beam_size` integer DEFAULT 5,`patience` real DEFAULT 1,`length_penalty` real DEFAULT 1,`suppress_tokens` text,`suppress_numerals` boolean DEFAULT false,`initial_prompt` text,`condition_on_previous_text` boolean DEFAULT false,`fp16` boolean DEFAULT true,`temperature_increment_on_fallback` real DEFAULT 0.2,`compression_ratio_threshold` real DEFAULT 2.4,`logprob_threshold` real DEFAULT -1,`no_speech_threshold` real DEFAULT 0.6,`max_line_width` integer,`max_line_count` integer,`highlight_words` boolean DEFAULT false,`segment_resolution` varchar(20) DEFAULT "sentence",`hf_token` text,`print_progress` boolean DEFAULT false,`attention_context_left` integer DEFAULT 256,`attention_context_right` integer DEFAULT 256,`is_multi_track_enabled` boolean DEFAULT false,`created_at` datetime,`updated_at` datetime,PRIMARY KEY (`id`));
+CREATE TABLE `users` (`id` integer PRIMARY KEY AUTOINCREMENT,`username` varchar(50) NOT NULL,`password` varchar(255) NOT NULL,`default_profile_id` varchar(36),`auto_transcription_enabled` numeric NOT NULL DEFAULT false,`fast_finalize_enabled` numeric NOT NULL DEFAULT true,`created_at` datetime,`updated_at` datetime);
+CREATE UNIQUE INDEX `idx_api_keys_key` ON `api_keys`(`key`);
+CREATE INDEX `idx_chat_messages_chat_session_id` ON `chat_messages`(`chat_session_id`);
+CREATE INDEX `idx_chat_messages_session_id` ON `chat_messages`(`session_id`);
+CREATE INDEX `idx_chat_sessions_transcription_id` ON `chat_sessions`(`transcription_id`);
+CREATE INDEX `idx_live_transcription_chunks_session_id` ON `live_transcription_chunks`(`session_id`);
+CREATE INDEX `idx_multi_track_files_transcription_job_id` ON `multi_track_files`(`transcription_job_id`);
+CREATE INDEX `idx_notes_transcription_id` ON `notes`(`transcription_id`);
+CREATE INDEX `idx_refresh_tokens_expires_at` ON `refresh_tokens`(`expires_at`);
+CREATE UNIQUE INDEX `idx_refresh_tokens_hashed` ON `refresh_tokens`(`hashed`);
+CREATE INDEX `idx_refresh_tokens_revoked` ON `refresh_tokens`(`revoked`);
+CREATE INDEX `idx_refresh_tokens_user_id` ON `refresh_tokens`(`user_id`);
+CREATE INDEX `idx_speaker_mappings_transcription_job_id` ON `speaker_mappings`(`transcription_job_id`);
+CREATE UNIQUE INDEX idx_speaker_mappings_unique ON speaker_mappings(transcription_job_id, original_speaker);
+CREATE INDEX `idx_summaries_transcription_id` ON `summaries`(`transcription_id`);
+CREATE INDEX `idx_transcription_job_executions_transcription_job_id` ON `transcription_job_executions`(`transcription_job_id`);
+CREATE UNIQUE INDEX `idx_users_username` ON `users`(`username`);
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"context"
+	"testing"
+
+	"synthezia/internal/models"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/transcription/pipeline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type TextPostprocessingTestSuite struct {
+	suite.Suite
+}
+
+func (suite *TextPostprocessingTestSuite) TestNormalizeNumbers() {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"we shipped twenty one releases", "we shipped 21 releases"},
+		{"it cost one hundred and five dollars.", "it cost 105 dollars."},
+		{"about three thousand two hundred users", "about 3200 users"},
+		{"twenty-one, twenty-two", "21, 22"},
+		{"one of them said two things", "one of them said two things"},
+		{"room twelve", "room 12"},
+		{"nineteen hundred", "1900"},
+		{"five and a half", "five and a half"},
+		{"one two three", "one two three"},
+		{" Forty Two is the answer", " 42 is the answer"},
+		{"a thousand things", "a thousand things"},
+		{"no numbers here", "no numbers here"},
+	}
+	for _, tc := range cases {
+		assert.Equal(suite.T(), tc.expected, pipeline.NormalizeNumbers(tc.input), tc.input)
+	}
+}
+
+func (suite *TextPostprocessingTestSuite) TestMaskProfanity() {
+	assert.Equal(suite.T(), "well s*** happens", pipeline.MaskProfanity("well shit happens"))
+	assert.Equal(suite.T(), "F******", pipeline.MaskProfanity("Fucking"))
+	assert.Equal(suite.T(), "Dickens wrote it, not a d***", pipeline.MaskProfanity("Dickens wrote it, not a dick"))
+	assert.Equal(suite.T(), "the class assessment", pipeline.MaskProfanity("the class assessment"))
+}
+
+func (suite *TextPostprocessingTestSuite) TestValidateReplacementRules() {
+	assert.NoError(suite.T(), pipeline.ValidateReplacementRules([]models.ReplacementRule{
+		{Pattern: "synthesia", Replacement: "Synthezia"},
+		{Pattern: `v(\d+)`, Replacement: "version $1", Regex: true},
+	}))
+	assert.Error(suite.T(), pipeline.ValidateReplacementRules([]models.ReplacementRule{{Pattern: " "}}))
+	assert.Error(suite.T(), pipeline.ValidateReplacementRules([]models.ReplacementRule{{Pattern: "(", Regex: true}}))
+}
+
+func (suite *TextPostprocessingTestSuite) TestReplacements() {
+	rules := []models.ReplacementRule{
+		{Pattern: "synthesia", Replacement: "Synthezia", WholeWord: true},
+		{Pattern: "Q.A.", Replacement: "QA"},
+		{Pattern: `(\d+) percent`, Replacement: "$1%", Regex: true},
+		{Pattern: "Go", Replacement: "Golang", CaseSensitive: true, WholeWord: true},
+	}
+	params := map[string]interface{}{pipeline.ReplacementsParam: rules}
+	replace := &pipeline.ReplacementPostprocessor{}
+	assert.True(suite.T(), replace.AppliesTo(interfaces.ModelCapabilities{}, params))
+	assert.False(suite.T(), replace.AppliesTo(interfaces.ModelCapabilities{}, map[string]interface{}{}))
+
+	result := &interfaces.TranscriptResult{
+		Text: "SYNTHESIA passed Q.A. with 90 percent, written in Go, let's go",
+		Segments: []interfaces.TranscriptSegment{
+			{Text: "SYNTHESIA passed Q.A. with 90 percent,"},
+			{Text: "written in Go, let's go. Synthesias"},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Word: "Synthesia"}, {Word: "QxA."}, {Word: "Go,"},
+		},
+	}
+	result, err := replace.ProcessTranscript(context.Background(), result, params)
+	assert.NoError(suite.T(), err)
+
+	assert.Equal(suite.T(), "Synthezia passed QA with 90%, written in Golang, let's go", result.Text)
+	assert.Equal(suite.T(), "Synthezia passed QA with 90%,", result.Segments[0].Text)
+	assert.Equal(suite.T(), "written in Golang, let's go. Synthesias", result.Segments[1].Text)
+	// Exact patterns are literal: "." does not match any character
+	assert.Equal(suite.T(), []string{"Synthezia", "QxA.", "Golang,"},
+		[]string{result.WordSegments[0].Word, result.WordSegments[1].Word, result.WordSegments[2].Word})
+}
+
+func (suite *TextPostprocessingTestSuite) TestMultiWordReplacementMergesWords() {
+	rules := []models.ReplacementRule{{Pattern: "synth easier", Replacement: "Synthezia", WholeWord: true}}
+	params := map[string]interface{}{pipeline.ReplacementsParam: rules}
+	result := &interfaces.TranscriptResult{
+		Text: "we use synth easier daily. Synth",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2.5, Text: "we use synth easier daily."},
+			{Start: 2.5, End: 3.5, Text: "Synth"},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.0, End: 0.3, Word: "we", Score: 0.9},
+			{Start: 0.4, End: 0.6, Word: "use", Score: 0.9},
+			{Start: 0.7, End: 1.0, Word: "synth", Score: 0.6},
+			{Start: 1.1, End: 1.5, Word: "easier", Score: 0.8},
+			{Start: 1.6, End: 2.4, Word: "daily.", Score: 0.95},
+			{Start: 2.6, End: 3.0, Word: "Synth", Score: 0.7},
+		},
+	}
+
+	result, err := (&pipeline.ReplacementPostprocessor{}).ProcessTranscript(context.Background(), result, params)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "we use Synthezia daily. Synth", result.Text)
+	assert.Equal(suite.T(), "we use Synthezia daily.", result.Segments[0].Text)
+	assert.Equal(suite.T(), "Synth", result.Segments[1].Text)
+
+	words := make([]string, len(result.WordSegments))
+	for i, word := range result.WordSegments {
+		words[i] = word.Word
+	}
+	assert.Equal(suite.T(), []string{"we", "use", "Synthezia", "daily.", "Synth"}, words)
+	if assert.Len(suite.T(), result.WordSegments, 5) {
+		merged := result.WordSegments[2]
+		assert.Equal(suite.T(), 0.7, merged.Start)
+		assert.Equal(suite.T(), 1.5, merged.End)
+		assert.Equal(suite.T(), 0.6, merged.Score)
+	}
+}
+
+func (suite *TextPostprocessingTestSuite) TestNumberWordsMerged() {
+	result := &interfaces.TranscriptResult{
+		Text:     "about twenty five people.",
+		Segments: []interfaces.TranscriptSegment{{Start: 1, End: 3, Text: " about twenty five people."}},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 1.0, End: 1.3, Word: "about", Score: 0.9},
+			{Start: 1.4, End: 1.8, Word: "twenty", Score: 0.8},
+			{Start: 1.9, End: 2.2, Word: "five", Score: 0.7},
+			{Start: 2.3, End: 3.0, Word: "people.", Score: 0.95},
+		},
+	}
+	params := map[string]interface{}{pipeline.NormalizeNumbersParam: true}
+	numbers := &pipeline.NumberPostprocessor{}
+	assert.True(suite.T(), numbers.AppliesTo(interfaces.ModelCapabilities{}, params))
+
+	result, err := numbers.ProcessTranscript(context.Background(), result, params)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "about 25 people.", result.Text)
+	assert.Equal(suite.T(), " about 25 people.", result.Segments[0].Text)
+	if assert.Len(suite.T(), result.WordSegments, 3) {
+		merged := result.WordSegments[1]
+		assert.Equal(suite.T(), "25", merged.Word)
+		assert.Equal(suite.T(), 1.4, merged.Start)
+		assert.Equal(suite.T(), 2.2, merged.End)
+		assert.Equal(suite.T(), 0.7, merged.Score)
+		assert.Equal(suite.T(), "people.", result.WordSegments[2].Word)
+	}
+}
+
+func (suite *TextPostprocessingTestSuite) TestNumberWordsNotMergedAcrossSegments() {
+	result := &interfaces.TranscriptResult{
+		Text: "we counted twenty five people came",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 2, Text: "we counted twenty"},
+			{Start: 2, End: 4, Text: "five people came"},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0.0, End: 0.4, Word: "we", Score: 0.9},
+			{Start: 0.5, End: 1.0, Word: "counted", Score: 0.9},
+			{Start: 1.2, End: 1.8, Word: "twenty", Score: 0.8},
+			{Start: 2.1, End: 2.4, Word: "five", Score: 0.7},
+			{Start: 2.5, End: 3.0, Word: "people", Score: 0.9},
+			{Start: 3.1, End: 3.6, Word: "came", Score: 0.9},
+		},
+	}
+	params := map[string]interface{}{pipeline.NormalizeNumbersParam: true}
+
+	result, err := (&pipeline.NumberPostprocessor{}).ProcessTranscript(context.Background(), result, params)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "we counted 20", result.Segments[0].Text)
+	assert.Equal(suite.T(), "five people came", result.Segments[1].Text)
+
+	words := make([]string, len(result.WordSegments))
+	for i, word := range result.WordSegments {
+		words[i] = word.Word
+	}
+	assert.Equal(suite.T(), []string{"we", "counted", "20", "five", "people", "came"}, words)
+}
+
+func (suite *TextPostprocessingTestSuite) TestPipelineOrder() {
+	// Numbers are normalized before replacements run, and profanity is masked last
+	rules := []models.ReplacementRule{
+		{Pattern: "21", Replacement: "twenty-one"},
+		{Pattern: "darn", Replacement: "shit", WholeWord: true},
+	}
+	params := map[string]interface{}{
+		pipeline.ReplacementsParam:     rules,
+		pipeline.NormalizeNumbersParam: true,
+		pipeline.MaskProfanityParam:    true,
+	}
+	result := &interfaces.TranscriptResult{
+		Segments: []interfaces.TranscriptSegment{{Text: "twenty one darn times"}},
+	}
+	result, err := pipeline.NewProcessingPipeline().ProcessTranscript(context.Background(), result, interfaces.ModelCapabilities{}, params)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "twenty-one s*** times", result.Segments[0].Text)
+}
+
+func TestTextPostprocessingTestSuite(t *testing.T) {
+	suite.Run(t, new(TextPostprocessingTestSuite))
+}
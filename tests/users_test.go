@@ -0,0 +1,255 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"synthezia/internal/api"
+	"synthezia/internal/auth"
+	"synthezia/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type UsersTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *UsersTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "users_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *UsersTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *UsersTestSuite) SetupTest() {
+	suite.helper.DB.Where("1 = 1").Delete(&models.Invitation{})
+	suite.helper.DB.Where("id <> ?", suite.helper.TestUser.ID).Delete(&models.User{})
+	suite.helper.DB.Model(&models.User{}).Where("id = ?", suite.helper.TestUser.ID).
+		Updates(map[string]interface{}{"role": models.RoleAdmin, "disabled": false})
+}
+
+// createUser stores a user with a role and returns a token for them
+func (suite *UsersTestSuite) createUser(username, role string) (*models.User, string) {
+	hashedPassword, err := auth.HashPassword("password123")
+	assert.NoError(suite.T(), err)
+	user := &models.User{Username: username, Password: hashedPassword, Role: role}
+	assert.NoError(suite.T(), suite.helper.DB.Create(user).Error)
+	token, err := suite.helper.AuthService.GenerateToken(user)
+	assert.NoError(suite.T(), err)
+	return user, token
+}
+
+func (suite *UsersTestSuite) request(token, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *UsersTestSuite) register(username, invitationToken string) *httptest.ResponseRecorder {
+	return suite.request("", "POST", "/api/v1/auth/register", map[string]string{
+		"username":        username,
+		"password":        "password123",
+		"confirmPassword": "password123",
+		"invitationToken": invitationToken,
+	})
+}
+
+func (suite *UsersTestSuite) TestInvitationRegistration() {
+	w := suite.register("stranger", "")
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", "/api/v1/invitations/", map[string]interface{}{"role": "owner"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", "/api/v1/invitations/", map[string]interface{}{"role": "viewer"})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var invitation api.InvitationResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &invitation))
+	assert.NotEmpty(suite.T(), invitation.Token)
+	assert.WithinDuration(suite.T(), time.Now().Add(72*time.Hour), invitation.ExpiresAt, time.Minute)
+
+	w = suite.request("", "GET", "/api/v1/auth/registration-status?invitation="+invitation.Token, nil)
+	var status api.RegistrationStatusResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &status))
+	assert.True(suite.T(), status.RegistrationEnabled)
+	assert.Equal(suite.T(), models.RoleViewer, status.InvitationRole)
+
+	w = suite.register("wrong-token", "not-a-token")
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.register("newviewer", invitation.Token)
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var login api.LoginResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &login))
+	assert.Equal(suite.T(), models.RoleViewer, login.User.Role)
+	assert.NotEmpty(suite.T(), login.Token)
+
+	// Invitations are single-use
+	w = suite.register("second", invitation.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "GET", "/api/v1/invitations/", nil)
+	var invitations []api.InvitationResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &invitations))
+	assert.Len(suite.T(), invitations, 1)
+	assert.NotNil(suite.T(), invitations[0].AcceptedAt)
+	assert.Empty(suite.T(), invitations[0].Token)
+}
+
+func (suite *UsersTestSuite) TestExpiredAndDeletedInvitations() {
+	w := suite.request(suite.helper.TestToken, "POST", "/api/v1/invitations/", map[string]interface{}{"role": "editor", "expires_in_hours": 1})
+	var expired api.InvitationResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &expired))
+	suite.helper.DB.Model(&models.Invitation{}).Where("id = ?", expired.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	w = suite.register("late", expired.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", "/api/v1/invitations/", map[string]interface{}{"role": "editor"})
+	var revoked api.InvitationResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &revoked))
+	w = suite.request(suite.helper.TestToken, "DELETE", fmt.Sprintf("/api/v1/invitations/%d", revoked.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.register("revoked", revoked.Token)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+}
+
+func (suite *UsersTestSuite) TestCreateAndListUsers() {
+	w := suite.request(suite.helper.TestToken, "POST", "/api/v1/users/", map[string]string{
+		"username": "editor1", "password": "password123", "role": "editor",
+	})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	assert.NotContains(suite.T(), w.Body.String(), "password")
+
+	w = suite.request(suite.helper.TestToken, "POST", "/api/v1/users/", map[string]string{
+		"username": "editor1", "password": "password123", "role": "editor",
+	})
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", "/api/v1/users/", map[string]string{
+		"username": "someone", "password": "password123", "role": "superuser",
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "GET", "/api/v1/users/", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var users []models.User
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &users))
+	assert.Len(suite.T(), users, 2)
+	assert.Equal(suite.T(), models.RoleEditor, users[1].Role)
+}
+
+func (suite *UsersTestSuite) TestRolesAreEnforced() {
+	_, viewerToken := suite.createUser("viewer1", models.RoleViewer)
+	_, editorToken := suite.createUser("editor1", models.RoleEditor)
+
+	w := suite.request(viewerToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.request(viewerToken, "POST", "/api/v1/tags/", map[string]string{"name": "Mine"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.request(viewerToken, "GET", "/api/v1/users/", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(editorToken, "POST", "/api/v1/tags/", map[string]string{"name": "Mine"})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	w = suite.request(editorToken, "POST", "/api/v1/llm/config", map[string]interface{}{"provider": "ollama", "base_url": "http://localhost:11434"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.request(editorToken, "GET", "/api/v1/admin/queue/stats", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.request(editorToken, "POST", "/api/v1/invitations/", map[string]string{"role": "admin"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// Viewers can still manage their own account
+	w = suite.request(viewerToken, "GET", "/api/v1/user/settings", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var settings api.UserSettingsResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &settings))
+	assert.Equal(suite.T(), models.RoleViewer, settings.Role)
+}
+
+func (suite *UsersTestSuite) TestChangeRole() {
+	editor, editorToken := suite.createUser("editor1", models.RoleEditor)
+
+	w := suite.request(suite.helper.TestToken, "PUT", fmt.Sprintf("/api/v1/users/%d/role", editor.ID), map[string]string{"role": "viewer"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.request(editorToken, "POST", "/api/v1/tags/", map[string]string{"name": "Mine"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// The only admin can't demote themselves
+	w = suite.request(suite.helper.TestToken, "PUT", fmt.Sprintf("/api/v1/users/%d/role", suite.helper.TestUser.ID), map[string]string{"role": "editor"})
+	assert.Equal(suite.T(), http.StatusConflict, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "PUT", "/api/v1/users/999/role", map[string]string{"role": "editor"})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *UsersTestSuite) TestDisableUser() {
+	editor, editorToken := suite.createUser("editor1", models.RoleEditor)
+	apiKey := models.APIKey{UserID: editor.ID, Key: auth.HashAPIKey("editor-key"), Hashed: true, Name: "Editor key", IsActive: true}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&apiKey).Error)
+
+	w := suite.request(suite.helper.TestToken, "POST", fmt.Sprintf("/api/v1/users/%d/disable", suite.helper.TestUser.ID), nil)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", fmt.Sprintf("/api/v1/users/%d/disable", editor.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request("", "POST", "/api/v1/auth/login", map[string]string{"username": "editor1", "password": "password123"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+	w = suite.request(editorToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	req, _ := http.NewRequest("GET", "/api/v1/transcription/list", nil)
+	req.Header.Set("X-API-Key", "editor-key")
+	rec := httptest.NewRecorder()
+	suite.router.ServeHTTP(rec, req)
+	assert.Equal(suite.T(), http.StatusForbidden, rec.Code)
+
+	w = suite.request(suite.helper.TestToken, "POST", fmt.Sprintf("/api/v1/users/%d/enable", editor.ID), nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.request("", "POST", "/api/v1/auth/login", map[string]string{"username": "editor1", "password": "password123"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var login api.LoginResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &login))
+	assert.Equal(suite.T(), models.RoleEditor, login.User.Role)
+}
+
+func (suite *UsersTestSuite) TestDeletedUserIsRejected() {
+	viewer, viewerToken := suite.createUser("viewer1", models.RoleViewer)
+	w := suite.request(viewerToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	// The token outlives the account, and must not pass as a request without a role
+	assert.NoError(suite.T(), suite.helper.DB.Delete(&models.User{}, viewer.ID).Error)
+	w = suite.request(viewerToken, "GET", "/api/v1/transcription/list", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+	w = suite.request(viewerToken, "POST", "/api/v1/transcription/upload", nil)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+func TestUsersTestSuite(t *testing.T) {
+	suite.Run(t, new(UsersTestSuite))
+}
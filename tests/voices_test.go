@@ -0,0 +1,284 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+	"synthezia/internal/voices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type VoiceLibraryTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *VoiceLibraryTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "voices_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *VoiceLibraryTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *VoiceLibraryTestSuite) SetupTest() {
+	voices.SetThresholds(voices.Thresholds{Suggest: 0.6, AutoApply: 0.75})
+	suite.helper.DB.Where("1 = 1").Delete(&models.VoiceSample{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.VoiceProfile{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.SpeakerEmbedding{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.SpeakerMapping{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.TranscriptionJob{})
+}
+
+// createDiarizedJob stores a completed two-speaker job with the given speaker embeddings
+func (suite *VoiceLibraryTestSuite) createDiarizedJob(embeddings map[string][]float64) *models.TranscriptionJob {
+	data, _ := json.Marshal(editableTranscript())
+	transcript := string(data)
+	job := &models.TranscriptionJob{
+		UserID:      suite.helper.TestUser.ID,
+		Status:      models.StatusCompleted,
+		AudioPath:   "test/path/audio.mp3",
+		Diarization: true,
+		Transcript:  &transcript,
+	}
+	assert.NoError(suite.T(), suite.helper.DB.Create(job).Error)
+	assert.NoError(suite.T(), voices.SaveJobEmbeddings(job.ID, "pyannote/speaker-diarization-3.1", embeddings))
+	return job
+}
+
+func (suite *VoiceLibraryTestSuite) speakerNames(jobID string) map[string]string {
+	var mappings []models.SpeakerMapping
+	suite.helper.DB.Where("transcription_job_id = ?", jobID).Find(&mappings)
+	names := make(map[string]string)
+	for _, m := range mappings {
+		names[m.OriginalSpeaker] = m.CustomName
+	}
+	return names
+}
+
+func (suite *VoiceLibraryTestSuite) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *VoiceLibraryTestSuite) TestSimilarity() {
+	assert.InDelta(suite.T(), 1.0, voices.Similarity([]float64{1, 2, 3}, []float64{2, 4, 6}), 1e-9)
+	assert.InDelta(suite.T(), 0.0, voices.Similarity([]float64{1, 0}, []float64{0, 1}), 1e-9)
+	assert.InDelta(suite.T(), 1/math.Sqrt2, voices.Similarity([]float64{1, 0}, []float64{1, 1}), 1e-9)
+	assert.Equal(suite.T(), 0.0, voices.Similarity([]float64{1, 0}, []float64{1, 0, 0}))
+	assert.Equal(suite.T(), 0.0, voices.Similarity([]float64{0, 0}, []float64{1, 0}))
+}
+
+func (suite *VoiceLibraryTestSuite) TestEnrolledVoiceIsAppliedToLaterJobs() {
+	first := suite.createDiarizedJob(map[string][]float64{
+		"SPEAKER_00": {1, 0, 0},
+		"SPEAKER_01": {0, 1, 0},
+	})
+	profile, err := voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: first.ID, Speaker: "SPEAKER_00", Name: "Alice"})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), profile.Samples, 1)
+	assert.Equal(suite.T(), "Alice", suite.speakerNames(first.ID)["SPEAKER_00"])
+
+	// Alice speaks second in the next recording, and a stranger first
+	second := suite.createDiarizedJob(map[string][]float64{
+		"SPEAKER_00": {0, 0.95, 0.1},
+		"SPEAKER_01": {0.9, 0.1, 0.05},
+	})
+	applied, err := voices.AutoApply(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), applied, 1) {
+		assert.Equal(suite.T(), "SPEAKER_01", applied[0].Speaker)
+		assert.Equal(suite.T(), profile.ID, applied[0].VoiceProfileID)
+		assert.Greater(suite.T(), applied[0].Similarity, 0.9)
+	}
+	assert.Equal(suite.T(), map[string]string{"SPEAKER_01": "Alice"}, suite.speakerNames(second.ID))
+
+	// Enrolling the same speaker again replaces its sample instead of adding one
+	profile, err = voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: first.ID, Speaker: "SPEAKER_00", Name: "alice"})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Alice", profile.Name)
+	assert.Len(suite.T(), profile.Samples, 1)
+}
+
+func (suite *VoiceLibraryTestSuite) TestWeakMatchesAreOnlySuggested() {
+	first := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+	_, err := voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: first.ID, Speaker: "SPEAKER_00", Name: "Alice"})
+	assert.NoError(suite.T(), err)
+
+	second := suite.createDiarizedJob(map[string][]float64{
+		"SPEAKER_00": {0.7, 0.7},  // similarity 0.71: suggested
+		"SPEAKER_01": {0.2, 0.98}, // similarity 0.2: not a match
+	})
+	applied, err := voices.AutoApply(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), applied)
+	assert.Empty(suite.T(), suite.speakerNames(second.ID))
+
+	matches, err := voices.Suggest(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), matches, 1) {
+		assert.Equal(suite.T(), "SPEAKER_00", matches[0].Speaker)
+		assert.Equal(suite.T(), "Alice", matches[0].Name)
+		assert.False(suite.T(), matches[0].Applied)
+	}
+}
+
+func (suite *VoiceLibraryTestSuite) TestAutoApplyKeepsNamesSetByHand() {
+	first := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+	_, err := voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: first.ID, Speaker: "SPEAKER_00", Name: "Alice"})
+	assert.NoError(suite.T(), err)
+
+	second := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0.05}})
+	suite.helper.DB.Create(&models.SpeakerMapping{TranscriptionJobID: second.ID, OriginalSpeaker: "SPEAKER_00", CustomName: "Host"})
+
+	applied, err := voices.AutoApply(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), applied)
+	assert.Equal(suite.T(), map[string]string{"SPEAKER_00": "Host"}, suite.speakerNames(second.ID))
+}
+
+func (suite *VoiceLibraryTestSuite) TestEmbeddingsOfOtherModelsAreNotCompared() {
+	first := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+	_, err := voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: first.ID, Speaker: "SPEAKER_00", Name: "Alice"})
+	assert.NoError(suite.T(), err)
+
+	second := suite.createDiarizedJob(nil)
+	assert.NoError(suite.T(), voices.SaveJobEmbeddings(second.ID, "other-model", map[string][]float64{"SPEAKER_00": {1, 0}}))
+	matches, err := voices.Suggest(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), matches)
+}
+
+func (suite *VoiceLibraryTestSuite) TestVoiceProfileAPI() {
+	first := suite.createDiarizedJob(map[string][]float64{
+		"SPEAKER_00": {1, 0},
+		"SPEAKER_01": {0, 1},
+	})
+
+	// The speaker of segment 1 is SPEAKER_01
+	w := suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{
+		"transcription_id": first.ID,
+		"segment_index":    1,
+		"name":             "Bob",
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var profile models.VoiceProfile
+	json.Unmarshal(w.Body.Bytes(), &profile)
+	assert.Equal(suite.T(), "Bob", profile.Name)
+	if assert.Len(suite.T(), profile.Samples, 1) {
+		assert.Equal(suite.T(), "SPEAKER_01", profile.Samples[0].Speaker)
+	}
+	assert.Equal(suite.T(), "Bob", suite.speakerNames(first.ID)["SPEAKER_01"])
+
+	// A speaker already named by hand is enrolled under that name
+	suite.helper.DB.Create(&models.SpeakerMapping{TranscriptionJobID: first.ID, OriginalSpeaker: "SPEAKER_00", CustomName: "Carol"})
+	w = suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": first.ID, "speaker": "SPEAKER_00"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request("GET", "/api/v1/voices/", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var profiles []models.VoiceProfile
+	json.Unmarshal(w.Body.Bytes(), &profiles)
+	if assert.Len(suite.T(), profiles, 2) {
+		assert.Equal(suite.T(), "Bob", profiles[0].Name)
+		assert.Equal(suite.T(), "Carol", profiles[1].Name)
+	}
+
+	second := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {0.1, 1}})
+	_, err := voices.AutoApply(suite.helper.TestUser.ID, second.ID)
+	assert.NoError(suite.T(), err)
+	w = suite.request("GET", "/api/v1/transcription/"+second.ID+"/speakers/suggestions", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var matches []voices.Match
+	json.Unmarshal(w.Body.Bytes(), &matches)
+	if assert.Len(suite.T(), matches, 1) {
+		assert.Equal(suite.T(), "Bob", matches[0].Name)
+		assert.True(suite.T(), matches[0].Applied)
+	}
+
+	// Renaming the profile renames the speakers named after it
+	w = suite.request("PUT", "/api/v1/voices/"+profile.ID, map[string]string{"name": "Robert"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), "Robert", suite.speakerNames(first.ID)["SPEAKER_01"])
+	assert.Equal(suite.T(), "Robert", suite.speakerNames(second.ID)["SPEAKER_00"])
+
+	w = suite.request("DELETE", "/api/v1/voices/"+profile.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.request("GET", "/api/v1/voices/"+profile.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+	assert.Equal(suite.T(), "Robert", suite.speakerNames(second.ID)["SPEAKER_00"])
+}
+
+func (suite *VoiceLibraryTestSuite) TestEnrollValidation() {
+	job := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+
+	w := suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": job.ID, "speaker": "SPEAKER_01", "name": "Bob"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, "speaker without an embedding")
+
+	w = suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": job.ID, "speaker": "SPEAKER_00"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, "unnamed speaker without a name")
+
+	w = suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": job.ID, "segment_index": 5, "name": "Bob"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code, "segment out of range")
+
+	w = suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": job.ID, "speaker": "SPEAKER_00", "profile_id": "missing"})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.request("POST", "/api/v1/voices/enroll", map[string]interface{}{"transcription_id": "missing", "speaker": "SPEAKER_00", "name": "Bob"})
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *VoiceLibraryTestSuite) TestHasProfiles() {
+	hasProfiles, err := voices.HasProfiles(suite.helper.TestUser.ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), hasProfiles)
+
+	job := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+	_, err = voices.Enroll(voices.Enrollment{UserID: suite.helper.TestUser.ID, JobID: job.ID, Speaker: "SPEAKER_00", Name: "Alice"})
+	assert.NoError(suite.T(), err)
+
+	hasProfiles, err = voices.HasProfiles(suite.helper.TestUser.ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), hasProfiles)
+}
+
+func (suite *VoiceLibraryTestSuite) TestSortformerRejectsSpeakerEmbeddings() {
+	job := suite.createDiarizedJob(map[string][]float64{"SPEAKER_00": {1, 0}})
+
+	w := suite.request("POST", "/api/v1/transcription/"+job.ID+"/rediarize", map[string]interface{}{
+		"diarize_model":      "nvidia_sortformer",
+		"speaker_embeddings": true,
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Contains(suite.T(), w.Body.String(), "pyannote")
+
+	w = suite.request("POST", "/api/v1/profiles/", map[string]interface{}{
+		"name":       "Sortformer voices",
+		"parameters": map[string]interface{}{"diarize": true, "diarize_model": "nvidia_sortformer", "speaker_embeddings": true},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func TestVoiceLibraryTestSuite(t *testing.T) {
+	suite.Run(t, new(VoiceLibraryTestSuite))
+}
@@ -0,0 +1,338 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"synthezia/internal/api"
+	"synthezia/internal/models"
+	"synthezia/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WebhookTestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+// receivedRequest captures a request made to the test endpoint
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookReceiver is an HTTP endpoint that records requests and answers with a configurable status
+type webhookReceiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []receivedRequest
+	server   *httptest.Server
+}
+
+func newWebhookReceiver(status int) *webhookReceiver {
+	r := &webhookReceiver{status: status}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, receivedRequest{header: req.Header.Clone(), body: body})
+		status := r.status
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		w.Write([]byte("ack"))
+	}))
+	return r
+}
+
+func (suite *WebhookTestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "webhook_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *WebhookTestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+func (suite *WebhookTestSuite) SetupTest() {
+	// The test receivers listen on loopback
+	suite.T().Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "true")
+	suite.helper.DB.Where("1 = 1").Delete(&models.WebhookDelivery{})
+	suite.helper.DB.Where("1 = 1").Delete(&models.Webhook{})
+}
+
+func (suite *WebhookTestSuite) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req, _ := http.NewRequest(method, path, bytes.NewBuffer(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", suite.helper.TestAPIKey)
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// createWebhook registers a webhook through the API and returns it with its secret
+func (suite *WebhookTestSuite) createWebhook(url string, events []string) api.CreateWebhookResponse {
+	w := suite.request("POST", "/api/v1/webhooks/", map[string]interface{}{"url": url, "events": events})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var created api.CreateWebhookResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &created))
+	return created
+}
+
+func (suite *WebhookTestSuite) deliveries(webhookID string) []models.WebhookDelivery {
+	var deliveries []models.WebhookDelivery
+	suite.helper.DB.Where("webhook_id = ?", webhookID).Order("created_at ASC").Find(&deliveries)
+	return deliveries
+}
+
+func (suite *WebhookTestSuite) TestWebhookCRUD() {
+	created := suite.createWebhook("https://example.com/hook", []string{webhooks.EventJobCompleted})
+	assert.NotEmpty(suite.T(), created.ID)
+	assert.Contains(suite.T(), created.Secret, "whsec_")
+	assert.Equal(suite.T(), suite.helper.TestUser.ID, created.UserID)
+
+	// The secret is only returned on creation
+	w := suite.request("GET", "/api/v1/webhooks/"+created.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.NotContains(suite.T(), w.Body.String(), created.Secret)
+
+	w = suite.request("PUT", "/api/v1/webhooks/"+created.ID, map[string]interface{}{
+		"url":       "https://example.com/other",
+		"events":    []string{webhooks.EventJobFailed},
+		"is_active": false,
+	})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var updated models.Webhook
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(suite.T(), "https://example.com/other", updated.URL)
+	assert.Equal(suite.T(), []string{webhooks.EventJobFailed}, updated.Events)
+	assert.False(suite.T(), updated.IsActive)
+
+	w = suite.request("GET", "/api/v1/webhooks/", nil)
+	var hooks []models.Webhook
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &hooks))
+	assert.Len(suite.T(), hooks, 1)
+
+	w = suite.request("DELETE", "/api/v1/webhooks/"+created.ID, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	w = suite.request("GET", "/api/v1/webhooks/"+created.ID, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *WebhookTestSuite) TestCreateWebhookValidation() {
+	w := suite.request("POST", "/api/v1/webhooks/", map[string]interface{}{"url": "ftp://example.com"})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+
+	w = suite.request("POST", "/api/v1/webhooks/", map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"events": []string{"job.exploded"},
+	})
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+}
+
+func (suite *WebhookTestSuite) TestCreateInactiveWebhook() {
+	w := suite.request("POST", "/api/v1/webhooks/", map[string]interface{}{
+		"url":       "https://example.com/hook",
+		"is_active": false,
+	})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+	var created api.CreateWebhookResponse
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &created))
+	assert.False(suite.T(), created.IsActive)
+
+	w = suite.request("GET", "/api/v1/webhooks/"+created.ID, nil)
+	var stored models.Webhook
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &stored))
+	assert.False(suite.T(), stored.IsActive)
+
+	// Inactive webhooks receive nothing
+	webhooks.Emit(webhooks.EventJobCompleted, suite.helper.TestUser.ID, nil)
+	assert.Len(suite.T(), suite.deliveries(created.ID), 0)
+}
+
+func (suite *WebhookTestSuite) TestRejectsPrivateTargets() {
+	suite.T().Setenv("WEBHOOK_ALLOW_PRIVATE_TARGETS", "")
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://api.localhost/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.20/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fe80::1]/hook",
+	} {
+		w := suite.request("POST", "/api/v1/webhooks/", map[string]interface{}{"url": url})
+		assert.Equal(suite.T(), http.StatusBadRequest, w.Code, url)
+		assert.Contains(suite.T(), w.Body.String(), "private", url)
+	}
+
+	// Host names resolving into the internal network are refused when connecting
+	receiver := newWebhookReceiver(http.StatusOK)
+	defer receiver.server.Close()
+	hook := models.Webhook{UserID: suite.helper.TestUser.ID, URL: receiver.server.URL, Secret: "s", IsActive: true}
+	assert.NoError(suite.T(), suite.helper.DB.Create(&hook).Error)
+	webhooks.Emit(webhooks.EventJobCompleted, suite.helper.TestUser.ID, nil)
+
+	dispatcher := webhooks.NewDispatcher()
+	dispatcher.MaxAttempts = 1
+	assert.Equal(suite.T(), 1, dispatcher.ProcessDue())
+	assert.Len(suite.T(), receiver.requests, 0)
+	deliveries := suite.deliveries(hook.ID)
+	if assert.Len(suite.T(), deliveries, 1) && assert.NotNil(suite.T(), deliveries[0].Error) {
+		assert.Equal(suite.T(), models.DeliveryFailed, deliveries[0].Status)
+		assert.Contains(suite.T(), *deliveries[0].Error, "private")
+	}
+}
+
+func (suite *WebhookTestSuite) TestEmitOnlyQueuesSubscribedWebhooks() {
+	completed := suite.createWebhook("https://example.com/completed", []string{webhooks.EventJobCompleted})
+	all := suite.createWebhook("https://example.com/all", nil)
+
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Webhook job")
+	webhooks.EmitJobEvent(webhooks.EventJobFailed, job.ID)
+
+	assert.Len(suite.T(), suite.deliveries(completed.ID), 0)
+	allDeliveries := suite.deliveries(all.ID)
+	if assert.Len(suite.T(), allDeliveries, 1) {
+		assert.Equal(suite.T(), webhooks.EventJobFailed, allDeliveries[0].Event)
+		assert.Equal(suite.T(), models.DeliveryPending, allDeliveries[0].Status)
+
+		var payload struct {
+			Event string           `json:"event"`
+			Data  webhooks.JobData `json:"data"`
+		}
+		assert.NoError(suite.T(), json.Unmarshal([]byte(allDeliveries[0].Payload), &payload))
+		assert.Equal(suite.T(), webhooks.EventJobFailed, payload.Event)
+		assert.Equal(suite.T(), job.ID, payload.Data.JobID)
+	}
+
+	// Other users' events never reach this user's webhooks
+	webhooks.Emit(webhooks.EventJobCompleted, suite.helper.TestUser.ID+1, nil)
+	assert.Len(suite.T(), suite.deliveries(completed.ID), 0)
+}
+
+func (suite *WebhookTestSuite) TestDeliverySignedAndRecorded() {
+	receiver := newWebhookReceiver(http.StatusOK)
+	defer receiver.server.Close()
+
+	created := suite.createWebhook(receiver.server.URL, nil)
+	job := suite.helper.CreateTestTranscriptionJob(suite.T(), "Signed job")
+	webhooks.EmitJobEvent(webhooks.EventJobCompleted, job.ID)
+
+	dispatcher := webhooks.NewDispatcher()
+	assert.Equal(suite.T(), 1, dispatcher.ProcessDue())
+
+	if assert.Len(suite.T(), receiver.requests, 1) {
+		req := receiver.requests[0]
+		assert.Equal(suite.T(), webhooks.EventJobCompleted, req.header.Get(webhooks.HeaderEvent))
+		timestamp, err := strconv.ParseInt(req.header.Get(webhooks.HeaderTimestamp), 10, 64)
+		assert.NoError(suite.T(), err)
+		assert.True(suite.T(), webhooks.Verify(created.Secret, timestamp, req.body, req.header.Get(webhooks.HeaderSignature)))
+		assert.False(suite.T(), webhooks.Verify("wrong-secret", timestamp, req.body, req.header.Get(webhooks.HeaderSignature)))
+	}
+
+	deliveries := suite.deliveries(created.ID)
+	if assert.Len(suite.T(), deliveries, 1) {
+		assert.Equal(suite.T(), models.DeliverySucceeded, deliveries[0].Status)
+		assert.Equal(suite.T(), 1, deliveries[0].Attempts)
+		assert.Equal(suite.T(), http.StatusOK, *deliveries[0].ResponseStatus)
+		assert.NotNil(suite.T(), deliveries[0].DeliveredAt)
+	}
+}
+
+func (suite *WebhookTestSuite) TestFailedDeliveryRetriesWithBackoff() {
+	receiver := newWebhookReceiver(http.StatusInternalServerError)
+	defer receiver.server.Close()
+
+	created := suite.createWebhook(receiver.server.URL, nil)
+	webhooks.Emit(webhooks.EventSummaryCompleted, suite.helper.TestUser.ID, map[string]string{"summary_id": "s1"})
+
+	dispatcher := webhooks.NewDispatcher()
+	dispatcher.MaxAttempts = 3
+	dispatcher.BaseDelay = time.Minute
+
+	before := time.Now()
+	assert.Equal(suite.T(), 1, dispatcher.ProcessDue())
+	delivery := suite.deliveries(created.ID)[0]
+	assert.Equal(suite.T(), models.DeliveryPending, delivery.Status)
+	assert.Equal(suite.T(), 1, delivery.Attempts)
+	assert.Equal(suite.T(), http.StatusInternalServerError, *delivery.ResponseStatus)
+	assert.WithinDuration(suite.T(), before.Add(time.Minute), *delivery.NextAttemptAt, 5*time.Second)
+
+	// Not due yet
+	assert.Equal(suite.T(), 0, dispatcher.ProcessDue())
+
+	assert.Equal(suite.T(), time.Minute, dispatcher.Backoff(1))
+	assert.Equal(suite.T(), 2*time.Minute, dispatcher.Backoff(2))
+	assert.Equal(suite.T(), 4*time.Minute, dispatcher.Backoff(3))
+	assert.Equal(suite.T(), time.Hour, dispatcher.Backoff(20))
+
+	// Make the remaining attempts due and exhaust them
+	for i := 0; i < 2; i++ {
+		suite.helper.DB.Model(&models.WebhookDelivery{}).Where("id = ?", delivery.ID).Update("next_attempt_at", time.Now().Add(-time.Second))
+		assert.Equal(suite.T(), 1, dispatcher.ProcessDue())
+	}
+	delivery = suite.deliveries(created.ID)[0]
+	assert.Equal(suite.T(), models.DeliveryFailed, delivery.Status)
+	assert.Equal(suite.T(), 3, delivery.Attempts)
+	assert.Nil(suite.T(), delivery.NextAttemptAt)
+	assert.Len(suite.T(), receiver.requests, 3)
+}
+
+func (suite *WebhookTestSuite) TestReplayDelivery() {
+	receiver := newWebhookReceiver(http.StatusBadGateway)
+	defer receiver.server.Close()
+
+	created := suite.createWebhook(receiver.server.URL, nil)
+	webhooks.Emit(webhooks.EventJobCompleted, suite.helper.TestUser.ID, map[string]string{"job_id": "j1"})
+
+	dispatcher := webhooks.NewDispatcher()
+	dispatcher.MaxAttempts = 1
+	dispatcher.ProcessDue()
+	original := suite.deliveries(created.ID)[0]
+	assert.Equal(suite.T(), models.DeliveryFailed, original.Status)
+
+	w := suite.request("GET", "/api/v1/webhooks/"+created.ID+"/deliveries?status=failed", nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	var listed map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Len(suite.T(), listed["deliveries"].([]interface{}), 1)
+
+	receiver.mu.Lock()
+	receiver.status = http.StatusNoContent
+	receiver.mu.Unlock()
+
+	w = suite.request("POST", "/api/v1/webhooks/"+created.ID+"/deliveries/"+original.ID+"/replay", nil)
+	assert.Equal(suite.T(), http.StatusAccepted, w.Code)
+	var replay models.WebhookDelivery
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &replay))
+	assert.Equal(suite.T(), original.ID, *replay.ReplayOf)
+	assert.Equal(suite.T(), original.Payload, replay.Payload)
+
+	assert.Equal(suite.T(), 1, dispatcher.ProcessDue())
+	assert.Len(suite.T(), receiver.requests, 2)
+	assert.Equal(suite.T(), receiver.requests[0].body, receiver.requests[1].body)
+
+	w = suite.request("POST", "/api/v1/webhooks/"+created.ID+"/deliveries/missing/replay", nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func TestWebhookTestSuite(t *testing.T) {
+	suite.Run(t, new(WebhookTestSuite))
+}
@@ -0,0 +1,223 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"synthezia/internal/api"
+	"synthezia/internal/transcription/interfaces"
+	"synthezia/internal/whisperapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+)
+
+type WhisperAPITestSuite struct {
+	suite.Suite
+	helper *TestHelper
+	router *gin.Engine
+}
+
+func (suite *WhisperAPITestSuite) SetupSuite() {
+	suite.helper = NewTestHelper(suite.T(), "whisperapi_test.db")
+	handler := api.NewHandler(suite.helper.Config, suite.helper.AuthService, nil, nil, nil, nil)
+	suite.router = api.SetupRoutes(handler, suite.helper.AuthService)
+}
+
+func (suite *WhisperAPITestSuite) TearDownSuite() {
+	suite.helper.Cleanup()
+}
+
+// audioRequest posts a multipart form to an OpenAI-compatible endpoint
+func (suite *WhisperAPITestSuite) audioRequest(path, auth string, fields map[string]string, withFile bool) *httptest.ResponseRecorder {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for k, v := range fields {
+		writer.WriteField(k, v)
+	}
+	if withFile {
+		part, _ := writer.CreateFormFile("file", "clip.wav")
+		part.Write([]byte("RIFF0000WAVEfmt "))
+	}
+	writer.Close()
+
+	req, _ := http.NewRequest("POST", path, body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// errorParam extracts error.param from an OpenAI-style error body
+func (suite *WhisperAPITestSuite) errorParam(w *httptest.ResponseRecorder) interface{} {
+	var body map[string]map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &body))
+	return body["error"]["param"]
+}
+
+func sampleWhisperResult() *interfaces.TranscriptResult {
+	return &interfaces.TranscriptResult{
+		Language: "en",
+		Segments: []interfaces.TranscriptSegment{
+			{Start: 0, End: 1.5, Text: " Hello there."},
+			{Start: 1.5, End: 3.25, Text: " General Kenobi."},
+		},
+		WordSegments: []interfaces.TranscriptWord{
+			{Start: 0, End: 0.5, Word: "Hello"},
+			{Start: 0.6, End: 1.5, Word: "there."},
+			{Start: 1.5, End: 2.2, Word: "General"},
+			{Start: 2.3, End: 3.25, Word: "Kenobi."},
+		},
+	}
+}
+
+func (suite *WhisperAPITestSuite) TestModelParams() {
+	params, ok := whisperapi.ModelParams("whisper-1")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "whisper", params.ModelFamily)
+
+	params, ok = whisperapi.ModelParams("whisper-large-v3")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "large-v3", params.Model)
+
+	params, ok = whisperapi.ModelParams("parakeet")
+	assert.True(suite.T(), ok)
+	assert.Equal(suite.T(), "nvidia_parakeet", params.ModelFamily)
+	assert.False(suite.T(), whisperapi.SupportsTranslation(params))
+
+	_, ok = whisperapi.ModelParams("gpt-4o")
+	assert.False(suite.T(), ok)
+}
+
+func (suite *WhisperAPITestSuite) TestApplyRequest() {
+	temperature := 0.4
+	params := whisperapi.ApplyRequest(whisperapi.DefaultParams(), whisperapi.Request{
+		Language:    "de",
+		Prompt:      "Technical vocabulary",
+		Temperature: &temperature,
+	})
+	assert.Equal(suite.T(), "transcribe", params.Task)
+	assert.Equal(suite.T(), "de", *params.Language)
+	assert.Equal(suite.T(), "Technical vocabulary", *params.InitialPrompt)
+	assert.Equal(suite.T(), 0.4, params.Temperature)
+	assert.False(suite.T(), params.Diarize)
+
+	params = whisperapi.ApplyRequest(whisperapi.DefaultParams(), whisperapi.Request{Translate: true})
+	assert.Equal(suite.T(), "translate", params.Task)
+	assert.Nil(suite.T(), params.Language)
+}
+
+func (suite *WhisperAPITestSuite) TestRenderFormats() {
+	result := sampleWhisperResult()
+
+	out, err := whisperapi.Render(result, whisperapi.Request{ResponseFormat: whisperapi.FormatJSON})
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"text":"Hello there. General Kenobi."}`, string(out.Data))
+
+	out, err = whisperapi.Render(result, whisperapi.Request{ResponseFormat: whisperapi.FormatText})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Hello there. General Kenobi.\n", string(out.Data))
+	assert.Contains(suite.T(), out.ContentType, "text/plain")
+
+	out, err = whisperapi.Render(result, whisperapi.Request{ResponseFormat: whisperapi.FormatSRT})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(string(out.Data), "1\n00:00:00,000 --> 00:00:01,500\nHello there."))
+
+	out, err = whisperapi.Render(result, whisperapi.Request{ResponseFormat: whisperapi.FormatVTT})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(string(out.Data), "WEBVTT"))
+}
+
+func (suite *WhisperAPITestSuite) TestRenderVerboseJSON() {
+	result := sampleWhisperResult()
+	temperature := 0.2
+
+	out, err := whisperapi.Render(result, whisperapi.Request{ResponseFormat: whisperapi.FormatVerboseJSON, Temperature: &temperature})
+	assert.NoError(suite.T(), err)
+	var body map[string]interface{}
+	assert.NoError(suite.T(), json.Unmarshal(out.Data, &body))
+	assert.Equal(suite.T(), "transcribe", body["task"])
+	assert.Equal(suite.T(), "en", body["language"])
+	assert.Equal(suite.T(), 3.25, body["duration"])
+	segments := body["segments"].([]interface{})
+	assert.Len(suite.T(), segments, 2)
+	assert.Equal(suite.T(), 0.2, segments[0].(map[string]interface{})["temperature"])
+	assert.NotContains(suite.T(), body, "words")
+
+	// Only words were requested
+	out, err = whisperapi.Render(result, whisperapi.Request{
+		ResponseFormat:         whisperapi.FormatVerboseJSON,
+		TimestampGranularities: []string{whisperapi.GranularityWord},
+	})
+	assert.NoError(suite.T(), err)
+	body = map[string]interface{}{}
+	assert.NoError(suite.T(), json.Unmarshal(out.Data, &body))
+	assert.NotContains(suite.T(), body, "segments")
+	words := body["words"].([]interface{})
+	assert.Len(suite.T(), words, 4)
+	assert.Equal(suite.T(), "General", words[2].(map[string]interface{})["word"])
+}
+
+func (suite *WhisperAPITestSuite) TestBearerAPIKeyRequired() {
+	fields := map[string]string{"model": "whisper-1"}
+
+	w := suite.audioRequest("/v1/audio/transcriptions", "", fields, true)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	w = suite.audioRequest("/v1/audio/transcriptions", "Bearer not-a-key", fields, true)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	// JWTs are not API keys
+	w = suite.audioRequest("/v1/audio/transcriptions", "Bearer "+suite.helper.TestToken, fields, true)
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+}
+
+func (suite *WhisperAPITestSuite) TestRequestValidation() {
+	auth := "Bearer " + suite.helper.TestAPIKey
+
+	w := suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "model", suite.errorParam(w))
+
+	w = suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{"model": "whisper-1"}, false)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "file", suite.errorParam(w))
+
+	w = suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{"model": "whisper-1", "response_format": "xml"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "response_format", suite.errorParam(w))
+
+	w = suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{"model": "whisper-1", "temperature": "3"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "temperature", suite.errorParam(w))
+
+	w = suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{"model": "gpt-4o"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "model", suite.errorParam(w))
+
+	w = suite.audioRequest("/v1/audio/translations", auth, map[string]string{"model": "canary"}, true)
+	assert.Equal(suite.T(), http.StatusBadRequest, w.Code)
+	assert.Equal(suite.T(), "model", suite.errorParam(w))
+}
+
+func (suite *WhisperAPITestSuite) TestProfileNameAsModel() {
+	suite.helper.CreateTestProfile(suite.T(), "meetings", false)
+	auth := "Bearer " + suite.helper.TestAPIKey
+
+	// The profile resolves, so the request gets as far as the (unavailable) transcription service
+	w := suite.audioRequest("/v1/audio/transcriptions", auth, map[string]string{"model": "meetings"}, true)
+	assert.Equal(suite.T(), http.StatusServiceUnavailable, w.Code)
+}
+
+func TestWhisperAPITestSuite(t *testing.T) {
+	suite.Run(t, new(WhisperAPITestSuite))
+}